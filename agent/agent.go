@@ -152,10 +152,13 @@ type Agent struct {
 	header             http.Header
 	inProgress         map[string]*corev2.CheckConfig
 	inProgressMu       *sync.Mutex
+	localChecks        []*corev2.CheckConfig
 	localEntityConfig  *corev3.EntityConfig
+	otlp               *otlpReceiver
 	statsdServer       StatsdServer
 	sendq              chan *transport.Message
 	systemInfo         *corev2.System
+	processDetails     string
 	systemInfoMu       sync.RWMutex
 	wg                 sync.WaitGroup
 	apiQueue           queue
@@ -186,7 +189,6 @@ func NewAgentContext(ctx context.Context, config *Config) (*Agent, error) {
 		return nil, errors.New("keepalive warning timeout must be greater than keepalive interval")
 	}
 	agent := &Agent{
-		backendSelector:  &RandomBackendSelector{Backends: config.BackendURLs},
 		connected:        false,
 		config:           config,
 		executor:         command.NewExecutor(),
@@ -203,6 +205,24 @@ func NewAgentContext(ctx context.Context, config *Config) (*Agent, error) {
 		maxSessionLength: config.MaxSessionLength,
 	}
 
+	if config.CollectProcesses {
+		getter, err := process.NewGetter(process.Config{
+			Include:      config.ProcessesInclude,
+			Exclude:      config.ProcessesExclude,
+			MaxProcesses: config.ProcessesMaxCount,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating agent: %s", err)
+		}
+		agent.ProcessGetter = getter
+	}
+
+	selector, err := newBackendSelector(config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating agent: %s", err)
+	}
+	agent.backendSelector = selector
+
 	agent.statsdServer = NewStatsdServer(agent)
 	agent.handler.AddHandler(transport.MessageTypeEntityConfig, agent.handleEntityConfig)
 
@@ -214,7 +234,6 @@ func NewAgentContext(ctx context.Context, config *Config) (*Agent, error) {
 	if err := systemInfoCtx.Err(); err != nil {
 		logger.WithError(err).Error("couldn't refresh all system information within deadline")
 	}
-	var err error
 	agent.apiQueue, err = newQueue(config.CacheDir)
 	if err != nil {
 		return nil, fmt.Errorf("error creating agent: %s", err)
@@ -226,6 +245,16 @@ func NewAgentContext(ctx context.Context, config *Config) (*Agent, error) {
 	}
 	agent.allowList = allowList
 
+	localChecks, err := loadLocalChecks(config.LocalChecksDir, config.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	agent.localChecks = localChecks
+
+	if config.OTLP != nil && !config.OTLP.Disable {
+		agent.otlp = newOTLPReceiver(agent)
+	}
+
 	if config.PrometheusBinding != "" {
 		go func() {
 			logger.WithError(http.ListenAndServe(config.PrometheusBinding, promhttp.Handler())).Error("couldn't serve prometheus metrics")
@@ -258,11 +287,23 @@ func (a *Agent) RefreshSystemInfo(ctx context.Context) error {
 		info.CloudProvider = system.GetCloudProvider(ctx)
 	}
 
-	proccessInfo, err := a.ProcessGetter.Get(ctx)
-	info.Processes = proccessInfo
+	// The details of the processes are only known to listers, and are
+	// reported in an annotation of the entity
+	var processDetails string
+	if lister, ok := a.ProcessGetter.(process.Lister); ok {
+		var infos []process.Info
+		infos, err = lister.List(ctx)
+		info.Processes = process.ToCoreProcesses(infos)
+		if len(infos) > 0 {
+			processDetails, err = process.Annotation(infos)
+		}
+	} else {
+		info.Processes, err = a.ProcessGetter.Get(ctx)
+	}
 
 	a.systemInfoMu.Lock()
 	a.systemInfo = &info
+	a.processDetails = processDetails
 	a.systemInfoMu.Unlock()
 
 	return err
@@ -394,10 +435,27 @@ func (a *Agent) Run(ctx context.Context) error {
 		a.StartStatsd(ctx)
 	}
 
+	if a.config.Socket != nil && a.config.Socket.Enable {
+		if err := a.StartSocketListeners(ctx); err != nil {
+			return err
+		}
+	}
+
 	if !a.config.DisableAPI {
 		a.StartAPI(ctx)
+
+		// The OTLP metrics are received by the API
+		if a.otlp != nil {
+			a.wg.Add(1)
+			go func() {
+				defer a.wg.Done()
+				a.otlp.Run(ctx)
+			}()
+		}
 	}
 
+	a.startLocalChecks(ctx)
+
 	// Increment the waitgroup counter here too in case none of the components
 	// above were started, and rely on the system info collector to decrement it
 	// once it exits
@@ -454,7 +512,7 @@ func (a *Agent) connectionManager(ctx context.Context, cancel context.CancelFunc
 
 		a.clearAgentEntity()
 
-		conn, err := a.connectWithBackoff(ctx)
+		conn, backendURL, err := a.connectWithBackoff(ctx)
 		if err != nil {
 			if err == ctx.Err() {
 				return
@@ -476,6 +534,7 @@ func (a *Agent) connectionManager(ctx context.Context, cancel context.CancelFunc
 		newConnections.WithLabelValues().Inc()
 
 		go a.enforceMaxSessionLength(connCancel)
+		go a.failback(connCtx, connCancel, backendURL)
 		go a.receiveLoop(connCtx, connCancel, conn)
 
 		// Block until we receive an entity config, or the grace period expires,
@@ -530,6 +589,31 @@ func (a *Agent) enforceMaxSessionLength(connCancel context.CancelFunc) {
 	}
 }
 
+// failback periodically checks if a backend preferred over the current backend
+// is reachable, and cancels the connection's context if so, to make the agent
+// reconnect to it. It only applies to the backend selectors that prefer some
+// backends over others.
+func (a *Agent) failback(ctx context.Context, connCancel context.CancelFunc, current string) {
+	selector, ok := a.backendSelector.(FailbackBackendSelector)
+	if !ok || a.config.BackendFailbackInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(a.config.BackendFailbackInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if backend := selector.Failback(current); backend != "" {
+				logger.Infof("failing back from backend URL %q to preferred backend URL %q", current, backend)
+				connCancel()
+				return
+			}
+		}
+	}
+}
+
 func (a *Agent) receiveLoop(ctx context.Context, cancel context.CancelFunc, conn transport.Transport) {
 	defer cancel()
 	for {
@@ -722,8 +806,10 @@ func (a *Agent) StartStatsd(ctx context.Context) {
 	}()
 }
 
-func (a *Agent) connectWithBackoff(ctx context.Context) (transport.Transport, error) {
+func (a *Agent) connectWithBackoff(ctx context.Context) (transport.Transport, string, error) {
 	var conn transport.Transport
+	var backendURL string
+	selector, reports := a.backendSelector.(FailbackBackendSelector)
 
 	backoff := retry.ExponentialBackoff{
 		InitialDelayInterval: a.config.RetryMin,
@@ -733,12 +819,15 @@ func (a *Agent) connectWithBackoff(ctx context.Context) (transport.Transport, er
 	}
 
 	err := backoff.Retry(func(retry int) (bool, error) {
-		backendURL := a.backendSelector.Select()
+		backendURL = a.backendSelector.Select()
 
 		logger.Infof("connecting to backend URL %q", backendURL)
 		a.header.Set("Accept", ProtobufSerializationHeader)
 		logger.WithField("header", fmt.Sprintf("Accept: %s", ProtobufSerializationHeader)).Debug("setting header")
 		c, respHeader, err := transport.Connect(backendURL, a.config.TLS, a.header, a.config.BackendHandshakeTimeout)
+		if reports {
+			selector.Report(backendURL, err)
+		}
 		if err != nil {
 			if err == transport.ErrTooManyRequests {
 				// Give the backend extra breathing room
@@ -778,7 +867,7 @@ func (a *Agent) connectWithBackoff(ctx context.Context) (transport.Transport, er
 		return true, nil
 	})
 
-	return conn, err
+	return conn, backendURL, err
 }
 
 // GracefulShutdown listens for the SIGINT & SIGTERM signals and cancel the
@@ -49,6 +49,9 @@ func registerRoutes(a *Agent, r *mux.Router) {
 	r.HandleFunc("/healthz", healthz(a.Connected)).Methods(http.MethodGet)
 	r.HandleFunc("/version", versionShow()).Methods(http.MethodGet)
 	r.Handle("/metrics", promhttp.Handler())
+	if a.otlp != nil {
+		r.Handle(OTLPMetricsPath, a.otlp).Methods(http.MethodPost)
+	}
 }
 
 // healthz returns an OK status if the agent is up and connected to a backend.
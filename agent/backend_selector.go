@@ -1,8 +1,15 @@
 package agent
 
 import (
+	"crypto/tls"
+	"fmt"
 	"math/rand"
+	"net"
+	"net/url"
+	"sync"
 	"time"
+
+	corev2 "github.com/sensu/core/v2"
 )
 
 // A BackendSelector is repsonsible for selecting an appropriate backend from
@@ -44,3 +51,314 @@ func (b *RandomBackendSelector) Select() string {
 
 	return b.Backends[next]
 }
+
+const (
+	// RandomBackendSelection selects the backends in a random order.
+	RandomBackendSelection = "random"
+
+	// PriorityBackendSelection selects the backends by priority group.
+	PriorityBackendSelection = "priority"
+
+	// LatencyBackendSelection selects the backends with the lowest latency.
+	LatencyBackendSelection = "latency"
+
+	// DefaultLatencyTolerance is the default fraction by which the latency of
+	// a backend must be lower than the latency of the current backend for the
+	// LatencyBackendSelector to prefer it.
+	DefaultLatencyTolerance = 0.2
+)
+
+// A FailbackBackendSelector is a BackendSelector that prefers some backends
+// over others. It is told the outcome of the connections to the backends it
+// selects, so it can fail over to less preferred backends, and it lets the
+// agent return to a preferred backend once it is reachable again.
+type FailbackBackendSelector interface {
+	BackendSelector
+
+	// Report records the outcome of a connection to a backend. A nil error
+	// means the backend is reachable.
+	Report(backend string, err error)
+
+	// Failback probes the backends preferred over the current backend, and
+	// returns the one the agent should reconnect to, or an empty string if
+	// the agent should stay connected to the current backend.
+	Failback(current string) string
+}
+
+// A ProbeFunc measures the time it takes to establish a connection with a
+// backend, without connecting the agent to it.
+type ProbeFunc func(backend string) (time.Duration, error)
+
+// A PriorityBackendSelector selects the backends of the first priority group
+// that has reachable backends. The backends of a group are selected in a
+// random order. The selector fails over to the next group only when every
+// backend of the current group is unreachable.
+type PriorityBackendSelector struct {
+	// Groups are the groups of backend URLs, most preferred first.
+	Groups [][]string
+
+	// Probe checks if a backend is reachable before failing back to it.
+	Probe ProbeFunc
+
+	mu          sync.Mutex
+	selectors   []*RandomBackendSelector
+	unreachable map[string]bool
+}
+
+// Select returns the next backend of the first group that has reachable
+// backends. If every backend is unreachable, the selector starts over with the
+// first group.
+func (p *PriorityBackendSelector) Select() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.selectors == nil {
+		p.unreachable = make(map[string]bool)
+		for _, group := range p.Groups {
+			p.selectors = append(p.selectors, &RandomBackendSelector{Backends: group})
+		}
+	}
+	for i := 0; i < 2; i++ {
+		for j, group := range p.Groups {
+			for range group {
+				if backend := p.selectors[j].Select(); !p.unreachable[backend] {
+					return backend
+				}
+			}
+		}
+		// Every backend is unreachable, try them all again
+		p.unreachable = make(map[string]bool)
+	}
+	return ""
+}
+
+// Report marks a backend as reachable or unreachable.
+func (p *PriorityBackendSelector) Report(backend string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.unreachable == nil {
+		p.unreachable = make(map[string]bool)
+	}
+	if err != nil {
+		p.unreachable[backend] = true
+	} else {
+		delete(p.unreachable, backend)
+	}
+}
+
+// Failback probes the backends of the groups preferred over the group of the
+// current backend, and returns the first reachable one.
+func (p *PriorityBackendSelector) Failback(current string) string {
+	if p.Probe == nil {
+		return ""
+	}
+	for _, group := range p.Groups {
+		for _, backend := range group {
+			if backend == current {
+				return ""
+			}
+		}
+		for _, backend := range group {
+			_, err := p.Probe(backend)
+			p.Report(backend, err)
+			if err == nil {
+				return backend
+			}
+		}
+	}
+	return ""
+}
+
+// A LatencyBackendSelector selects the reachable backend with the lowest
+// latency, as measured by its probe.
+type LatencyBackendSelector struct {
+	// Backends is the list of backend URLs to select from.
+	Backends []string
+
+	// Probe measures the latency of the backends.
+	Probe ProbeFunc
+
+	// Tolerance is the fraction by which the latency of a backend must be
+	// lower than the latency of the current backend for the agent to fail
+	// back to it. DefaultLatencyTolerance is used if it is zero.
+	Tolerance float64
+
+	mu          sync.Mutex
+	latencies   map[string]time.Duration
+	unreachable map[string]bool
+	fallback    RandomBackendSelector
+}
+
+// Select returns the reachable backend with the lowest latency. The backends
+// are probed the first time a backend is selected, and once every backend has
+// been found unreachable. If no backend can be probed, the backends are
+// returned in a random order.
+func (l *LatencyBackendSelector) Select() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.latencies == nil || len(l.unreachable) >= len(l.Backends) {
+		l.probeAll()
+	}
+	if backend := l.fastest(); backend != "" {
+		return backend
+	}
+	l.fallback.Backends = l.Backends
+	return l.fallback.Select()
+}
+
+// probeAll measures the latency of every backend concurrently. It must be
+// called with the lock held.
+func (l *LatencyBackendSelector) probeAll() {
+	l.latencies = make(map[string]time.Duration, len(l.Backends))
+	l.unreachable = make(map[string]bool)
+	if l.Probe == nil {
+		return
+	}
+	type result struct {
+		backend string
+		latency time.Duration
+		err     error
+	}
+	results := make(chan result, len(l.Backends))
+	for _, backend := range l.Backends {
+		go func(backend string) {
+			latency, err := l.Probe(backend)
+			results <- result{backend: backend, latency: latency, err: err}
+		}(backend)
+	}
+	for range l.Backends {
+		r := <-results
+		if r.err != nil {
+			l.unreachable[r.backend] = true
+			continue
+		}
+		l.latencies[r.backend] = r.latency
+	}
+}
+
+// fastest returns the reachable backend with the lowest latency. It must be
+// called with the lock held.
+func (l *LatencyBackendSelector) fastest() string {
+	var fastest string
+	for _, backend := range l.Backends {
+		latency, ok := l.latencies[backend]
+		if !ok || l.unreachable[backend] {
+			continue
+		}
+		if fastest == "" || latency < l.latencies[fastest] {
+			fastest = backend
+		}
+	}
+	return fastest
+}
+
+// Report marks a backend as reachable or unreachable.
+func (l *LatencyBackendSelector) Report(backend string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.unreachable == nil {
+		l.unreachable = make(map[string]bool)
+	}
+	if err != nil {
+		l.unreachable[backend] = true
+	} else {
+		delete(l.unreachable, backend)
+	}
+}
+
+// Failback probes every backend, and returns the reachable backend with the
+// lowest latency if it is lower than the latency of the current backend by
+// more than the tolerance.
+func (l *LatencyBackendSelector) Failback(current string) string {
+	if l.Probe == nil {
+		return ""
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.probeAll()
+	fastest := l.fastest()
+	if fastest == "" || fastest == current {
+		return ""
+	}
+	currentLatency, ok := l.latencies[current]
+	if !ok {
+		// The current backend could not be probed
+		return fastest
+	}
+	tolerance := l.Tolerance
+	if tolerance == 0 {
+		tolerance = DefaultLatencyTolerance
+	}
+	if float64(l.latencies[fastest]) < float64(currentLatency)*(1-tolerance) {
+		return fastest
+	}
+	return ""
+}
+
+// newBackendSelector returns the backend selector configured for the agent.
+func newBackendSelector(config *Config) (BackendSelector, error) {
+	probe := newBackendProbe(config.TLS, config.BackendHandshakeTimeout)
+	switch config.BackendSelector {
+	case "", RandomBackendSelection:
+		return &RandomBackendSelector{Backends: config.BackendURLs}, nil
+	case PriorityBackendSelection:
+		groups := config.BackendPriorityGroups
+		if len(groups) == 0 {
+			// Every backend is a group of its own, in the order they are
+			// configured
+			for _, backend := range config.BackendURLs {
+				groups = append(groups, []string{backend})
+			}
+		}
+		return &PriorityBackendSelector{Groups: groups, Probe: probe}, nil
+	case LatencyBackendSelection:
+		return &LatencyBackendSelector{Backends: config.BackendURLs, Probe: probe}, nil
+	}
+	return nil, fmt.Errorf("unknown backend selector %q", config.BackendSelector)
+}
+
+// newBackendProbe returns a ProbeFunc that measures the time it takes to open
+// a TCP connection to a backend, and to complete the TLS handshake for wss
+// backends.
+func newBackendProbe(tlsOpts *corev2.TLSOptions, handshakeTimeout int) ProbeFunc {
+	if handshakeTimeout < 1 {
+		handshakeTimeout = 15
+	}
+	timeout := time.Duration(handshakeTimeout) * time.Second
+	return func(backend string) (time.Duration, error) {
+		u, err := url.Parse(backend)
+		if err != nil {
+			return 0, err
+		}
+		host := u.Host
+		if u.Port() == "" {
+			port := "80"
+			if u.Scheme == "wss" {
+				port = "443"
+			}
+			host = net.JoinHostPort(u.Hostname(), port)
+		}
+		dialer := &net.Dialer{Timeout: timeout}
+		start := time.Now()
+		var conn net.Conn
+		if u.Scheme == "wss" {
+			tlsConfig := &tls.Config{}
+			if tlsOpts != nil {
+				if tlsConfig, err = tlsOpts.ToClientTLSConfig(); err != nil {
+					return 0, err
+				}
+			}
+			if tlsConfig.ServerName == "" {
+				tlsConfig.ServerName = u.Hostname()
+			}
+			conn, err = tls.DialWithDialer(dialer, "tcp", host, tlsConfig)
+		} else {
+			conn, err = dialer.Dial("tcp", host)
+		}
+		if err != nil {
+			return 0, err
+		}
+		latency := time.Since(start)
+		_ = conn.Close()
+		return latency, nil
+	}
+}
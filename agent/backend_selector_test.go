@@ -1,10 +1,14 @@
 package agent
 
 import (
+	"errors"
+	"net"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBackendSelector(t *testing.T) {
@@ -27,3 +31,134 @@ func TestEmptyBackendSelector(t *testing.T) {
 	assert.Equal(t, "", selector.Select())
 	assert.Equal(t, "", selector.Select())
 }
+
+// fakeProbe is a ProbeFunc that returns the latencies of the backends, and an
+// error for the backends it doesn't know.
+type fakeProbe map[string]time.Duration
+
+func (f fakeProbe) probe(backend string) (time.Duration, error) {
+	latency, ok := f[backend]
+	if !ok {
+		return 0, errors.New("unreachable")
+	}
+	return latency, nil
+}
+
+func TestPriorityBackendSelector(t *testing.T) {
+	probe := fakeProbe{}
+	selector := &PriorityBackendSelector{
+		Groups: [][]string{{"a1", "a2"}, {"b1"}},
+		Probe:  probe.probe,
+	}
+
+	// The backends of the first group are selected while they are reachable
+	first := selector.Select()
+	assert.Contains(t, []string{"a1", "a2"}, first)
+	selector.Report(first, errors.New("unreachable"))
+	second := selector.Select()
+	assert.Contains(t, []string{"a1", "a2"}, second)
+	assert.NotEqual(t, first, second)
+
+	// Fail over once every backend of the first group is unreachable
+	selector.Report(second, errors.New("unreachable"))
+	assert.Equal(t, "b1", selector.Select())
+	selector.Report("b1", nil)
+	assert.Equal(t, "b1", selector.Select())
+
+	// Stay on the current backend while the preferred ones are unreachable
+	assert.Equal(t, "", selector.Failback("b1"))
+	assert.Equal(t, "b1", selector.Select())
+
+	// Fail back once a preferred backend is reachable
+	probe["a2"] = time.Millisecond
+	assert.Equal(t, "a2", selector.Failback("b1"))
+	assert.Equal(t, "a2", selector.Select())
+
+	// Nothing is preferred over the first group
+	assert.Equal(t, "", selector.Failback("a2"))
+
+	// Start over when every backend is unreachable
+	for _, backend := range []string{"a1", "a2", "b1"} {
+		selector.Report(backend, errors.New("unreachable"))
+	}
+	assert.Contains(t, []string{"a1", "a2"}, selector.Select())
+}
+
+func TestLatencyBackendSelector(t *testing.T) {
+	probe := fakeProbe{"a": 30 * time.Millisecond, "b": 10 * time.Millisecond, "c": 20 * time.Millisecond}
+	selector := &LatencyBackendSelector{
+		Backends: []string{"a", "b", "c"},
+		Probe:    probe.probe,
+	}
+
+	assert.Equal(t, "b", selector.Select())
+	selector.Report("b", errors.New("unreachable"))
+	assert.Equal(t, "c", selector.Select())
+
+	// b is faster than c again
+	assert.Equal(t, "b", selector.Failback("c"))
+
+	// a is faster than b, but within the tolerance
+	probe["a"] = 9 * time.Millisecond
+	assert.Equal(t, "", selector.Failback("b"))
+
+	// c is no longer reachable
+	delete(probe, "c")
+	assert.Equal(t, "a", selector.Failback("c"))
+	assert.Equal(t, "a", selector.Select())
+}
+
+func TestLatencyBackendSelectorUnreachable(t *testing.T) {
+	selector := &LatencyBackendSelector{
+		Backends: []string{"a", "b"},
+		Probe:    fakeProbe{}.probe,
+	}
+	received := []string{selector.Select(), selector.Select()}
+	sort.Strings(received)
+	assert.Equal(t, []string{"a", "b"}, received)
+}
+
+func TestNewBackendSelector(t *testing.T) {
+	config := &Config{BackendURLs: []string{"ws://a:8081", "ws://b:8081"}}
+	selector, err := newBackendSelector(config)
+	require.NoError(t, err)
+	assert.IsType(t, &RandomBackendSelector{}, selector)
+
+	config.BackendSelector = PriorityBackendSelection
+	selector, err = newBackendSelector(config)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"ws://a:8081"}, {"ws://b:8081"}}, selector.(*PriorityBackendSelector).Groups)
+
+	config.BackendSelector = LatencyBackendSelection
+	selector, err = newBackendSelector(config)
+	require.NoError(t, err)
+	assert.IsType(t, &LatencyBackendSelector{}, selector)
+
+	config.BackendSelector = "nearest"
+	_, err = newBackendSelector(config)
+	assert.Error(t, err)
+}
+
+func TestBackendProbe(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	probe := newBackendProbe(nil, 1)
+	_, err = probe("ws://" + listener.Addr().String())
+	assert.NoError(t, err)
+
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+	_, err = probe("ws://" + addr)
+	assert.Error(t, err)
+}
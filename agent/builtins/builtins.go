@@ -0,0 +1,204 @@
+// Package builtins provides the checks that are executed by the agent itself,
+// instead of by a plugin process. A builtin check is selected by a command
+// starting with the "sensu:" prefix and the check type, followed by the
+// target and the options of the check, split and quoted like shell words, for
+// example:
+//
+//	sensu:http https://example.com/health --expect-status 200
+//	sensu:http https://example.com/health --expect-body "all good"
+//	sensu:tcp db.example.com:5432
+//	sensu:dns example.com --type MX --server 8.8.8.8
+//	sensu:cert example.com:443 --warning 30 --critical 7
+//	sensu:prometheus localhost:9100 --allow ^node_cpu
+//
+// When the target is omitted, the checks target the host named after the
+// proxy entity of the check.
+package builtins
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/spf13/pflag"
+)
+
+// Prefix is the command prefix of the builtin checks.
+const Prefix = "sensu:"
+
+// DefaultTimeout is the timeout of the builtin checks without a timeout.
+const DefaultTimeout = 10 * time.Second
+
+// The statuses of the builtin checks.
+const (
+	StatusOK       = 0
+	StatusWarning  = 1
+	StatusCritical = 2
+	StatusUnknown  = 3
+)
+
+// Result is the result of a builtin check execution.
+type Result struct {
+	// Output is the human readable output of the check.
+	Output string
+
+	// Status is the status of the check.
+	Status int
+
+	// Duration is the duration of the execution, in seconds.
+	Duration float64
+
+	// Metrics are the metrics measured by the check.
+	Metrics []*corev2.MetricPoint
+}
+
+// An execution is the context of a builtin check execution.
+type execution struct {
+	// check is the configuration of the check.
+	check *corev2.CheckConfig
+
+	// secrets are the secrets of the check, as environment variables.
+	secrets []string
+}
+
+// secret returns the value of the secret named name.
+func (e *execution) secret(name string) (string, bool) {
+	for _, secret := range e.secrets {
+		if key, value, ok := strings.Cut(secret, "="); ok && key == name {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// A probe checks its target, and returns its result without a duration.
+type probe func(ctx context.Context, target string) *Result
+
+// checks are the builtin checks by type. Each of them defines its options on
+// the flag set, and returns the probe that uses them.
+var checks = map[string]func(*pflag.FlagSet, *execution) probe{
+	"http":       httpCheck,
+	"tcp":        tcpCheck,
+	"dns":        dnsCheck,
+	"cert":       certCheck,
+	"prometheus": prometheusCheck,
+}
+
+// Types returns the sorted types of the builtin checks.
+func Types() []string {
+	types := make([]string, 0, len(checks))
+	for t := range checks {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// IsBuiltin returns true if the command selects a builtin check.
+func IsBuiltin(command string) bool {
+	return strings.HasPrefix(strings.TrimSpace(command), Prefix)
+}
+
+// Run executes the builtin check selected by the command of the check
+// configuration, within the check timeout. The secrets of the check are given
+// as environment variables. Like the check plugins, the builtin checks should
+// not be bound to the context of the check request: the timeout of the check
+// is applied to ctx.
+func Run(ctx context.Context, config *corev2.CheckConfig, secrets []string) *Result {
+	start := time.Now()
+	result := run(ctx, &execution{check: config, secrets: secrets})
+	result.Duration = time.Since(start).Seconds()
+	return result
+}
+
+func run(ctx context.Context, e *execution) *Result {
+	config := e.check
+	fields, err := shellquote.Split(config.Command)
+	if err != nil {
+		return unknown("invalid builtin check command %q: %s", config.Command, err)
+	}
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], Prefix) {
+		return unknown("%q is not a builtin check", config.Command)
+	}
+	checkType := strings.TrimPrefix(fields[0], Prefix)
+	newProbe, ok := checks[checkType]
+	if !ok {
+		return unknown("unknown builtin check type %q, must be one of: %s", checkType, strings.Join(Types(), ", "))
+	}
+
+	flagSet := pflag.NewFlagSet(fields[0], pflag.ContinueOnError)
+	flagSet.SetOutput(io.Discard)
+	probe := newProbe(flagSet, e)
+	if err := flagSet.Parse(fields[1:]); err != nil {
+		return unknown("%s: %s", fields[0], err)
+	}
+	var target string
+	switch flagSet.NArg() {
+	case 0:
+		target = config.ProxyEntityName
+	case 1:
+		target = flagSet.Arg(0)
+	default:
+		return unknown("%s: too many arguments: %s", fields[0], strings.Join(flagSet.Args(), " "))
+	}
+	if target == "" {
+		return unknown("%s: no target given", fields[0])
+	}
+
+	timeout := DefaultTimeout
+	if config.Timeout > 0 {
+		timeout = time.Duration(config.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return probe(ctx, target)
+}
+
+func result(status int, format string, args ...interface{}) *Result {
+	return &Result{Status: status, Output: fmt.Sprintf(format, args...) + "\n"}
+}
+
+func unknown(format string, args ...interface{}) *Result {
+	return result(StatusUnknown, format, args...)
+}
+
+// metric returns a metric point measured by the check, tagged with the given
+// tag names and values, and with the output metric tags of the check.
+func (e *execution) metric(name string, value float64, now time.Time, tags ...string) *corev2.MetricPoint {
+	point := &corev2.MetricPoint{
+		Name:      name,
+		Value:     value,
+		Timestamp: now.Unix(),
+		Tags:      []*corev2.MetricTag{},
+	}
+	for i := 0; i+1 < len(tags); i += 2 {
+		point.Tags = append(point.Tags, &corev2.MetricTag{Name: tags[i], Value: tags[i+1]})
+	}
+	point.Tags = append(point.Tags, e.check.OutputMetricTags...)
+	return point
+}
+
+func statusName(status int) string {
+	switch status {
+	case StatusOK:
+		return "OK"
+	case StatusWarning:
+		return "WARNING"
+	case StatusCritical:
+		return "CRITICAL"
+	}
+	return "UNKNOWN"
+}
+
+// details formats the problems found by a check.
+func details(problems []string) string {
+	if len(problems) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(problems, ", ") + ")"
+}
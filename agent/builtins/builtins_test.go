@@ -0,0 +1,179 @@
+package builtins
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runCommand(command string) *Result {
+	check := corev2.FixtureCheckConfig("check")
+	check.Command = command
+	return Run(context.Background(), check, nil)
+}
+
+func TestIsBuiltin(t *testing.T) {
+	assert.True(t, IsBuiltin("sensu:http https://example.com"))
+	assert.True(t, IsBuiltin(" sensu:tcp localhost:22"))
+	assert.False(t, IsBuiltin("check-http https://example.com"))
+}
+
+func TestRunInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+	}{
+		{name: "unknown type", command: "sensu:ftp example.com"},
+		{name: "unknown flag", command: "sensu:tcp localhost:22 --foo"},
+		{name: "no target", command: "sensu:tcp"},
+		{name: "too many targets", command: "sensu:tcp localhost:22 localhost:23"},
+		{name: "no port", command: "sensu:tcp localhost"},
+		{name: "unsupported record type", command: "sensu:dns localhost --type SRV"},
+		{name: "unterminated quote", command: "sensu:http localhost --expect-body 'foo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := runCommand(tt.command)
+			assert.Equal(t, StatusUnknown, result.Status, result.Output)
+		})
+	}
+}
+
+func TestRunProxyEntity(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	check := corev2.FixtureCheckConfig("check")
+	check.Command = "sensu:tcp --port " + port
+	check.ProxyEntityName = "127.0.0.1"
+	result := Run(context.Background(), check, nil)
+	assert.Equal(t, StatusOK, result.Status, result.Output)
+}
+
+func TestRunTimeout(t *testing.T) {
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer server.Close()
+	defer close(block)
+
+	check := corev2.FixtureCheckConfig("check")
+	check.Command = "sensu:http " + server.URL
+	check.Timeout = 1
+	result := Run(context.Background(), check, nil)
+	assert.Equal(t, StatusCritical, result.Status)
+	assert.True(t, strings.Contains(result.Output, "deadline exceeded"), result.Output)
+}
+
+func TestHTTPCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("all good"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		args    string
+		status  int
+		metrics bool
+	}{
+		{name: "ok", args: "--header X-Token:secret", status: StatusOK, metrics: true},
+		{name: "error status", args: "", status: StatusCritical, metrics: true},
+		{name: "expected status", args: "--expect-status 401", status: StatusOK, metrics: true},
+		{name: "expected body", args: "--header X-Token:secret --expect-body good", status: StatusOK, metrics: true},
+		{name: "unexpected body", args: "--header X-Token:secret --expect-body bad", status: StatusCritical, metrics: true},
+		{name: "quoted body", args: `--header 'X-Token: secret' --expect-body "all good"`, status: StatusOK, metrics: true},
+		{name: "unexpected quoted body", args: `--header X-Token:secret --expect-body "all bad"`, status: StatusCritical, metrics: true},
+		{name: "invalid header", args: "--header X-Token", status: StatusUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := runCommand("sensu:http " + server.URL + " " + tt.args)
+			assert.Equal(t, tt.status, result.Status, result.Output)
+			if tt.metrics {
+				require.Len(t, result.Metrics, 3)
+				assert.Equal(t, "http.response_time", result.Metrics[0].Name)
+				assert.Equal(t, "url", result.Metrics[0].Tags[0].Name)
+			}
+		})
+	}
+
+	result := runCommand("sensu:http http://127.0.0.1:1")
+	assert.Equal(t, StatusCritical, result.Status, result.Output)
+}
+
+func TestTCPCheck(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := ln.Addr().String()
+
+	result := runCommand("sensu:tcp " + address)
+	assert.Equal(t, StatusOK, result.Status, result.Output)
+	require.Len(t, result.Metrics, 1)
+	assert.Equal(t, "tcp.connect_time", result.Metrics[0].Name)
+	assert.NotZero(t, result.Duration)
+
+	// the output metric tags of the check are added to the metrics
+	check := corev2.FixtureCheckConfig("check")
+	check.Command = "sensu:tcp " + address
+	check.OutputMetricTags = []*corev2.MetricTag{{Name: "team", Value: "ops"}}
+	result = Run(context.Background(), check, nil)
+	require.Len(t, result.Metrics, 1)
+	assert.Equal(t, []*corev2.MetricTag{
+		{Name: "address", Value: address},
+		{Name: "team", Value: "ops"},
+	}, result.Metrics[0].Tags)
+
+	require.NoError(t, ln.Close())
+	result = runCommand("sensu:tcp " + address)
+	assert.Equal(t, StatusCritical, result.Status, result.Output)
+}
+
+func TestDNSCheck(t *testing.T) {
+	result := runCommand("sensu:dns localhost --expect 127.0.0.1")
+	assert.Equal(t, StatusOK, result.Status, result.Output)
+	require.Len(t, result.Metrics, 2)
+	assert.Equal(t, "dns.answers", result.Metrics[1].Name)
+
+	result = runCommand("sensu:dns localhost --expect 10.0.0.1")
+	assert.Equal(t, StatusCritical, result.Status, result.Output)
+
+	result = runCommand("sensu:dns doesnotexist.invalid")
+	assert.Equal(t, StatusCritical, result.Status, result.Output)
+}
+
+func TestCertCheck(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	address := strings.TrimPrefix(server.URL, "https://")
+
+	// The certificate of the test server isn't trusted
+	result := runCommand("sensu:cert " + address)
+	assert.Equal(t, StatusCritical, result.Status, result.Output)
+
+	result = runCommand("sensu:cert " + address + " --insecure-skip-verify")
+	assert.Equal(t, StatusOK, result.Status, result.Output)
+	require.Len(t, result.Metrics, 1)
+	assert.Equal(t, "cert.seconds_to_expiry", result.Metrics[0].Name)
+	assert.Greater(t, result.Metrics[0].Value, 0.0)
+
+	// The test certificate expires in decades
+	result = runCommand("sensu:cert " + address + " --insecure-skip-verify --warning 1000000")
+	assert.Equal(t, StatusWarning, result.Status, result.Output)
+	result = runCommand("sensu:cert " + address + " --insecure-skip-verify --critical 1000000")
+	assert.Equal(t, StatusCritical, result.Status, result.Output)
+}
@@ -0,0 +1,59 @@
+package builtins
+
+import (
+	"context"
+	"crypto/tls"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/spf13/pflag"
+)
+
+// certCheck checks the expiry of the certificate of a TLS server.
+func certCheck(flags *pflag.FlagSet, e *execution) probe {
+	serverName := flags.String("server-name", "", "server name of the TLS handshake, instead of the target host")
+	warning := flags.Int("warning", 30, "days before the expiry for a warning")
+	critical := flags.Int("critical", 7, "days before the expiry for a critical status")
+	insecure := flags.Bool("insecure-skip-verify", false, "skip the verification of the server certificate")
+
+	return func(ctx context.Context, target string) *Result {
+		address, err := hostPort(target, 443)
+		if err != nil {
+			return unknown("CERT %s", err)
+		}
+		dialer := &tls.Dialer{
+			Config: &tls.Config{
+				ServerName:         *serverName,
+				InsecureSkipVerify: *insecure, // #nosec G402
+			},
+		}
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return result(StatusCritical, "CERT CRITICAL: %s", err)
+		}
+		defer conn.Close()
+		certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+		if len(certs) == 0 {
+			return result(StatusCritical, "CERT CRITICAL: %s presented no certificate", address)
+		}
+
+		cert := certs[0]
+		now := time.Now()
+		remaining := cert.NotAfter.Sub(now)
+		days := remaining.Hours() / 24
+
+		status := StatusOK
+		switch {
+		case days < float64(*critical):
+			status = StatusCritical
+		case days < float64(*warning):
+			status = StatusWarning
+		}
+		r := result(status, "CERT %s: the certificate %q of %s expires in %.1f days, on %s", statusName(status),
+			cert.Subject.CommonName, address, days, cert.NotAfter.UTC().Format(time.RFC3339))
+		r.Metrics = []*corev2.MetricPoint{
+			e.metric("cert.seconds_to_expiry", remaining.Seconds(), now, "address", address),
+		}
+		return r
+	}
+}
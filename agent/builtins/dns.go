@@ -0,0 +1,129 @@
+package builtins
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/spf13/pflag"
+)
+
+// dnsCheck resolves a name, optionally with a given server, and checks that
+// the answers contain an expected value.
+func dnsCheck(flags *pflag.FlagSet, e *execution) probe {
+	recordType := flags.String("type", "A", "type of the records to resolve [A, AAAA, CNAME, MX, NS, TXT]")
+	server := flags.String("server", "", "address of the DNS server, instead of the system resolvers")
+	expect := flags.String("expect", "", "value expected in the answers")
+
+	return func(ctx context.Context, target string) *Result {
+		resolver := net.DefaultResolver
+		if *server != "" {
+			address := *server
+			if _, _, err := net.SplitHostPort(address); err != nil {
+				address = net.JoinHostPort(address, "53")
+			}
+			resolver = &net.Resolver{
+				PreferGo: true,
+				Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+					var dialer net.Dialer
+					return dialer.DialContext(ctx, network, address)
+				},
+			}
+		}
+
+		start := time.Now()
+		answers, err := lookup(ctx, resolver, strings.ToUpper(*recordType), target)
+		elapsed := time.Since(start)
+		if err != nil {
+			if _, ok := err.(unsupportedTypeError); ok {
+				return unknown("DNS %s", err)
+			}
+			return result(StatusCritical, "DNS CRITICAL: %s", err)
+		}
+
+		status := StatusOK
+		var problems []string
+		if len(answers) == 0 {
+			status = StatusCritical
+			problems = append(problems, "no answers")
+		}
+		if *expect != "" && !containsAnswer(answers, *expect) {
+			status = StatusCritical
+			problems = append(problems, fmt.Sprintf("expected %q", *expect))
+		}
+
+		r := result(status, "DNS %s: %s %s resolved to [%s] in %s%s", statusName(status), strings.ToUpper(*recordType),
+			target, strings.Join(answers, ", "), elapsed.Round(time.Millisecond), details(problems))
+		now := time.Now()
+		r.Metrics = []*corev2.MetricPoint{
+			e.metric("dns.lookup_time", elapsed.Seconds(), now, "name", target, "type", strings.ToUpper(*recordType)),
+			e.metric("dns.answers", float64(len(answers)), now, "name", target, "type", strings.ToUpper(*recordType)),
+		}
+		return r
+	}
+}
+
+type unsupportedTypeError string
+
+func (e unsupportedTypeError) Error() string {
+	return fmt.Sprintf("unsupported record type %q", string(e))
+}
+
+func lookup(ctx context.Context, resolver *net.Resolver, recordType, name string) ([]string, error) {
+	var answers []string
+	switch recordType {
+	case "A", "AAAA":
+		network := "ip4"
+		if recordType == "AAAA" {
+			network = "ip6"
+		}
+		ips, err := resolver.LookupIP(ctx, network, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			answers = append(answers, ip.String())
+		}
+	case "CNAME":
+		cname, err := resolver.LookupCNAME(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, cname)
+	case "MX":
+		records, err := resolver.LookupMX(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range records {
+			answers = append(answers, mx.Host)
+		}
+	case "NS":
+		records, err := resolver.LookupNS(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range records {
+			answers = append(answers, ns.Host)
+		}
+	case "TXT":
+		return resolver.LookupTXT(ctx, name)
+	default:
+		return nil, unsupportedTypeError(recordType)
+	}
+	return answers, nil
+}
+
+// containsAnswer returns true if an answer is the expected value, ignoring
+// the trailing dots of the names.
+func containsAnswer(answers []string, expected string) bool {
+	for _, answer := range answers {
+		if strings.TrimSuffix(answer, ".") == strings.TrimSuffix(expected, ".") {
+			return true
+		}
+	}
+	return false
+}
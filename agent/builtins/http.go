@@ -0,0 +1,95 @@
+package builtins
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/spf13/pflag"
+)
+
+// maxHTTPBodySize is the size of the response bodies searched by --expect-body.
+const maxHTTPBodySize = 1 << 20
+
+// httpCheck requests a URL, and checks the status and the body of the
+// response. Without an expected status, the 2xx and 3xx statuses are OK.
+func httpCheck(flags *pflag.FlagSet, e *execution) probe {
+	method := flags.String("method", http.MethodGet, "HTTP method of the request")
+	headers := flags.StringArray("header", nil, "header of the request, as Name:value")
+	expectStatus := flags.Int("expect-status", 0, "expected status of the response")
+	expectBody := flags.String("expect-body", "", "text expected in the body of the response")
+	insecure := flags.Bool("insecure-skip-verify", false, "skip the verification of the server certificate")
+
+	return func(ctx context.Context, target string) *Result {
+		url := target
+		if !strings.Contains(url, "://") {
+			url = "https://" + url
+		}
+		req, err := http.NewRequestWithContext(ctx, *method, url, nil)
+		if err != nil {
+			return unknown("HTTP %s", err)
+		}
+		for _, header := range *headers {
+			name, value, ok := strings.Cut(header, ":")
+			if !ok {
+				return unknown("HTTP invalid header %q", header)
+			}
+			req.Header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+		}
+		client := &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: *insecure}, // #nosec G402
+			},
+			// The redirects are reported, not followed
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+		defer client.CloseIdleConnections()
+
+		start := time.Now()
+		resp, err := client.Do(req)
+		if err != nil {
+			return result(StatusCritical, "HTTP CRITICAL: %s", err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPBodySize))
+		if err != nil {
+			return result(StatusCritical, "HTTP CRITICAL: %s %s: %s", *method, url, err)
+		}
+		elapsed := time.Since(start)
+
+		now := time.Now()
+		metrics := []*corev2.MetricPoint{
+			e.metric("http.response_time", elapsed.Seconds(), now, "url", url),
+			e.metric("http.status_code", float64(resp.StatusCode), now, "url", url),
+			e.metric("http.content_length", float64(len(body)), now, "url", url),
+		}
+
+		status := StatusOK
+		var problems []string
+		if *expectStatus != 0 {
+			if resp.StatusCode != *expectStatus {
+				status = StatusCritical
+				problems = append(problems, fmt.Sprintf("expected status %d", *expectStatus))
+			}
+		} else if resp.StatusCode >= http.StatusBadRequest {
+			status = StatusCritical
+		}
+		if *expectBody != "" && !strings.Contains(string(body), *expectBody) {
+			status = StatusCritical
+			problems = append(problems, fmt.Sprintf("expected body to contain %q", *expectBody))
+		}
+
+		r := result(status, "HTTP %s: %s %s returned %s in %s%s", statusName(status), *method, url, resp.Status,
+			elapsed.Round(time.Millisecond), details(problems))
+		r.Metrics = metrics
+		return r
+	}
+}
@@ -0,0 +1,132 @@
+package builtins
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/agent/transformers"
+	"github.com/spf13/pflag"
+)
+
+// maxScrapeSize is the maximum size of the scraped metrics.
+const maxScrapeSize = 16 << 20
+
+// prometheusCheck scrapes the metrics of a Prometheus exporter. The samples
+// are the metrics of the result, with the output metric tags of the check.
+// Without a scheme or a path, the target is scraped on http and /metrics.
+func prometheusCheck(flags *pflag.FlagSet, e *execution) probe {
+	bearerTokenSecret := flags.String("bearer-token-secret", "", "name of the check secret holding the bearer token of the requests")
+	caFile := flags.String("ca-file", "", "path to the CA certificates that verify the exporter certificate")
+	insecure := flags.Bool("insecure-skip-verify", false, "skip the verification of the exporter certificate")
+	allow := flags.String("allow", "", "regular expression the metric names must match")
+	deny := flags.String("deny", "", "regular expression of the metric names to drop")
+
+	return func(ctx context.Context, target string) *Result {
+		metricsURL, err := scrapeURL(target)
+		if err != nil {
+			return unknown("PROMETHEUS invalid target %q: %s", target, err)
+		}
+		var allowRe, denyRe *regexp.Regexp
+		if *allow != "" {
+			if allowRe, err = regexp.Compile(*allow); err != nil {
+				return unknown("PROMETHEUS invalid --allow: %s", err)
+			}
+		}
+		if *deny != "" {
+			if denyRe, err = regexp.Compile(*deny); err != nil {
+				return unknown("PROMETHEUS invalid --deny: %s", err)
+			}
+		}
+
+		tlsConfig := &tls.Config{InsecureSkipVerify: *insecure} // #nosec G402
+		if *caFile != "" {
+			pem, err := os.ReadFile(*caFile)
+			if err != nil {
+				return unknown("PROMETHEUS %s", err)
+			}
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+				return unknown("PROMETHEUS no certificates found in %s", *caFile)
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, metricsURL, nil)
+		if err != nil {
+			return unknown("PROMETHEUS %s", err)
+		}
+		req.Header.Set("Accept", "text/plain;version=0.0.4")
+		if *bearerTokenSecret != "" {
+			token, ok := e.secret(*bearerTokenSecret)
+			if !ok {
+				return unknown("PROMETHEUS the check has no secret %q", *bearerTokenSecret)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		client := &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			},
+		}
+		defer client.CloseIdleConnections()
+
+		start := time.Now()
+		resp, err := client.Do(req)
+		if err != nil {
+			return result(StatusCritical, "PROMETHEUS CRITICAL: %s", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return result(StatusCritical, "PROMETHEUS CRITICAL: %s returned %s", metricsURL, resp.Status)
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxScrapeSize))
+		if err != nil {
+			return result(StatusCritical, "PROMETHEUS CRITICAL: %s: %s", metricsURL, err)
+		}
+		elapsed := time.Since(start)
+
+		samples, err := transformers.ParsePromText(string(body), e.check.OutputMetricTags)
+		if err != nil {
+			return result(StatusCritical, "PROMETHEUS CRITICAL: invalid metrics from %s: %s", metricsURL, err)
+		}
+		var points []*corev2.MetricPoint
+		for _, point := range samples.Transform() {
+			if allowRe != nil && !allowRe.MatchString(point.Name) {
+				continue
+			}
+			if denyRe != nil && denyRe.MatchString(point.Name) {
+				continue
+			}
+			points = append(points, point)
+		}
+
+		r := result(StatusOK, "PROMETHEUS OK: scraped %d samples from %s in %s", len(points), metricsURL,
+			elapsed.Round(time.Millisecond))
+		r.Metrics = points
+		return r
+	}
+}
+
+// scrapeURL returns the URL of the metrics of the target.
+func scrapeURL(target string) (string, error) {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		// The target has no scheme
+		u, err = url.Parse("http://" + target)
+		if err != nil {
+			return "", err
+		}
+	}
+	if u.Path == "" {
+		u.Path = "/metrics"
+	}
+	return u.String(), nil
+}
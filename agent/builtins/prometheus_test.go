@@ -0,0 +1,141 @@
+package builtins
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const promMetrics = `# HELP node_cpu_seconds_total Seconds the CPUs spent in each mode.
+# TYPE node_cpu_seconds_total counter
+node_cpu_seconds_total{cpu="0",mode="idle"} 100
+node_cpu_seconds_total{cpu="0",mode="user"} 20
+# TYPE node_load1 gauge
+node_load1 0.5
+# TYPE go_goroutines gauge
+go_goroutines 12
+`
+
+func promHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer s3cr3t" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	_, _ = w.Write([]byte(promMetrics))
+}
+
+func TestPrometheusCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(promHandler))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		args    string
+		secrets []string
+		status  int
+		names   []string
+	}{
+		{
+			name:    "all metrics",
+			args:    "--bearer-token-secret TOKEN",
+			secrets: []string{"TOKEN=s3cr3t"},
+			status:  StatusOK,
+			names:   []string{"go_goroutines", "node_cpu_seconds_total", "node_cpu_seconds_total", "node_load1"},
+		},
+		{
+			name:    "allowed metrics",
+			args:    "--bearer-token-secret TOKEN --allow ^node_",
+			secrets: []string{"TOKEN=s3cr3t"},
+			status:  StatusOK,
+			names:   []string{"node_cpu_seconds_total", "node_cpu_seconds_total", "node_load1"},
+		},
+		{
+			name:    "denied metrics",
+			args:    "--bearer-token-secret TOKEN --allow ^node_ --deny _total$",
+			secrets: []string{"TOKEN=s3cr3t"},
+			status:  StatusOK,
+			names:   []string{"node_load1"},
+		},
+		{
+			name:   "unauthorized",
+			status: StatusCritical,
+		},
+		{
+			name:   "missing secret",
+			args:   "--bearer-token-secret TOKEN",
+			status: StatusUnknown,
+		},
+		{
+			name:   "invalid regexp",
+			args:   "--allow (",
+			status: StatusUnknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := corev2.FixtureCheckConfig("check")
+			check.Command = "sensu:prometheus " + server.URL + "/metrics " + tt.args
+			check.OutputMetricTags = []*corev2.MetricTag{{Name: "team", Value: "ops"}}
+			result := Run(context.Background(), check, tt.secrets)
+			require.Equal(t, tt.status, result.Status, result.Output)
+
+			var names []string
+			for _, point := range result.Metrics {
+				names = append(names, point.Name)
+				assert.Contains(t, point.Tags, &corev2.MetricTag{Name: "team", Value: "ops"})
+			}
+			assert.ElementsMatch(t, tt.names, names)
+		})
+	}
+}
+
+func TestPrometheusCheckInvalidMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("not { metrics"))
+	}))
+	defer server.Close()
+
+	result := runCommand("sensu:prometheus " + server.URL)
+	assert.Equal(t, StatusCritical, result.Status, result.Output)
+}
+
+func TestPrometheusCheckTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(promHandler))
+	defer server.Close()
+
+	check := corev2.FixtureCheckConfig("check")
+	check.Command = "sensu:prometheus " + server.URL + " --bearer-token-secret TOKEN"
+	result := Run(context.Background(), check, []string{"TOKEN=s3cr3t"})
+	assert.Equal(t, StatusCritical, result.Status, result.Output)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, data, 0644))
+	check.Command += " --ca-file " + caFile
+	result = Run(context.Background(), check, []string{"TOKEN=s3cr3t"})
+	assert.Equal(t, StatusOK, result.Status, result.Output)
+	assert.Len(t, result.Metrics, 4)
+}
+
+func TestScrapeURL(t *testing.T) {
+	tests := map[string]string{
+		"localhost:9100":               "http://localhost:9100/metrics",
+		"node1":                        "http://node1/metrics",
+		"https://node1:9100":           "https://node1:9100/metrics",
+		"https://node1/federate?x=1":   "https://node1/federate?x=1",
+		"http://127.0.0.1:9090/custom": "http://127.0.0.1:9090/custom",
+	}
+	for target, want := range tests {
+		got, err := scrapeURL(target)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, target)
+	}
+}
@@ -0,0 +1,49 @@
+package builtins
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/spf13/pflag"
+)
+
+// tcpCheck connects to a host and port.
+func tcpCheck(flags *pflag.FlagSet, e *execution) probe {
+	port := flags.Int("port", 0, "port to connect to, when the target has none")
+
+	return func(ctx context.Context, target string) *Result {
+		address, err := hostPort(target, *port)
+		if err != nil {
+			return unknown("TCP %s", err)
+		}
+
+		var dialer net.Dialer
+		start := time.Now()
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return result(StatusCritical, "TCP CRITICAL: %s", err)
+		}
+		elapsed := time.Since(start)
+		_ = conn.Close()
+
+		r := result(StatusOK, "TCP OK: connected to %s in %s", address, elapsed.Round(time.Millisecond))
+		r.Metrics = []*corev2.MetricPoint{
+			e.metric("tcp.connect_time", elapsed.Seconds(), time.Now(), "address", address),
+		}
+		return r
+	}
+}
+
+// hostPort joins the target with the port, unless the target has a port.
+func hostPort(target string, port int) (string, error) {
+	if _, _, err := net.SplitHostPort(target); err == nil {
+		return target, nil
+	}
+	if port == 0 {
+		return "", &net.AddrError{Err: "missing port", Addr: target}
+	}
+	return net.JoinHostPort(target, strconv.Itoa(port)), nil
+}
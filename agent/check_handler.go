@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/sensu/sensu-go/agent/builtins"
 	"github.com/sensu/sensu-go/agent/transformers"
 	corev2 "github.com/sensu/core/v2"
 	"github.com/sensu/sensu-go/asset"
@@ -45,7 +46,7 @@ func (a *Agent) handleCheck(ctx context.Context, payload []byte) error {
 			ObjectMeta: corev2.NewObjectMeta("", check.Namespace),
 			Check:      check,
 		}
-		a.sendFailure(event, err)
+		a.sendFailure(ctx, event, err)
 	}
 
 	if a.config.DisableAssets && len(request.Assets) > 0 {
@@ -140,7 +141,7 @@ func (a *Agent) executeCheck(ctx context.Context, request *corev2.CheckRequest,
 		// we aren't doing load testing with the undocumented test check
 		// command.
 		if err := token.SubstituteCheck(checkConfig, entity); err != nil {
-			a.sendFailure(createEvent(), fmt.Errorf("error while substituting check tokens: %s", err))
+			a.sendFailure(ctx, createEvent(), fmt.Errorf("error while substituting check tokens: %s", err))
 			return
 		}
 	}
@@ -165,7 +166,7 @@ func (a *Agent) executeCheck(ctx context.Context, request *corev2.CheckRequest,
 		matchedEntry, match = a.matchAllowList(checkConfig.Command)
 		if !match {
 			logger.WithFields(fields).Debug("check does not match agent allow list")
-			a.sendFailure(event, fmt.Errorf(allowListOnDenyOutput))
+			a.sendFailure(ctx, event, fmt.Errorf(allowListOnDenyOutput))
 			return
 		}
 		logger.WithFields(fields).Debug("check matches agent allow list")
@@ -180,7 +181,7 @@ func (a *Agent) executeCheck(ctx context.Context, request *corev2.CheckRequest,
 		var err error
 		assets, err = asset.GetAll(ctx, a.assetGetter, checkAssets)
 		if err != nil {
-			a.sendFailure(event, fmt.Errorf("error getting assets for check: %s", err))
+			a.sendFailure(ctx, event, fmt.Errorf("error getting assets for check: %s", err))
 			return
 		}
 	}
@@ -194,25 +195,26 @@ func (a *Agent) executeCheck(ctx context.Context, request *corev2.CheckRequest,
 		env = environment.MergeEnvironments(os.Environ(), assets.Env(), secrets, checkConfig.EnvVars)
 	}
 
-	// Verify sha against the allow list
-	if matchedEntry.Sha512 != "" {
+	// Verify sha against the allow list. The builtin checks have no executable.
+	isBuiltin := builtins.IsBuiltin(checkConfig.Command)
+	if matchedEntry.Sha512 != "" && !isBuiltin {
 		logger.WithFields(fields).Debug("matching check sha against agent allow list")
 		path, err := lookPath(strings.Split(checkConfig.Command, " ")[0], env)
 		if err != nil {
 			logger.WithFields(fields).WithError(err).Error("unable to find the executable path")
-			a.sendFailure(event, fmt.Errorf(allowListOnDenyOutput))
+			a.sendFailure(ctx, event, fmt.Errorf(allowListOnDenyOutput))
 			return
 		}
 		file, err := os.Open(path)
 		if err != nil {
 			logger.WithFields(fields).WithError(err).Error("unable to open executable")
-			a.sendFailure(event, fmt.Errorf(allowListOnDenyOutput))
+			a.sendFailure(ctx, event, fmt.Errorf(allowListOnDenyOutput))
 			return
 		}
 		verifier := asset.Sha512Verifier{}
 		if err := verifier.Verify(file, matchedEntry.Sha512); err != nil {
 			logger.WithFields(fields).WithError(err).Error("check sha does not match agent allow list")
-			a.sendFailure(event, fmt.Errorf(allowListOnDenyOutput))
+			a.sendFailure(ctx, event, fmt.Errorf(allowListOnDenyOutput))
 			return
 		}
 	}
@@ -232,18 +234,34 @@ func (a *Agent) executeCheck(ctx context.Context, request *corev2.CheckRequest,
 	if checkConfig.Stdin {
 		input, err := json.Marshal(event)
 		if err != nil {
-			a.sendFailure(event, fmt.Errorf("error marshaling json from event: %s", err))
+			a.sendFailure(ctx, event, fmt.Errorf("error marshaling json from event: %s", err))
 			return
 		}
 		ex.Input = string(input)
 	}
 
-	checkExec, err := a.executor.Execute(context.Background(), ex)
-	if err != nil {
-		event.Check.Output = err.Error()
-		checkExec.Status = 3
-	} else {
+	var checkExec *command.ExecutionResponse
+	var builtinMetrics []*corev2.MetricPoint
+	if isBuiltin {
+		// The builtin checks are executed by the agent itself, within the
+		// check timeout only, like the check plugins
+		result := builtins.Run(context.Background(), checkConfig, secrets)
+		checkExec = &command.ExecutionResponse{
+			Output:   result.Output,
+			Status:   result.Status,
+			Duration: result.Duration,
+		}
+		builtinMetrics = result.Metrics
 		event.Check.Output = checkExec.Output
+	} else {
+		var err error
+		checkExec, err = a.executor.Execute(context.Background(), ex)
+		if err != nil {
+			event.Check.Output = err.Error()
+			checkExec.Status = 3
+		} else {
+			event.Check.Output = checkExec.Output
+		}
 	}
 
 	event.Check.Duration = checkExec.Duration
@@ -256,17 +274,22 @@ func (a *Agent) executeCheck(ctx context.Context, request *corev2.CheckRequest,
 		event.ID = id[:]
 	}
 
-	// Instantiate metrics in the event if the check is attempting to extract metrics
-	if check.OutputMetricFormat != "" || len(check.OutputMetricHandlers) != 0 {
+	// Instantiate metrics in the event if the check is attempting to extract
+	// metrics, or if it's a builtin check that measured some
+	if check.OutputMetricFormat != "" || len(check.OutputMetricHandlers) != 0 || len(builtinMetrics) > 0 {
 		event.Metrics = &corev2.Metrics{}
 	}
 
-	if check.OutputMetricFormat != "" {
+	if isBuiltin {
+		if event.Metrics != nil {
+			event.Metrics.Points = builtinMetrics
+		}
+	} else if check.OutputMetricFormat != "" {
 		event.Metrics.Points = extractMetrics(event)
+	}
 
-		if event.Check.Status == 0 && len(event.Metrics.Points) > 0 && len(check.OutputMetricThresholds) > 0 {
-			event.Check.Status = evaluateOutputMetricThresholds(event)
-		}
+	if event.Metrics != nil && event.Check.Status == 0 && len(event.Metrics.Points) > 0 && len(check.OutputMetricThresholds) > 0 {
+		event.Check.Status = evaluateOutputMetricThresholds(event)
 	}
 
 	if len(check.OutputMetricHandlers) != 0 {
@@ -290,17 +313,30 @@ func (a *Agent) executeCheck(ctx context.Context, request *corev2.CheckRequest,
 		return
 	}
 
+	logEvent(event)
+
+	a.sendCheckResult(ctx, msg)
+}
+
+// sendCheckResult sends the marshaled event of a check execution to the
+// backend. The results of the local checks are queued on the API queue
+// instead, so that they are delivered once the agent is connected.
+func (a *Agent) sendCheckResult(ctx context.Context, msg []byte) {
+	if isLocalCheck(ctx) && a.config.CacheDir != os.DevNull {
+		if _, err := a.apiQueue.Send(compressMessage(msg)); err != nil {
+			logger.WithError(err).Error("error queueing local check result")
+		}
+		return
+	}
+
 	tm := &transport.Message{
 		Type:    transport.MessageTypeEvent,
 		Payload: msg,
 	}
-
-	logEvent(event)
-
 	a.sendMessage(tm)
 }
 
-func (a *Agent) sendFailure(event *corev2.Event, err error) {
+func (a *Agent) sendFailure(ctx context.Context, event *corev2.Event, err error) {
 	logger.WithFields(logrus.Fields{
 		"event": event,
 	}).Error(err)
@@ -322,11 +358,7 @@ func (a *Agent) sendFailure(event *corev2.Event, err error) {
 	if msg, err := a.marshal(event); err != nil {
 		logger.WithError(err).Error("error marshaling check failure")
 	} else {
-		tm := &transport.Message{
-			Type:    transport.MessageTypeEvent,
-			Payload: msg,
-		}
-		a.sendMessage(tm)
+		a.sendCheckResult(ctx, msg)
 	}
 }
 
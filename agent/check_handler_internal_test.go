@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"testing"
 	"time"
 
@@ -314,6 +315,44 @@ func TestExecuteCheckDiscardOutput(t *testing.T) {
 	}
 }
 
+func TestExecuteBuiltinCheck(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	checkConfig := corev2.FixtureCheckConfig("check")
+	checkConfig.Command = "sensu:tcp " + ln.Addr().String()
+	checkConfig.OutputMetricThresholds = []*corev2.MetricThreshold{
+		{
+			Name: "tcp.connect_time",
+			Thresholds: []*corev2.MetricThresholdRule{
+				{Max: "0", Status: 1},
+			},
+		},
+	}
+	request := &corev2.CheckRequest{Config: checkConfig, Issued: time.Now().Unix()}
+
+	config, cleanup := FixtureConfig()
+	defer cleanup()
+	agent, err := NewAgent(config)
+	require.NoError(t, err)
+	ch := make(chan *transport.Message, 1)
+	agent.sendq = ch
+	// The builtin checks don't use the executor
+	agent.executor = &mockexecutor.MockExecutor{}
+
+	agent.executeCheck(context.TODO(), request, agent.getAgentEntity())
+	msg := <-ch
+
+	event := &corev2.Event{}
+	require.NoError(t, json.Unmarshal(msg.Payload, event))
+	assert.Contains(t, event.Check.Output, "TCP OK")
+	require.NotNil(t, event.Metrics)
+	require.Len(t, event.Metrics.Points, 1)
+	assert.Equal(t, "tcp.connect_time", event.Metrics.Points[0].Name)
+	assert.Equal(t, uint32(1), event.Check.Status)
+}
+
 func TestHandleTokenSubstitution(t *testing.T) {
 	assert := assert.New(t)
 
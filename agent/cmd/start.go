@@ -59,6 +59,12 @@ const (
 	flagNamespace                 = "namespace"
 	flagPassword                  = "password"
 	flagRedact                    = "redact"
+	flagSocketEnable              = "socket-enable"
+	flagSocketHost                = "socket-host"
+	flagSocketPort                = "socket-port"
+	flagOTLPDisable               = "otlp-disable"
+	flagOTLPEventHandlers         = "otlp-event-handlers"
+	flagOTLPFlushInterval         = "otlp-flush-interval"
 	flagStatsdDisable             = "statsd-disable"
 	flagStatsdEventHandlers       = "statsd-event-handlers"
 	flagStatsdFlushInterval       = "statsd-flush-interval"
@@ -72,15 +78,23 @@ const (
 	flagLabels                    = "labels"
 	flagAnnotations               = "annotations"
 	flagAllowList                 = "allow-list"
+	flagLocalChecksDir            = "local-checks-dir"
 	flagBackendHandshakeTimeout   = "backend-handshake-timeout"
 	flagBackendHeartbeatInterval  = "backend-heartbeat-interval"
 	flagBackendHeartbeatTimeout   = "backend-heartbeat-timeout"
+	flagBackendSelector           = "backend-selector"
+	flagBackendPriorityGroups     = "backend-priority-groups"
+	flagBackendFailbackInterval   = "backend-failback-interval"
 	flagAgentManagedEntity        = "agent-managed-entity"
 	flagRetryMin                  = "retry-min"
 	flagRetryMax                  = "retry-max"
 	flagRetryMultiplier           = "retry-multiplier"
 	flagMaxSessionLength          = "max-session-length"
 	flagStripNetworks             = "strip-networks"
+	flagCollectProcesses          = "collect-processes"
+	flagProcessesInclude          = "processes-include"
+	flagProcessesExclude          = "processes-exclude"
+	flagProcessesMaxCount         = "processes-max-count"
 
 	// TLS flags
 	flagTrustedCAFile         = "trusted-ca-file"
@@ -130,6 +144,12 @@ func NewAgentConfig(cmd *cobra.Command) (*agent.Config, error) {
 	cfg.KeepalivePipelines = viper.GetStringSlice(flagKeepalivePipelines)
 	cfg.Namespace = viper.GetString(flagNamespace)
 	cfg.Password = viper.GetString(flagPassword)
+	cfg.Socket.Enable = viper.GetBool(flagSocketEnable)
+	cfg.Socket.Host = viper.GetString(flagSocketHost)
+	cfg.Socket.Port = viper.GetInt(flagSocketPort)
+	cfg.OTLP.Disable = viper.GetBool(flagOTLPDisable)
+	cfg.OTLP.FlushInterval = viper.GetInt(flagOTLPFlushInterval)
+	cfg.OTLP.Handlers = viper.GetStringSlice(flagOTLPEventHandlers)
 	cfg.StatsdServer.Disable = viper.GetBool(flagStatsdDisable)
 	cfg.StatsdServer.FlushInterval = viper.GetInt(flagStatsdFlushInterval)
 	cfg.StatsdServer.Host = viper.GetString(flagStatsdMetricsHost)
@@ -137,14 +157,21 @@ func NewAgentConfig(cmd *cobra.Command) (*agent.Config, error) {
 	cfg.StatsdServer.Handlers = viper.GetStringSlice(flagStatsdEventHandlers)
 	cfg.User = viper.GetString(flagUser)
 	cfg.AllowList = viper.GetString(flagAllowList)
+	cfg.LocalChecksDir = viper.GetString(flagLocalChecksDir)
 	cfg.BackendHandshakeTimeout = viper.GetInt(flagBackendHandshakeTimeout)
 	cfg.BackendHeartbeatInterval = viper.GetInt(flagBackendHeartbeatInterval)
 	cfg.BackendHeartbeatTimeout = viper.GetInt(flagBackendHeartbeatTimeout)
+	cfg.BackendSelector = viper.GetString(flagBackendSelector)
+	cfg.BackendFailbackInterval = viper.GetDuration(flagBackendFailbackInterval)
 	cfg.RetryMin = viper.GetDuration(flagRetryMin)
 	cfg.RetryMax = viper.GetDuration(flagRetryMax)
 	cfg.RetryMultiplier = viper.GetFloat64(flagRetryMultiplier)
 	cfg.MaxSessionLength = viper.GetDuration(flagMaxSessionLength)
 	cfg.StripNetworks = viper.GetBool(flagStripNetworks)
+	cfg.CollectProcesses = viper.GetBool(flagCollectProcesses)
+	cfg.ProcessesInclude = viper.GetStringSlice(flagProcessesInclude)
+	cfg.ProcessesExclude = viper.GetStringSlice(flagProcessesExclude)
+	cfg.ProcessesMaxCount = viper.GetInt(flagProcessesMaxCount)
 
 	// Set the labels & annotations using values defined configuration files
 	// and/or environment variables for now
@@ -176,6 +203,27 @@ func NewAgentConfig(cmd *cobra.Command) (*agent.Config, error) {
 		cfg.BackendURLs = append(cfg.BackendURLs, newURL)
 	}
 
+	// Each priority group is a whitespace-delimited list of backend URLs. The
+	// priority groups replace the backend URLs when they are defined.
+	var priorityURLs []string
+	for _, group := range viper.GetStringSlice(flagBackendPriorityGroups) {
+		var groupURLs []string
+		for _, backendURL := range strings.Fields(group) {
+			newURL, err := url.AppendPortIfMissing(backendURL, DefaultBackendPort)
+			if err != nil {
+				return nil, err
+			}
+			groupURLs = append(groupURLs, newURL)
+		}
+		if len(groupURLs) > 0 {
+			cfg.BackendPriorityGroups = append(cfg.BackendPriorityGroups, groupURLs)
+			priorityURLs = append(priorityURLs, groupURLs...)
+		}
+	}
+	if len(priorityURLs) > 0 {
+		cfg.BackendURLs = priorityURLs
+	}
+
 	cfg.Redact = viper.GetStringSlice(flagRedact)
 	cfg.Subscriptions = viper.GetStringSlice(flagSubscriptions)
 
@@ -307,6 +355,12 @@ func handleConfig(cmd *cobra.Command, arguments []string) error {
 	viper.SetDefault(flagNamespace, agent.DefaultNamespace)
 	viper.SetDefault(flagPassword, agent.DefaultPassword)
 	viper.SetDefault(flagRedact, corev2.DefaultRedactFields)
+	viper.SetDefault(flagSocketEnable, false)
+	viper.SetDefault(flagSocketHost, agent.DefaultSocketHost)
+	viper.SetDefault(flagSocketPort, agent.DefaultSocketPort)
+	viper.SetDefault(flagOTLPDisable, agent.DefaultOTLPDisable)
+	viper.SetDefault(flagOTLPFlushInterval, agent.DefaultOTLPFlushInterval)
+	viper.SetDefault(flagOTLPEventHandlers, []string{})
 	viper.SetDefault(flagStatsdDisable, agent.DefaultStatsdDisable)
 	viper.SetDefault(flagStatsdFlushInterval, agent.DefaultStatsdFlushInterval)
 	viper.SetDefault(flagStatsdMetricsHost, agent.DefaultStatsdMetricsHost)
@@ -320,11 +374,18 @@ func handleConfig(cmd *cobra.Command, arguments []string) error {
 	viper.SetDefault(flagBackendHandshakeTimeout, 15)
 	viper.SetDefault(flagBackendHeartbeatInterval, 30)
 	viper.SetDefault(flagBackendHeartbeatTimeout, 45)
+	viper.SetDefault(flagBackendSelector, agent.RandomBackendSelection)
+	viper.SetDefault(flagBackendPriorityGroups, []string{})
+	viper.SetDefault(flagBackendFailbackInterval, agent.DefaultBackendFailbackInterval)
 	viper.SetDefault(flagRetryMin, time.Second)
 	viper.SetDefault(flagRetryMax, 120*time.Second)
 	viper.SetDefault(flagRetryMultiplier, 2.0)
 	viper.SetDefault(flagMaxSessionLength, 0*time.Second)
 	viper.SetDefault(flagStripNetworks, false)
+	viper.SetDefault(flagCollectProcesses, false)
+	viper.SetDefault(flagProcessesInclude, []string{})
+	viper.SetDefault(flagProcessesExclude, []string{})
+	viper.SetDefault(flagProcessesMaxCount, agent.DefaultProcessesMaxCount)
 
 	// Merge in flag set so that it appears in command usage
 	flags := flagSet()
@@ -414,6 +475,12 @@ func flagSet() *pflag.FlagSet {
 	flagSet.String(flagNamespace, viper.GetString(flagNamespace), "agent namespace")
 	flagSet.String(flagPassword, viper.GetString(flagPassword), "agent password")
 	flagSet.StringSlice(flagRedact, viper.GetStringSlice(flagRedact), "comma-delimited list of fields to redact, overwrites the default fields. This flag can also be invoked multiple times")
+	flagSet.Bool(flagSocketEnable, viper.GetBool(flagSocketEnable), "enable the TCP and UDP sockets that accept check results")
+	flagSet.String(flagSocketHost, viper.GetString(flagSocketHost), "address to bind the check result sockets to")
+	flagSet.Int(flagSocketPort, viper.GetInt(flagSocketPort), "port the check result sockets listen on")
+	flagSet.Bool(flagOTLPDisable, viper.GetBool(flagOTLPDisable), "disables the OTLP metrics receiver of the agent API")
+	flagSet.StringSlice(flagOTLPEventHandlers, viper.GetStringSlice(flagOTLPEventHandlers), "comma-delimited list of event handlers for OTLP metrics. This flag can also be invoked multiple times")
+	flagSet.Int(flagOTLPFlushInterval, viper.GetInt(flagOTLPFlushInterval), "number of seconds between OTLP metrics flush")
 	flagSet.Bool(flagStatsdDisable, viper.GetBool(flagStatsdDisable), "disables the statsd listener and metrics server")
 	flagSet.StringSlice(flagStatsdEventHandlers, viper.GetStringSlice(flagStatsdEventHandlers), "comma-delimited list of event handlers for statsd metrics. This flag can also be invoked multiple times")
 	flagSet.Int(flagStatsdFlushInterval, viper.GetInt(flagStatsdFlushInterval), "number of seconds between statsd flush")
@@ -439,15 +506,23 @@ func flagSet() *pflag.FlagSet {
 	flagSet.StringToStringVar(&labels, flagLabels, nil, "entity labels map")
 	flagSet.StringToStringVar(&annotations, flagAnnotations, nil, "entity annotations map")
 	flagSet.String(flagAllowList, viper.GetString(flagAllowList), "path to agent execution allow list configuration file")
+	flagSet.String(flagLocalChecksDir, viper.GetString(flagLocalChecksDir), "path to a directory of check definitions executed by the agent itself")
 	flagSet.Int(flagBackendHandshakeTimeout, viper.GetInt(flagBackendHandshakeTimeout), "number of seconds the agent should wait when negotiating a new WebSocket connection")
 	flagSet.Int(flagBackendHeartbeatInterval, viper.GetInt(flagBackendHeartbeatInterval), "interval at which the agent should send heartbeats to the backend")
 	flagSet.Int(flagBackendHeartbeatTimeout, viper.GetInt(flagBackendHeartbeatTimeout), "number of seconds the agent should wait for a response to a hearbeat")
 	flagSet.Bool(flagAgentManagedEntity, viper.GetBool(flagAgentManagedEntity), "manage this entity via the agent")
+	flagSet.String(flagBackendSelector, viper.GetString(flagBackendSelector), "strategy used to select the backend to connect to [random, priority, latency]")
+	flagSet.StringSlice(flagBackendPriorityGroups, viper.GetStringSlice(flagBackendPriorityGroups), "comma-delimited list of groups of space-delimited backend URLs, most preferred first, used by the priority backend selector instead of --backend-url. This flag can also be invoked multiple times")
+	flagSet.Duration(flagBackendFailbackInterval, viper.GetDuration(flagBackendFailbackInterval), "interval at which the agent tries to return to a preferred backend with the priority and latency backend selectors (0 disables it)")
 	flagSet.Duration(flagRetryMin, viper.GetDuration(flagRetryMin), "minimum amount of time to wait before retrying an agent connection to the backend")
 	flagSet.Duration(flagRetryMax, viper.GetDuration(flagRetryMax), "maximum amount of time to wait before retrying an agent connection to the backend")
 	flagSet.Float64(flagRetryMultiplier, viper.GetFloat64(flagRetryMultiplier), "value multiplied with the current retry delay to produce a longer retry delay (bounded by --retry-max)")
 	flagSet.Duration(flagMaxSessionLength, viper.GetDuration(flagMaxSessionLength), "maximum amount of time after which the agent will reconnect to one of the configured backends (no maximum by default)")
 	flagSet.Bool(flagStripNetworks, viper.GetBool(flagStripNetworks), "do not include Network info in agent entity state")
+	flagSet.Bool(flagCollectProcesses, viper.GetBool(flagCollectProcesses), "include local processes in agent entity state (linux only)")
+	flagSet.StringSlice(flagProcessesInclude, viper.GetStringSlice(flagProcessesInclude), "comma-delimited list of regular expressions; only processes with a matching name or command line are reported. This flag can also be invoked multiple times")
+	flagSet.StringSlice(flagProcessesExclude, viper.GetStringSlice(flagProcessesExclude), "comma-delimited list of regular expressions; processes with a matching name or command line are not reported. This flag can also be invoked multiple times")
+	flagSet.Int(flagProcessesMaxCount, viper.GetInt(flagProcessesMaxCount), "maximum number of processes reported in agent entity state")
 
 	flagSet.SetOutput(ioutil.Discard)
 
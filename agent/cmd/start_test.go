@@ -108,6 +108,34 @@ func TestNewAgentConfigKeepalivePipelinesFlag(t *testing.T) {
 	}
 }
 
+func TestNewAgentConfigBackendPriorityGroupsFlag(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+	}
+	if err := handleConfig(cmd, []string{}); err != nil {
+		t.Fatal("unexpected error while calling handleConfig: ", err)
+	}
+	_ = cmd.Flags().Set(flagBackendSelector, agent.PriorityBackendSelection)
+	_ = cmd.Flags().Set(flagBackendPriorityGroups, "ws://a ws://b:8082,ws://c")
+
+	cfg, err := NewAgentConfig(cmd)
+	if err != nil {
+		t.Fatal("unexpected error while calling handleConfig: ", err)
+	}
+
+	if got, want := cfg.BackendSelector, agent.PriorityBackendSelection; got != want {
+		t.Errorf("TestNewAgentConfigBackendPriorityGroupsFlag() selector = %v, want %v", got, want)
+	}
+	wantGroups := [][]string{{"ws://a:8081", "ws://b:8082"}, {"ws://c:8081"}}
+	if !reflect.DeepEqual(cfg.BackendPriorityGroups, wantGroups) {
+		t.Errorf("TestNewAgentConfigBackendPriorityGroupsFlag() groups = %v, want %v", cfg.BackendPriorityGroups, wantGroups)
+	}
+	wantURLs := []string{"ws://a:8081", "ws://b:8082", "ws://c:8081"}
+	if !reflect.DeepEqual(cfg.BackendURLs, wantURLs) {
+		t.Errorf("TestNewAgentConfigBackendPriorityGroupsFlag() backend URLs = %v, want %v", cfg.BackendURLs, wantURLs)
+	}
+}
+
 func TestNewAgentConfig_AgentManagedEntityFlag(t *testing.T) {
 	cmd := &cobra.Command{
 		Use: "test",
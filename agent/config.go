@@ -7,6 +7,7 @@ import (
 
 	corev2 "github.com/sensu/core/v2"
 	"github.com/sensu/sensu-go/asset"
+	"github.com/sensu/sensu-go/process"
 	"golang.org/x/time/rate"
 )
 
@@ -24,6 +25,10 @@ const (
 	// DefaultBackendURL specifies the default backend URL
 	DefaultBackendURL = "ws://127.0.0.1:8081"
 
+	// DefaultBackendFailbackInterval specifies the default interval at which
+	// the agent tries to return to a preferred backend
+	DefaultBackendFailbackInterval = 5 * time.Minute
+
 	// DefaultEventsAPIRateLimit defines the rate limit, in events per second,
 	// for outgoing events.
 	DefaultEventsAPIRateLimit rate.Limit = 10.0
@@ -42,6 +47,19 @@ const (
 	// DefaultPassword specifies the default password
 	DefaultPassword = "P@ssw0rd!"
 
+	// DefaultOTLPDisable specifies if the OTLP metrics receiver is disabled
+	DefaultOTLPDisable = false
+
+	// DefaultOTLPFlushInterval specifies the default flush interval for OTLP
+	// metrics
+	DefaultOTLPFlushInterval = 10
+
+	// DefaultSocketHost specifies the default host of the check result sockets
+	DefaultSocketHost = "127.0.0.1"
+
+	// DefaultSocketPort specifies the default port of the check result sockets
+	DefaultSocketPort = 3030
+
 	// DefaultStatsdDisable specifies if the statsd listener is disabled
 	DefaultStatsdDisable = false
 
@@ -58,6 +76,10 @@ const (
 	// (in seconds) for the agent's cached system information.
 	DefaultSystemInfoRefreshInterval = 20
 
+	// DefaultProcessesMaxCount specifies the default maximum number of
+	// processes reported by the agent
+	DefaultProcessesMaxCount = process.DefaultMaxProcesses
+
 	// DefaultUser specifies the default user
 	DefaultUser = "agent"
 )
@@ -144,6 +166,13 @@ type Config struct {
 	// Annotations are key-value pairs that users can provide to agent entities
 	Annotations map[string]string
 
+	// LocalChecksDir is the path to a directory of check definitions, in YAML
+	// files, that the agent schedules and executes by itself.
+	LocalChecksDir string
+
+	// OTLP contains the OpenTelemetry metrics receiver configuration
+	OTLP *OTLPConfig
+
 	// Namespace sets the Agent's RBAC namespace identifier
 	Namespace string
 
@@ -153,6 +182,10 @@ type Config struct {
 	// Redact contains the fields to redact when marshalling the agent's entity
 	Redact []string
 
+	// Socket contains the configuration of the TCP and UDP sockets that
+	// accept check results
+	Socket *SocketConfig
+
 	// StatsdServer contains the statsd server configuration
 	StatsdServer *StatsdServerConfig
 
@@ -181,6 +214,20 @@ type Config struct {
 	// reconnect with exponential backoff
 	BackendHeartbeatTimeout int
 
+	// BackendSelector is the strategy used to select the backend to connect
+	// to: random, priority or latency. Default: random
+	BackendSelector string
+
+	// BackendPriorityGroups are the groups of backend URLs used by the
+	// priority backend selector, most preferred first. Every backend URL is
+	// a group of its own if empty.
+	BackendPriorityGroups [][]string
+
+	// BackendFailbackInterval is the interval at which the agent tries to
+	// return to a preferred backend, when it is connected to another one.
+	// Only the priority and latency backend selectors prefer some backends.
+	BackendFailbackInterval time.Duration
+
 	// MockSystemInfo determines whether the system info collection should return
 	// mocked system information. This should only be used for testing.
 	MockSystemInfo bool
@@ -211,6 +258,22 @@ type Config struct {
 	// StripNetworks is a boolean to specify if we need to strip network
 	// information from the agent entity state
 	StripNetworks bool
+
+	// CollectProcesses enables the collection of the agent's local processes,
+	// which are reported in the entity system information.
+	CollectProcesses bool
+
+	// ProcessesInclude is a list of regular expressions matched against
+	// process names and command lines. When not empty, only matching
+	// processes are reported.
+	ProcessesInclude []string
+
+	// ProcessesExclude is a list of regular expressions matched against
+	// process names and command lines. Matching processes are not reported.
+	ProcessesExclude []string
+
+	// ProcessesMaxCount is the maximum number of processes reported.
+	ProcessesMaxCount int
 }
 
 // StatsdServerConfig contains the statsd server configuration
@@ -222,6 +285,14 @@ type StatsdServerConfig struct {
 	Disable       bool
 }
 
+// OTLPConfig contains the configuration of the OpenTelemetry (OTLP/HTTP)
+// metrics receiver of the agent API
+type OTLPConfig struct {
+	FlushInterval int
+	Handlers      []string
+	Disable       bool
+}
+
 // FixtureConfig provides a new Config object initialized with defaults for use
 // in tests, as well as a cleanup function to call at the end of the test.
 func FixtureConfig() (*Config, func()) {
@@ -245,7 +316,16 @@ func FixtureConfig() (*Config, func()) {
 		KeepaliveInterval:       DefaultKeepaliveInterval,
 		KeepaliveWarningTimeout: corev2.DefaultKeepaliveTimeout,
 		Namespace:               DefaultNamespace,
-		Password:                DefaultPassword,
+		OTLP: &OTLPConfig{
+			FlushInterval: DefaultOTLPFlushInterval,
+			Handlers:      []string{},
+			Disable:       DefaultOTLPDisable,
+		},
+		Password: DefaultPassword,
+		Socket: &SocketConfig{
+			Host: DefaultSocketHost,
+			Port: DefaultSocketPort,
+		},
 		StatsdServer: &StatsdServerConfig{
 			Host:          DefaultStatsdMetricsHost,
 			Port:          DefaultStatsdMetricsPort,
@@ -266,6 +346,8 @@ func FixtureConfig() (*Config, func()) {
 func NewConfig() *Config {
 	c := &Config{
 		API:          &APIConfig{},
+		OTLP:         &OTLPConfig{},
+		Socket:       &SocketConfig{},
 		StatsdServer: &StatsdServerConfig{},
 	}
 	return c
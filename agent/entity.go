@@ -5,6 +5,7 @@ import (
 
 	corev2 "github.com/sensu/core/v2"
 	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/process"
 	"github.com/sensu/sensu-go/version"
 )
 
@@ -15,7 +16,11 @@ func (a *Agent) getAgentEntity() *corev2.Entity {
 		a.entityConfig = a.getLocalEntityConfig()
 	}
 
-	return v3EntityToV2(a.entityConfig, a.getEntityState())
+	entity := v3EntityToV2(a.entityConfig, a.getEntityState())
+	if details := a.getProcessDetails(); details != "" {
+		entity.Annotations[process.ProcessesAnnotation] = details
+	}
+	return entity
 }
 
 func (a *Agent) clearAgentEntity() {
@@ -77,6 +82,12 @@ func (a *Agent) getSystemInfo() corev2.System {
 	return *a.systemInfo
 }
 
+func (a *Agent) getProcessDetails() string {
+	a.systemInfoMu.RLock()
+	defer a.systemInfoMu.RUnlock()
+	return a.processDetails
+}
+
 // getEntities receives an event and verifies if we have a proxy entity, so it
 // can be added as the source, and ensures that the event uses the agent's
 // entity
@@ -1,11 +1,15 @@
 package agent
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
 
 	corev2 "github.com/sensu/core/v2"
 	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/process"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetAgentEntity(t *testing.T) {
@@ -51,6 +55,50 @@ func TestGetAgentEntity(t *testing.T) {
 	}
 }
 
+type fakeProcessLister []process.Info
+
+func (f fakeProcessLister) Get(ctx context.Context) ([]*corev2.Process, error) {
+	return process.ToCoreProcesses(f), nil
+}
+
+func (f fakeProcessLister) List(ctx context.Context) ([]process.Info, error) {
+	return f, nil
+}
+
+func TestGetAgentEntityProcesses(t *testing.T) {
+	nginx := process.Info{
+		PID:        42,
+		PPID:       1,
+		Name:       "nginx",
+		Cmdline:    "nginx: worker process",
+		User:       "www-data",
+		State:      "S",
+		CPUPercent: 1.5,
+		RSS:        4096,
+	}
+	agent := &Agent{
+		config: &Config{
+			AgentName:   "foo",
+			Namespace:   "default",
+			Annotations: map[string]string{"team": "ops"},
+		},
+		ProcessGetter: fakeProcessLister{nginx},
+	}
+	require.NoError(t, agent.RefreshSystemInfo(context.Background()))
+
+	entity := agent.getAgentEntity()
+	require.Len(t, entity.System.Processes, 1)
+	assert.Equal(t, "nginx", entity.System.Processes[0].Name)
+	assert.Equal(t, "ops", entity.Annotations["team"])
+
+	var details []process.Info
+	require.NoError(t, json.Unmarshal([]byte(entity.Annotations[process.ProcessesAnnotation]), &details))
+	assert.Equal(t, []process.Info{nginx}, details)
+
+	// The annotations of the agent configuration are left untouched
+	assert.NotContains(t, agent.config.Annotations, process.ProcessesAnnotation)
+}
+
 func TestGetEntities(t *testing.T) {
 	assert := assert.New(t)
 
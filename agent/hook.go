@@ -142,7 +142,7 @@ func (a *Agent) executeHook(ctx context.Context, hookConfig *corev2.HookConfig,
 	if hookConfig.Stdin {
 		input, err := json.Marshal(event)
 		if err != nil {
-			a.sendFailure(event, fmt.Errorf("error marshaling json from event: %s", err))
+			a.sendFailure(ctx, event, fmt.Errorf("error marshaling json from event: %s", err))
 			return nil
 		}
 		ex.Input = string(input)
@@ -0,0 +1,215 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/gogo/protobuf/proto"
+	"github.com/robfig/cron/v3"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/core/v3/types"
+)
+
+type localCheckKey struct{}
+
+// withLocalCheck marks the context of a local check execution, so that its
+// result is queued instead of sent over the websocket.
+func withLocalCheck(ctx context.Context) context.Context {
+	return context.WithValue(ctx, localCheckKey{}, true)
+}
+
+func isLocalCheck(ctx context.Context) bool {
+	local, _ := ctx.Value(localCheckKey{}).(bool)
+	return local
+}
+
+// loadLocalChecks reads the check definitions of the YAML files in dir. The
+// files may contain several definitions, separated by "---" lines. The checks
+// without a namespace are given the namespace of the agent.
+func loadLocalChecks(dir, namespace string) ([]*corev2.CheckConfig, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	var paths []string
+	for _, pattern := range []string{"*.yml", "*.yaml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+
+	var checks []*corev2.CheckConfig
+	names := make(map[string]string)
+	for _, path := range paths {
+		fileChecks, err := readLocalChecks(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading local checks from %s: %s", path, err)
+		}
+		for _, check := range fileChecks {
+			if check.Namespace == "" {
+				check.Namespace = namespace
+			}
+			if err := validateLocalCheck(check); err != nil {
+				return nil, fmt.Errorf("invalid local check %q in %s: %s", check.Name, path, err)
+			}
+			if other, ok := names[check.Name]; ok {
+				return nil, fmt.Errorf("local check %q in %s is already defined in %s", check.Name, path, other)
+			}
+			names[check.Name] = path
+			checks = append(checks, check)
+		}
+	}
+	return checks, nil
+}
+
+func readLocalChecks(path string) ([]*corev2.CheckConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var checks []*corev2.CheckConfig
+	var doc bytes.Buffer
+	decode := func() error {
+		defer doc.Reset()
+		if len(bytes.TrimSpace(doc.Bytes())) == 0 {
+			return nil
+		}
+		b, err := yaml.YAMLToJSON(doc.Bytes())
+		if err != nil {
+			return err
+		}
+		var w types.Wrapper
+		if err := json.Unmarshal(b, &w); err != nil {
+			return err
+		}
+		check, ok := w.Value.(*corev2.CheckConfig)
+		if !ok {
+			return fmt.Errorf("%s is not a check definition", w.Type)
+		}
+		// The metadata of the core/v2 resources is outside of their spec
+		var outer struct {
+			Metadata *corev2.ObjectMeta `json:"metadata"`
+		}
+		if err := json.Unmarshal(b, &outer); err != nil {
+			return err
+		}
+		if outer.Metadata != nil {
+			check.ObjectMeta = *outer.Metadata
+		}
+		checks = append(checks, check)
+		return nil
+	}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "---" {
+			if err := decode(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		doc.Write(scanner.Bytes())
+		doc.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := decode(); err != nil {
+		return nil, err
+	}
+	return checks, nil
+}
+
+// validateLocalCheck validates the check, and rejects the features that
+// require the backend.
+func validateLocalCheck(check *corev2.CheckConfig) error {
+	if err := check.Validate(); err != nil {
+		return err
+	}
+	if check.Interval == 0 && check.Cron == "" {
+		return errors.New("local checks must have an interval or a cron schedule")
+	}
+	if len(check.RuntimeAssets) > 0 {
+		return errors.New("local checks cannot have runtime assets")
+	}
+	if len(check.CheckHooks) > 0 {
+		return errors.New("local checks cannot have check hooks")
+	}
+	if check.ProxyEntityName != "" || check.ProxyRequests != nil {
+		return errors.New("local checks cannot be proxy checks")
+	}
+	return nil
+}
+
+// nextLocalCheckExecution returns the duration until the next execution of
+// the check. Interval checks are aligned on their interval, like the checks
+// scheduled by the backend.
+func nextLocalCheckExecution(now time.Time, check *corev2.CheckConfig) (time.Duration, error) {
+	if check.Cron != "" {
+		schedule, err := cron.ParseStandard(check.Cron)
+		if err != nil {
+			return 0, err
+		}
+		return schedule.Next(now).Sub(now), nil
+	}
+	interval := time.Duration(check.Interval) * time.Second
+	return interval - time.Duration(now.UnixNano())%interval, nil
+}
+
+// startLocalChecks schedules the executions of the local checks until the
+// context is canceled.
+func (a *Agent) startLocalChecks(ctx context.Context) {
+	for _, check := range a.localChecks {
+		logger.WithField("check", check.Name).Info("scheduling local check")
+		a.wg.Add(1)
+		go func(check *corev2.CheckConfig) {
+			defer a.wg.Done()
+			a.scheduleLocalCheck(ctx, check)
+		}(check)
+	}
+}
+
+func (a *Agent) scheduleLocalCheck(ctx context.Context, check *corev2.CheckConfig) {
+	for {
+		delay, err := nextLocalCheckExecution(time.Now(), check)
+		if err != nil {
+			logger.WithError(err).WithField("check", check.Name).Error("couldn't schedule local check")
+			return
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		a.executeLocalCheck(ctx, check)
+	}
+}
+
+func (a *Agent) executeLocalCheck(ctx context.Context, check *corev2.CheckConfig) {
+	// The check execution modifies the check configuration
+	request := &corev2.CheckRequest{
+		Config: proto.Clone(check).(*corev2.CheckConfig),
+		Issued: time.Now().Unix(),
+	}
+	if a.checkInProgress(request) {
+		logger.WithField("check", check.Name).Warn("local check execution still in progress")
+		return
+	}
+	a.executeCheck(withLocalCheck(ctx), request, a.getAgentEntity())
+}
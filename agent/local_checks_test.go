@@ -0,0 +1,136 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/command"
+	"github.com/sensu/sensu-go/testing/mockexecutor"
+	"github.com/sensu/sensu-go/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const localChecksFixture = `type: CheckConfig
+api_version: core/v2
+metadata:
+  name: disk
+spec:
+  command: check-disk
+  interval: 60
+---
+type: CheckConfig
+api_version: core/v2
+metadata:
+  name: ntp
+  namespace: ops
+spec:
+  command: check-ntp
+  cron: "*/5 * * * *"
+`
+
+func writeLocalChecks(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+}
+
+func TestLoadLocalChecks(t *testing.T) {
+	dir := t.TempDir()
+	writeLocalChecks(t, dir, "checks.yml", localChecksFixture)
+	writeLocalChecks(t, dir, "README.md", "not a check")
+
+	checks, err := loadLocalChecks(dir, "default")
+	require.NoError(t, err)
+	require.Len(t, checks, 2)
+	assert.Equal(t, "disk", checks[0].Name)
+	assert.Equal(t, "default", checks[0].Namespace)
+	assert.Equal(t, uint32(60), checks[0].Interval)
+	assert.Equal(t, "ntp", checks[1].Name)
+	assert.Equal(t, "ops", checks[1].Namespace)
+	assert.Equal(t, "*/5 * * * *", checks[1].Cron)
+
+	checks, err = loadLocalChecks("", "default")
+	assert.NoError(t, err)
+	assert.Empty(t, checks)
+}
+
+func TestLoadLocalChecksInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "not a check",
+			content: "type: Handler\napi_version: core/v2\nmetadata:\n  name: h\nspec:\n  type: pipe\n  command: cat\n",
+		},
+		{
+			name:    "no schedule",
+			content: "type: CheckConfig\nmetadata:\n  name: disk\nspec:\n  command: check-disk\n",
+		},
+		{
+			name:    "runtime assets",
+			content: "type: CheckConfig\nmetadata:\n  name: disk\nspec:\n  command: check-disk\n  interval: 60\n  runtime_assets: [disk]\n",
+		},
+		{
+			name:    "duplicate",
+			content: localChecksFixture + "---\n" + localChecksFixture,
+		},
+		{
+			name:    "invalid yaml",
+			content: "type: [CheckConfig\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeLocalChecks(t, dir, "checks.yaml", tt.content)
+			_, err := loadLocalChecks(dir, "default")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNextLocalCheckExecution(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 10, 0, time.UTC)
+
+	delay, err := nextLocalCheckExecution(now, &corev2.CheckConfig{Interval: 60})
+	require.NoError(t, err)
+	assert.Equal(t, 50*time.Second, delay)
+
+	delay, err = nextLocalCheckExecution(now, &corev2.CheckConfig{Cron: "*/5 * * * *"})
+	require.NoError(t, err)
+	assert.Equal(t, 4*time.Minute+50*time.Second, delay)
+
+	_, err = nextLocalCheckExecution(now, &corev2.CheckConfig{Cron: "invalid"})
+	assert.Error(t, err)
+}
+
+func TestExecuteLocalCheck(t *testing.T) {
+	config, cleanup := FixtureConfig()
+	defer cleanup()
+	agent, err := NewAgent(config)
+	require.NoError(t, err)
+	sendq := make(chan *transport.Message, 1)
+	agent.sendq = sendq
+	agent.apiQueue = newMemoryQueue(1)
+	ex := &mockexecutor.MockExecutor{}
+	agent.executor = ex
+	ex.Return(command.FixtureExecutionResponse(0, "ok"), nil)
+
+	check := corev2.FixtureCheckConfig("disk")
+	agent.executeLocalCheck(context.Background(), check)
+
+	// The result is queued on the API queue, not sent over the websocket
+	assert.Empty(t, sendq)
+	message, err := agent.apiQueue.Receive(context.Background())
+	require.NoError(t, err)
+	event := &corev2.Event{}
+	require.NoError(t, json.Unmarshal(decompressMessage(message.Body), event))
+	assert.Equal(t, "disk", event.Check.Name)
+	assert.Equal(t, "ok", event.Check.Output)
+}
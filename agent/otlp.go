@@ -0,0 +1,364 @@
+package agent
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sirupsen/logrus"
+	commonv1 "go.opentelemetry.io/proto/otlp/common/v1"
+	metricsv1 "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// OTLPMetricsPath is the path of the OTLP/HTTP metrics receiver.
+	OTLPMetricsPath = "/v1/metrics"
+
+	otlpProtobufContentType = "application/x-protobuf"
+	otlpJSONContentType     = "application/json"
+
+	// maxOTLPRequestSize is the maximum size of the OTLP requests, once
+	// decompressed.
+	maxOTLPRequestSize = 16 << 20
+
+	// maxOTLPPoints is the maximum number of metric points of the OTLP metric
+	// events. The received points are flushed as soon as they reach it, instead
+	// of growing until the next flush interval.
+	maxOTLPPoints = 10000
+)
+
+// otlpReceiver receives OpenTelemetry metrics on the agent API, and queues
+// them as metric events once per flush interval, or once maxOTLPPoints points
+// were received.
+type otlpReceiver struct {
+	agent  *Agent
+	mu     sync.Mutex
+	points []*corev2.MetricPoint
+}
+
+func newOTLPReceiver(a *Agent) *otlpReceiver {
+	return &otlpReceiver{agent: a}
+}
+
+// Run flushes the received metrics every flush interval, until the context is
+// canceled.
+func (o *otlpReceiver) Run(ctx context.Context) {
+	interval := o.agent.config.OTLP.FlushInterval
+	if interval <= 0 {
+		logger.Errorf("invalid otlp flush interval of %d, using the default %ds", interval, DefaultOTLPFlushInterval)
+		interval = DefaultOTLPFlushInterval
+	}
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			o.flush()
+			return
+		case <-ticker.C:
+			o.flush()
+		}
+	}
+}
+
+func (o *otlpReceiver) add(points []*corev2.MetricPoint) {
+	o.mu.Lock()
+	o.points = append(o.points, points...)
+	full := len(o.points) >= maxOTLPPoints
+	o.mu.Unlock()
+	if full {
+		o.flush()
+	}
+}
+
+// flush queues the metrics received since the last flush in metric events of
+// at most maxOTLPPoints points, so they get sent to the backend like the
+// events received by the API.
+func (o *otlpReceiver) flush() {
+	o.mu.Lock()
+	points := o.points
+	o.points = nil
+	o.mu.Unlock()
+	for len(points) > 0 {
+		n := len(points)
+		if n > maxOTLPPoints {
+			n = maxOTLPPoints
+		}
+		o.queue(points[:n])
+		points = points[n:]
+	}
+}
+
+func (o *otlpReceiver) queue(points []*corev2.MetricPoint) {
+	event := &corev2.Event{
+		Entity:    o.agent.getAgentEntity(),
+		Timestamp: time.Now().Unix(),
+		Metrics: &corev2.Metrics{
+			Points:   points,
+			Handlers: o.agent.config.OTLP.Handlers,
+		},
+	}
+	msg, err := o.agent.marshal(event)
+	if err != nil {
+		logger.WithError(err).Error("error marshaling otlp metric event")
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"points": len(points),
+		"entity": event.Entity.Name,
+	}).Debug("queueing otlp metrics")
+	if _, err := o.agent.apiQueue.Send(compressMessage(msg)); err != nil {
+		logger.WithError(err).Error("error queueing otlp metric event")
+	}
+}
+
+// ServeHTTP receives an OTLP/HTTP metrics export request, encoded in protobuf
+// or JSON.
+func (o *otlpReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (contentType != otlpProtobufContentType && contentType != otlpJSONContentType) {
+		http.Error(w, fmt.Sprintf("unsupported content type, must be %s or %s", otlpProtobufContentType, otlpJSONContentType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var body io.Reader = r.Body
+	switch r.Header.Get("Content-Encoding") {
+	case "":
+	case "gzip":
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer gz.Close()
+		body = gz
+	default:
+		http.Error(w, "unsupported content encoding", http.StatusUnsupportedMediaType)
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxOTLPRequestSize+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(data) > maxOTLPRequestSize {
+		http.Error(w, "request too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var resourceMetrics []*metricsv1.ResourceMetrics
+	if contentType == otlpJSONContentType {
+		resourceMetrics, err = unmarshalOTLPJSON(data)
+	} else {
+		resourceMetrics, err = unmarshalOTLPProtobuf(data)
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid metrics export request: %s", err), http.StatusBadRequest)
+		return
+	}
+	o.add(otlpMetricPoints(resourceMetrics, time.Now()))
+
+	// Reply with an empty export response
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	if contentType == otlpJSONContentType {
+		_, _ = w.Write([]byte("{}"))
+	}
+}
+
+// unmarshalOTLPProtobuf decodes the resource metrics, the repeated field 1,
+// of a protobuf ExportMetricsServiceRequest.
+func unmarshalOTLPProtobuf(data []byte) ([]*metricsv1.ResourceMetrics, error) {
+	var resourceMetrics []*metricsv1.ResourceMetrics
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		data = data[n:]
+		if num != 1 || typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			data = data[n:]
+			continue
+		}
+		value, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		data = data[n:]
+		rm := &metricsv1.ResourceMetrics{}
+		if err := proto.Unmarshal(value, rm); err != nil {
+			return nil, err
+		}
+		resourceMetrics = append(resourceMetrics, rm)
+	}
+	return resourceMetrics, nil
+}
+
+// unmarshalOTLPJSON decodes the resource metrics of a JSON
+// ExportMetricsServiceRequest.
+func unmarshalOTLPJSON(data []byte) ([]*metricsv1.ResourceMetrics, error) {
+	var request struct {
+		ResourceMetrics []map[string]json.RawMessage `json:"resourceMetrics"`
+	}
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, err
+	}
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	resourceMetrics := make([]*metricsv1.ResourceMetrics, 0, len(request.ResourceMetrics))
+	for _, fields := range request.ResourceMetrics {
+		// The instrumentation libraries were renamed scopes, with the same
+		// protobuf encoding
+		if scopeMetrics, ok := fields["scopeMetrics"]; ok {
+			fields["instrumentationLibraryMetrics"] = scopeMetrics
+			delete(fields, "scopeMetrics")
+		}
+		b, err := json.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+		rm := &metricsv1.ResourceMetrics{}
+		if err := unmarshaler.Unmarshal(b, rm); err != nil {
+			return nil, err
+		}
+		resourceMetrics = append(resourceMetrics, rm)
+	}
+	return resourceMetrics, nil
+}
+
+// otlpMetricPoints converts the gauges, sums and histograms to metric points,
+// tagged with the attributes of their resource and data points. The other
+// metric types are ignored. The histograms are converted to the count and sum
+// of their values, and to a point per bucket, tagged with its cumulative upper
+// bound like the Prometheus histograms.
+func otlpMetricPoints(resourceMetrics []*metricsv1.ResourceMetrics, now time.Time) []*corev2.MetricPoint {
+	var points []*corev2.MetricPoint
+	for _, rm := range resourceMetrics {
+		resourceTags := otlpTags(nil, rm.GetResource().GetAttributes())
+		for _, ilm := range rm.GetInstrumentationLibraryMetrics() {
+			for _, metric := range ilm.GetMetrics() {
+				switch data := metric.GetData().(type) {
+				case *metricsv1.Metric_Gauge:
+					points = append(points, otlpNumberPoints(metric.GetName(), data.Gauge.GetDataPoints(), resourceTags, now)...)
+				case *metricsv1.Metric_Sum:
+					points = append(points, otlpNumberPoints(metric.GetName(), data.Sum.GetDataPoints(), resourceTags, now)...)
+				case *metricsv1.Metric_Histogram:
+					points = append(points, otlpHistogramPoints(metric.GetName(), data.Histogram.GetDataPoints(), resourceTags, now)...)
+				default:
+					logger.WithField("metric", metric.GetName()).Debug("ignoring otlp metric of unsupported type")
+				}
+			}
+		}
+	}
+	return points
+}
+
+func otlpNumberPoints(name string, dataPoints []*metricsv1.NumberDataPoint, resourceTags []*corev2.MetricTag, now time.Time) []*corev2.MetricPoint {
+	points := make([]*corev2.MetricPoint, 0, len(dataPoints))
+	for _, dp := range dataPoints {
+		var value float64
+		switch v := dp.GetValue().(type) {
+		case *metricsv1.NumberDataPoint_AsDouble:
+			value = v.AsDouble
+		case *metricsv1.NumberDataPoint_AsInt:
+			value = float64(v.AsInt)
+		default:
+			continue
+		}
+		points = append(points, &corev2.MetricPoint{
+			Name:      name,
+			Value:     value,
+			Timestamp: otlpTimestamp(dp.GetTimeUnixNano(), now),
+			Tags:      otlpTags(resourceTags, dp.GetAttributes()),
+		})
+	}
+	return points
+}
+
+func otlpHistogramPoints(name string, dataPoints []*metricsv1.HistogramDataPoint, resourceTags []*corev2.MetricTag, now time.Time) []*corev2.MetricPoint {
+	var points []*corev2.MetricPoint
+	for _, dp := range dataPoints {
+		timestamp := otlpTimestamp(dp.GetTimeUnixNano(), now)
+		tags := otlpTags(resourceTags, dp.GetAttributes())
+		points = append(points,
+			&corev2.MetricPoint{Name: name + ".count", Value: float64(dp.GetCount()), Timestamp: timestamp, Tags: tags},
+			&corev2.MetricPoint{Name: name + ".sum", Value: dp.GetSum(), Timestamp: timestamp, Tags: tags},
+		)
+		bounds := dp.GetExplicitBounds()
+		var cumulative uint64
+		for i, count := range dp.GetBucketCounts() {
+			cumulative += count
+			bound := math.Inf(1)
+			if i < len(bounds) {
+				bound = bounds[i]
+			}
+			bucketTags := append(append([]*corev2.MetricTag{}, tags...), &corev2.MetricTag{
+				Name:  "le",
+				Value: strconv.FormatFloat(bound, 'g', -1, 64),
+			})
+			points = append(points, &corev2.MetricPoint{
+				Name:      name + ".bucket",
+				Value:     float64(cumulative),
+				Timestamp: timestamp,
+				Tags:      bucketTags,
+			})
+		}
+	}
+	return points
+}
+
+func otlpTimestamp(timeUnixNano uint64, now time.Time) int64 {
+	if timeUnixNano == 0 {
+		return now.UnixNano()
+	}
+	return int64(timeUnixNano)
+}
+
+// otlpTags returns the tags followed by the attributes.
+func otlpTags(tags []*corev2.MetricTag, attributes []*commonv1.KeyValue) []*corev2.MetricTag {
+	result := make([]*corev2.MetricTag, 0, len(tags)+len(attributes))
+	result = append(result, tags...)
+	for _, attribute := range attributes {
+		result = append(result, &corev2.MetricTag{
+			Name:  attribute.GetKey(),
+			Value: otlpAttributeValue(attribute.GetValue()),
+		})
+	}
+	return result
+}
+
+func otlpAttributeValue(value *commonv1.AnyValue) string {
+	switch v := value.GetValue().(type) {
+	case *commonv1.AnyValue_StringValue:
+		return v.StringValue
+	case *commonv1.AnyValue_BoolValue:
+		return strconv.FormatBool(v.BoolValue)
+	case *commonv1.AnyValue_IntValue:
+		return strconv.FormatInt(v.IntValue, 10)
+	case *commonv1.AnyValue_DoubleValue:
+		return strconv.FormatFloat(v.DoubleValue, 'g', -1, 64)
+	case nil:
+		return ""
+	}
+	b, err := protojson.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
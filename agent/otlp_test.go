@@ -0,0 +1,237 @@
+package agent
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	commonv1 "go.opentelemetry.io/proto/otlp/common/v1"
+	metricsv1 "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcev1 "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// The JSON encoding of the OTLP 1.0 metrics, with scopes
+const otlpJSONFixture = `{
+  "resourceMetrics": [{
+    "resource": {
+      "attributes": [{"key": "service.name", "value": {"stringValue": "checkout"}}]
+    },
+    "scopeMetrics": [{
+      "scope": {"name": "meter"},
+      "metrics": [
+        {
+          "name": "queue.size",
+          "gauge": {"dataPoints": [{"asInt": "42", "timeUnixNano": "1700000000000000000"}]}
+        },
+        {
+          "name": "http.server.duration",
+          "histogram": {
+            "aggregationTemporality": 2,
+            "dataPoints": [{
+              "attributes": [{"key": "http.method", "value": {"stringValue": "GET"}}],
+              "timeUnixNano": "1700000000000000000",
+              "count": "6",
+              "sum": 1.5,
+              "bucketCounts": ["1", "2", "3"],
+              "explicitBounds": [0.1, 0.5],
+              "min": 0.01
+            }]
+          }
+        },
+        {
+          "name": "ignored.summary",
+          "summary": {"dataPoints": [{"count": "1", "sum": 1}]}
+        }
+      ]
+    }]
+  }]
+}`
+
+func stringAttribute(key, value string) *commonv1.KeyValue {
+	return &commonv1.KeyValue{
+		Key:   key,
+		Value: &commonv1.AnyValue{Value: &commonv1.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+// otlpProtobufFixture returns an ExportMetricsServiceRequest with a sum.
+func otlpProtobufFixture(t *testing.T) []byte {
+	t.Helper()
+	rm := &metricsv1.ResourceMetrics{
+		Resource: &resourcev1.Resource{
+			Attributes: []*commonv1.KeyValue{stringAttribute("service.name", "checkout")},
+		},
+		InstrumentationLibraryMetrics: []*metricsv1.InstrumentationLibraryMetrics{{
+			Metrics: []*metricsv1.Metric{{
+				Name: "orders.total",
+				Data: &metricsv1.Metric_Sum{Sum: &metricsv1.Sum{
+					IsMonotonic: true,
+					DataPoints: []*metricsv1.NumberDataPoint{{
+						Attributes:   []*commonv1.KeyValue{stringAttribute("region", "eu")},
+						TimeUnixNano: 1700000000000000000,
+						Value:        &metricsv1.NumberDataPoint_AsDouble{AsDouble: 12.5},
+					}},
+				}},
+			}},
+		}},
+	}
+	b, err := proto.Marshal(rm)
+	require.NoError(t, err)
+	request := protowire.AppendTag(nil, 1, protowire.BytesType)
+	return protowire.AppendBytes(request, b)
+}
+
+func newOTLPTestAgent(t *testing.T) (*Agent, *memoryQueue) {
+	t.Helper()
+	config, cleanup := FixtureConfig()
+	t.Cleanup(cleanup)
+	config.OTLP.Handlers = []string{"influxdb"}
+	agent, err := NewAgent(config)
+	require.NoError(t, err)
+	require.NotNil(t, agent.otlp)
+	queue := newMemoryQueue(10)
+	agent.apiQueue = queue
+	return agent, queue
+}
+
+func postOTLP(agent *Agent, contentType string, body []byte, gzipped bool) *httptest.ResponseRecorder {
+	if gzipped {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, _ = gz.Write(body)
+		_ = gz.Close()
+		body = buf.Bytes()
+	}
+	req := httptest.NewRequest(http.MethodPost, OTLPMetricsPath, bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	if gzipped {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	w := httptest.NewRecorder()
+	agent.otlp.ServeHTTP(w, req)
+	return w
+}
+
+func tagValue(point *corev2.MetricPoint, name string) string {
+	for _, tag := range point.Tags {
+		if tag.Name == name {
+			return tag.Value
+		}
+	}
+	return ""
+}
+
+func TestOTLPReceiverProtobuf(t *testing.T) {
+	agent, queue := newOTLPTestAgent(t)
+
+	w := postOTLP(agent, "application/x-protobuf", otlpProtobufFixture(t), true)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "application/x-protobuf", w.Header().Get("Content-Type"))
+
+	// The metrics are queued on flush
+	assert.Empty(t, queue.queue)
+	agent.otlp.flush()
+	event := receiveQueuedEvent(t, agent)
+	require.NotNil(t, event.Metrics)
+	assert.Equal(t, []string{"influxdb"}, event.Metrics.Handlers)
+	require.Len(t, event.Metrics.Points, 1)
+	point := event.Metrics.Points[0]
+	assert.Equal(t, "orders.total", point.Name)
+	assert.Equal(t, 12.5, point.Value)
+	assert.Equal(t, int64(1700000000000000000), point.Timestamp)
+	assert.Equal(t, "checkout", tagValue(point, "service.name"))
+	assert.Equal(t, "eu", tagValue(point, "region"))
+
+	// Nothing is queued without new metrics
+	agent.otlp.flush()
+	assert.Empty(t, queue.queue)
+}
+
+func TestOTLPReceiverJSON(t *testing.T) {
+	agent, _ := newOTLPTestAgent(t)
+
+	w := postOTLP(agent, "application/json", []byte(otlpJSONFixture), false)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "{}", w.Body.String())
+	agent.otlp.flush()
+	event := receiveQueuedEvent(t, agent)
+
+	points := map[string][]*corev2.MetricPoint{}
+	for _, point := range event.Metrics.Points {
+		points[point.Name] = append(points[point.Name], point)
+		assert.Equal(t, "checkout", tagValue(point, "service.name"))
+	}
+	require.Len(t, points["queue.size"], 1)
+	assert.Equal(t, 42.0, points["queue.size"][0].Value)
+	require.Len(t, points["http.server.duration.count"], 1)
+	assert.Equal(t, 6.0, points["http.server.duration.count"][0].Value)
+	assert.Equal(t, "GET", tagValue(points["http.server.duration.count"][0], "http.method"))
+	require.Len(t, points["http.server.duration.sum"], 1)
+	assert.Equal(t, 1.5, points["http.server.duration.sum"][0].Value)
+
+	buckets := points["http.server.duration.bucket"]
+	require.Len(t, buckets, 3)
+	assert.Equal(t, "0.1", tagValue(buckets[0], "le"))
+	assert.Equal(t, 1.0, buckets[0].Value)
+	assert.Equal(t, "0.5", tagValue(buckets[1], "le"))
+	assert.Equal(t, 3.0, buckets[1].Value)
+	assert.Equal(t, "+Inf", tagValue(buckets[2], "le"))
+	assert.Equal(t, 6.0, buckets[2].Value)
+
+	assert.Empty(t, points["ignored.summary"])
+}
+
+func TestOTLPReceiverMaxPoints(t *testing.T) {
+	agent, queue := newOTLPTestAgent(t)
+
+	points := make([]*corev2.MetricPoint, maxOTLPPoints+1)
+	for i := range points {
+		points[i] = &corev2.MetricPoint{Name: "orders.total", Value: float64(i)}
+	}
+
+	// The points are flushed as soon as they reach the maximum, in events of
+	// at most the maximum number of points
+	agent.otlp.add(points[:maxOTLPPoints-1])
+	assert.Empty(t, queue.queue)
+	agent.otlp.add(points[maxOTLPPoints-1:])
+	require.Len(t, queue.queue, 2)
+	assert.Len(t, receiveQueuedEvent(t, agent).Metrics.Points, maxOTLPPoints)
+	assert.Len(t, receiveQueuedEvent(t, agent).Metrics.Points, 1)
+	assert.Empty(t, agent.otlp.points)
+}
+
+func TestOTLPReceiverInvalid(t *testing.T) {
+	agent, _ := newOTLPTestAgent(t)
+
+	w := postOTLP(agent, "text/plain", []byte("metrics"), false)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+	w = postOTLP(agent, "application/json", []byte("{"), false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = postOTLP(agent, "application/x-protobuf", []byte{0x0a, 0xff}, false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestOTLPReceiverDisabled(t *testing.T) {
+	config, cleanup := FixtureConfig()
+	defer cleanup()
+	config.OTLP.Disable = true
+	agent, err := NewAgent(config)
+	require.NoError(t, err)
+	assert.Nil(t, agent.otlp)
+
+	router := newServer(agent).Handler
+	req := httptest.NewRequest(http.MethodPost, OTLPMetricsPath, bytes.NewReader(otlpProtobufFixture(t)))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,289 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+)
+
+const (
+	// socketMaxMessageSize is the maximum size of a check result received on
+	// the sockets.
+	socketMaxMessageSize = 1 << 20
+
+	// socketReadTimeout is the maximum time to wait for data on a TCP
+	// connection before closing it.
+	socketReadTimeout = 30 * time.Second
+)
+
+var (
+	socketPing = []byte("ping")
+	socketPong = []byte("pong")
+	socketOK   = []byte("ok")
+	socketBad  = []byte("invalid")
+)
+
+// SocketConfig contains the configuration of the TCP and UDP sockets that
+// accept check results, like the Sensu Classic client socket.
+type SocketConfig struct {
+	Enable bool
+	Host   string
+	Port   int
+}
+
+// classicCheckResult is a check result as written to the Sensu Classic client
+// socket.
+type classicCheckResult struct {
+	Name     string   `json:"name"`
+	Output   string   `json:"output"`
+	Status   uint32   `json:"status"`
+	Source   string   `json:"source"`
+	Command  string   `json:"command"`
+	Interval uint32   `json:"interval"`
+	TTL      int64    `json:"ttl"`
+	Handler  string   `json:"handler"`
+	Handlers []string `json:"handlers"`
+	Executed int64    `json:"executed"`
+	Duration float64  `json:"duration"`
+}
+
+// StartSocketListeners starts the TCP and UDP sockets that accept check
+// results. The sockets are closed when the context is canceled.
+func (a *Agent) StartSocketListeners(ctx context.Context) error {
+	addr := fmt.Sprintf("%s:%d", a.config.Socket.Host, a.config.Socket.Port)
+	tcpListener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("couldn't start the TCP socket: %s", err)
+	}
+	udpListener, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		_ = tcpListener.Close()
+		return fmt.Errorf("couldn't start the UDP socket: %s", err)
+	}
+
+	logger.Info("starting the TCP and UDP sockets on address: ", addr)
+	a.wg.Add(2)
+	go a.serveTCPSocket(ctx, tcpListener)
+	go a.serveUDPSocket(ctx, udpListener)
+	return nil
+}
+
+// serveTCPSocket accepts the connections to the TCP socket until the context
+// is canceled.
+func (a *Agent) serveTCPSocket(ctx context.Context, listener net.Listener) {
+	defer a.wg.Done()
+	go func() {
+		<-ctx.Done()
+		logger.Info("TCP socket shutting down")
+		_ = listener.Close()
+	}()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			logger.WithError(err).Error("error accepting TCP socket connection")
+			return
+		}
+		go a.handleTCPConn(ctx, conn)
+	}
+}
+
+// handleTCPConn reads the check results written to a TCP connection. A check
+// result is delimited by a newline, or by the end of the connection, and is
+// acknowledged with "ok", or "invalid" if it can't be processed. A "ping" is
+// answered with a "pong".
+func (a *Agent) handleTCPConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	chunk := make([]byte, 4096)
+	var buf []byte
+	for ctx.Err() == nil {
+		_ = conn.SetReadDeadline(time.Now().Add(socketReadTimeout))
+		n, err := conn.Read(chunk)
+		buf = append(buf, chunk[:n]...)
+
+		var messages [][]byte
+		messages, buf = splitSocketMessages(buf)
+		if err != nil || len(buf) > socketMaxMessageSize {
+			// The remaining data is delimited by the end of the connection
+			if data := bytes.TrimSpace(buf); len(data) > 0 {
+				messages = append(messages, data)
+			}
+			buf = nil
+		}
+		for _, message := range messages {
+			if _, werr := conn.Write(a.handleSocketMessage(message)); werr != nil {
+				logger.WithError(werr).Debug("error writing to TCP socket connection")
+				return
+			}
+		}
+		if err != nil {
+			if err != io.EOF {
+				logger.WithError(err).Debug("error reading from TCP socket connection")
+			}
+			return
+		}
+	}
+}
+
+// splitSocketMessages returns the complete messages found in the data read
+// from a TCP connection, and the remaining data. Check results may span
+// several lines, so a line ends a message only if the message is valid JSON.
+func splitSocketMessages(data []byte) ([][]byte, []byte) {
+	var messages [][]byte
+	start := 0
+	for i := 0; i < len(data); i++ {
+		if data[i] != '\n' {
+			continue
+		}
+		message := bytes.TrimSpace(data[start:i])
+		if len(message) == 0 {
+			start = i + 1
+		} else if bytes.Equal(message, socketPing) || json.Valid(message) {
+			messages = append(messages, message)
+			start = i + 1
+		}
+	}
+	rest := data[start:]
+	if bytes.Equal(bytes.TrimSpace(rest), socketPing) {
+		// A ping isn't required to end with a newline
+		return append(messages, socketPing), nil
+	}
+	return messages, append([]byte(nil), rest...)
+}
+
+// serveUDPSocket reads the check results sent to the UDP socket until the
+// context is canceled. Each datagram contains a single check result, and only
+// a "ping" gets a response.
+func (a *Agent) serveUDPSocket(ctx context.Context, conn net.PacketConn) {
+	defer a.wg.Done()
+	go func() {
+		<-ctx.Done()
+		logger.Info("UDP socket shutting down")
+		_ = conn.Close()
+	}()
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			logger.WithError(err).Error("error reading from UDP socket")
+			return
+		}
+		data := bytes.TrimSpace(buf[:n])
+		if len(data) == 0 {
+			continue
+		}
+		response := a.handleSocketMessage(data)
+		if bytes.Equal(response, socketPong) {
+			if _, err := conn.WriteTo(response, addr); err != nil {
+				logger.WithError(err).Debug("error writing to UDP socket")
+			}
+		}
+	}
+}
+
+// handleSocketMessage processes a message received on a socket, and returns
+// the response to send back.
+func (a *Agent) handleSocketMessage(data []byte) []byte {
+	if bytes.Equal(data, socketPing) {
+		return socketPong
+	}
+	if len(data) > socketMaxMessageSize {
+		logger.Error("socket check result exceeds the maximum size")
+		return socketBad
+	}
+	event, err := decodeSocketEvent(data)
+	if err != nil {
+		logger.WithError(err).Error("invalid socket check result")
+		return socketBad
+	}
+	if err := a.queueSocketEvent(event); err != nil {
+		logger.WithError(err).Error("couldn't process socket check result")
+		return socketBad
+	}
+	return socketOK
+}
+
+// queueSocketEvent prepares an event received on a socket and queues it, so
+// it gets sent to the backend like the events received by the API.
+func (a *Agent) queueSocketEvent(event *corev2.Event) error {
+	if err := prepareEvent(a, event); err != nil {
+		return err
+	}
+	payload, err := a.marshal(event)
+	if err != nil {
+		return fmt.Errorf("error marshaling check result: %s", err)
+	}
+	logEvent(event)
+	if _, err := a.apiQueue.Send(compressMessage(payload)); err != nil {
+		return fmt.Errorf("error queueing message: %s", err)
+	}
+	return nil
+}
+
+// decodeSocketEvent decodes either a Sensu Go event, or a Sensu Classic check
+// result.
+func decodeSocketEvent(data []byte) (*corev2.Event, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	_, hasCheck := fields["check"]
+	_, hasMetrics := fields["metrics"]
+	if hasCheck || hasMetrics {
+		var event corev2.Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			return nil, err
+		}
+		return &event, nil
+	}
+
+	var result classicCheckResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	if result.Name == "" {
+		return nil, errors.New("the check result must have a name")
+	}
+	if result.Handler != "" {
+		result.Handlers = append(result.Handlers, result.Handler)
+	}
+	check := &corev2.Check{
+		ObjectMeta: corev2.ObjectMeta{Name: result.Name},
+		Output:     result.Output,
+		Status:     result.Status,
+		Command:    result.Command,
+		Interval:   result.Interval,
+		Ttl:        result.TTL,
+		Handlers:   result.Handlers,
+		Executed:   result.Executed,
+		Duration:   result.Duration,
+	}
+	event := &corev2.Event{Check: check}
+	if result.Source != "" {
+		event.Entity = &corev2.Entity{
+			ObjectMeta:  corev2.ObjectMeta{Name: result.Source},
+			EntityClass: corev2.EntityProxyClass,
+		}
+	}
+	return event, nil
+}
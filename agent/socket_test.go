@@ -0,0 +1,138 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func receiveQueuedEvent(t *testing.T, agent *Agent) *corev2.Event {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	message, err := agent.apiQueue.Receive(ctx)
+	require.NoError(t, err)
+	require.NoError(t, message.Ack())
+	var event corev2.Event
+	require.NoError(t, json.Unmarshal(decompressMessage(message.Body), &event))
+	return &event
+}
+
+func TestDecodeSocketEvent(t *testing.T) {
+	event, err := decodeSocketEvent([]byte(`{"name":"app","output":"down","status":2,"source":"db01","handler":"slack"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "app", event.Check.Name)
+	assert.Equal(t, "down", event.Check.Output)
+	assert.Equal(t, uint32(2), event.Check.Status)
+	assert.Equal(t, []string{"slack"}, event.Check.Handlers)
+	assert.Equal(t, "db01", event.Entity.Name)
+
+	event, err = decodeSocketEvent([]byte(`{"check":{"metadata":{"name":"app"},"status":1}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "app", event.Check.Name)
+	assert.Nil(t, event.Entity)
+
+	_, err = decodeSocketEvent([]byte(`{"output":"no name"}`))
+	assert.Error(t, err)
+
+	_, err = decodeSocketEvent([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestTCPSocket(t *testing.T) {
+	config, cleanup := FixtureConfig()
+	defer cleanup()
+	agent, err := NewAgent(config)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	agent.wg.Add(1)
+	go agent.serveTCPSocket(ctx, listener)
+
+	// ping
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "pong", string(buf))
+	_ = conn.Close()
+
+	// newline-delimited check results, one of which spans several lines
+	conn, err = net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("{\"name\":\"first\",\"output\":\"ok\"}\n{\n\"name\": \"second\",\n\"status\": 1\n}\n{\"status\":1}\n"))
+	require.NoError(t, err)
+	buf = make([]byte, len("okokinvalid"))
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "okokinvalid", string(buf))
+	_ = conn.Close()
+
+	// connection-delimited check result
+	conn, err = net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	_, err = conn.Write([]byte(`{"name":"third","source":"proxy"}`))
+	require.NoError(t, err)
+	require.NoError(t, conn.(*net.TCPConn).CloseWrite())
+	response, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(response))
+	_ = conn.Close()
+
+	first := receiveQueuedEvent(t, agent)
+	assert.Equal(t, "first", first.Check.Name)
+	assert.Equal(t, config.AgentName, first.Entity.Name)
+	assert.Equal(t, "second", receiveQueuedEvent(t, agent).Check.Name)
+	third := receiveQueuedEvent(t, agent)
+	assert.Equal(t, "third", third.Check.Name)
+	assert.Equal(t, "proxy", third.Check.ProxyEntityName)
+
+	cancel()
+	agent.wg.Wait()
+}
+
+func TestUDPSocket(t *testing.T) {
+	config, cleanup := FixtureConfig()
+	defer cleanup()
+	agent, err := NewAgent(config)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	agent.wg.Add(1)
+	go agent.serveUDPSocket(ctx, packetConn)
+
+	conn, err := net.Dial("udp", packetConn.LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, 4)
+	_, err = conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "pong", string(buf))
+
+	_, err = conn.Write([]byte(`{"name":"udp","output":"ok"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "udp", receiveQueuedEvent(t, agent).Check.Name)
+
+	cancel()
+	agent.wg.Wait()
+}
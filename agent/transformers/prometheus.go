@@ -60,14 +60,22 @@ func ParseProm(event *v2.Event) PromList {
 		"check":	event.Check.Name,
 	}
 
-	t := strings.NewReader(event.Check.Output)
-	var parser expfmt.TextParser
-	metricFamilies, err := parser.TextToMetricFamilies(t)
-
+	p, err := ParsePromText(event.Check.Output, event.Check.OutputMetricTags)
 	if err != nil {
 		logger.WithFields(fields).WithError(ErrMetricExtraction).Error(err)
 	}
 
+	return p
+}
+
+// ParsePromText parses a Prometheus Exposition Text Formated string into
+// an Prometheus Vector (sample), with the given tags added to its samples. It
+// returns the samples parsed before any error.
+func ParsePromText(text string, tags []*v2.MetricTag) (PromList, error) {
+	t := strings.NewReader(text)
+	var parser expfmt.TextParser
+	metricFamilies, err := parser.TextToMetricFamilies(t)
+
 	p := PromList{}
 
 	decodeOptions := &expfmt.DecodeOptions{
@@ -86,13 +94,13 @@ func ParseProm(event *v2.Event) PromList {
 		p = append(p, familySamples...)
 	}
 
-	if len(event.Check.OutputMetricTags) > 0 {
+	if len(tags) > 0 {
 		for _, prom := range p {
-			for _, tag := range event.Check.OutputMetricTags {
+			for _, tag := range tags {
 				prom.Metric[model.LabelName(tag.Name)] = model.LabelValue(tag.Value)
 			}
 		}
 	}
 
-	return p
+	return p, err
 }
@@ -287,3 +287,17 @@ func TestTransformProm(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePromText(t *testing.T) {
+	assert := assert.New(t)
+
+	tags := []*v2.MetricTag{{Name: "team", Value: "ops"}}
+	p, err := ParsePromText("# TYPE go_goroutines gauge\ngo_goroutines 12\n", tags)
+	assert.NoError(err)
+	assert.Len(p, 1)
+	assert.Equal(model.LabelValue("ops"), p[0].Metric["team"])
+	assert.Equal(model.LabelValue("gauge"), p[0].Metric[PromTypeTagName])
+
+	_, err = ParsePromText("not { metrics", nil)
+	assert.Error(err)
+}
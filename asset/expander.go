@@ -1,9 +1,15 @@
 package asset
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
 
 	archiver "github.com/mholt/archiver/v3"
 
@@ -14,10 +20,30 @@ import (
 const (
 	// Size of file header for sniffing type
 	headerSize = 262
+
+	// DefaultMaxExpandedSize is the maximum total size, in bytes, of the
+	// files expanded from an asset archive.
+	DefaultMaxExpandedSize int64 = 4 << 30
+
+	// DefaultMaxExpandedFiles is the maximum number of entries expanded from
+	// an asset archive.
+	DefaultMaxExpandedFiles = 100000
+
+	// maxSymlinkSize is the maximum size of a symbolic link target stored in
+	// a zip archive.
+	maxSymlinkSize = 4096
+
+	// maxSymlinks is the maximum number of symbolic links followed to resolve
+	// the target of a symbolic link.
+	maxSymlinks = 40
 )
 
 var (
 	defaultExpander = &archiveExpander{}
+
+	// zstd isn't known by filetype
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	typeZstd  = filetype_types.NewType("zst", "application/zstd")
 )
 
 // An Expander expands the provided *os.File to the target direcrtory.
@@ -26,12 +52,26 @@ type Expander interface {
 }
 
 // A archiveExpander detects the archive type and expands it to the local
-// filesystem.
+// filesystem. Entries that would be written outside of the target directory,
+// that would overwrite an existing file, or that link outside of the target
+// directory are rejected, and the expansion fails if the archive expands to
+// more than MaxSize bytes or MaxFiles entries.
 //
 // Supported archive types:
 // - tar
 // - tar-gzip
-type archiveExpander struct{}
+// - tar-xz
+// - tar-zstd
+// - zip
+type archiveExpander struct {
+	// MaxSize is the maximum total size of the expanded files. Defaults to
+	// DefaultMaxExpandedSize.
+	MaxSize int64
+
+	// MaxFiles is the maximum number of expanded entries. Defaults to
+	// DefaultMaxExpandedFiles.
+	MaxFiles int
+}
 
 type namer interface {
 	Name() string
@@ -45,7 +85,7 @@ func (a *archiveExpander) Expand(archive io.ReadSeeker, targetDirectory string)
 		return err
 	}
 
-	var ar archiver.Unarchiver
+	var ar archiver.Walker
 
 	// If the file is not an archive, exit with an error.
 	switch ft.MIME.Value {
@@ -53,6 +93,12 @@ func (a *archiveExpander) Expand(archive io.ReadSeeker, targetDirectory string)
 		ar = archiver.NewTar()
 	case "application/gzip":
 		ar = archiver.NewTarGz()
+	case "application/x-xz":
+		ar = archiver.NewTarXz()
+	case typeZstd.MIME.Value:
+		ar = archiver.NewTarZstd()
+	case "application/zip":
+		ar = archiver.NewZip()
 
 	default:
 		return fmt.Errorf(
@@ -66,14 +112,261 @@ func (a *archiveExpander) Expand(archive io.ReadSeeker, targetDirectory string)
 		return errors.New("couldn't get path to archive")
 	}
 
+	root, err := filepath.Abs(targetDirectory)
+	if err != nil {
+		return fmt.Errorf("error extracting asset: %s", err)
+	}
+	e := &expansion{
+		root:      root,
+		sizeLeft:  a.MaxSize,
+		filesLeft: a.MaxFiles,
+		entries:   make(map[string]struct{}),
+	}
+	if e.sizeLeft <= 0 {
+		e.sizeLeft = DefaultMaxExpandedSize
+	}
+	if e.filesLeft <= 0 {
+		e.filesLeft = DefaultMaxExpandedFiles
+	}
+
 	// Extract the archive to the desired path
-	if err := ar.Unarchive(namer.Name(), targetDirectory); err != nil {
+	if err := ar.Walk(namer.Name(), e.expand); err != nil {
 		return fmt.Errorf("error extracting asset: %s", err)
 	}
 
 	return nil
 }
 
+// expansion tracks the extraction of a single archive.
+type expansion struct {
+	root      string
+	sizeLeft  int64
+	filesLeft int
+
+	// entries are the paths of the expanded entries
+	entries map[string]struct{}
+}
+
+func (e *expansion) expand(f archiver.File) error {
+	e.filesLeft--
+	if e.filesLeft < 0 {
+		return errors.New("archive contains too many files")
+	}
+
+	switch header := f.Header.(type) {
+	case *tar.Header:
+		switch header.Typeflag {
+		case tar.TypeDir:
+			return e.mkdir(header.Name)
+		case tar.TypeReg, tar.TypeRegA:
+			return e.writeFile(header.Name, f, f.Mode())
+		case tar.TypeSymlink:
+			return e.symlink(header.Name, header.Linkname)
+		case tar.TypeLink:
+			return e.link(header.Name, header.Linkname)
+		default:
+			// devices, fifos and metadata entries are not expanded
+			return nil
+		}
+	case zip.FileHeader:
+		switch {
+		case f.IsDir():
+			return e.mkdir(header.Name)
+		case f.Mode()&os.ModeSymlink != 0:
+			linkname, err := io.ReadAll(io.LimitReader(f, maxSymlinkSize))
+			if err != nil {
+				return err
+			}
+			return e.symlink(header.Name, string(linkname))
+		default:
+			return e.writeFile(header.Name, f, f.Mode())
+		}
+	default:
+		return fmt.Errorf("unexpected archive header %T", f.Header)
+	}
+}
+
+// path returns the path of an archive entry in the target directory, or an
+// error if the entry would be written outside of it, directly or through a
+// symbolic link.
+func (e *expansion) path(name string) (string, error) {
+	if filepath.IsAbs(filepath.FromSlash(name)) {
+		return "", fmt.Errorf("illegal file path: %s", name)
+	}
+	target := filepath.Join(e.root, filepath.FromSlash(name))
+	if !within(e.root, target) {
+		return "", fmt.Errorf("illegal file path: %s", name)
+	}
+	// none of the parent directories of the entry may be a symbolic link
+	rel, _ := filepath.Rel(e.root, filepath.Dir(target))
+	dir := e.root
+	for _, elem := range strings.Split(rel, string(filepath.Separator)) {
+		if elem == "." || elem == "" {
+			continue
+		}
+		dir = filepath.Join(dir, elem)
+		fi, err := os.Lstat(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				break
+			}
+			return "", err
+		}
+		if fi.Mode()&os.ModeSymlink != 0 {
+			return "", fmt.Errorf("illegal file path through symbolic link: %s", name)
+		}
+	}
+	return target, nil
+}
+
+// entry returns the path of a new archive entry in the target directory, or an
+// error if the entry would be written outside of it, or if the archive already
+// contained it.
+func (e *expansion) entry(name string) (string, error) {
+	target, err := e.path(name)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := e.entries[target]; ok {
+		return "", fmt.Errorf("duplicate archive entry: %s", name)
+	}
+	e.entries[target] = struct{}{}
+	return target, nil
+}
+
+// resolve resolves the path name, relative to the directory dir of the target
+// directory, following the symbolic links expanded so far, and returns an
+// error if it resolves outside of the target directory. The path elements that
+// don't exist yet may be expanded later, possibly as symbolic links, so they
+// can't be followed by "..".
+func (e *expansion) resolve(dir, name string, links *int) (string, error) {
+	missing := false
+	for _, elem := range strings.Split(filepath.FromSlash(name), string(filepath.Separator)) {
+		switch elem {
+		case "", ".":
+			continue
+		case "..":
+			if missing || dir == e.root {
+				return "", errors.New("path resolves outside of the target directory")
+			}
+			dir = filepath.Dir(dir)
+			continue
+		}
+		next := filepath.Join(dir, elem)
+		if !missing {
+			fi, err := os.Lstat(next)
+			switch {
+			case os.IsNotExist(err):
+				missing = true
+			case err != nil:
+				return "", err
+			case fi.Mode()&os.ModeSymlink != 0:
+				*links++
+				if *links > maxSymlinks {
+					return "", errors.New("too many levels of symbolic links")
+				}
+				linkname, err := os.Readlink(next)
+				if err != nil {
+					return "", err
+				}
+				if filepath.IsAbs(linkname) {
+					return "", errors.New("absolute symbolic link")
+				}
+				if next, err = e.resolve(dir, linkname, links); err != nil {
+					return "", err
+				}
+			}
+		}
+		dir = next
+	}
+	return dir, nil
+}
+
+func (e *expansion) mkdir(name string) error {
+	target, err := e.entry(name)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(target, 0755)
+}
+
+func (e *expansion) writeFile(name string, r io.Reader, mode os.FileMode) error {
+	target, err := e.entry(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	// existing files, and symbolic links in particular, are never overwritten
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode.Perm())
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	// read one byte past the limit to detect archives that are too large
+	written, err := io.Copy(out, io.LimitReader(r, e.sizeLeft+1))
+	if err != nil {
+		return err
+	}
+	e.sizeLeft -= written
+	if e.sizeLeft < 0 {
+		return errors.New("archive exceeds the maximum expanded size")
+	}
+	return out.Close()
+}
+
+func (e *expansion) symlink(name, linkname string) error {
+	target, err := e.entry(name)
+	if err != nil {
+		return err
+	}
+	if filepath.IsAbs(filepath.FromSlash(linkname)) {
+		return fmt.Errorf("illegal symbolic link: %s -> %s", name, linkname)
+	}
+	var links int
+	if _, err := e.resolve(filepath.Dir(target), linkname, &links); err != nil {
+		return fmt.Errorf("illegal symbolic link: %s -> %s: %s", name, linkname, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	return os.Symlink(linkname, target)
+}
+
+func (e *expansion) link(name, linkname string) error {
+	target, err := e.entry(name)
+	if err != nil {
+		return err
+	}
+	source, err := e.path(linkname)
+	if err != nil {
+		return err
+	}
+	// a hard link to a symbolic link would copy it to another directory,
+	// where it could resolve outside of the target directory
+	fi, err := os.Lstat(source)
+	if err != nil {
+		return err
+	}
+	if !fi.Mode().IsRegular() {
+		return fmt.Errorf("illegal hard link to a non-regular file: %s -> %s", name, linkname)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	return os.Link(source, target)
+}
+
+// within returns true if sub is parent, or is contained in parent.
+func within(parent, sub string) bool {
+	rel, err := filepath.Rel(parent, sub)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 func sniffType(f io.ReadSeeker) (filetype_types.Type, error) {
 	header := make([]byte, headerSize)
 	if _, err := f.Read(header); err != nil {
@@ -83,6 +376,9 @@ func sniffType(f io.ReadSeeker) (filetype_types.Type, error) {
 	if err != nil {
 		return ft, err
 	}
+	if ft == filetype.Unknown && bytes.HasPrefix(header, zstdMagic) {
+		ft = typeZstd
+	}
 
 	if _, err := f.Seek(0, 0); err != nil {
 		return filetype_types.Type{}, err
@@ -1,6 +1,7 @@
 package asset
 
 import (
+	"archive/tar"
 	"os"
 	"path/filepath"
 	"testing"
@@ -66,10 +67,242 @@ func TestExpandValidTGZ(t *testing.T) {
 	}
 }
 
+func TestExpandCompressedArchives(t *testing.T) {
+	t.Parallel()
+
+	for _, fixture := range []string{
+		"rubby-on-rails.tar.xz",
+		"rubby-on-rails.tar.zst",
+		"rubby-on-rails.zip",
+	} {
+		fixture := fixture
+		t.Run(fixture, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := os.Open(getFixturePath(fixture))
+			if err != nil {
+				t.Fatalf("unable to open asset fixture, err: %v", err)
+			}
+			defer f.Close()
+
+			targetDirectory := t.TempDir()
+			expander := &archiveExpander{}
+			if err := expander.Expand(f, targetDirectory); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			b, err := os.ReadFile(filepath.Join(targetDirectory, "bin", "rails"))
+			if err != nil {
+				t.Fatalf("could not read asset contents, err: %v", err)
+			}
+			if len(b) == 0 {
+				t.Error("expected asset contents, got empty file")
+			}
+		})
+	}
+}
+
+func TestExpandMaliciousArchives(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		headers  []*tar.Header
+		maxSize  int64
+		maxFiles int
+	}{
+		{
+			name: "path traversal",
+			headers: []*tar.Header{
+				{Name: "../escaped", Typeflag: tar.TypeReg, Mode: 0644, Size: 4},
+			},
+		},
+		{
+			name: "absolute path",
+			headers: []*tar.Header{
+				{Name: "/tmp/escaped", Typeflag: tar.TypeReg, Mode: 0644, Size: 4},
+			},
+		},
+		{
+			name: "symlink escape",
+			headers: []*tar.Header{
+				{Name: "bin", Typeflag: tar.TypeSymlink, Linkname: "../../"},
+			},
+		},
+		{
+			name: "write through symlink",
+			headers: []*tar.Header{
+				{Name: "bin", Typeflag: tar.TypeSymlink, Linkname: "."},
+				{Name: "bin/rails", Typeflag: tar.TypeReg, Mode: 0644, Size: 4},
+			},
+		},
+		{
+			name: "write through symlink resolved outside",
+			headers: []*tar.Header{
+				{Name: "a", Typeflag: tar.TypeSymlink, Linkname: "."},
+				{Name: "c", Typeflag: tar.TypeSymlink, Linkname: "a/../escaped"},
+				{Name: "c", Typeflag: tar.TypeReg, Mode: 0644, Size: 4},
+			},
+		},
+		{
+			name: "symlink through a later symlink",
+			headers: []*tar.Header{
+				{Name: "c", Typeflag: tar.TypeSymlink, Linkname: "a/../escaped"},
+				{Name: "a", Typeflag: tar.TypeSymlink, Linkname: "."},
+			},
+		},
+		{
+			name: "overwrite symlink",
+			headers: []*tar.Header{
+				{Name: "c", Typeflag: tar.TypeSymlink, Linkname: "b"},
+				{Name: "c", Typeflag: tar.TypeReg, Mode: 0644, Size: 4},
+			},
+		},
+		{
+			name: "duplicate file",
+			headers: []*tar.Header{
+				{Name: "a", Typeflag: tar.TypeReg, Mode: 0644, Size: 4},
+				{Name: "./a", Typeflag: tar.TypeReg, Mode: 0644, Size: 4},
+			},
+		},
+		{
+			name: "hardlink to symlink",
+			headers: []*tar.Header{
+				{Name: "a/b/up", Typeflag: tar.TypeSymlink, Linkname: "../.."},
+				{Name: "up", Typeflag: tar.TypeLink, Linkname: "a/b/up"},
+			},
+		},
+		{
+			name: "hardlink escape",
+			headers: []*tar.Header{
+				{Name: "passwd", Typeflag: tar.TypeLink, Linkname: "../../../etc/passwd"},
+			},
+		},
+		{
+			name: "too large",
+			headers: []*tar.Header{
+				{Name: "a", Typeflag: tar.TypeReg, Mode: 0644, Size: 4},
+				{Name: "b", Typeflag: tar.TypeReg, Mode: 0644, Size: 4},
+			},
+			maxSize: 6,
+		},
+		{
+			name: "too many files",
+			headers: []*tar.Header{
+				{Name: "a", Typeflag: tar.TypeReg, Mode: 0644, Size: 4},
+				{Name: "b", Typeflag: tar.TypeReg, Mode: 0644, Size: 4},
+			},
+			maxFiles: 1,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			tmpDir := t.TempDir()
+			f := writeTar(t, filepath.Join(tmpDir, "asset.tar"), test.headers)
+			defer f.Close()
+
+			targetDirectory := filepath.Join(tmpDir, "asset")
+			if err := os.Mkdir(targetDirectory, 0755); err != nil {
+				t.Fatalf("unable to create target directory, err: %v", err)
+			}
+
+			expander := &archiveExpander{MaxSize: test.maxSize, MaxFiles: test.maxFiles}
+			if err := expander.Expand(f, targetDirectory); err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if _, err := os.Lstat(filepath.Join(tmpDir, "escaped")); err == nil {
+				t.Error("file was written outside of the target directory")
+			}
+		})
+	}
+}
+
+func TestExpandExistingSymlink(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	f := writeTar(t, filepath.Join(tmpDir, "asset.tar"), []*tar.Header{
+		{Name: "c", Typeflag: tar.TypeReg, Mode: 0644, Size: 4},
+	})
+	defer f.Close()
+
+	targetDirectory := filepath.Join(tmpDir, "asset")
+	if err := os.Mkdir(targetDirectory, 0755); err != nil {
+		t.Fatalf("unable to create target directory, err: %v", err)
+	}
+	if err := os.Symlink("../escaped", filepath.Join(targetDirectory, "c")); err != nil {
+		t.Fatal(err)
+	}
+
+	expander := &archiveExpander{}
+	if err := expander.Expand(f, targetDirectory); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if _, err := os.Lstat(filepath.Join(tmpDir, "escaped")); err == nil {
+		t.Error("file was written outside of the target directory")
+	}
+}
+
+func TestExpandArchiveWithSymlink(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	f := writeTar(t, filepath.Join(tmpDir, "asset.tar"), []*tar.Header{
+		{Name: "bin/", Typeflag: tar.TypeDir, Mode: 0755},
+		{Name: "bin/rails", Typeflag: tar.TypeReg, Mode: 0755, Size: 4},
+		{Name: "bin/rake", Typeflag: tar.TypeSymlink, Linkname: "rails"},
+		{Name: "bin/ruby", Typeflag: tar.TypeLink, Linkname: "bin/rails"},
+	})
+	defer f.Close()
+
+	targetDirectory := filepath.Join(tmpDir, "asset")
+	expander := &archiveExpander{}
+	if err := expander.Expand(f, targetDirectory); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, name := range []string{"rails", "rake", "ruby"} {
+		if _, err := os.Stat(filepath.Join(targetDirectory, "bin", name)); err != nil {
+			t.Errorf("expected bin/%s to be expanded, err: %v", name, err)
+		}
+	}
+}
+
+// writeTar writes a tar archive with the given headers to path, filling
+// regular files with their size in bytes, and returns it opened for reading.
+func writeTar(t *testing.T, path string, headers []*tar.Header) *os.File {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := tar.NewWriter(f)
+	for _, header := range headers {
+		if err := w.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			if _, err := w.Write(make([]byte, header.Size)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
 func TestExpandUnsupportedArchive(t *testing.T) {
 	t.Parallel()
 
-	assetPath := getFixturePath("unsupported.zip")
+	assetPath := getFixturePath("unsupported.tar.bz2")
 	f, err := os.Open(assetPath)
 	if err != nil {
 		t.Fatalf("unable to open asset fixture, err: %v", err)
@@ -78,7 +311,7 @@ func TestExpandUnsupportedArchive(t *testing.T) {
 
 	tmpDir, remove := testutil.TempDir(t)
 	defer remove()
-	targetDirectory := filepath.Join(tmpDir, "unsupported-zip")
+	targetDirectory := filepath.Join(tmpDir, "unsupported-tar-bz2")
 	if err := os.Mkdir(targetDirectory, 0755); err != nil {
 		t.Fatalf("unable to create target directory, err: %v", err)
 	}
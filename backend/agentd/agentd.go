@@ -2,6 +2,8 @@ package agentd
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"log"
 	"net"
@@ -98,6 +100,7 @@ type Agentd struct {
 	watcher        <-chan []storev2.WatchEvent
 	healthRouter   routers.Router
 	authenticator  Authenticator
+	certAuth       *CertificateAuth
 }
 
 // Config configures an Agentd.
@@ -112,6 +115,11 @@ type Config struct {
 	Watcher       <-chan []storev2.WatchEvent
 	HealthRouter  routers.Router
 	Authenticator Authenticator
+
+	// CertificateAuth authenticates the agents by their TLS client
+	// certificates, if not nil. The agents without certificates are still
+	// authenticated by username and password.
+	CertificateAuth *CertificateAuth
 }
 
 // Option is a functional option.
@@ -136,6 +144,7 @@ func New(c Config, opts ...Option) (*Agentd, error) {
 		store:         c.Store,
 		watcher:       c.Watcher,
 		authenticator: c.Authenticator,
+		certAuth:      c.CertificateAuth,
 	}
 
 	// prepare server TLS config
@@ -143,6 +152,19 @@ func New(c Config, opts ...Option) (*Agentd, error) {
 	if err != nil {
 		return nil, err
 	}
+	if c.CertificateAuth != nil {
+		if c.TLS == nil || tlsServerConfig.ClientCAs == nil {
+			return nil, errors.New("agent certificate authentication requires TLS and a trusted CA file")
+		}
+		if err := c.CertificateAuth.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid agent certificate authentication: %s", err)
+		}
+		// Verify the client certificates without requiring them, so that the
+		// agents can still use basic auth
+		if tlsServerConfig.ClientAuth != tls.RequireAndVerifyClientCert {
+			tlsServerConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
 
 	// Configure the middlewares used by agentd's HTTP server by assigning them to
 	// public variables so they can be overriden from the enterprise codebase
@@ -288,6 +310,17 @@ func (a *Agentd) Err() <-chan error {
 	return a.errChan
 }
 
+// MutualTLS returns true if agentd verifies the client certificates of the
+// agents. Only the sessions of agents with a verified certificate are sent
+// check secrets.
+func (a *Agentd) MutualTLS() bool {
+	config := a.httpServer.TLSConfig
+	if config == nil {
+		return false
+	}
+	return config.ClientAuth == tls.RequireAndVerifyClientCert || config.ClientAuth == tls.VerifyClientCertIfGiven
+}
+
 // Name returns the daemon name
 func (a *Agentd) Name() string {
 	return "agentd"
@@ -366,6 +399,7 @@ func (a *Agentd) webSocketHandler(w http.ResponseWriter, r *http.Request) {
 		Storev2:       a.store,
 		Marshal:       marshal,
 		Unmarshal:     unmarshal,
+		MutualTLS:     r.TLS != nil && len(r.TLS.VerifiedChains) > 0,
 	}
 
 	cfg.Subscriptions = corev2.AddEntitySubscription(cfg.AgentName, cfg.Subscriptions)
@@ -402,6 +436,23 @@ func (a *Agentd) webSocketHandler(w http.ResponseWriter, r *http.Request) {
 // agentd, which consists of basic authentication.
 func (a *Agentd) AuthenticationMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Authenticate the agent by its verified client certificate, if any
+		if a.certAuth != nil && r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+			agentName := r.Header.Get(transport.HeaderKeyAgentName)
+			claims, err := a.certAuth.Authenticate(agentName, r.TLS.VerifiedChains[0])
+			if err != nil {
+				logger.
+					WithField("agent", agentName).
+					WithError(err).
+					Error("invalid client certificate")
+				http.Error(w, "bad certificate", http.StatusUnauthorized)
+				return
+			}
+			ctx := jwt.SetClaimsIntoContext(r, claims)
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
 		username, password, ok := r.BasicAuth()
 		if !ok {
 			http.Error(w, "missing credentials", http.StatusUnauthorized)
@@ -0,0 +1,221 @@
+package agentd
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/authentication/jwt"
+)
+
+// DefaultCertificateGroups are the default groups of the agents authenticated
+// by certificate, which are granted the system-agent cluster role.
+var DefaultCertificateGroups = []string{"system:agents"}
+
+// CertificateUsernamePrefix prefixes the usernames of the agents
+// authenticated by certificate, so that they can't be mistaken for users.
+const CertificateUsernamePrefix = "agent:"
+
+// The fields of the client certificates that can be mapped to groups.
+const (
+	CertificateFieldCommonName         = "cn"
+	CertificateFieldOrganization       = "o"
+	CertificateFieldOrganizationalUnit = "ou"
+	CertificateFieldDNSName            = "dns"
+	CertificateFieldURI                = "uri"
+	CertificateFieldEmail              = "email"
+)
+
+// CertificateAuth authenticates the agents by their verified TLS client
+// certificates, instead of their username and password. An agent must be
+// named after the common name, a DNS SAN or a URI SAN of its certificate, and
+// is authenticated as the user agent:<name>. As the agents connect with mutual
+// TLS, they are sent the secrets of their checks.
+type CertificateAuth struct {
+	// Groups are the groups of every agent authenticated by certificate.
+	Groups []string
+
+	// GroupFields maps fields of the certificates to group prefixes. Each
+	// value of a field adds a group to the agent, made of the prefix and the
+	// value. For example, "ou": "ou:" maps the OU=ops certificates to the
+	// ou:ops group. The prefixes can't be empty, so that the certificates
+	// can't grant arbitrary groups, such as cluster-admins.
+	GroupFields map[string]string
+
+	// CRLFile is the path to a certificate revocation list, in PEM or DER
+	// format. The certificates it revokes are rejected. The list is reloaded
+	// when the file is modified, and every certificate of its issuer is
+	// rejected once it's past its next update.
+	CRLFile string
+
+	mu         sync.Mutex
+	crl        *x509.RevocationList
+	crlModTime time.Time
+}
+
+// Validate checks the certificate fields and prefixes of the group mapping,
+// and loads the revocation list.
+func (c *CertificateAuth) Validate() error {
+	for field, prefix := range c.GroupFields {
+		switch field {
+		case CertificateFieldCommonName, CertificateFieldOrganization, CertificateFieldOrganizationalUnit,
+			CertificateFieldDNSName, CertificateFieldURI, CertificateFieldEmail:
+		default:
+			return fmt.Errorf("invalid certificate field %q", field)
+		}
+		if prefix == "" {
+			return fmt.Errorf("empty group prefix for certificate field %q", field)
+		}
+	}
+	if c.CRLFile == "" {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.loadCRL()
+}
+
+// Authenticate returns the claims of the agent named agentName, given the
+// verified chain of its certificate.
+func (c *CertificateAuth) Authenticate(agentName string, chain []*x509.Certificate) (*corev2.Claims, error) {
+	if len(chain) == 0 {
+		return nil, errors.New("no verified client certificate")
+	}
+	cert := chain[0]
+	if !certificateNamesAgent(cert, agentName) {
+		return nil, fmt.Errorf("certificate %q is not valid for agent %q", cert.Subject.CommonName, agentName)
+	}
+	if err := c.checkRevocation(chain); err != nil {
+		return nil, err
+	}
+
+	groups := c.Groups
+	if groups == nil {
+		groups = DefaultCertificateGroups
+	}
+	groups = append([]string{}, groups...)
+	for field, prefix := range c.GroupFields {
+		if prefix == "" {
+			continue
+		}
+		for _, value := range certificateField(cert, field) {
+			groups = append(groups, prefix+value)
+		}
+	}
+	return jwt.NewClaims(&corev2.User{Username: CertificateUsernamePrefix + agentName, Groups: groups})
+}
+
+// certificateNamesAgent returns true if the agent is named after the common
+// name, or a DNS or URI SAN of the certificate.
+func certificateNamesAgent(cert *x509.Certificate, agentName string) bool {
+	if agentName == "" {
+		return false
+	}
+	if cert.Subject.CommonName == agentName {
+		return true
+	}
+	for _, name := range certificateField(cert, CertificateFieldDNSName) {
+		if name == agentName {
+			return true
+		}
+	}
+	for _, name := range certificateField(cert, CertificateFieldURI) {
+		if name == agentName {
+			return true
+		}
+	}
+	return false
+}
+
+func certificateField(cert *x509.Certificate, field string) []string {
+	switch field {
+	case CertificateFieldCommonName:
+		if cert.Subject.CommonName == "" {
+			return nil
+		}
+		return []string{cert.Subject.CommonName}
+	case CertificateFieldOrganization:
+		return cert.Subject.Organization
+	case CertificateFieldOrganizationalUnit:
+		return cert.Subject.OrganizationalUnit
+	case CertificateFieldDNSName:
+		return cert.DNSNames
+	case CertificateFieldURI:
+		uris := make([]string, 0, len(cert.URIs))
+		for _, uri := range cert.URIs {
+			uris = append(uris, uri.String())
+		}
+		return uris
+	case CertificateFieldEmail:
+		return cert.EmailAddresses
+	}
+	return nil
+}
+
+// checkRevocation returns an error if the certificate at the head of the
+// chain is revoked by the revocation list of its issuer, or if the list
+// expired.
+func (c *CertificateAuth) checkRevocation(chain []*x509.Certificate) error {
+	if c.CRLFile == "" || len(chain) < 2 {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.loadCRL(); err != nil {
+		// Keep enforcing the last valid revocation list
+		logger.WithError(err).Error("could not reload the certificate revocation list")
+	}
+	if c.crl == nil {
+		return nil
+	}
+
+	cert, issuer := chain[0], chain[1]
+	if !bytes.Equal(c.crl.RawIssuer, cert.RawIssuer) {
+		// The list was issued by another CA
+		return nil
+	}
+	if err := c.crl.CheckSignatureFrom(issuer); err != nil {
+		return fmt.Errorf("invalid certificate revocation list: %s", err)
+	}
+	if !c.crl.NextUpdate.IsZero() && time.Now().After(c.crl.NextUpdate) {
+		return fmt.Errorf("certificate revocation list expired on %s", c.crl.NextUpdate.Format(time.RFC3339))
+	}
+	for _, revoked := range c.crl.RevokedCertificates {
+		if revoked.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+			return fmt.Errorf("certificate %q is revoked", cert.Subject.CommonName)
+		}
+	}
+	return nil
+}
+
+// loadCRL loads the revocation list if its file was modified since it was
+// last loaded. c.mu must be held.
+func (c *CertificateAuth) loadCRL() error {
+	info, err := os.Stat(c.CRLFile)
+	if err != nil {
+		return err
+	}
+	if c.crl != nil && info.ModTime().Equal(c.crlModTime) {
+		return nil
+	}
+	data, err := os.ReadFile(c.CRLFile)
+	if err != nil {
+		return err
+	}
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+	crl, err := x509.ParseRevocationList(data)
+	if err != nil {
+		return fmt.Errorf("couldn't parse %s: %s", c.CRLFile, err)
+	}
+	c.crl = crl
+	c.crlModTime = info.ModTime()
+	return nil
+}
@@ -0,0 +1,258 @@
+package agentd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/authentication/jwt"
+	"github.com/sensu/sensu-go/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key}
+}
+
+// issue returns the verified chain of a new agent certificate.
+func (ca *testCA) issue(t *testing.T, serial int64, subject pkix.Name, dnsNames []string, uris ...string) []*x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      subject,
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	for _, uri := range uris {
+		u, err := url.Parse(uri)
+		require.NoError(t, err)
+		template.URIs = append(template.URIs, u)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return []*x509.Certificate{cert, ca.cert}
+}
+
+// writeCRL writes a PEM revocation list of the serial numbers to a file.
+func (ca *testCA) writeCRL(t *testing.T, path string, nextUpdate time.Time, serials ...int64) {
+	t.Helper()
+	template := &x509.RevocationList{
+		Number:     big.NewInt(time.Now().UnixNano()),
+		ThisUpdate: time.Now().Add(-2 * time.Hour),
+		NextUpdate: nextUpdate,
+	}
+	for _, serial := range serials {
+		template.RevokedCertificates = append(template.RevokedCertificates, pkix.RevokedCertificate{
+			SerialNumber:   big.NewInt(serial),
+			RevocationTime: time.Now(),
+		})
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, template, ca.cert, ca.key)
+	require.NoError(t, err)
+	data := pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der})
+	require.NoError(t, os.WriteFile(path, data, 0644))
+}
+
+func TestCertificateAuthAuthenticate(t *testing.T) {
+	ca := newTestCA(t, "sensu-ca")
+	auth := &CertificateAuth{
+		GroupFields: map[string]string{
+			CertificateFieldOrganizationalUnit: "ou:",
+			CertificateFieldDNSName:            "host:",
+		},
+	}
+	require.NoError(t, auth.Validate())
+	subject := pkix.Name{CommonName: "agent1", OrganizationalUnit: []string{"ops"}}
+
+	tests := []struct {
+		name      string
+		agentName string
+		chain     []*x509.Certificate
+		wantErr   bool
+	}{
+		{
+			name:      "common name",
+			agentName: "agent1",
+			chain:     ca.issue(t, 10, subject, nil),
+		},
+		{
+			name:      "dns name",
+			agentName: "agent1.example.com",
+			chain:     ca.issue(t, 11, subject, []string{"agent1.example.com"}),
+		},
+		{
+			name:      "uri",
+			agentName: "spiffe://example.com/agent1",
+			chain:     ca.issue(t, 12, subject, nil, "spiffe://example.com/agent1"),
+		},
+		{
+			name:      "other agent",
+			agentName: "agent2",
+			chain:     ca.issue(t, 13, subject, []string{"agent1.example.com"}),
+			wantErr:   true,
+		},
+		{
+			name:      "no agent name",
+			agentName: "",
+			chain:     ca.issue(t, 14, pkix.Name{}, nil),
+			wantErr:   true,
+		},
+		{
+			name:      "no certificate",
+			agentName: "agent1",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := auth.Authenticate(tt.agentName, tt.chain)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "agent:"+tt.agentName, claims.Subject)
+			assert.Contains(t, claims.Groups, "system:agents")
+			assert.Contains(t, claims.Groups, "ou:ops")
+		})
+	}
+}
+
+func TestCertificateAuthGroups(t *testing.T) {
+	ca := newTestCA(t, "sensu-ca")
+	auth := &CertificateAuth{
+		Groups:      []string{"fleet"},
+		GroupFields: map[string]string{CertificateFieldOrganization: "o:"},
+	}
+	chain := ca.issue(t, 10, pkix.Name{CommonName: "agent1", Organization: []string{"acme", "sensu"}}, nil)
+
+	claims, err := auth.Authenticate("agent1", chain)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fleet", "o:acme", "o:sensu"}, claims.Groups)
+	assert.Equal(t, []string{"fleet"}, auth.Groups)
+}
+
+func TestCertificateAuthValidate(t *testing.T) {
+	auth := &CertificateAuth{GroupFields: map[string]string{"serial": "serial:"}}
+	assert.Error(t, auth.Validate())
+
+	// the certificates can't grant arbitrary groups
+	auth = &CertificateAuth{GroupFields: map[string]string{CertificateFieldOrganization: ""}}
+	assert.Error(t, auth.Validate())
+
+	auth = &CertificateAuth{CRLFile: filepath.Join(t.TempDir(), "missing.crl")}
+	assert.Error(t, auth.Validate())
+}
+
+func TestCertificateAuthCRL(t *testing.T) {
+	ca := newTestCA(t, "sensu-ca")
+	crlFile := filepath.Join(t.TempDir(), "agents.crl")
+	ca.writeCRL(t, crlFile, time.Now().Add(time.Hour), 20)
+	auth := &CertificateAuth{CRLFile: crlFile}
+	require.NoError(t, auth.Validate())
+	subject := pkix.Name{CommonName: "agent1"}
+
+	_, err := auth.Authenticate("agent1", ca.issue(t, 20, subject, nil))
+	assert.Error(t, err)
+	_, err = auth.Authenticate("agent1", ca.issue(t, 21, subject, nil))
+	assert.NoError(t, err)
+
+	// The revocation list is reloaded when modified
+	ca.writeCRL(t, crlFile, time.Now().Add(time.Hour), 20, 21)
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(crlFile, later, later))
+	_, err = auth.Authenticate("agent1", ca.issue(t, 21, subject, nil))
+	assert.Error(t, err)
+
+	// The certificates are rejected once the revocation list expired
+	ca.writeCRL(t, crlFile, time.Now().Add(-time.Hour))
+	later = later.Add(time.Minute)
+	require.NoError(t, os.Chtimes(crlFile, later, later))
+	_, err = auth.Authenticate("agent1", ca.issue(t, 22, subject, nil))
+	assert.Error(t, err)
+
+	// The revocation lists of other CAs are ignored
+	_, err = auth.Authenticate("agent1", newTestCA(t, "other-ca").issue(t, 21, subject, nil))
+	assert.NoError(t, err)
+}
+
+func TestAgentdCertificateAuthentication(t *testing.T) {
+	ca := newTestCA(t, "sensu-ca")
+	var claims *corev2.Claims
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims = jwt.GetClaimsFromContext(r.Context())
+	})
+	agentd := &Agentd{certAuth: &CertificateAuth{}, authenticator: &mockAuthenticator{}}
+	handler := agentd.AuthenticationMiddleware(testHandler)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(transport.HeaderKeyAgentName, "agent1")
+	req.TLS = &tls.ConnectionState{
+		VerifiedChains: [][]*x509.Certificate{ca.issue(t, 10, pkix.Name{CommonName: "agent1"}, nil)},
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, claims)
+	assert.Equal(t, "agent:agent1", claims.Subject)
+
+	req.Header.Set(transport.HeaderKeyAgentName, "agent2")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// The agents without certificates need credentials
+	req.TLS = nil
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAgentdCertificateAuthenticationMutualTLS(t *testing.T) {
+	// agentd verifies the client certificates, without requiring them, when
+	// it authenticates the agents by certificate. Their sessions are then
+	// sent the check secrets.
+	agentd := &Agentd{httpServer: &http.Server{TLSConfig: &tls.Config{ClientAuth: tls.VerifyClientCertIfGiven}}}
+	assert.True(t, agentd.MutualTLS())
+}
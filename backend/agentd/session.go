@@ -144,6 +144,10 @@ type SessionConfig struct {
 
 	Marshal   agent.MarshalFunc
 	Unmarshal agent.UnmarshalFunc
+
+	// MutualTLS is true if the agent connected with a verified client
+	// certificate. The check secrets are only sent to such agents.
+	MutualTLS bool
 }
 
 // NewSession creates a new Session object given the triple of a transport
@@ -339,6 +343,14 @@ func (s *Session) sender() {
 				logger.Error("session received non-config over check channel")
 				continue
 			}
+			if len(request.Secrets) > 0 && !s.cfg.MutualTLS {
+				logger.WithField("check", request.Config.Name).Warning(
+					"secrets will not be transmitted to agents without mutual TLS authentication (mTLS)",
+				)
+				withoutSecrets := *request
+				withoutSecrets.Secrets = nil
+				request = &withoutSecrets
+			}
 
 			configBytes, err := s.marshal(request)
 			if err != nil {
@@ -286,6 +286,76 @@ func TestSession_sender(t *testing.T) {
 	}
 }
 
+func TestSession_senderSecrets(t *testing.T) {
+	tests := []struct {
+		name      string
+		mutualTLS bool
+		want      []string
+	}{
+		{
+			name:      "agents with a client certificate receive the secrets",
+			mutualTLS: true,
+			want:      []string{"TOKEN=s3cr3t"},
+		},
+		{
+			name: "agents without a client certificate do not",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus, err := messaging.NewWizardBus(messaging.WizardBusConfig{})
+			require.NoError(t, err)
+			require.NoError(t, bus.Start())
+			defer func() { _ = bus.Stop() }()
+
+			received := make(chan *corev2.CheckRequest, 1)
+			conn := new(mocktransport.MockTransport)
+			conn.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+				msg := args[0].(*transport.Message)
+				if msg.Type != corev2.CheckRequestType {
+					return
+				}
+				request := &corev2.CheckRequest{}
+				if err := agent.UnmarshalJSON(msg.Payload, request); err != nil {
+					t.Error(err)
+				}
+				received <- request
+			}).Return(nil)
+
+			session, err := NewSession(context.Background(), SessionConfig{
+				AgentName:     "testing",
+				Namespace:     "default",
+				Subscriptions: []string{"linux"},
+				Conn:          conn,
+				Bus:           bus,
+				Storev2:       &mockstore.V2MockStore{},
+				Unmarshal:     agent.UnmarshalJSON,
+				Marshal:       agent.MarshalJSON,
+				MutualTLS:     tt.mutualTLS,
+			})
+			require.NoError(t, err)
+			defer session.Stop()
+			require.NoError(t, session.subscribe(session.cfg.Subscriptions))
+			session.wg = &sync.WaitGroup{}
+			session.wg.Add(1)
+			go session.sender()
+
+			request := corev2.FixtureCheckRequest("foo")
+			request.Secrets = []string{"TOKEN=s3cr3t"}
+			require.NoError(t, bus.Publish(messaging.SubscriptionTopic("default", "linux"), request))
+
+			select {
+			case got := <-received:
+				assert.Equal(t, tt.want, got.Secrets)
+			case <-time.After(5 * time.Second):
+				t.Fatal("the check request was never sent")
+			}
+			// The published request is left untouched
+			assert.Equal(t, []string{"TOKEN=s3cr3t"}, request.Secrets)
+		})
+	}
+}
+
 func TestSession_Start(t *testing.T) {
 	type connFunc func(*mocktransport.MockTransport, *sync.WaitGroup)
 	type storeFunc func(*mockstore.V2MockStore, *sync.WaitGroup)
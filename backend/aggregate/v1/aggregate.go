@@ -0,0 +1,198 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/selector"
+)
+
+const (
+	// AggregatesResource is the RBAC name of the aggregates.
+	AggregatesResource = "aggregates"
+
+	// StateOK counts the events with an OK status.
+	StateOK = "ok"
+
+	// StateWarning counts the events with a warning status.
+	StateWarning = "warning"
+
+	// StateCritical counts the events with a critical status.
+	StateCritical = "critical"
+
+	// StateUnknown counts the events with a status other than OK, warning
+	// and critical.
+	StateUnknown = "unknown"
+
+	// StateFailing counts the events with a non-zero status.
+	StateFailing = "failing"
+)
+
+// Aggregate is a check whose status is computed from a group of events, e.g.
+// critical if more than 30% of the http checks of the web tier are critical.
+// It is evaluated by a single backend every interval, which emits its event on
+// a proxy entity.
+type Aggregate struct {
+	// Metadata contains the name, namespace, labels and annotations of the
+	// aggregate.
+	Metadata corev2.ObjectMeta `json:"metadata"`
+
+	// Checks restricts the aggregate to the events of the named checks.
+	Checks []string `json:"checks,omitempty"`
+
+	// Subscriptions restricts the aggregate to the events of the entities
+	// that have at least one of the subscriptions.
+	Subscriptions []string `json:"subscriptions,omitempty"`
+
+	// EventSelector restricts the aggregate to the events matching the field
+	// selector, e.g. "event.labels.tier == web".
+	EventSelector string `json:"event_selector,omitempty"`
+
+	// Interval is the interval at which the aggregate is evaluated, in
+	// seconds.
+	Interval uint32 `json:"interval"`
+
+	// Thresholds define the status of the aggregate. The aggregate takes the
+	// highest status of its exceeded thresholds, or OK if none is exceeded.
+	Thresholds []*AggregateThreshold `json:"thresholds,omitempty"`
+
+	// ProxyEntityName is the name of the proxy entity of the aggregate
+	// event. Defaults to the name of the aggregate.
+	ProxyEntityName string `json:"proxy_entity_name,omitempty"`
+
+	// Handlers are the handlers of the aggregate event.
+	Handlers []string `json:"handlers,omitempty"`
+
+	// Pipelines are the pipelines of the aggregate event.
+	Pipelines []*corev2.ResourceReference `json:"pipelines,omitempty"`
+}
+
+// AggregateThreshold is exceeded when more than a percentage, or a number, of
+// the aggregated events are in the given state.
+type AggregateThreshold struct {
+	// Status is the status of the aggregate when the threshold is exceeded,
+	// 1 for warning or 2 for critical.
+	Status uint32 `json:"status"`
+
+	// State is the state of the counted events: ok, warning, critical,
+	// unknown or failing.
+	State string `json:"state"`
+
+	// Percentage is the percentage of the events that must be exceeded.
+	Percentage float64 `json:"percentage,omitempty"`
+
+	// Count is the number of events that must be exceeded.
+	Count uint32 `json:"count,omitempty"`
+}
+
+// GetMetadata returns the aggregate metadata.
+func (a *Aggregate) GetMetadata() *corev2.ObjectMeta {
+	return &a.Metadata
+}
+
+// SetMetadata sets the aggregate metadata.
+func (a *Aggregate) SetMetadata(meta *corev2.ObjectMeta) {
+	if meta == nil {
+		meta = &corev2.ObjectMeta{}
+	}
+	a.Metadata = *meta
+}
+
+// StoreName returns the store name of the aggregate.
+func (a *Aggregate) StoreName() string {
+	return "aggregate/aggregates"
+}
+
+// RBACName returns the RBAC name of the aggregate.
+func (a *Aggregate) RBACName() string {
+	return AggregatesResource
+}
+
+// URIPath returns the path of the aggregate.
+func (a *Aggregate) URIPath() string {
+	if a.Metadata.Namespace == "" {
+		return path.Join(URLPrefix, AggregatesResource, url.PathEscape(a.Metadata.Name))
+	}
+	return path.Join(URLPrefix, "namespaces", url.PathEscape(a.Metadata.Namespace), AggregatesResource, url.PathEscape(a.Metadata.Name))
+}
+
+// GetTypeMeta returns the type metadata of the aggregate.
+func (a *Aggregate) GetTypeMeta() corev2.TypeMeta {
+	return typeMeta("Aggregate")
+}
+
+// Validate returns an error if the aggregate is invalid.
+func (a *Aggregate) Validate() error {
+	if err := corev2.ValidateName(a.Metadata.Name); err != nil {
+		return errors.New("aggregate name " + err.Error())
+	}
+	if a.Metadata.Namespace == "" {
+		return errors.New("namespace must be set")
+	}
+	if a.Interval == 0 {
+		return errors.New("interval must be greater than 0")
+	}
+	if a.ProxyEntityName != "" {
+		if err := corev2.ValidateName(a.ProxyEntityName); err != nil {
+			return errors.New("proxy entity name " + err.Error())
+		}
+	}
+	if _, err := a.Selector(); err != nil {
+		return fmt.Errorf("invalid event selector: %s", err)
+	}
+	if len(a.Thresholds) == 0 {
+		return errors.New("at least one threshold must be set")
+	}
+	for _, threshold := range a.Thresholds {
+		if threshold == nil {
+			return errors.New("thresholds must not be empty")
+		}
+		if err := threshold.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, ref := range a.Pipelines {
+		if ref == nil || ref.Name == "" {
+			return errors.New("pipelines must have a name")
+		}
+	}
+	return nil
+}
+
+// Selector returns the event selector of the aggregate, or nil if it has none.
+func (a *Aggregate) Selector() (*selector.Selector, error) {
+	if a.EventSelector == "" {
+		return nil, nil
+	}
+	return selector.ParseFieldSelector(a.EventSelector)
+}
+
+// GetProxyEntityName returns the name of the entity of the aggregate event.
+func (a *Aggregate) GetProxyEntityName() string {
+	if a.ProxyEntityName == "" {
+		return a.Metadata.Name
+	}
+	return a.ProxyEntityName
+}
+
+// Validate returns an error if the threshold is invalid.
+func (t *AggregateThreshold) Validate() error {
+	if t.Status != 1 && t.Status != 2 {
+		return errors.New("threshold status must be 1 (warning) or 2 (critical)")
+	}
+	switch t.State {
+	case StateOK, StateWarning, StateCritical, StateUnknown, StateFailing:
+	default:
+		return fmt.Errorf("invalid threshold state %q", t.State)
+	}
+	if (t.Percentage > 0) == (t.Count > 0) {
+		return errors.New("thresholds must have either a percentage or a count")
+	}
+	if t.Percentage < 0 || t.Percentage > 100 {
+		return errors.New("threshold percentage must be between 0 and 100")
+	}
+	return nil
+}
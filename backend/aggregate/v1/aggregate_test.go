@@ -0,0 +1,115 @@
+package v1
+
+import (
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func fixtureAggregate() *Aggregate {
+	return &Aggregate{
+		Metadata:      corev2.ObjectMeta{Name: "web-http", Namespace: "default"},
+		Checks:        []string{"http"},
+		EventSelector: "event.labels.tier == web",
+		Interval:      60,
+		Thresholds: []*AggregateThreshold{
+			{Status: 2, State: StateCritical, Percentage: 30},
+		},
+	}
+}
+
+func TestAggregateValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(*Aggregate)
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			mutate: func(*Aggregate) {},
+		},
+		{
+			name:    "missing namespace",
+			mutate:  func(a *Aggregate) { a.Metadata.Namespace = "" },
+			wantErr: true,
+		},
+		{
+			name:    "missing interval",
+			mutate:  func(a *Aggregate) { a.Interval = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "invalid proxy entity name",
+			mutate:  func(a *Aggregate) { a.ProxyEntityName = "web tier" },
+			wantErr: true,
+		},
+		{
+			name:    "invalid event selector",
+			mutate:  func(a *Aggregate) { a.EventSelector = "event.labels.tier ==" },
+			wantErr: true,
+		},
+		{
+			name:    "missing thresholds",
+			mutate:  func(a *Aggregate) { a.Thresholds = nil },
+			wantErr: true,
+		},
+		{
+			name:    "invalid threshold status",
+			mutate:  func(a *Aggregate) { a.Thresholds[0].Status = 3 },
+			wantErr: true,
+		},
+		{
+			name:    "invalid threshold state",
+			mutate:  func(a *Aggregate) { a.Thresholds[0].State = "down" },
+			wantErr: true,
+		},
+		{
+			name:    "percentage and count",
+			mutate:  func(a *Aggregate) { a.Thresholds[0].Count = 3 },
+			wantErr: true,
+		},
+		{
+			name:    "percentage out of range",
+			mutate:  func(a *Aggregate) { a.Thresholds[0].Percentage = 120 },
+			wantErr: true,
+		},
+		{
+			name: "count",
+			mutate: func(a *Aggregate) {
+				a.Thresholds[0].Percentage = 0
+				a.Thresholds[0].Count = 3
+			},
+		},
+		{
+			name:    "unnamed pipeline",
+			mutate:  func(a *Aggregate) { a.Pipelines = []*corev2.ResourceReference{{}} },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aggregate := fixtureAggregate()
+			tt.mutate(aggregate)
+			err := aggregate.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAggregateDefaults(t *testing.T) {
+	aggregate := fixtureAggregate()
+	assert.Equal(t, "web-http", aggregate.GetProxyEntityName())
+	aggregate.ProxyEntityName = "web-tier"
+	assert.Equal(t, "web-tier", aggregate.GetProxyEntityName())
+	assert.Equal(t, "/api/aggregate/v1/namespaces/default/aggregates/web-http", aggregate.URIPath())
+
+	fields := AggregateFields(aggregate)
+	assert.Equal(t, "web-http", fields["aggregate.name"])
+	assert.Equal(t, "60", fields["aggregate.interval"])
+	assert.Equal(t, "web-tier", fields["aggregate.proxy_entity_name"])
+}
@@ -0,0 +1,21 @@
+package v1
+
+import (
+	"strconv"
+
+	corev3 "github.com/sensu/core/v3"
+)
+
+// AggregateFields returns a set of fields that represent the aggregate for
+// the purposes of field selectors.
+func AggregateFields(r corev3.Resource) map[string]string {
+	resource := r.(*Aggregate)
+	fields := map[string]string{
+		"aggregate.name":              resource.Metadata.Name,
+		"aggregate.namespace":         resource.Metadata.Namespace,
+		"aggregate.interval":          strconv.FormatUint(uint64(resource.Interval), 10),
+		"aggregate.proxy_entity_name": resource.GetProxyEntityName(),
+	}
+	mergeLabels(fields, resource.Metadata.Labels, "aggregate.labels.")
+	return fields
+}
@@ -0,0 +1,33 @@
+// Package v1 contains the aggregate/v1 API types: checks whose status is
+// computed by sensu-backend from a group of events.
+package v1
+
+import (
+	corev2 "github.com/sensu/core/v2"
+	apitools "github.com/sensu/sensu-api-tools"
+)
+
+const (
+	// APIVersion is the API version of the types in this package.
+	APIVersion = "aggregate/v1"
+
+	// URLPrefix is the URL prefix of the aggregate/v1 API.
+	URLPrefix = "/api/aggregate/v1"
+)
+
+func init() {
+	apitools.RegisterType(APIVersion, new(Aggregate))
+}
+
+func mergeLabels(fields map[string]string, labels map[string]string, prefix string) {
+	for k, v := range labels {
+		fields[prefix+k] = v
+	}
+}
+
+func typeMeta(typ string) corev2.TypeMeta {
+	return corev2.TypeMeta{
+		Type:       typ,
+		APIVersion: APIVersion,
+	}
+}
@@ -0,0 +1,270 @@
+// Package aggregated evaluates the aggregates. Every aggregate is an operator
+// of the operator concierge, controlled by a backend, and checked in with its
+// interval as timeout. Each backend monitors the aggregates it controls, so
+// that every aggregate is evaluated exactly once per interval, by a single
+// backend, and the aggregates of a backend that goes away are taken over by
+// the others.
+package aggregated
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	aggregatev1 "github.com/sensu/sensu-go/backend/aggregate/v1"
+	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultReconcileInterval is the interval at which the aggregates are
+	// reconciled with their operators.
+	DefaultReconcileInterval = 10 * time.Second
+
+	// DefaultStoreTimeout is the timeout of the store operations.
+	DefaultStoreTimeout = time.Minute
+)
+
+var logger = logrus.WithFields(logrus.Fields{
+	"component": "aggregated",
+})
+
+// Config configures Aggregated.
+type Config struct {
+	Store             storev2.Interface
+	Bus               messaging.MessageBus
+	OperatorConcierge store.OperatorConcierge
+	OperatorMonitor   store.OperatorMonitor
+	OperatorQueryer   store.OperatorQueryer
+	BackendName       string
+	ReconcileInterval time.Duration
+	StoreTimeout      time.Duration
+}
+
+// Aggregated is the daemon that evaluates the aggregates and publishes their
+// events.
+type Aggregated struct {
+	store             storev2.Interface
+	bus               messaging.MessageBus
+	operatorConcierge store.OperatorConcierge
+	operatorMonitor   store.OperatorMonitor
+	operatorQueryer   store.OperatorQueryer
+	backendName       string
+	reconcileInterval time.Duration
+	storeTimeout      time.Duration
+	ctx               context.Context
+	cancel            context.CancelFunc
+	wg                sync.WaitGroup
+	errChan           chan error
+}
+
+// New creates a new Aggregated.
+func New(c Config) (*Aggregated, error) {
+	if c.Store == nil {
+		return nil, errors.New("no store")
+	}
+	if c.Bus == nil {
+		return nil, errors.New("no message bus")
+	}
+	if c.OperatorConcierge == nil || c.OperatorMonitor == nil || c.OperatorQueryer == nil {
+		return nil, errors.New("no operator concierge")
+	}
+	if c.ReconcileInterval == 0 {
+		c.ReconcileInterval = DefaultReconcileInterval
+	}
+	if c.StoreTimeout == 0 {
+		c.StoreTimeout = DefaultStoreTimeout
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Aggregated{
+		store:             c.Store,
+		bus:               c.Bus,
+		operatorConcierge: c.OperatorConcierge,
+		operatorMonitor:   c.OperatorMonitor,
+		operatorQueryer:   c.OperatorQueryer,
+		backendName:       c.BackendName,
+		reconcileInterval: c.ReconcileInterval,
+		storeTimeout:      c.StoreTimeout,
+		ctx:               ctx,
+		cancel:            cancel,
+		errChan:           make(chan error, 1),
+	}, nil
+}
+
+// Start starts the daemon.
+func (a *Aggregated) Start() error {
+	a.wg.Add(2)
+	go func() {
+		defer a.wg.Done()
+		a.reconcileLoop(a.ctx)
+	}()
+	go func() {
+		defer a.wg.Done()
+		a.monitorAggregates(a.ctx)
+	}()
+	return nil
+}
+
+// Stop stops the daemon.
+func (a *Aggregated) Stop() error {
+	a.cancel()
+	a.wg.Wait()
+	close(a.errChan)
+	return nil
+}
+
+// Err returns a channel that the caller can use to listen for terminal errors
+// indicating a premature shutdown of the Daemon.
+func (a *Aggregated) Err() <-chan error {
+	return a.errChan
+}
+
+// Name returns the daemon name.
+func (a *Aggregated) Name() string {
+	return "aggregated"
+}
+
+func (a *Aggregated) reconcileLoop(ctx context.Context) {
+	ticker := time.NewTicker(a.reconcileInterval)
+	defer ticker.Stop()
+	for {
+		if err := a.reconcile(ctx); err != nil && ctx.Err() == nil {
+			logger.WithError(err).Error("error reconciling aggregates")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reconcile checks in the aggregates that have no operator, or whose interval
+// changed, and checks out the operators of the deleted aggregates. The new
+// operators are controlled by this backend; the operator concierge reassigns
+// them when it goes away.
+func (a *Aggregated) reconcile(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, a.storeTimeout)
+	defer cancel()
+
+	aggregates, err := storev2.Of[*aggregatev1.Aggregate](a.store).List(ctx, storev2.ID{}, &store.SelectionPredicate{})
+	if err != nil {
+		return fmt.Errorf("couldn't list aggregates: %w", err)
+	}
+	operators, err := a.operatorQueryer.ListOperators(ctx, store.OperatorKey{Type: store.AggregateOperator})
+	if err != nil {
+		return fmt.Errorf("couldn't list aggregate operators: %w", err)
+	}
+
+	timeouts := make(map[string]time.Duration, len(operators))
+	for _, op := range operators {
+		timeouts[aggregateKey(op.Namespace, op.Name)] = op.CheckInTimeout
+	}
+
+	for _, aggregate := range aggregates {
+		key := aggregateKey(aggregate.Metadata.Namespace, aggregate.Metadata.Name)
+		interval := time.Duration(aggregate.Interval) * time.Second
+		timeout, ok := timeouts[key]
+		delete(timeouts, key)
+		if ok && timeout == interval {
+			continue
+		}
+		state := store.OperatorState{
+			Namespace:      aggregate.Metadata.Namespace,
+			Name:           aggregate.Metadata.Name,
+			Type:           store.AggregateOperator,
+			CheckInTimeout: interval,
+			Present:        true,
+			Controller: &store.OperatorKey{
+				Type: store.BackendOperator,
+				Name: a.backendName,
+			},
+		}
+		if err := a.operatorConcierge.CheckIn(ctx, state); err != nil {
+			return fmt.Errorf("couldn't check in aggregate %s: %w", key, err)
+		}
+	}
+
+	for _, op := range operators {
+		if _, ok := timeouts[aggregateKey(op.Namespace, op.Name)]; !ok {
+			continue
+		}
+		if err := a.checkOut(ctx, op.Namespace, op.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *Aggregated) monitorAggregates(ctx context.Context) {
+	req := store.MonitorOperatorsRequest{
+		Type:           store.AggregateOperator,
+		ControllerType: store.BackendOperator,
+		ControllerName: a.backendName,
+		Every:          time.Second,
+		ErrorHandler: func(err error) {
+			logger.WithError(err).Error("error monitoring aggregates")
+		},
+	}
+	stateCh := a.operatorMonitor.MonitorOperators(ctx, req)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case states := <-stateCh:
+			for _, state := range states {
+				if err := a.handleNotification(ctx, state); err != nil {
+					logger.WithError(err).WithFields(logrus.Fields{
+						"namespace": state.Namespace,
+						"aggregate": state.Name,
+					}).Error("error evaluating aggregate")
+				}
+			}
+		}
+	}
+}
+
+// handleNotification evaluates the aggregate whose interval elapsed and
+// publishes its event, to be processed by eventd like any other event.
+func (a *Aggregated) handleNotification(ctx context.Context, state store.OperatorState) error {
+	ctx, cancel := context.WithTimeout(ctx, a.storeTimeout)
+	defer cancel()
+
+	id := storev2.ID{Namespace: state.Namespace, Name: state.Name}
+	aggregate, err := storev2.Of[*aggregatev1.Aggregate](a.store).Get(ctx, id)
+	if err != nil {
+		if _, ok := err.(*store.ErrNotFound); ok {
+			return a.checkOut(ctx, state.Namespace, state.Name)
+		}
+		return err
+	}
+	counts, err := Count(ctx, a.store.GetEventStore(), aggregate)
+	if err != nil {
+		return err
+	}
+	event := NewEvent(aggregate, counts, time.Now())
+	if err := event.Validate(); err != nil {
+		return err
+	}
+	return a.bus.Publish(messaging.TopicEventRaw, event)
+}
+
+func (a *Aggregated) checkOut(ctx context.Context, namespace, name string) error {
+	key := store.OperatorKey{
+		Namespace: namespace,
+		Name:      name,
+		Type:      store.AggregateOperator,
+	}
+	if err := a.operatorConcierge.CheckOut(ctx, key); err != nil {
+		return fmt.Errorf("couldn't check out aggregate %s: %w", aggregateKey(namespace, name), err)
+	}
+	return nil
+}
+
+func aggregateKey(namespace, name string) string {
+	return namespace + "/" + name
+}
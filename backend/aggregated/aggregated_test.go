@@ -0,0 +1,117 @@
+package aggregated
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	aggregatev1 "github.com/sensu/sensu-go/backend/aggregate/v1"
+	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/sensu/sensu-go/backend/store"
+	"github.com/sensu/sensu-go/testing/mockbus"
+	"github.com/sensu/sensu-go/testing/mockstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAggregated(t *testing.T, st *mockstore.V2MockStore, opc *mockstore.OPC, bus messaging.MessageBus) *Aggregated {
+	t.Helper()
+	a, err := New(Config{
+		Store:             st,
+		Bus:               bus,
+		OperatorConcierge: opc,
+		OperatorMonitor:   opc,
+		OperatorQueryer:   opc,
+		BackendName:       "backend01",
+	})
+	require.NoError(t, err)
+	return a
+}
+
+func TestReconcile(t *testing.T) {
+	unchanged := fixtureAggregate()
+	unchanged.Metadata.Name = "unchanged"
+	changed := fixtureAggregate()
+	changed.Metadata.Name = "changed"
+	added := fixtureAggregate()
+	added.Metadata.Name = "added"
+
+	st := new(mockstore.V2MockStore)
+	cs := new(mockstore.ConfigStore)
+	st.On("GetConfigStore").Return(cs)
+	cs.On("List", mock.Anything, mock.Anything, mock.Anything).
+		Return(mockstore.WrapList[*aggregatev1.Aggregate]{unchanged, changed, added}, nil)
+
+	opc := new(mockstore.OPC)
+	opc.On("ListOperators", mock.Anything, store.OperatorKey{Type: store.AggregateOperator}).Return([]store.OperatorState{
+		{Namespace: "default", Name: "unchanged", Type: store.AggregateOperator, CheckInTimeout: time.Minute},
+		{Namespace: "default", Name: "changed", Type: store.AggregateOperator, CheckInTimeout: time.Second},
+		{Namespace: "default", Name: "deleted", Type: store.AggregateOperator, CheckInTimeout: time.Minute},
+	}, nil)
+	opc.On("CheckIn", mock.Anything, mock.Anything).Return(nil)
+	opc.On("CheckOut", mock.Anything, mock.Anything).Return(nil)
+
+	a := newTestAggregated(t, st, opc, &mockbus.MockBus{})
+	require.NoError(t, a.reconcile(context.Background()))
+
+	opc.AssertNumberOfCalls(t, "CheckIn", 2)
+	for _, name := range []string{"changed", "added"} {
+		opc.AssertCalled(t, "CheckIn", mock.Anything, store.OperatorState{
+			Namespace:      "default",
+			Name:           name,
+			Type:           store.AggregateOperator,
+			CheckInTimeout: time.Minute,
+			Present:        true,
+			Controller:     &store.OperatorKey{Type: store.BackendOperator, Name: "backend01"},
+		})
+	}
+	opc.AssertNumberOfCalls(t, "CheckOut", 1)
+	opc.AssertCalled(t, "CheckOut", mock.Anything, store.OperatorKey{Namespace: "default", Name: "deleted", Type: store.AggregateOperator})
+}
+
+func TestHandleNotification(t *testing.T) {
+	es := &mockstore.MockStore{}
+	es.On("GetEvents", mock.Anything, mock.Anything).Return([]*corev2.Event{
+		fixtureEvent("web01", "http", 0),
+		fixtureEvent("web02", "http", 2),
+	}, nil)
+
+	st := new(mockstore.V2MockStore)
+	cs := new(mockstore.ConfigStore)
+	st.On("GetConfigStore").Return(cs)
+	st.On("GetEventStore").Return(es)
+	cs.On("Get", mock.Anything, mock.Anything).Return(mockstore.Wrapper[*aggregatev1.Aggregate]{Value: fixtureAggregate()}, nil)
+
+	bus := &mockbus.MockBus{}
+	bus.On("Publish", messaging.TopicEventRaw, mock.Anything).Return(nil)
+
+	a := newTestAggregated(t, st, new(mockstore.OPC), bus)
+	state := store.OperatorState{Namespace: "default", Name: "web-http", Type: store.AggregateOperator}
+	require.NoError(t, a.handleNotification(context.Background(), state))
+
+	bus.AssertNumberOfCalls(t, "Publish", 1)
+	event := bus.Calls[0].Arguments.Get(1).(*corev2.Event)
+	assert.Equal(t, "web-http", event.Entity.Name)
+	assert.Equal(t, uint32(2), event.Check.Status)
+}
+
+func TestHandleNotificationDeleted(t *testing.T) {
+	st := new(mockstore.V2MockStore)
+	cs := new(mockstore.ConfigStore)
+	st.On("GetConfigStore").Return(cs)
+	cs.On("Get", mock.Anything, mock.Anything).Return(nil, &store.ErrNotFound{Key: "web-http"})
+
+	opc := new(mockstore.OPC)
+	key := store.OperatorKey{Namespace: "default", Name: "web-http", Type: store.AggregateOperator}
+	opc.On("CheckOut", mock.Anything, key).Return(nil)
+
+	bus := &mockbus.MockBus{}
+	a := newTestAggregated(t, st, opc, bus)
+	state := store.OperatorState{Namespace: "default", Name: "web-http", Type: store.AggregateOperator}
+	require.NoError(t, a.handleNotification(context.Background(), state))
+
+	opc.AssertCalled(t, "CheckOut", mock.Anything, key)
+	bus.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
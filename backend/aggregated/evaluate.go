@@ -0,0 +1,175 @@
+package aggregated
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	aggregatev1 "github.com/sensu/sensu-go/backend/aggregate/v1"
+	"github.com/sensu/sensu-go/backend/selector"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	stringsutil "github.com/sensu/sensu-go/util/strings"
+)
+
+// Counts are the number of aggregated events in each state.
+type Counts struct {
+	Total    int
+	OK       int
+	Warning  int
+	Critical int
+	Unknown  int
+}
+
+// Add counts an event status.
+func (c *Counts) Add(status uint32) {
+	c.Total++
+	switch status {
+	case 0:
+		c.OK++
+	case 1:
+		c.Warning++
+	case 2:
+		c.Critical++
+	default:
+		c.Unknown++
+	}
+}
+
+// Of returns the number of events in the given threshold state.
+func (c Counts) Of(state string) int {
+	switch state {
+	case aggregatev1.StateOK:
+		return c.OK
+	case aggregatev1.StateWarning:
+		return c.Warning
+	case aggregatev1.StateCritical:
+		return c.Critical
+	case aggregatev1.StateUnknown:
+		return c.Unknown
+	case aggregatev1.StateFailing:
+		return c.Total - c.OK
+	}
+	return 0
+}
+
+// Exceeds returns whether the counts exceed the threshold.
+func (c Counts) Exceeds(threshold *aggregatev1.AggregateThreshold) bool {
+	count := c.Of(threshold.State)
+	if threshold.Count > 0 {
+		return count > int(threshold.Count)
+	}
+	if c.Total == 0 {
+		return false
+	}
+	return float64(count)*100/float64(c.Total) > threshold.Percentage
+}
+
+// Count counts the events selected by the aggregate in its namespace.
+func Count(ctx context.Context, events store.EventStore, aggregate *aggregatev1.Aggregate) (Counts, error) {
+	var counts Counts
+	sel, err := aggregate.Selector()
+	if err != nil {
+		return counts, err
+	}
+	ctx = context.WithValue(ctx, corev2.NamespaceKey, aggregate.Metadata.Namespace)
+	if sel != nil {
+		ctx = storev2.EventContextWithSelector(ctx, sel)
+	}
+	results, err := events.GetEvents(ctx, &store.SelectionPredicate{})
+	if err != nil {
+		return counts, err
+	}
+	for _, event := range results {
+		if selects(aggregate, sel, event) {
+			counts.Add(event.Check.Status)
+		}
+	}
+	return counts, nil
+}
+
+func selects(aggregate *aggregatev1.Aggregate, sel *selector.Selector, event *corev2.Event) bool {
+	if !event.HasCheck() || event.Entity == nil {
+		return false
+	}
+	// Never aggregate the aggregate itself
+	if event.Check.Name == aggregate.Metadata.Name && event.Entity.Name == aggregate.GetProxyEntityName() {
+		return false
+	}
+	if len(aggregate.Checks) > 0 && !stringsutil.InArray(event.Check.Name, aggregate.Checks) {
+		return false
+	}
+	if len(aggregate.Subscriptions) > 0 && len(stringsutil.Intersect(aggregate.Subscriptions, event.Entity.Subscriptions)) == 0 {
+		return false
+	}
+	if sel != nil && !sel.Matches(event.Fields()) {
+		return false
+	}
+	return true
+}
+
+// Status returns the status of the aggregate given the counts of its events,
+// along with the thresholds that were exceeded.
+func Status(aggregate *aggregatev1.Aggregate, counts Counts) (uint32, []*aggregatev1.AggregateThreshold) {
+	var status uint32
+	var exceeded []*aggregatev1.AggregateThreshold
+	for _, threshold := range aggregate.Thresholds {
+		if !counts.Exceeds(threshold) {
+			continue
+		}
+		exceeded = append(exceeded, threshold)
+		if threshold.Status > status {
+			status = threshold.Status
+		}
+	}
+	return status, exceeded
+}
+
+// NewEvent returns the event of the aggregate, on its proxy entity.
+func NewEvent(aggregate *aggregatev1.Aggregate, counts Counts, now time.Time) *corev2.Event {
+	namespace := aggregate.Metadata.Namespace
+	entityName := aggregate.GetProxyEntityName()
+	status, exceeded := Status(aggregate, counts)
+
+	output := fmt.Sprintf("%d events: %d ok, %d warning, %d critical, %d unknown",
+		counts.Total, counts.OK, counts.Warning, counts.Critical, counts.Unknown)
+	if len(exceeded) > 0 {
+		reasons := make([]string, 0, len(exceeded))
+		for _, threshold := range exceeded {
+			if threshold.Count > 0 {
+				reasons = append(reasons, fmt.Sprintf("more than %d %s", threshold.Count, threshold.State))
+			} else {
+				reasons = append(reasons, fmt.Sprintf("more than %g%% %s", threshold.Percentage, threshold.State))
+			}
+		}
+		output += "; " + strings.Join(reasons, ", ")
+	}
+
+	check := &corev2.Check{
+		ObjectMeta: corev2.ObjectMeta{
+			Name:        aggregate.Metadata.Name,
+			Namespace:   namespace,
+			Labels:      aggregate.Metadata.Labels,
+			Annotations: aggregate.Metadata.Annotations,
+		},
+		Interval:        aggregate.Interval,
+		Handlers:        aggregate.Handlers,
+		Pipelines:       aggregate.Pipelines,
+		ProxyEntityName: entityName,
+		Status:          status,
+		Output:          output,
+		Executed:        now.Unix(),
+		Issued:          now.Unix(),
+	}
+	entity := &corev2.Entity{
+		ObjectMeta:  corev2.NewObjectMeta(entityName, namespace),
+		EntityClass: corev2.EntityProxyClass,
+	}
+	event := corev2.NewEvent(corev2.NewObjectMeta("", namespace))
+	event.Timestamp = now.Unix()
+	event.Entity = entity
+	event.Check = check
+	return event
+}
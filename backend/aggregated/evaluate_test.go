@@ -0,0 +1,117 @@
+package aggregated
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	aggregatev1 "github.com/sensu/sensu-go/backend/aggregate/v1"
+	"github.com/sensu/sensu-go/testing/mockstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func fixtureAggregate() *aggregatev1.Aggregate {
+	return &aggregatev1.Aggregate{
+		Metadata:      corev2.ObjectMeta{Name: "web-http", Namespace: "default"},
+		Checks:        []string{"http"},
+		Subscriptions: []string{"web"},
+		EventSelector: "event.labels.tier == web",
+		Interval:      60,
+		Handlers:      []string{"slack"},
+		Thresholds: []*aggregatev1.AggregateThreshold{
+			{Status: 1, State: aggregatev1.StateFailing, Count: 1},
+			{Status: 2, State: aggregatev1.StateCritical, Percentage: 30},
+		},
+	}
+}
+
+func fixtureEvent(entity, check string, status uint32) *corev2.Event {
+	event := corev2.FixtureEvent(entity, check)
+	event.Check.Status = status
+	event.Entity.Subscriptions = []string{"web"}
+	event.Labels = map[string]string{"tier": "web"}
+	return event
+}
+
+func TestCount(t *testing.T) {
+	other := fixtureEvent("db01", "http", 2)
+	other.Labels["tier"] = "db"
+	unsubscribed := fixtureEvent("web04", "http", 2)
+	unsubscribed.Entity.Subscriptions = nil
+	events := []*corev2.Event{
+		fixtureEvent("web01", "http", 0),
+		fixtureEvent("web02", "http", 2),
+		fixtureEvent("web03", "http", 3),
+		fixtureEvent("web01", "disk", 2),
+		fixtureEvent("web-http", "web-http", 2),
+		other,
+		unsubscribed,
+	}
+	st := &mockstore.MockStore{}
+	st.On("GetEvents", mock.Anything, mock.Anything).Return(events, nil)
+
+	counts, err := Count(context.Background(), st, fixtureAggregate())
+	require.NoError(t, err)
+	assert.Equal(t, Counts{Total: 3, OK: 1, Critical: 1, Unknown: 1}, counts)
+
+	ctx := st.Calls[0].Arguments.Get(0).(context.Context)
+	assert.Equal(t, "default", ctx.Value(corev2.NamespaceKey))
+}
+
+func TestStatus(t *testing.T) {
+	aggregate := fixtureAggregate()
+	tests := []struct {
+		name     string
+		counts   Counts
+		status   uint32
+		exceeded int
+	}{
+		{
+			name:   "no events",
+			counts: Counts{},
+		},
+		{
+			name:   "all ok",
+			counts: Counts{Total: 10, OK: 10},
+		},
+		{
+			name:     "failing",
+			counts:   Counts{Total: 10, OK: 8, Warning: 1, Critical: 1},
+			status:   1,
+			exceeded: 1,
+		},
+		{
+			name:     "critical",
+			counts:   Counts{Total: 10, OK: 6, Critical: 4},
+			status:   2,
+			exceeded: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, exceeded := Status(aggregate, tt.counts)
+			assert.Equal(t, tt.status, status)
+			assert.Len(t, exceeded, tt.exceeded)
+		})
+	}
+}
+
+func TestNewEvent(t *testing.T) {
+	aggregate := fixtureAggregate()
+	aggregate.ProxyEntityName = "web-tier"
+	now := time.Unix(1700000000, 0)
+
+	event := NewEvent(aggregate, Counts{Total: 10, OK: 6, Critical: 4}, now)
+	require.NoError(t, event.Validate())
+	assert.Equal(t, "default", event.Namespace)
+	assert.Equal(t, "web-tier", event.Entity.Name)
+	assert.Equal(t, corev2.EntityProxyClass, event.Entity.EntityClass)
+	assert.Equal(t, "web-http", event.Check.Name)
+	assert.Equal(t, uint32(2), event.Check.Status)
+	assert.Equal(t, []string{"slack"}, event.Check.Handlers)
+	assert.Equal(t, now.Unix(), event.Check.Executed)
+	assert.Equal(t, "10 events: 6 ok, 0 warning, 4 critical, 0 unknown; more than 1 failing, more than 30% critical", event.Check.Output)
+}
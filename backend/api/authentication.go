@@ -9,6 +9,8 @@ import (
 	"github.com/sensu/sensu-go/backend/authentication"
 	"github.com/sensu/sensu-go/backend/authentication/jwt"
 	"github.com/sensu/sensu-go/backend/authentication/providers/basic"
+	authenticationv2 "github.com/sensu/sensu-go/backend/authentication/v2"
+	"github.com/sirupsen/logrus"
 )
 
 // AuthenticationClient is an API client for authentication.
@@ -32,6 +34,70 @@ func (a *AuthenticationClient) CreateAccessToken(ctx context.Context, username,
 		return nil, corev2.ErrUnauthorized
 	}
 
+	return issueTokens(ctx, claims, 0)
+}
+
+// OIDCAuthCodeURL returns the URL of the identity provider a user must visit
+// to log in with an OIDC provider.
+func (a *AuthenticationClient) OIDCAuthCodeURL(ctx context.Context, req *authenticationv2.OIDCAuthorizeRequest) (string, error) {
+	provider, err := a.oidcProvider(req.Provider)
+	if err != nil {
+		return "", err
+	}
+	return provider.AuthCodeURL(ctx, req)
+}
+
+// CreateOIDCAccessToken creates a new access token, given an authorization
+// code issued by the identity provider of an OIDC provider. The session ends
+// when the ID token issued by the identity provider expires.
+func (a *AuthenticationClient) CreateOIDCAccessToken(ctx context.Context, req *authenticationv2.OIDCTokenRequest) (*corev2.Tokens, error) {
+	provider, err := a.oidcProvider(req.Provider)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := provider.Exchange(ctx, req)
+	if err != nil {
+		if errors.Is(err, authenticationv2.ErrInvalidRequest) {
+			return nil, err
+		}
+		logger.WithError(err).WithField("provider", provider.Name()).Error("oidc authentication failed")
+		return nil, corev2.ErrUnauthorized
+	}
+	logger.WithFields(logrus.Fields{
+		"subject":     claims.Subject,
+		"groups":      claims.Groups,
+		"provider_id": claims.Provider.ProviderID,
+	}).Info("login successful")
+	return issueTokens(ctx, claims, claims.ExpiresAt)
+}
+
+// oidcProvider returns the OIDC provider with the given name, or the only
+// OIDC provider if name is empty.
+func (a *AuthenticationClient) oidcProvider(name string) (*authenticationv2.OIDC, error) {
+	var found []*authenticationv2.OIDC
+	for _, provider := range a.auth.Providers() {
+		oidc, ok := provider.(*authenticationv2.OIDC)
+		if !ok || (name != "" && oidc.Name() != name) {
+			continue
+		}
+		found = append(found, oidc)
+	}
+	switch {
+	case len(found) == 1:
+		return found[0], nil
+	case name != "":
+		return nil, fmt.Errorf("%w: oidc provider %q not found", authenticationv2.ErrInvalidRequest, name)
+	case len(found) == 0:
+		return nil, fmt.Errorf("%w: no oidc provider configured", authenticationv2.ErrInvalidRequest)
+	default:
+		return nil, fmt.Errorf("%w: several oidc providers are configured, the provider must be specified", authenticationv2.ErrInvalidRequest)
+	}
+}
+
+// issueTokens creates access and refresh tokens for the claims of an
+// authenticated user. The refresh token expires at sessionExpiresAt, a unix
+// timestamp, if not zero.
+func issueTokens(ctx context.Context, claims *corev2.Claims, sessionExpiresAt int64) (*corev2.Tokens, error) {
 	// Add the 'system:users' group to this user
 	claims.Groups = append(claims.Groups, "system:users")
 
@@ -48,6 +114,7 @@ func (a *AuthenticationClient) CreateAccessToken(ctx context.Context, username,
 
 	// Create a refresh token and its signed version
 	refreshClaims := &corev2.Claims{StandardClaims: corev2.StandardClaims(claims.Subject)}
+	refreshClaims.ExpiresAt = sessionExpiresAt
 	_, refreshTokenString, err := jwt.RefreshToken(refreshClaims)
 	if err != nil {
 		return nil, fmt.Errorf("error creating access token: %s", err)
@@ -60,7 +127,6 @@ func (a *AuthenticationClient) CreateAccessToken(ctx context.Context, username,
 	}
 
 	return result, nil
-
 }
 
 // TestCreds detects if the username and password are valid.
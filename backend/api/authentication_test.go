@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	corev2 "github.com/sensu/core/v2"
 	"github.com/sensu/sensu-go/backend/authentication"
 	"github.com/sensu/sensu-go/backend/authentication/bcrypt"
 	"github.com/sensu/sensu-go/backend/authentication/jwt"
 	"github.com/sensu/sensu-go/backend/authentication/providers/basic"
+	authenticationv2 "github.com/sensu/sensu-go/backend/authentication/v2"
 	storev2 "github.com/sensu/sensu-go/backend/store/v2"
 	"github.com/sensu/sensu-go/testing/mockstore"
 	"github.com/stretchr/testify/mock"
@@ -228,3 +230,59 @@ func TestRefreshAccessToken(t *testing.T) {
 		})
 	}
 }
+
+func TestOIDCProviderSelection(t *testing.T) {
+	auth := defaultAuth(defaultStore())
+	client := NewAuthenticationClient(auth)
+
+	if _, err := client.oidcProvider(""); !errors.Is(err, authenticationv2.ErrInvalidRequest) {
+		t.Fatalf("expected invalid request error without oidc provider, got %v", err)
+	}
+
+	auth.AddProvider(&authenticationv2.OIDC{Metadata: corev2.ObjectMeta{Name: "idp1"}})
+	provider, err := client.oidcProvider("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := provider.Name(), "idp1"; got != want {
+		t.Errorf("bad provider: got %q, want %q", got, want)
+	}
+
+	auth.AddProvider(&authenticationv2.OIDC{Metadata: corev2.ObjectMeta{Name: "idp2"}})
+	if _, err := client.oidcProvider(""); !errors.Is(err, authenticationv2.ErrInvalidRequest) {
+		t.Fatalf("expected invalid request error with several oidc providers, got %v", err)
+	}
+	provider, err = client.oidcProvider("idp2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := provider.Name(), "idp2"; got != want {
+		t.Errorf("bad provider: got %q, want %q", got, want)
+	}
+	if _, err := client.oidcProvider("basic"); !errors.Is(err, authenticationv2.ErrInvalidRequest) {
+		t.Fatalf("expected invalid request error for a non oidc provider, got %v", err)
+	}
+}
+
+func TestIssueTokensSessionExpiry(t *testing.T) {
+	claims, err := jwt.NewClaims(&corev2.User{Username: "oidc:jane"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := issueTokens(context.Background(), claims, time.Now().Add(time.Hour).Unix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jwt.ValidateToken(tokens.Refresh); err != nil {
+		t.Fatalf("expected a valid refresh token, got %v", err)
+	}
+
+	// The refresh token can't be used once the session is over
+	tokens, err = issueTokens(context.Background(), claims, time.Now().Add(-time.Minute).Unix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jwt.ValidateToken(tokens.Refresh); err == nil {
+		t.Fatal("expected an expired refresh token")
+	}
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/authorization"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+// EventHistoryClient is an API client for the event history.
+type EventHistoryClient struct {
+	store store.EventHistoryStore
+	auth  authorization.Authorizer
+}
+
+// NewEventHistoryClient creates a new EventHistoryClient, given a store and
+// authorizer.
+func NewEventHistoryClient(store store.EventHistoryStore, auth authorization.Authorizer) *EventHistoryClient {
+	return &EventHistoryClient{
+		store: store,
+		auth:  auth,
+	}
+}
+
+// ListEventHistory lists the recorded events of an entity and check, most
+// recent first, if authorized to get the event.
+func (e *EventHistoryClient) ListEventHistory(ctx context.Context, entity, check string, pred *store.EventHistoryPredicate) ([]*corev2.Event, error) {
+	attrs := eventGetAttributes(ctx, fmt.Sprintf("%s:%s", entity, check))
+	if err := authorize(ctx, e.auth, attrs); err != nil {
+		return nil, err
+	}
+	events, err := e.store.GetEventHistory(ctx, entity, check, pred)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list event history: %s", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"errors"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/authorization"
+	"github.com/sensu/sensu-go/backend/store"
+	"github.com/sensu/sensu-go/testing/mockstore"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestListEventHistory(t *testing.T) {
+	getAttrs := authorization.AttributesKey{
+		APIGroup:     "core",
+		APIVersion:   "v2",
+		Namespace:    "default",
+		Resource:     "events",
+		ResourceName: "default:default",
+		UserName:     "legit",
+		Verb:         "get",
+	}
+	tests := []struct {
+		Name     string
+		UserName string
+		StoreErr error
+		Exp      int
+		ExpErr   bool
+	}{
+		{
+			Name:     "wrong user",
+			UserName: "haxor",
+			ExpErr:   true,
+		},
+		{
+			Name:     "store error",
+			UserName: "legit",
+			StoreErr: errors.New("error"),
+			ExpErr:   true,
+		},
+		{
+			Name:     "authorized",
+			UserName: "legit",
+			Exp:      2,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ctx := contextWithUser(defaultContext(), test.UserName, nil)
+			history := new(mockstore.MockStore)
+			history.On("GetEventHistory", mock.Anything, "default", "default", mock.Anything).
+				Return([]*corev2.Event{defaultEvent, defaultEvent}, test.StoreErr)
+			auth := &mockAuth{attrs: map[authorization.AttributesKey]bool{getAttrs: true}}
+			client := NewEventHistoryClient(history, auth)
+			events, err := client.ListEventHistory(ctx, "default", "default", &store.EventHistoryPredicate{})
+			if err != nil && !test.ExpErr {
+				t.Fatal(err)
+			}
+			if err == nil && test.ExpErr {
+				t.Fatal("expected non-nil error")
+			}
+			if got, want := len(events), test.Exp; got != want {
+				t.Errorf("bad number of events: got %d, want %d", got, want)
+			}
+		})
+	}
+}
@@ -55,6 +55,10 @@ const (
 
 	// Gone indicates that an API that was once supported but no longer is.
 	Gone
+
+	// ResourceExhausted indicates that a rate limit, or a quota, was
+	// exceeded, and that the request can be retried later.
+	ResourceExhausted
 )
 
 // Default error messages if not message is provided.
@@ -69,6 +73,7 @@ var standardErrorMessages = map[ErrCode]string{
 	PreconditionFailed: "precondition failed",
 	DeadlineExceeded:   "deadline exceeded",
 	Gone:               "this action is no longer supported",
+	ResourceExhausted:  "resource exhausted",
 }
 
 // Error describes an issue that ocurred while performing the action.
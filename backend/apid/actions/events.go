@@ -18,15 +18,17 @@ const deletedEventSentinel = -1
 
 // EventController expose actions in which a viewer can perform.
 type EventController struct {
-	store store.EventStore
-	bus   messaging.MessageBus
+	store        store.EventStore
+	historyStore store.EventHistoryStore
+	bus          messaging.MessageBus
 }
 
 // NewEventController returns new EventController
 func NewEventController(store storev2.Interface, bus messaging.MessageBus) EventController {
 	return EventController{
-		store: store.GetEventStore(),
-		bus:   bus,
+		store:        store.GetEventStore(),
+		historyStore: store.GetEventHistoryStore(),
+		bus:          bus,
 	}
 }
 
@@ -78,6 +80,21 @@ func (a EventController) Get(ctx context.Context, entity, check string) (*corev2
 	return result, nil
 }
 
+// History returns the recorded events of the given entity and check, most
+// recent first.
+func (a EventController) History(ctx context.Context, entity, check string, pred *store.EventHistoryPredicate) ([]*corev2.Event, error) {
+	if entity == "" || check == "" {
+		return nil, NewErrorf(InvalidArgument, "History() requires both an entity and a check")
+	}
+
+	results, err := a.historyStore.GetEventHistory(ctx, entity, check, pred)
+	if err != nil {
+		return nil, NewError(InternalErr, err)
+	}
+
+	return results, nil
+}
+
 // Delete destroys the event indicated by the supplied entity and check.
 func (a EventController) Delete(ctx context.Context, entity, check string) error {
 	// Destroy (for events) requires both an entity and check
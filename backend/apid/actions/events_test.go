@@ -72,6 +72,7 @@ func TestEventList(t *testing.T) {
 		s := &mockstore.MockStore{}
 		sv2 := new(mockstore.V2MockStore)
 		sv2.On("GetEventStore").Return(s)
+		sv2.On("GetEventHistoryStore").Return(s)
 		bus := &mockbus.MockBus{}
 		eventController := NewEventController(sv2, bus)
 
@@ -151,6 +152,7 @@ func TestEventFind(t *testing.T) {
 		store := &mockstore.MockStore{}
 		sv2 := new(mockstore.V2MockStore)
 		sv2.On("GetEventStore").Return(store)
+		sv2.On("GetEventHistoryStore").Return(store)
 		bus := &mockbus.MockBus{}
 		eventController := NewEventController(sv2, bus)
 
@@ -226,6 +228,7 @@ func TestEventDestroy(t *testing.T) {
 		store := &mockstore.MockStore{}
 		sv2 := new(mockstore.V2MockStore)
 		sv2.On("GetEventStore").Return(store)
+		sv2.On("GetEventHistoryStore").Return(store)
 		bus := &mockbus.MockBus{}
 		eventController := NewEventController(sv2, bus)
 
@@ -317,6 +320,7 @@ func TestEventCreateOrReplace(t *testing.T) {
 		store := &mockstore.MockStore{}
 		sv2 := new(mockstore.V2MockStore)
 		sv2.On("GetEventStore").Return(store)
+		sv2.On("GetEventHistoryStore").Return(store)
 		bus := &mockbus.MockBus{}
 		actions := NewEventController(sv2, bus)
 
@@ -354,6 +358,70 @@ func TestEventCreateOrReplace(t *testing.T) {
 	}
 }
 
+func TestEventHistory(t *testing.T) {
+	ctx := context.Background()
+	events := []*corev2.Event{
+		corev2.FixtureEvent("entity1", "check1"),
+		corev2.FixtureEvent("entity1", "check1"),
+	}
+
+	testCases := []struct {
+		name            string
+		entity          string
+		check           string
+		storeErr        error
+		expectedLen     int
+		expectedErr     bool
+		expectedErrCode ErrCode
+	}{
+		{
+			name:        "History",
+			entity:      "entity1",
+			check:       "check1",
+			expectedLen: 2,
+		},
+		{
+			name:            "Missing Check",
+			entity:          "entity1",
+			expectedErr:     true,
+			expectedErrCode: InvalidArgument,
+		},
+		{
+			name:            "Store Failure",
+			entity:          "entity1",
+			check:           "check1",
+			storeErr:        errors.New("error"),
+			expectedErr:     true,
+			expectedErrCode: InternalErr,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &mockstore.MockStore{}
+			sv2 := new(mockstore.V2MockStore)
+			sv2.On("GetEventStore").Return(s)
+			sv2.On("GetEventHistoryStore").Return(s)
+			eventController := NewEventController(sv2, &mockbus.MockBus{})
+
+			pred := &store.EventHistoryPredicate{Limit: 10}
+			s.On("GetEventHistory", ctx, tc.entity, tc.check, pred).Return(events, tc.storeErr)
+
+			results, err := eventController.History(ctx, tc.entity, tc.check, pred)
+			if tc.expectedErr {
+				inferErr, ok := err.(Error)
+				if !ok {
+					t.Fatalf("expected error of type Error, got %v", err)
+				}
+				assert.Equal(t, tc.expectedErrCode, inferErr.Code)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, results, tc.expectedLen)
+		})
+	}
+}
+
 func TestEventCreatedBy(t *testing.T) {
 	claims, err := jwt.NewClaims(&corev2.User{Username: "admin"})
 	assert.NoError(t, err)
@@ -363,6 +431,7 @@ func TestEventCreatedBy(t *testing.T) {
 	store := &mockstore.MockStore{}
 	sv2 := new(mockstore.V2MockStore)
 	sv2.On("GetEventStore").Return(store)
+	sv2.On("GetEventHistoryStore").Return(store)
 	bus := &mockbus.MockBus{}
 	actions := NewEventController(sv2, bus)
 
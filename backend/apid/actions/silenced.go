@@ -59,7 +59,9 @@ func (c SilencedController) Create(ctx context.Context, entry *corev2.Silenced)
 
 	// Check for existing
 	if e, serr := c.Store.GetSilenceByName(ctx, namespace, entry.Name); serr != nil {
-		return NewError(InternalErr, serr)
+		if _, ok := serr.(*store.ErrNotFound); !ok {
+			return NewError(InternalErr, serr)
+		}
 	} else if e != nil {
 		return NewErrorf(AlreadyExistsErr)
 	}
@@ -96,6 +98,9 @@ func (c SilencedController) CreateOrReplace(ctx context.Context, entry *corev2.S
 
 func (c SilencedController) Get(ctx context.Context, name string) (*corev2.Silenced, error) {
 	entry, err := c.Store.GetSilenceByName(ctx, corev2.ContextNamespace(ctx), name)
+	if _, ok := err.(*store.ErrNotFound); ok {
+		return nil, NewError(NotFound, errors.New("silenced entry not found"))
+	}
 	if err != nil {
 		return nil, NewError(InternalErr, err)
 	}
@@ -9,6 +9,7 @@ import (
 	jwt "github.com/golang-jwt/jwt/v4"
 	corev2 "github.com/sensu/core/v2"
 	coreJWT "github.com/sensu/sensu-go/backend/authentication/jwt"
+	"github.com/sensu/sensu-go/backend/store"
 	"github.com/sensu/sensu-go/testing/mockstore"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -248,6 +249,14 @@ func TestSilencedCreate(t *testing.T) {
 			expectedErrCode:	InternalErr,
 			expectedID:		"*:silence1",
 		},
+		{
+			name:		"Not Found on Fetch",
+			ctx:		defaultCtx,
+			argument:	corev2.FixtureSilenced("*:silence1"),
+			fetchErr:	&store.ErrNotFound{Key: "*:silence1"},
+			expectedErr:	false,
+			expectedID:	"*:silence1",
+		},
 		{
 			name:			"Store Err on Fetch",
 			ctx:			defaultCtx,
@@ -22,11 +22,13 @@ import (
 	"github.com/sensu/sensu-go/backend/apid/handlers"
 	"github.com/sensu/sensu-go/backend/apid/middlewares"
 	"github.com/sensu/sensu-go/backend/apid/routers"
+	"github.com/sensu/sensu-go/backend/audit"
 	"github.com/sensu/sensu-go/backend/authentication"
 	"github.com/sensu/sensu-go/backend/authorization/rbac"
 	"github.com/sensu/sensu-go/backend/messaging"
 	"github.com/sensu/sensu-go/backend/queue"
 	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	"github.com/sensu/sensu-go/backend/stream"
 )
 
 // APId is the backend HTTP API.
@@ -35,6 +37,11 @@ type APId struct {
 	HTTPServer                 *http.Server
 	CoreSubrouter              *mux.Router
 	CoreV3Subrouter            *mux.Router
+	SecretsSubrouter           *mux.Router
+	AuthenticationV2Subrouter  *mux.Router
+	PipelineSubrouter          *mux.Router
+	AggregateSubrouter         *mux.Router
+	QuotaSubrouter             *mux.Router
 	EntityLimitedCoreSubrouter *mux.Router
 	GraphQLSubrouter           *mux.Router
 	RequestLimit               int64
@@ -64,6 +71,31 @@ type Config struct {
 	ClusterVersion string
 	GraphQLService *graphql.Service
 	Queue          queue.Client
+
+	// AuditLogger records the requests that mutate resources, if not nil.
+	AuditLogger *audit.Logger
+
+	// AuditStore serves the audit records, if not nil.
+	AuditStore audit.Store
+
+	// StreamHub serves the streams of resource changes, if not nil.
+	StreamHub *stream.Hub
+
+	// RetryQueue is the queue of the handler retries, on which the dead
+	// letters are replayed.
+	RetryQueue queue.Client
+
+	// PipelineTracer runs the events of the pipeline tests, if not nil.
+	PipelineTracer routers.PipelineTracer
+
+	// QuotaEnforcer enforces the namespace quotas, if not nil.
+	QuotaEnforcer QuotaEnforcer
+}
+
+// QuotaEnforcer enforces the namespace quotas and reports their usage.
+type QuotaEnforcer interface {
+	middlewares.QuotaEnforcer
+	routers.QuotaUsageGetter
 }
 
 // New creates a new APId.
@@ -94,8 +126,14 @@ func New(c Config, opts ...Option) (*APId, error) {
 	_ = PublicSubrouter(router, c)
 	a.GraphQLSubrouter = GraphQLSubrouter(router, c)
 	_ = AuthenticationSubrouter(router, c)
+	_ = OIDCSubrouter(router, c)
 	a.CoreSubrouter = CoreSubrouter(router, c)
 	a.CoreV3Subrouter = CoreV3Subrouter(router, c)
+	a.SecretsSubrouter = SecretsSubrouter(router, c)
+	a.AuthenticationV2Subrouter = AuthenticationV2Subrouter(router, c)
+	a.PipelineSubrouter = PipelineSubrouter(router, c)
+	a.AggregateSubrouter = AggregateSubrouter(router, c)
+	a.QuotaSubrouter = QuotaSubrouter(router, c)
 	a.EntityLimitedCoreSubrouter = EntityLimitedCoreSubrouter(router, c)
 
 	a.HTTPServer = &http.Server{
@@ -143,6 +181,22 @@ func AuthenticationSubrouter(router *mux.Router, cfg Config) *mux.Router {
 	return subrouter
 }
 
+// OIDCSubrouter initializes a subrouter that handles the OIDC login requests.
+// Users are not authenticated yet, so the requests carry no token.
+func OIDCSubrouter(router *mux.Router, cfg Config) *mux.Router {
+	subrouter := NewSubrouter(
+		router.NewRoute(),
+		middlewares.SimpleLogger{},
+		middlewares.LimitRequest{Limit: cfg.RequestLimit},
+	)
+
+	mountRouters(subrouter,
+		routers.NewOIDCRouter(api.NewAuthenticationClient(cfg.Authenticator)),
+	)
+
+	return subrouter
+}
+
 // CoreSubrouter initializes a subrouter that handles all requests coming to
 // /api/core/v2
 func CoreSubrouter(router *mux.Router, cfg Config) *mux.Router {
@@ -152,7 +206,9 @@ func CoreSubrouter(router *mux.Router, cfg Config) *mux.Router {
 		middlewares.Authentication{Store: cfg.Store},
 		middlewares.SimpleLogger{},
 		middlewares.AuthorizationAttributes{},
+		middlewares.Audit{Logger: cfg.AuditLogger, Limit: cfg.RequestLimit},
 		middlewares.Authorization{Authorizer: &rbac.Authorizer{Store: cfg.Store}},
+		middlewares.Quota{Enforcer: cfg.QuotaEnforcer},
 		middlewares.LimitRequest{Limit: cfg.RequestLimit},
 		middlewares.Pagination{},
 		middlewares.Selectors{},
@@ -168,7 +224,7 @@ func CoreSubrouter(router *mux.Router, cfg Config) *mux.Router {
 		routers.NewHandlersRouter(cfg.Store),
 		routers.NewHooksRouter(cfg.Store),
 		routers.NewMutatorsRouter(cfg.Store),
-		routers.NewPipelinesRouter(cfg.Store),
+		routers.NewPipelinesRouter(cfg.Store, cfg.PipelineTracer),
 		routers.NewRolesRouter(cfg.Store),
 		routers.NewRoleBindingsRouter(cfg.Store),
 		routers.NewSilencedRouter(cfg.Store),
@@ -186,6 +242,7 @@ func CoreV3Subrouter(router *mux.Router, cfg Config) *mux.Router {
 		middlewares.Authentication{Store: cfg.Store},
 		middlewares.SimpleLogger{},
 		middlewares.AuthorizationAttributes{},
+		middlewares.Audit{Logger: cfg.AuditLogger, Limit: cfg.RequestLimit},
 		middlewares.Authorization{Authorizer: &rbac.Authorizer{Store: cfg.Store}},
 		middlewares.LimitRequest{Limit: cfg.RequestLimit},
 		middlewares.Pagination{},
@@ -195,6 +252,124 @@ func CoreV3Subrouter(router *mux.Router, cfg Config) *mux.Router {
 		subrouter,
 		routers.NewNamespacesRouter(api.NewNamespaceClient(cfg.Store, &rbac.Authorizer{Store: cfg.Store}), handlers.NewHandlers[*corev3.Namespace](cfg.Store)),
 	)
+	if cfg.AuditStore != nil {
+		mountRouters(subrouter, routers.NewAuditRouter(cfg.AuditStore))
+	}
+	if cfg.StreamHub != nil {
+		mountRouters(subrouter, routers.NewStreamRouter(cfg.StreamHub, &rbac.Authorizer{Store: cfg.Store}))
+	}
+	return subrouter
+}
+
+// SecretsSubrouter initializes a subrouter that handles all requests coming to
+// /api/secrets/v1
+func SecretsSubrouter(router *mux.Router, cfg Config) *mux.Router {
+	subrouter := NewSubrouter(
+		router.PathPrefix("/api/{group:secrets}/{version:v1}/"),
+		middlewares.Namespace{},
+		middlewares.Authentication{Store: cfg.Store},
+		middlewares.SimpleLogger{},
+		middlewares.AuthorizationAttributes{},
+		middlewares.Audit{Logger: cfg.AuditLogger, Limit: cfg.RequestLimit},
+		middlewares.Authorization{Authorizer: &rbac.Authorizer{Store: cfg.Store}},
+		middlewares.LimitRequest{Limit: cfg.RequestLimit},
+		middlewares.Pagination{},
+		middlewares.Selectors{},
+	)
+	mountRouters(
+		subrouter,
+		routers.NewSecretsProvidersRouter(cfg.Store),
+		routers.NewSecretsRouter(cfg.Store),
+	)
+	return subrouter
+}
+
+// AuthenticationV2Subrouter initializes a subrouter that handles all requests
+// coming to /api/authentication/v2
+func AuthenticationV2Subrouter(router *mux.Router, cfg Config) *mux.Router {
+	subrouter := NewSubrouter(
+		router.PathPrefix("/api/{group:authentication}/{version:v2}/"),
+		middlewares.Namespace{},
+		middlewares.Authentication{Store: cfg.Store},
+		middlewares.SimpleLogger{},
+		middlewares.AuthorizationAttributes{},
+		middlewares.Audit{Logger: cfg.AuditLogger, Limit: cfg.RequestLimit},
+		middlewares.Authorization{Authorizer: &rbac.Authorizer{Store: cfg.Store}},
+		middlewares.LimitRequest{Limit: cfg.RequestLimit},
+		middlewares.Pagination{},
+		middlewares.Selectors{},
+	)
+	mountRouters(
+		subrouter,
+		routers.NewAuthProvidersRouter(cfg.Store),
+	)
+	return subrouter
+}
+
+// PipelineSubrouter initializes a subrouter that handles all requests coming
+// to /api/pipeline/v1
+func PipelineSubrouter(router *mux.Router, cfg Config) *mux.Router {
+	subrouter := NewSubrouter(
+		router.PathPrefix("/api/{group:pipeline}/{version:v1}/"),
+		middlewares.Namespace{},
+		middlewares.Authentication{Store: cfg.Store},
+		middlewares.SimpleLogger{},
+		middlewares.AuthorizationAttributes{},
+		middlewares.Audit{Logger: cfg.AuditLogger, Limit: cfg.RequestLimit},
+		middlewares.Authorization{Authorizer: &rbac.Authorizer{Store: cfg.Store}},
+		middlewares.LimitRequest{Limit: cfg.RequestLimit},
+		middlewares.Pagination{},
+		middlewares.Selectors{},
+	)
+	mountRouters(
+		subrouter,
+		routers.NewHTTPHandlersRouter(cfg.Store),
+		routers.NewDeadLettersRouter(cfg.Store, cfg.RetryQueue),
+	)
+	return subrouter
+}
+
+// AggregateSubrouter initializes a subrouter that handles all requests coming
+// to /api/aggregate/v1
+func AggregateSubrouter(router *mux.Router, cfg Config) *mux.Router {
+	subrouter := NewSubrouter(
+		router.PathPrefix("/api/{group:aggregate}/{version:v1}/"),
+		middlewares.Namespace{},
+		middlewares.Authentication{Store: cfg.Store},
+		middlewares.SimpleLogger{},
+		middlewares.AuthorizationAttributes{},
+		middlewares.Audit{Logger: cfg.AuditLogger, Limit: cfg.RequestLimit},
+		middlewares.Authorization{Authorizer: &rbac.Authorizer{Store: cfg.Store}},
+		middlewares.LimitRequest{Limit: cfg.RequestLimit},
+		middlewares.Pagination{},
+		middlewares.Selectors{},
+	)
+	mountRouters(
+		subrouter,
+		routers.NewAggregatesRouter(cfg.Store),
+	)
+	return subrouter
+}
+
+// QuotaSubrouter initializes a subrouter that handles all requests coming
+// to /api/quota/v1
+func QuotaSubrouter(router *mux.Router, cfg Config) *mux.Router {
+	subrouter := NewSubrouter(
+		router.PathPrefix("/api/{group:quota}/{version:v1}/"),
+		middlewares.Namespace{},
+		middlewares.Authentication{Store: cfg.Store},
+		middlewares.SimpleLogger{},
+		middlewares.AuthorizationAttributes{},
+		middlewares.Audit{Logger: cfg.AuditLogger, Limit: cfg.RequestLimit},
+		middlewares.Authorization{Authorizer: &rbac.Authorizer{Store: cfg.Store}},
+		middlewares.LimitRequest{Limit: cfg.RequestLimit},
+		middlewares.Pagination{},
+		middlewares.Selectors{},
+	)
+	mountRouters(
+		subrouter,
+		routers.NewQuotasRouter(cfg.Store, cfg.QuotaEnforcer),
+	)
 	return subrouter
 }
 
@@ -207,7 +382,9 @@ func EntityLimitedCoreSubrouter(router *mux.Router, cfg Config) *mux.Router {
 		middlewares.Authentication{Store: cfg.Store},
 		middlewares.SimpleLogger{},
 		middlewares.AuthorizationAttributes{},
+		middlewares.Audit{Logger: cfg.AuditLogger, Limit: cfg.RequestLimit},
 		middlewares.Authorization{Authorizer: &rbac.Authorizer{Store: cfg.Store}},
+		middlewares.Quota{Enforcer: cfg.QuotaEnforcer},
 		middlewares.LimitRequest{Limit: cfg.RequestLimit},
 		middlewares.Pagination{},
 		middlewares.Selectors{},
@@ -236,6 +413,7 @@ func GraphQLSubrouter(router *mux.Router, cfg Config) *mux.Router {
 		// https://graphql.org/learn/introspection/
 		middlewares.Authentication{IgnoreUnauthorized: true, Store: cfg.Store},
 		middlewares.SimpleLogger{},
+		middlewares.GraphQLAudit{Logger: cfg.AuditLogger, Limit: cfg.RequestLimit},
 	)
 
 	// The write timeout hangs up the request making it more difficult for
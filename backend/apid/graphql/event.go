@@ -7,6 +7,8 @@ import (
 	corev3 "github.com/sensu/core/v3"
 	"github.com/sensu/sensu-go/backend/apid/graphql/globalid"
 	"github.com/sensu/sensu-go/backend/apid/graphql/schema"
+	"github.com/sensu/sensu-go/backend/dependencies"
+	"github.com/sensu/sensu-go/backend/store"
 	"github.com/sensu/sensu-go/graphql"
 	"github.com/sensu/core/v3/types"
 )
@@ -19,6 +21,7 @@ var _ schema.EventFieldResolvers = (*eventImpl)(nil)
 
 type eventImpl struct {
 	schema.EventAliases
+	historyClient EventHistoryClient
 }
 
 // ID implements response to request for 'id' field.
@@ -91,6 +94,33 @@ func (r *eventImpl) Silences(p graphql.ResolveParams) (interface{}, error) {
 	return records, err
 }
 
+// DependencyFailed implements response to request for 'dependencyFailed' field.
+func (r *eventImpl) DependencyFailed(p graphql.ResolveParams) (bool, error) {
+	event := p.Source.(*corev2.Event)
+	return dependencies.IsFailed(event), nil
+}
+
+// FailedDependencies implements response to request for 'failedDependencies' field.
+func (r *eventImpl) FailedDependencies(p graphql.ResolveParams) ([]string, error) {
+	event := p.Source.(*corev2.Event)
+	return append([]string{}, dependencies.Failed(event)...), nil
+}
+
+// History implements response to request for 'history' field.
+func (r *eventImpl) History(p schema.EventHistoryFieldResolverParams) (interface{}, error) {
+	event := p.Source.(*corev2.Event)
+	if r.historyClient == nil || !event.HasCheck() || event.Entity == nil {
+		return []*corev2.Event{}, nil
+	}
+	ctx := contextWithNamespace(p.Context, event.Entity.Namespace)
+	pred := &store.EventHistoryPredicate{
+		Start: p.Args.Since,
+		End:   p.Args.Until,
+		Limit: int64(clampInt(p.Args.Limit, 0, 1000)),
+	}
+	return r.historyClient.ListEventHistory(ctx, event.Entity.Name, event.Check.Name, pred)
+}
+
 // IsTypeOf is used to determine if a given value is associated with the type
 func (r *eventImpl) IsTypeOf(s interface{}, p graphql.IsTypeOfParams) bool {
 	_, ok := s.(*corev2.Event)
@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/apid/graphql/schema"
+	"github.com/sensu/sensu-go/backend/store"
 	"github.com/sensu/sensu-go/graphql"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -102,3 +105,44 @@ func TestEventTypeSilencesField(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, res, 4)
 }
+
+func TestEventTypeHistoryField(t *testing.T) {
+	event := corev2.FixtureEvent("my-entity", "my-check")
+	since := time.Unix(1700000000, 0)
+
+	client := new(MockEventHistoryClient)
+	client.On("ListEventHistory", mock.Anything, "my-entity", "my-check", &store.EventHistoryPredicate{Start: since, Limit: 5}).
+		Return([]*corev2.Event{event, event}, nil).Once()
+
+	impl := &eventImpl{historyClient: client}
+	params := schema.EventHistoryFieldResolverParams{}
+	params.Context = context.Background()
+	params.Source = event
+	params.Args.Since = since
+	params.Args.Limit = 5
+
+	res, err := impl.History(params)
+	require.NoError(t, err)
+	assert.Len(t, res, 2)
+}
+
+func TestEventTypeDependencyFields(t *testing.T) {
+	event := corev2.FixtureEvent("my-entity", "my-check")
+	impl := &eventImpl{}
+	params := graphql.ResolveParams{Context: context.Background(), Source: event}
+
+	failed, err := impl.DependencyFailed(params)
+	require.NoError(t, err)
+	assert.False(t, failed)
+	names, err := impl.FailedDependencies(params)
+	require.NoError(t, err)
+	assert.Empty(t, names)
+
+	event.AddAnnotation("sensu.io/failed_dependencies", "router01/ping,switch01/ping")
+	failed, err = impl.DependencyFailed(params)
+	require.NoError(t, err)
+	assert.True(t, failed)
+	names, err = impl.FailedDependencies(params)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"router01/ping", "switch01/ping"}, names)
+}
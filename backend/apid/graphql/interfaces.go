@@ -44,6 +44,10 @@ type EventClient interface {
 	EventStoreSupportsFiltering(context.Context) bool
 }
 
+type EventHistoryClient interface {
+	ListEventHistory(ctx context.Context, entity, check string, pred *store.EventHistoryPredicate) ([]*corev2.Event, error)
+}
+
 type EventFilterClient interface {
 	ListEventFilters(ctx context.Context) ([]*corev2.EventFilter, error)
 	FetchEventFilter(ctx context.Context, name string) (*corev2.EventFilter, error)
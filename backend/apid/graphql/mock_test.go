@@ -145,6 +145,15 @@ func (c *MockEntityClient) ListEntities(ctx context.Context, pred *store.Selecti
 	return args.Get(0).([]*corev2.Entity), args.Error(1)
 }
 
+type MockEventHistoryClient struct {
+	mock.Mock
+}
+
+func (c *MockEventHistoryClient) ListEventHistory(ctx context.Context, entity, check string, pred *store.EventHistoryPredicate) ([]*corev2.Event, error) {
+	args := c.Called(ctx, entity, check, pred)
+	return args.Get(0).([]*corev2.Event), args.Error(1)
+}
+
 type MockEventClient struct {
 	mock.Mock
 }
@@ -5,10 +5,24 @@ package schema
 import (
 	errors "errors"
 	graphql1 "github.com/graphql-go/graphql"
+	mapstructure "github.com/mitchellh/mapstructure"
 	graphql "github.com/sensu/sensu-go/graphql"
 	time "time"
 )
 
+// EventHistoryFieldResolverArgs contains arguments provided to history when selected
+type EventHistoryFieldResolverArgs struct {
+	Since time.Time // Since - Only include occurrences at or after the given time.
+	Until time.Time // Until - Only include occurrences before the given time.
+	Limit int       // Limit - self descriptive
+}
+
+// EventHistoryFieldResolverParams contains contextual info to resolve history field
+type EventHistoryFieldResolverParams struct {
+	graphql.ResolveParams
+	Args EventHistoryFieldResolverArgs
+}
+
 // EventFieldResolvers represents a collection of methods whose products represent the
 // response values of the 'Event' type.
 type EventFieldResolvers interface {
@@ -54,6 +68,15 @@ type EventFieldResolvers interface {
 	// Silenced implements response to request for 'silenced' field.
 	Silenced(p graphql.ResolveParams) ([]string, error)
 
+	// DependencyFailed implements response to request for 'dependencyFailed' field.
+	DependencyFailed(p graphql.ResolveParams) (bool, error)
+
+	// FailedDependencies implements response to request for 'failedDependencies' field.
+	FailedDependencies(p graphql.ResolveParams) ([]string, error)
+
+	// History implements response to request for 'history' field.
+	History(p EventHistoryFieldResolverParams) (interface{}, error)
+
 	// ToJSON implements response to request for 'toJSON' field.
 	ToJSON(p graphql.ResolveParams) (interface{}, error)
 }
@@ -211,6 +234,38 @@ func (_ EventAliases) Silenced(p graphql.ResolveParams) ([]string, error) {
 	return ret, err
 }
 
+// DependencyFailed implements response to request for 'dependencyFailed' field.
+func (_ EventAliases) DependencyFailed(p graphql.ResolveParams) (bool, error) {
+	val, err := graphql.DefaultResolver(p.Source, p.Info.FieldName)
+	ret, ok := val.(bool)
+	if err != nil {
+		return ret, err
+	}
+	if !ok {
+		return ret, errors.New("unable to coerce value for field 'dependencyFailed'")
+	}
+	return ret, err
+}
+
+// FailedDependencies implements response to request for 'failedDependencies' field.
+func (_ EventAliases) FailedDependencies(p graphql.ResolveParams) ([]string, error) {
+	val, err := graphql.DefaultResolver(p.Source, p.Info.FieldName)
+	ret, ok := val.([]string)
+	if err != nil {
+		return ret, err
+	}
+	if !ok {
+		return ret, errors.New("unable to coerce value for field 'failedDependencies'")
+	}
+	return ret, err
+}
+
+// History implements response to request for 'history' field.
+func (_ EventAliases) History(p EventHistoryFieldResolverParams) (interface{}, error) {
+	val, err := graphql.DefaultResolver(p.Source, p.Info.FieldName)
+	return val, err
+}
+
 // ToJSON implements response to request for 'toJSON' field.
 func (_ EventAliases) ToJSON(p graphql.ResolveParams) (interface{}, error) {
 	val, err := graphql.DefaultResolver(p.Source, p.Info.FieldName)
@@ -350,6 +405,39 @@ func _ObjTypeEventSilencedHandler(impl interface{}) graphql1.FieldResolveFn {
 	}
 }
 
+func _ObjTypeEventDependencyFailedHandler(impl interface{}) graphql1.FieldResolveFn {
+	resolver := impl.(interface {
+		DependencyFailed(p graphql.ResolveParams) (bool, error)
+	})
+	return func(frp graphql1.ResolveParams) (interface{}, error) {
+		return resolver.DependencyFailed(frp)
+	}
+}
+
+func _ObjTypeEventFailedDependenciesHandler(impl interface{}) graphql1.FieldResolveFn {
+	resolver := impl.(interface {
+		FailedDependencies(p graphql.ResolveParams) ([]string, error)
+	})
+	return func(frp graphql1.ResolveParams) (interface{}, error) {
+		return resolver.FailedDependencies(frp)
+	}
+}
+
+func _ObjTypeEventHistoryHandler(impl interface{}) graphql1.FieldResolveFn {
+	resolver := impl.(interface {
+		History(p EventHistoryFieldResolverParams) (interface{}, error)
+	})
+	return func(p graphql1.ResolveParams) (interface{}, error) {
+		frp := EventHistoryFieldResolverParams{ResolveParams: p}
+		err := mapstructure.Decode(p.Args, &frp.Args)
+		if err != nil {
+			return nil, err
+		}
+
+		return resolver.History(frp)
+	}
+}
+
 func _ObjTypeEventToJSONHandler(impl interface{}) graphql1.FieldResolveFn {
 	resolver := impl.(interface {
 		ToJSON(p graphql.ResolveParams) (interface{}, error)
@@ -370,6 +458,13 @@ func _ObjectTypeEventConfigFn() graphql1.ObjectConfig {
 				Name:              "check",
 				Type:              graphql.OutputType("Check"),
 			},
+			"dependencyFailed": &graphql1.Field{
+				Args:              graphql1.FieldConfigArgument{},
+				DeprecationReason: "",
+				Description:       "dependencyFailed returns true if a dependency of the event's check or entity\nwas failing when the event was processed.",
+				Name:              "dependencyFailed",
+				Type:              graphql1.NewNonNull(graphql1.Boolean),
+			},
 			"entity": &graphql1.Field{
 				Args:              graphql1.FieldConfigArgument{},
 				DeprecationReason: "",
@@ -377,6 +472,34 @@ func _ObjectTypeEventConfigFn() graphql1.ObjectConfig {
 				Name:              "entity",
 				Type:              graphql.OutputType("Entity"),
 			},
+			"failedDependencies": &graphql1.Field{
+				Args:              graphql1.FieldConfigArgument{},
+				DeprecationReason: "",
+				Description:       "failedDependencies lists the failing dependencies, as entity/check names.",
+				Name:              "failedDependencies",
+				Type:              graphql1.NewNonNull(graphql1.NewList(graphql1.NewNonNull(graphql1.String))),
+			},
+			"history": &graphql1.Field{
+				Args: graphql1.FieldConfigArgument{
+					"limit": &graphql1.ArgumentConfig{
+						DefaultValue: 10,
+						Description:  "self descriptive",
+						Type:         graphql1.Int,
+					},
+					"since": &graphql1.ArgumentConfig{
+						Description: "Only include occurrences at or after the given time.",
+						Type:        graphql1.DateTime,
+					},
+					"until": &graphql1.ArgumentConfig{
+						Description: "Only include occurrences before the given time.",
+						Type:        graphql1.DateTime,
+					},
+				},
+				DeprecationReason: "",
+				Description:       "history returns the past occurrences of the event, most recent first. Events\nare only recorded when the event history is enabled on the backend.",
+				Name:              "history",
+				Type:              graphql1.NewNonNull(graphql1.NewList(graphql1.NewNonNull(graphql.OutputType("Event")))),
+			},
 			"hooks": &graphql1.Field{
 				Args:              graphql1.FieldConfigArgument{},
 				DeprecationReason: "",
@@ -490,21 +613,24 @@ func _ObjectTypeEventConfigFn() graphql1.ObjectConfig {
 var _ObjectTypeEventDesc = graphql.ObjectDesc{
 	Config: _ObjectTypeEventConfigFn,
 	FieldHandlers: map[string]graphql.FieldHandler{
-		"check":         _ObjTypeEventCheckHandler,
-		"entity":        _ObjTypeEventEntityHandler,
-		"hooks":         _ObjTypeEventHooksHandler,
-		"id":            _ObjTypeEventIDHandler,
-		"isIncident":    _ObjTypeEventIsIncidentHandler,
-		"isNewIncident": _ObjTypeEventIsNewIncidentHandler,
-		"isResolution":  _ObjTypeEventIsResolutionHandler,
-		"isSilenced":    _ObjTypeEventIsSilencedHandler,
-		"metadata":      _ObjTypeEventMetadataHandler,
-		"namespace":     _ObjTypeEventNamespaceHandler,
-		"silenced":      _ObjTypeEventSilencedHandler,
-		"silences":      _ObjTypeEventSilencesHandler,
-		"timestamp":     _ObjTypeEventTimestampHandler,
-		"toJSON":        _ObjTypeEventToJSONHandler,
-		"wasSilenced":   _ObjTypeEventWasSilencedHandler,
+		"check":              _ObjTypeEventCheckHandler,
+		"dependencyFailed":   _ObjTypeEventDependencyFailedHandler,
+		"entity":             _ObjTypeEventEntityHandler,
+		"failedDependencies": _ObjTypeEventFailedDependenciesHandler,
+		"history":            _ObjTypeEventHistoryHandler,
+		"hooks":              _ObjTypeEventHooksHandler,
+		"id":                 _ObjTypeEventIDHandler,
+		"isIncident":         _ObjTypeEventIsIncidentHandler,
+		"isNewIncident":      _ObjTypeEventIsNewIncidentHandler,
+		"isResolution":       _ObjTypeEventIsResolutionHandler,
+		"isSilenced":         _ObjTypeEventIsSilencedHandler,
+		"metadata":           _ObjTypeEventMetadataHandler,
+		"namespace":          _ObjTypeEventNamespaceHandler,
+		"silenced":           _ObjTypeEventSilencedHandler,
+		"silences":           _ObjTypeEventSilencesHandler,
+		"timestamp":          _ObjTypeEventTimestampHandler,
+		"toJSON":             _ObjTypeEventToJSONHandler,
+		"wasSilenced":        _ObjTypeEventWasSilencedHandler,
 	},
 }
 
@@ -27,6 +27,7 @@ type ServiceConfig struct {
 	CheckClient        CheckClient
 	EntityClient       EntityClient
 	EventClient        EventClient
+	EventHistoryClient EventHistoryClient
 	EventFilterClient  EventFilterClient
 	HandlerClient      HandlerClient
 	HealthController   EtcdHealthController
@@ -83,7 +84,7 @@ func NewService(cfg ServiceConfig) (*Service, error) {
 	schema.RegisterCoreV3EntityStateExtensionOverrides(svc, &corev3EntityStateExtImpl{client: cfg.GenericClient, entityClient: cfg.EntityClient})
 	schema.RegisterNamespace(svc, &namespaceImpl{client: cfg.NamespaceClient, entityClient: cfg.EntityClient, eventClient: cfg.EventClient, serviceConfig: &cfg})
 	schema.RegisterErrCode(svc)
-	schema.RegisterEvent(svc, &eventImpl{})
+	schema.RegisterEvent(svc, &eventImpl{historyClient: cfg.EventHistoryClient})
 	schema.RegisterEventsListOrder(svc)
 	schema.RegisterJSON(svc, jsonImpl{})
 	schema.RegisterKVPairString(svc, &schema.KVPairStringAliases{})
@@ -126,7 +127,7 @@ func NewService(cfg ServiceConfig) (*Service, error) {
 	schema.RegisterSystem(svc, &systemImpl{})
 
 	// Register event types
-	schema.RegisterEvent(svc, &eventImpl{})
+	schema.RegisterEvent(svc, &eventImpl{historyClient: cfg.EventHistoryClient})
 	schema.RegisterEventConnection(svc, &schema.EventConnectionAliases{})
 
 	// Register event filter types
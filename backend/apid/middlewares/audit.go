@@ -0,0 +1,295 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/sensu/sensu-go/backend/audit"
+	"github.com/sensu/sensu-go/backend/authentication/jwt"
+	"github.com/sensu/sensu-go/backend/authorization"
+)
+
+// Audit is an HTTP middleware that records the requests that create, update,
+// patch or delete resources in the audit log. It must be executed after the
+// AuthorizationAttributes middleware, and before the Authorization middleware
+// so that the requests it denies are recorded too. The records hold the
+// requested change, read from the request: the resource is not read from the
+// store.
+type Audit struct {
+	// Logger receives the audit records. The middleware does nothing if it
+	// is nil.
+	Logger *audit.Logger
+
+	// Limit is the maximum size of the request bodies recorded.
+	Limit int64
+}
+
+// Then middleware
+func (a Audit) Then(next http.Handler) http.Handler {
+	if a.Logger == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verb := auditVerb(r.Method)
+		attrs := authorization.GetAttributes(r.Context())
+		if verb == "" || attrs == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		record := &audit.Record{
+			Time:      time.Now(),
+			User:      attrs.User.Username,
+			Groups:    attrs.User.Groups,
+			SourceIP:  sourceIP(r),
+			Namespace: attrs.Namespace,
+			Resource:  attrs.Resource,
+			Name:      attrs.ResourceName,
+			Verb:      verb,
+		}
+		if attrs.APIGroup != "" {
+			record.APIVersion = path.Join(attrs.APIGroup, attrs.APIVersion)
+		}
+
+		var body []byte
+		if verb != "delete" {
+			var err error
+			body, err = io.ReadAll(http.MaxBytesReader(w, r.Body, a.limit()))
+			if err != nil {
+				http.Error(w, "Request exceeded max length", http.StatusInternalServerError)
+				record.Outcome, record.Status = audit.OutcomeFailure, http.StatusInternalServerError
+				a.Logger.Log(r.Context(), record)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			if record.Name == "" {
+				// The name of the created resource is found in its definition
+				record.Name = resourceName(body)
+			}
+		}
+
+		record.Request = audit.Redact(body)
+
+		writerWithCapture := makeResponseWriterWithCapture(w)
+		next.ServeHTTP(writerWithCapture, r)
+
+		record.Status = writerWithCapture.Status()
+		record.Outcome = audit.OutcomeSuccess
+		if record.Status >= http.StatusBadRequest {
+			record.Outcome = audit.OutcomeFailure
+		}
+		a.Logger.Log(r.Context(), record)
+	})
+}
+
+func (a Audit) limit() int64 {
+	if a.Limit > 0 {
+		return a.Limit
+	}
+	return MaxBytesLimit
+}
+
+// GraphQLAudit is an HTTP middleware that records the GraphQL mutations in
+// the audit log. It must be executed after the Authentication middleware.
+type GraphQLAudit struct {
+	// Logger receives the audit records. The middleware does nothing if it
+	// is nil.
+	Logger *audit.Logger
+
+	// Limit is the maximum size of the request bodies read.
+	Limit int64
+}
+
+// Then middleware
+func (a GraphQLAudit) Then(next http.Handler) http.Handler {
+	if a.Logger == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, a.limit()))
+		if err != nil {
+			http.Error(w, "Request exceeded max length", http.StatusInternalServerError)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		mutations := graphqlMutations(body)
+		if len(mutations) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		record := audit.Record{
+			Time:     time.Now(),
+			SourceIP: sourceIP(r),
+			Resource: "graphql",
+			Verb:     "mutate",
+		}
+		if claims := jwt.GetClaimsFromContext(r.Context()); claims != nil {
+			record.User = claims.StandardClaims.Subject
+			record.Groups = claims.Groups
+		}
+
+		tee := &teeResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(tee, r)
+
+		failed := graphqlFailedOperations(tee.body.Bytes())
+		for _, mutation := range mutations {
+			record := record
+			record.Name = strings.Join(mutation.names, ",")
+			record.Request = audit.Redact(mutation.variables)
+			record.Status = tee.status
+			record.Outcome = audit.OutcomeSuccess
+			if tee.status >= http.StatusBadRequest || failed[mutation.index] {
+				record.Outcome = audit.OutcomeFailure
+			}
+			a.Logger.Log(r.Context(), &record)
+		}
+	})
+}
+
+func (a GraphQLAudit) limit() int64 {
+	if a.Limit > 0 {
+		return a.Limit
+	}
+	return MaxBytesLimit
+}
+
+type graphqlMutation struct {
+	// index of the operation in the request
+	index     int
+	names     []string
+	variables json.RawMessage
+}
+
+// graphqlMutations returns the mutations found in a GraphQL request, which is
+// either a single operation or a list of operations.
+func graphqlMutations(body []byte) []graphqlMutation {
+	type operation struct {
+		Query         string          `json:"query"`
+		OperationName string          `json:"operationName"`
+		Variables     json.RawMessage `json:"variables"`
+	}
+	var ops []operation
+	if err := json.Unmarshal(body, &ops); err != nil {
+		var op operation
+		if err := json.Unmarshal(body, &op); err != nil {
+			return nil
+		}
+		ops = []operation{op}
+	}
+
+	var mutations []graphqlMutation
+	for i, op := range ops {
+		doc, err := parser.Parse(parser.ParseParams{Source: op.Query})
+		if err != nil {
+			continue
+		}
+		for _, def := range doc.Definitions {
+			opDef, ok := def.(*ast.OperationDefinition)
+			if !ok || opDef.Operation != ast.OperationTypeMutation {
+				continue
+			}
+			if op.OperationName != "" && (opDef.Name == nil || opDef.Name.Value != op.OperationName) {
+				continue
+			}
+			mutation := graphqlMutation{index: i, variables: op.Variables}
+			if opDef.SelectionSet != nil {
+				for _, selection := range opDef.SelectionSet.Selections {
+					if field, ok := selection.(*ast.Field); ok && field.Name != nil {
+						mutation.names = append(mutation.names, field.Name.Value)
+					}
+				}
+			}
+			mutations = append(mutations, mutation)
+		}
+	}
+	return mutations
+}
+
+// graphqlFailedOperations returns the indexes of the operations of a GraphQL
+// response that have errors.
+func graphqlFailedOperations(body []byte) map[int]bool {
+	type result struct {
+		Errors []json.RawMessage `json:"errors"`
+	}
+	var results []result
+	if err := json.Unmarshal(body, &results); err != nil {
+		var res result
+		if err := json.Unmarshal(body, &res); err != nil {
+			return nil
+		}
+		results = []result{res}
+	}
+	failed := make(map[int]bool)
+	for i, res := range results {
+		if len(res.Errors) > 0 {
+			failed[i] = true
+		}
+	}
+	return failed
+}
+
+func auditVerb(method string) string {
+	switch method {
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut:
+		return "update"
+	case http.MethodPatch:
+		return "patch"
+	case http.MethodDelete:
+		return "delete"
+	}
+	return ""
+}
+
+func sourceIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// resourceName returns the name found in the metadata of a resource
+// definition.
+func resourceName(body []byte) string {
+	var resource struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}
+	_ = json.Unmarshal(body, &resource)
+	return resource.Metadata.Name
+}
+
+// teeResponseWriter is a response writer that keeps a copy of the response
+// it writes.
+type teeResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *teeResponseWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *teeResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
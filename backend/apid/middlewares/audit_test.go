@@ -0,0 +1,305 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/audit"
+	"github.com/sensu/sensu-go/backend/authorization"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSink struct {
+	records []*audit.Record
+}
+
+func (s *recordingSink) AddRecord(_ context.Context, record *audit.Record) error {
+	s.records = append(s.records, record)
+	return nil
+}
+
+func withClaims(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims := &corev2.Claims{Groups: []string{"ops"}}
+		claims.Subject = "alice"
+		ctx := context.WithValue(r.Context(), corev2.ClaimsKey, claims)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// denyingAuthorizer denies the requests on the resources named denied.
+type denyingAuthorizer struct{}
+
+func (denyingAuthorizer) Authorize(_ context.Context, attrs *authorization.Attributes) (bool, error) {
+	return attrs.ResourceName != "denied", nil
+}
+
+// auditTestRouter serves checks and users from memory. The requests on the
+// check named denied are forbidden.
+func auditTestRouter(sink audit.Sink) *mux.Router {
+	checks := map[string]string{
+		"existing": `{"metadata":{"name":"existing"},"interval":10,"publish":true}`,
+		"denied":   `{"metadata":{"name":"denied"},"interval":10}`,
+	}
+	router := mux.NewRouter().UseEncodedPath()
+	router.Use(
+		withClaims,
+		AuthorizationAttributes{}.Then,
+		Audit{Logger: audit.NewLogger(sink), Limit: 1024}.Then,
+		Authorization{Authorizer: denyingAuthorizer{}}.Then,
+	)
+	subrouter := router.PathPrefix("/api/{group:core}/{version:v2}/namespaces/{namespace}").Subrouter()
+	subrouter.HandleFunc("/{resource:checks}/{id}", func(w http.ResponseWriter, r *http.Request) {
+		check, ok := checks[mux.Vars(r)["id"]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = io.WriteString(w, check)
+	}).Methods(http.MethodGet)
+	subrouter.HandleFunc("/{resource:checks}", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		checks[resourceName(body)] = string(body)
+		w.WriteHeader(http.StatusCreated)
+	}).Methods(http.MethodPost)
+	subrouter.HandleFunc("/{resource:checks}/{id}", func(w http.ResponseWriter, r *http.Request) {
+		name := mux.Vars(r)["id"]
+		body, _ := io.ReadAll(r.Body)
+		checks[name] = string(body)
+		w.WriteHeader(http.StatusCreated)
+	}).Methods(http.MethodPut)
+	subrouter.HandleFunc("/{resource:checks}/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}).Methods(http.MethodPatch)
+	subrouter.HandleFunc("/{resource:checks}/{id}", func(w http.ResponseWriter, r *http.Request) {
+		delete(checks, mux.Vars(r)["id"])
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods(http.MethodDelete)
+	router.HandleFunc("/api/{group:core}/{version:v2}/{resource:users}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}).Methods(http.MethodPost)
+	return router
+}
+
+func TestAudit(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		body        string
+		wantName    string
+		wantVerb    string
+		wantOutcome string
+		wantStatus  int
+		wantRequest string
+	}{
+		{
+			name:        "create",
+			method:      http.MethodPost,
+			path:        "/api/core/v2/namespaces/default/checks",
+			body:        `{"metadata":{"name":"new"},"interval":30}`,
+			wantName:    "new",
+			wantVerb:    "create",
+			wantOutcome: audit.OutcomeSuccess,
+			wantStatus:  http.StatusCreated,
+			wantRequest: `{"metadata":{"name":"new"},"interval":30}`,
+		},
+		{
+			name:        "update",
+			method:      http.MethodPut,
+			path:        "/api/core/v2/namespaces/default/checks/existing",
+			body:        `{"metadata":{"name":"existing"},"interval":20}`,
+			wantName:    "existing",
+			wantVerb:    "update",
+			wantOutcome: audit.OutcomeSuccess,
+			wantStatus:  http.StatusCreated,
+			wantRequest: `{"metadata":{"name":"existing"},"interval":20}`,
+		},
+		{
+			name:        "patch",
+			method:      http.MethodPatch,
+			path:        "/api/core/v2/namespaces/default/checks/existing",
+			body:        `{"interval":20}`,
+			wantName:    "existing",
+			wantVerb:    "patch",
+			wantOutcome: audit.OutcomeSuccess,
+			wantStatus:  http.StatusOK,
+			wantRequest: `{"interval":20}`,
+		},
+		{
+			name:        "delete",
+			method:      http.MethodDelete,
+			path:        "/api/core/v2/namespaces/default/checks/existing",
+			wantName:    "existing",
+			wantVerb:    "delete",
+			wantOutcome: audit.OutcomeSuccess,
+			wantStatus:  http.StatusNoContent,
+		},
+		{
+			name:        "denied",
+			method:      http.MethodPut,
+			path:        "/api/core/v2/namespaces/default/checks/denied",
+			body:        `{"metadata":{"name":"denied"},"interval":20}`,
+			wantName:    "denied",
+			wantVerb:    "update",
+			wantOutcome: audit.OutcomeFailure,
+			wantStatus:  http.StatusForbidden,
+			wantRequest: `{"metadata":{"name":"denied"},"interval":20}`,
+		},
+		{
+			name:        "denied delete",
+			method:      http.MethodDelete,
+			path:        "/api/core/v2/namespaces/default/checks/denied",
+			wantName:    "denied",
+			wantVerb:    "delete",
+			wantOutcome: audit.OutcomeFailure,
+			wantStatus:  http.StatusForbidden,
+		},
+		{
+			name:        "too large",
+			method:      http.MethodPut,
+			path:        "/api/core/v2/namespaces/default/checks/existing",
+			body:        `{"metadata":{"name":"existing"},"command":"` + strings.Repeat("x", 1024) + `"}`,
+			wantName:    "existing",
+			wantVerb:    "update",
+			wantOutcome: audit.OutcomeFailure,
+			wantStatus:  http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &recordingSink{}
+			router := auditTestRouter(sink)
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.RemoteAddr = "10.0.0.1:41234"
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Len(t, sink.records, 1)
+			record := sink.records[0]
+			assert.Equal(t, "alice", record.User)
+			assert.Equal(t, []string{"ops"}, record.Groups)
+			assert.Equal(t, "10.0.0.1", record.SourceIP)
+			assert.Equal(t, "default", record.Namespace)
+			assert.Equal(t, "core/v2", record.APIVersion)
+			assert.Equal(t, "checks", record.Resource)
+			assert.Equal(t, tt.wantName, record.Name)
+			assert.Equal(t, tt.wantVerb, record.Verb)
+			assert.Equal(t, tt.wantOutcome, record.Outcome)
+			assert.Equal(t, tt.wantStatus, record.Status)
+			if tt.wantRequest == "" {
+				assert.Nil(t, record.Request)
+			} else {
+				assert.JSONEq(t, tt.wantRequest, string(record.Request))
+			}
+		})
+	}
+}
+
+func TestAuditIgnoresReads(t *testing.T) {
+	sink := &recordingSink{}
+	router := auditTestRouter(sink)
+	req := httptest.NewRequest(http.MethodGet, "/api/core/v2/namespaces/default/checks/existing", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, sink.records)
+}
+
+func TestGraphQLAudit(t *testing.T) {
+	sink := &recordingSink{}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ops []json.RawMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&ops))
+		_, _ = io.WriteString(w, `[{"data":{}},{"data":{}},{"data":null,"errors":[{"message":"denied"}]}]`)
+	})
+	handler := withClaims(GraphQLAudit{Logger: audit.NewLogger(sink)}.Then(next))
+
+	body := `[
+		{"query": "query { viewer { user { username } } }"},
+		{"query": "mutation Execute($id: ID!) { executeCheck(input: {id: $id}) { errors { code } } }", "variables": {"id": "c3J"}},
+		{"query": "mutation { deleteEntity(input: {id: \"ZW5\"}) { deletedId } resolveEvent(input: {id: \"ZXZ\"}) { clientMutationId } }"}
+	]`
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Len(t, sink.records, 2)
+	assert.Equal(t, "alice", sink.records[0].User)
+	assert.Equal(t, "graphql", sink.records[0].Resource)
+	assert.Equal(t, "mutate", sink.records[0].Verb)
+	assert.Equal(t, "executeCheck", sink.records[0].Name)
+	assert.JSONEq(t, `{"id":"c3J"}`, string(sink.records[0].Request))
+	assert.Equal(t, audit.OutcomeSuccess, sink.records[0].Outcome)
+	assert.Equal(t, "deleteEntity,resolveEvent", sink.records[1].Name)
+	assert.Equal(t, audit.OutcomeFailure, sink.records[1].Outcome)
+}
+
+func TestGraphQLAuditIgnoresQueries(t *testing.T) {
+	sink := &recordingSink{}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	})
+	handler := GraphQLAudit{Logger: audit.NewLogger(sink)}.Then(next)
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "{ viewer { user { username } } }"}`))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Empty(t, sink.records)
+}
+
+func TestAuditRedactsSecrets(t *testing.T) {
+	sink := &recordingSink{}
+	router := auditTestRouter(sink)
+	body := `{"username":"bob","password":"P@ssw0rd!"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/core/v2/users", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	require.Len(t, sink.records, 1)
+	assert.Equal(t, "users", sink.records[0].Resource)
+	assert.JSONEq(t, `{"username":"bob","password":"REDACTED"}`, string(sink.records[0].Request))
+}
+
+func TestGraphQLAuditRedactsSecrets(t *testing.T) {
+	sink := &recordingSink{}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"data":{}}`)
+	})
+	handler := GraphQLAudit{Logger: audit.NewLogger(sink)}.Then(next)
+	body, err := json.Marshal(map[string]interface{}{
+		"query": "mutation Put($raw: String!) { putWrapped(raw: $raw) { errors { code } } }",
+		"variables": map[string]string{
+			"raw": `{"type":"User","api_version":"core/v2","spec":{"username":"bob","password":"P@ssw0rd!"}}`,
+		},
+	})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Len(t, sink.records, 1)
+	assert.NotContains(t, string(sink.records[0].Request), "P@ssw0rd!")
+	assert.Contains(t, string(sink.records[0].Request), "bob")
+}
+
+func TestGraphQLAuditLimit(t *testing.T) {
+	sink := &recordingSink{}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request should not be served")
+	})
+	handler := GraphQLAudit{Logger: audit.NewLogger(sink), Limit: 16}.Then(next)
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "{ viewer { user { username } } }"}`))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, sink.records)
+}
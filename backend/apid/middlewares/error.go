@@ -32,6 +32,8 @@ func writeErr(w http.ResponseWriter, err error) {
 		st = http.StatusForbidden
 	case actions.Unauthenticated:
 		st = http.StatusUnauthorized
+	case actions.ResourceExhausted:
+		st = http.StatusTooManyRequests
 	}
 
 	errJSON, err := json.Marshal(errRes)
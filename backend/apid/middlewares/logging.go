@@ -69,6 +69,7 @@ type loggingResponseWriter interface {
 	http.Flusher
 	Status() int
 	Size() int
+	Unwrap() http.ResponseWriter
 }
 
 // responseLogger is wrapper of http.ResponseWriter that keeps track of its HTTP
@@ -102,6 +103,11 @@ func (l *responseLogger) Size() int {
 	return l.size
 }
 
+// Unwrap returns the underlying response writer, for http.ResponseController.
+func (l *responseLogger) Unwrap() http.ResponseWriter {
+	return l.w
+}
+
 func (l *responseLogger) Flush() {
 	f, ok := l.w.(http.Flusher)
 	if ok {
@@ -0,0 +1,55 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/backend/authorization"
+	"github.com/sensu/sensu-go/backend/quota"
+	quotav1 "github.com/sensu/sensu-go/backend/quota/v1"
+)
+
+// QuotaEnforcer enforces the namespace quotas on the creation of resources.
+type QuotaEnforcer interface {
+	AllowCreate(ctx context.Context, namespace, resource, name string) error
+}
+
+// Quota is an HTTP middleware that enforces the namespace quotas on the
+// requests that may create resources. It relies on the authorization
+// attributes of the requests. The quota store doesn't enforce the quotas
+// again for the requests it allowed.
+type Quota struct {
+	Enforcer QuotaEnforcer
+}
+
+// Then middleware
+func (q Quota) Then(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if q.Enforcer == nil || (r.Method != http.MethodPost && r.Method != http.MethodPut) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		attrs := authorization.GetAttributes(r.Context())
+		if attrs == nil || attrs.Namespace == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		err := q.Enforcer.AllowCreate(r.Context(), attrs.Namespace, attrs.Resource, attrs.ResourceName)
+		if err == nil {
+			next.ServeHTTP(w, r.WithContext(quota.EnforcedContext(r.Context())))
+			return
+		}
+		if exceeded, ok := err.(*quotav1.ErrQuotaExceeded); ok {
+			code := actions.PermissionDenied
+			if exceeded.IsRateLimit() {
+				code = actions.ResourceExhausted
+			}
+			writeErr(w, actions.NewError(code, err))
+			return
+		}
+		logger.WithError(err).Error("could not enforce namespace quotas")
+		writeErr(w, actions.NewError(actions.InternalErr, err))
+	})
+}
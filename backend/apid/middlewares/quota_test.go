@@ -0,0 +1,104 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	quotav1 "github.com/sensu/sensu-go/backend/quota/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockQuotaEnforcer struct {
+	err       error
+	namespace string
+	resource  string
+	name      string
+}
+
+func (m *mockQuotaEnforcer) AllowCreate(_ context.Context, namespace, resource, name string) error {
+	m.namespace, m.resource, m.name = namespace, resource, name
+	return m.err
+}
+
+func TestQuota(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		err        error
+		wantStatus int
+		wantBody   string
+		wantName   string
+	}{
+		{
+			name:       "allowed",
+			method:     http.MethodPut,
+			path:       "/api/core/v2/namespaces/default/checks/check1",
+			wantStatus: http.StatusOK,
+			wantName:   "check1",
+		},
+		{
+			name:       "quota exceeded",
+			method:     http.MethodPost,
+			path:       "/api/core/v2/namespaces/default/checks",
+			err:        &quotav1.ErrQuotaExceeded{Quota: "team-a", Resource: quotav1.ResourceChecks, Limit: 10},
+			wantStatus: http.StatusForbidden,
+			wantBody:   `quota \"team-a\" exceeded`,
+		},
+		{
+			name:       "rate exceeded",
+			method:     http.MethodPost,
+			path:       "/api/core/v2/namespaces/default/checks",
+			err:        &quotav1.ErrQuotaExceeded{Quota: "team-a", Resource: quotav1.ResourceEventRate, Limit: 10},
+			wantStatus: http.StatusTooManyRequests,
+			wantBody:   `quota \"team-a\" exceeded`,
+		},
+		{
+			name:       "store error",
+			method:     http.MethodPost,
+			path:       "/api/core/v2/namespaces/default/checks",
+			err:        errors.New("store error"),
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "reads are not enforced",
+			method:     http.MethodGet,
+			path:       "/api/core/v2/namespaces/default/checks",
+			err:        errors.New("not enforced"),
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enforcer := &mockQuotaEnforcer{err: tt.err}
+			router := mux.NewRouter()
+			subrouter := router.PathPrefix("/api/{group:core}/{version:v2}/namespaces/{namespace}").Subrouter()
+			subrouter.Use(
+				withClaims,
+				AuthorizationAttributes{}.Then,
+				Quota{Enforcer: enforcer}.Then,
+			)
+			subrouter.HandleFunc("/{resource:checks}", testHandler())
+			subrouter.HandleFunc("/{resource:checks}/{id}", testHandler())
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.True(t, strings.Contains(w.Body.String(), tt.wantBody), w.Body.String())
+			}
+			if tt.method != http.MethodGet {
+				assert.Equal(t, "default", enforcer.namespace)
+				assert.Equal(t, "checks", enforcer.resource)
+				assert.Equal(t, tt.wantName, enforcer.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package routers
+
+import (
+	"github.com/gorilla/mux"
+	aggregatev1 "github.com/sensu/sensu-go/backend/aggregate/v1"
+	"github.com/sensu/sensu-go/backend/apid/handlers"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+// AggregatesRouter handles requests for /aggregates
+type AggregatesRouter struct {
+	store storev2.Interface
+}
+
+// NewAggregatesRouter instantiates a new router for aggregates.
+func NewAggregatesRouter(store storev2.Interface) *AggregatesRouter {
+	return &AggregatesRouter{
+		store: store,
+	}
+}
+
+// Mount the AggregatesRouter to a parent Router
+func (r *AggregatesRouter) Mount(parent *mux.Router) {
+	routes := ResourceRoute{
+		Router:     parent,
+		PathPrefix: "/namespaces/{namespace}/{resource:aggregates}",
+	}
+	handlers := handlers.NewHandlers[*aggregatev1.Aggregate](r.store)
+	routes.Del(handlers.DeleteResource)
+	routes.Get(handlers.GetResource)
+	routes.List(handlers.ListResources, aggregatev1.AggregateFields)
+	routes.ListAllNamespaces(handlers.ListResources, "/{resource:aggregates}", aggregatev1.AggregateFields)
+	routes.Patch(handlers.PatchResource)
+	routes.Post(handlers.CreateResource)
+	routes.Put(handlers.CreateOrUpdateResource)
+}
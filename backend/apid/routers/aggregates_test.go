@@ -0,0 +1,39 @@
+package routers
+
+import (
+	"testing"
+
+	"github.com/gorilla/mux"
+	corev2 "github.com/sensu/core/v2"
+	aggregatev1 "github.com/sensu/sensu-go/backend/aggregate/v1"
+	"github.com/sensu/sensu-go/testing/mockstore"
+)
+
+func TestAggregatesRouter(t *testing.T) {
+	// Setup the router
+	s := &mockstore.V2MockStore{}
+	cs := new(mockstore.ConfigStore)
+	s.On("GetConfigStore").Return(cs)
+	router := NewAggregatesRouter(s)
+	parentRouter := mux.NewRouter().PathPrefix(aggregatev1.URLPrefix).Subrouter()
+	router.Mount(parentRouter)
+
+	empty := &aggregatev1.Aggregate{Metadata: corev2.ObjectMeta{Namespace: "default"}}
+	fixture := &aggregatev1.Aggregate{
+		Metadata: corev2.ObjectMeta{Name: "web-http", Namespace: "default"},
+		Interval: 60,
+		Thresholds: []*aggregatev1.AggregateThreshold{
+			{Status: 2, State: aggregatev1.StateCritical, Percentage: 30},
+		},
+	}
+
+	tests := []routerTestCase{}
+	tests = append(tests, getTestCases[*aggregatev1.Aggregate](fixture)...)
+	tests = append(tests, listTestCases[*aggregatev1.Aggregate](empty)...)
+	tests = append(tests, createTestCases(fixture)...)
+	tests = append(tests, updateTestCases(fixture)...)
+	tests = append(tests, deleteTestCases(fixture)...)
+	for _, tt := range tests {
+		run(t, tt, parentRouter, s)
+	}
+}
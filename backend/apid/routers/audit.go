@@ -0,0 +1,74 @@
+package routers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/backend/audit"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+// AuditRouter handles requests for /audit
+type AuditRouter struct {
+	store audit.Store
+}
+
+// NewAuditRouter instantiates a new router for the audit log.
+func NewAuditRouter(store audit.Store) *AuditRouter {
+	return &AuditRouter{store: store}
+}
+
+// Mount the AuditRouter to a parent Router
+func (r *AuditRouter) Mount(parent *mux.Router) {
+	parent.HandleFunc("/{resource:audit}", r.list).Methods(http.MethodGet)
+	parent.HandleFunc("/namespaces/{namespace}/{resource:audit}", r.list).Methods(http.MethodGet)
+}
+
+// list lists the audit records, most recent first. The records of every
+// namespace are listed, unless the request is made within a namespace. The
+// user, resource and verb query parameters filter the records, and the start
+// and end query parameters bound their time, as RFC 3339 dates or unix
+// timestamps.
+func (r *AuditRouter) list(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	pred := &audit.Predicate{
+		Namespace: mux.Vars(req)["namespace"],
+		User:      query.Get("user"),
+		Resource:  query.Get("resource"),
+		Verb:      query.Get("verb"),
+		Continue:  corev2.PageContinueFromContext(req.Context()),
+		Limit:     int64(corev2.PageSizeFromContext(req.Context())),
+	}
+	var err error
+	if pred.Start, err = parseHistoryTime(query.Get("start")); err != nil {
+		WriteError(w, actions.NewErrorf(actions.InvalidArgument, "invalid start: %s", err))
+		return
+	}
+	if pred.End, err = parseHistoryTime(query.Get("end")); err != nil {
+		WriteError(w, actions.NewErrorf(actions.InvalidArgument, "invalid end: %s", err))
+		return
+	}
+
+	records, err := r.store.GetRecords(req.Context(), pred)
+	if err != nil {
+		if _, ok := err.(*store.ErrNotValid); ok {
+			WriteError(w, actions.NewError(actions.InvalidArgument, err))
+			return
+		}
+		WriteError(w, actions.NewError(actions.InternalErr, err))
+		return
+	}
+
+	if pred.Continue != "" {
+		encodedContinue := base64.RawURLEncoding.EncodeToString([]byte(pred.Continue))
+		w.Header().Set(corev2.PaginationContinueHeader, encodedContinue)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(records); err != nil {
+		logger.WithError(err).Error("couldn't write audit records")
+	}
+}
@@ -0,0 +1,102 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAuditStore struct {
+	mock.Mock
+}
+
+func (m *mockAuditStore) AddRecord(ctx context.Context, record *audit.Record) error {
+	return m.Called(ctx, record).Error(0)
+}
+
+func (m *mockAuditStore) GetRecords(ctx context.Context, pred *audit.Predicate) ([]*audit.Record, error) {
+	args := m.Called(ctx, pred)
+	return args.Get(0).([]*audit.Record), args.Error(1)
+}
+
+func TestAuditRouter(t *testing.T) {
+	records := []*audit.Record{{User: "alice", Resource: "checks", Name: "a", Verb: "create", Outcome: audit.OutcomeSuccess}}
+
+	tests := []struct {
+		name           string
+		path           string
+		storeFunc      func(*mockAuditStore)
+		wantStatusCode int
+		wantRecords    int
+	}{
+		{
+			name: "it lists the records of every namespace",
+			path: "/api/core/v3/audit?user=alice&verb=create",
+			storeFunc: func(s *mockAuditStore) {
+				s.On("GetRecords", mock.Anything, mock.MatchedBy(func(pred *audit.Predicate) bool {
+					return pred.Namespace == "" && pred.User == "alice" && pred.Verb == "create"
+				})).Return(records, nil)
+			},
+			wantStatusCode: http.StatusOK,
+			wantRecords:    1,
+		},
+		{
+			name: "it lists the records of a namespace within a time range",
+			path: "/api/core/v3/namespaces/default/audit?resource=checks&start=2023-01-01T00:00:00Z&end=1700000000",
+			storeFunc: func(s *mockAuditStore) {
+				s.On("GetRecords", mock.Anything, mock.MatchedBy(func(pred *audit.Predicate) bool {
+					return pred.Namespace == "default" && pred.Resource == "checks" &&
+						pred.Start.Equal(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)) &&
+						pred.End.Equal(time.Unix(1700000000, 0))
+				})).Return(records, nil)
+			},
+			wantStatusCode: http.StatusOK,
+			wantRecords:    1,
+		},
+		{
+			name:           "it returns 400 if the time range is invalid",
+			path:           "/api/core/v3/audit?end=tomorrow",
+			storeFunc:      func(s *mockAuditStore) {},
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "it returns 500 if the store returns an error",
+			path: "/api/core/v3/audit",
+			storeFunc: func(s *mockAuditStore) {
+				s.On("GetRecords", mock.Anything, mock.Anything).Return([]*audit.Record(nil), errors.New("error"))
+			},
+			wantStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &mockAuditStore{}
+			tt.storeFunc(s)
+			parentRouter := mux.NewRouter().PathPrefix("/api/{group:core}/{version:v3}").Subrouter()
+			NewAuditRouter(s).Mount(parentRouter)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+			parentRouter.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatusCode, w.Code)
+			if tt.wantStatusCode != http.StatusOK {
+				return
+			}
+			var got []*audit.Record
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+			assert.Len(t, got, tt.wantRecords)
+			s.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package routers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/backend/apid/handlers"
+	authenticationv2 "github.com/sensu/sensu-go/backend/authentication/v2"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+// AuthProvidersRouter handles requests for /authproviders
+type AuthProvidersRouter struct {
+	store storev2.Interface
+}
+
+// NewAuthProvidersRouter instantiates a new router for authentication
+// providers.
+func NewAuthProvidersRouter(store storev2.Interface) *AuthProvidersRouter {
+	return &AuthProvidersRouter{
+		store: store,
+	}
+}
+
+// Mount the AuthProvidersRouter to a parent Router
+func (r *AuthProvidersRouter) Mount(parent *mux.Router) {
+	oidcRoutes := ResourceRoute{
+		Router:     parent,
+		PathPrefix: "/{resource:authproviders}/" + authenticationv2.OIDCProviderKind,
+	}
+	oidc := handlers.NewHandlers[*authenticationv2.OIDC](r.store)
+	oidcRoutes.Del(oidc.DeleteResource)
+	oidcRoutes.Get(redactGet(oidc.GetResource))
+	oidcRoutes.List(redactList(oidc.ListResources), authenticationv2.ProviderFields)
+	oidcRoutes.Patch(oidc.PatchResource)
+	oidcRoutes.Post(oidc.CreateResource)
+	oidcRoutes.Put(oidc.CreateOrUpdateResource)
+
+	ldapRoutes := ResourceRoute{
+		Router:     parent,
+		PathPrefix: "/{resource:authproviders}/" + authenticationv2.LDAPProviderKind,
+	}
+	ldap := handlers.NewHandlers[*authenticationv2.LDAP](r.store)
+	ldapRoutes.Del(ldap.DeleteResource)
+	ldapRoutes.Get(redactGet(ldap.GetResource))
+	ldapRoutes.List(redactList(ldap.ListResources), authenticationv2.ProviderFields)
+	ldapRoutes.Patch(ldap.PatchResource)
+	ldapRoutes.Post(ldap.CreateResource)
+	ldapRoutes.Put(ldap.CreateOrUpdateResource)
+}
+
+// redactGet removes the secrets of the provider returned by get.
+func redactGet(get actionHandlerFunc) actionHandlerFunc {
+	return func(r *http.Request) (handlers.HandlerResponse, error) {
+		response, err := get(r)
+		if redacter, ok := response.Resource.(authenticationv2.Redacter); ok {
+			redacter.Redact()
+		}
+		return response, err
+	}
+}
+
+// redactList removes the secrets of the providers returned by list.
+func redactList(list ListControllerFunc) ListControllerFunc {
+	return func(ctx context.Context, pred *store.SelectionPredicate) ([]corev3.Resource, error) {
+		resources, err := list(ctx, pred)
+		for _, resource := range resources {
+			if redacter, ok := resource.(authenticationv2.Redacter); ok {
+				redacter.Redact()
+			}
+		}
+		return resources, err
+	}
+}
@@ -0,0 +1,107 @@
+package routers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	authenticationv2 "github.com/sensu/sensu-go/backend/authentication/v2"
+	"github.com/sensu/sensu-go/testing/mockstore"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuthProvidersRouter(t *testing.T) {
+	// Setup the router
+	s := &mockstore.V2MockStore{}
+	cs := new(mockstore.ConfigStore)
+	s.On("GetConfigStore").Return(cs)
+	router := NewAuthProvidersRouter(s)
+	parentRouter := mux.NewRouter().PathPrefix(authenticationv2.URLPrefix).Subrouter()
+	router.Mount(parentRouter)
+
+	oidc := &authenticationv2.OIDC{
+		Metadata:     corev2.ObjectMeta{Name: "idp"},
+		Server:       "https://idp.example.com",
+		ClientID:     "sensu",
+		ClientSecret: "hunter2",
+	}
+	ldap := &authenticationv2.LDAP{
+		Metadata:    corev2.ObjectMeta{Name: "directory"},
+		Host:        "ldap.example.com",
+		UserSearch:  authenticationv2.LDAPUserSearch{BaseDN: "ou=people,dc=example,dc=com"},
+		GroupSearch: authenticationv2.LDAPGroupSearch{BaseDN: "ou=groups,dc=example,dc=com"},
+	}
+
+	tests := []routerTestCase{}
+	tests = append(tests, getTestCases[*authenticationv2.OIDC](oidc)...)
+	tests = append(tests, listTestCases[*authenticationv2.OIDC](&authenticationv2.OIDC{})...)
+	tests = append(tests, createTestCases(oidc)...)
+	tests = append(tests, updateTestCases(oidc)...)
+	tests = append(tests, deleteTestCases(oidc)...)
+	tests = append(tests, getTestCases[*authenticationv2.LDAP](ldap)...)
+	tests = append(tests, listTestCases[*authenticationv2.LDAP](&authenticationv2.LDAP{})...)
+	tests = append(tests, createTestCases(ldap)...)
+	tests = append(tests, updateTestCases(ldap)...)
+	tests = append(tests, deleteTestCases(ldap)...)
+	for _, tt := range tests {
+		run(t, tt, parentRouter, s)
+	}
+}
+
+func TestAuthProvidersRouterRedactsSecrets(t *testing.T) {
+	oidc := &authenticationv2.OIDC{
+		Metadata:     corev2.ObjectMeta{Name: "idp"},
+		Server:       "https://idp.example.com",
+		ClientID:     "sensu",
+		ClientSecret: "hunter2",
+	}
+	ldap := &authenticationv2.LDAP{
+		Metadata:     corev2.ObjectMeta{Name: "directory"},
+		Host:         "ldap.example.com",
+		BindDN:       "cn=sensu,dc=example,dc=com",
+		BindPassword: "hunter2",
+	}
+	tests := []struct {
+		provider corev3.Resource
+		get      interface{}
+		list     interface{}
+	}{
+		{
+			provider: oidc,
+			get:      mockstore.Wrapper[*authenticationv2.OIDC]{Value: oidc},
+			list:     mockstore.WrapList[*authenticationv2.OIDC]{oidc},
+		},
+		{
+			provider: ldap,
+			get:      mockstore.Wrapper[*authenticationv2.LDAP]{Value: ldap},
+			list:     mockstore.WrapList[*authenticationv2.LDAP]{ldap},
+		},
+	}
+	for _, tt := range tests {
+		s := &mockstore.V2MockStore{}
+		cs := new(mockstore.ConfigStore)
+		s.On("GetConfigStore").Return(cs)
+		cs.On("Get", mock.Anything, mock.Anything).Return(tt.get, nil)
+		cs.On("List", mock.Anything, mock.Anything, mock.Anything).Return(tt.list, nil)
+		router := NewAuthProvidersRouter(s)
+		parentRouter := mux.NewRouter().PathPrefix(authenticationv2.URLPrefix).Subrouter()
+		router.Mount(parentRouter)
+
+		for _, p := range []string{tt.provider.URIPath(), path.Dir(tt.provider.URIPath())} {
+			req := httptest.NewRequest(http.MethodGet, p, nil)
+			w := httptest.NewRecorder()
+			parentRouter.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("%s: bad status: %d (%s)", p, w.Code, w.Body.String())
+			}
+			if strings.Contains(w.Body.String(), "hunter2") {
+				t.Errorf("%s: the provider secret is returned: %s", p, w.Body.String())
+			}
+		}
+	}
+}
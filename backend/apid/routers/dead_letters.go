@@ -0,0 +1,83 @@
+package routers
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/backend/apid/handlers"
+	"github.com/sensu/sensu-go/backend/pipeline/handler"
+	pipelinev1 "github.com/sensu/sensu-go/backend/pipeline/v1"
+	"github.com/sensu/sensu-go/backend/queue"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+// DeadLettersRouter handles requests for /dead-letters
+type DeadLettersRouter struct {
+	store storev2.Interface
+	queue queue.Client
+}
+
+// NewDeadLettersRouter instantiates a new router for the dead letters of the
+// handlers. Replayed dead letters are queued on the handler retry queue.
+func NewDeadLettersRouter(store storev2.Interface, queue queue.Client) *DeadLettersRouter {
+	return &DeadLettersRouter{
+		store: store,
+		queue: queue,
+	}
+}
+
+// Mount the DeadLettersRouter to a parent Router
+func (r *DeadLettersRouter) Mount(parent *mux.Router) {
+	routes := ResourceRoute{
+		Router:     parent,
+		PathPrefix: "/namespaces/{namespace}/{resource:dead-letters}",
+	}
+	handlers := handlers.NewHandlers[*pipelinev1.DeadLetter](r.store)
+	routes.Del(handlers.DeleteResource)
+	routes.Get(handlers.GetResource)
+	routes.List(handlers.ListResources, pipelinev1.DeadLetterFields)
+	routes.ListAllNamespaces(handlers.ListResources, "/{resource:dead-letters}", pipelinev1.DeadLetterFields)
+
+	// handlefunc returns a custom status and response
+	parent.HandleFunc(path.Join(routes.PathPrefix, "{id}/replay"), r.replay).Methods(http.MethodPost)
+}
+
+// replay queues the dead letter for a retry of its handler, and deletes it.
+func (r *DeadLettersRouter) replay(w http.ResponseWriter, req *http.Request) {
+	if r.queue == nil {
+		WriteError(w, actions.NewErrorf(actions.InternalErr, "handler retries are not enabled"))
+		return
+	}
+	params := mux.Vars(req)
+	name, err := url.PathUnescape(params["id"])
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	ctx := req.Context()
+	id := storev2.ID{Namespace: store.NewNamespaceFromContext(ctx), Name: name}
+	gstore := storev2.Of[*pipelinev1.DeadLetter](r.store)
+
+	letter, err := gstore.Get(ctx, id)
+	if err != nil {
+		if _, ok := err.(*store.ErrNotFound); ok {
+			WriteError(w, actions.NewErrorf(actions.NotFound))
+			return
+		}
+		WriteError(w, actions.NewError(actions.InternalErr, err))
+		return
+	}
+	if err := handler.Replay(ctx, r.queue, letter); err != nil {
+		WriteError(w, actions.NewError(actions.InternalErr, err))
+		return
+	}
+	if err := gstore.Delete(ctx, id); err != nil {
+		WriteError(w, actions.NewError(actions.InternalErr, err))
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,73 @@
+package routers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/pipeline/handler"
+	pipelinev1 "github.com/sensu/sensu-go/backend/pipeline/v1"
+	"github.com/sensu/sensu-go/backend/queue"
+	"github.com/sensu/sensu-go/backend/store"
+	"github.com/sensu/sensu-go/testing/mockstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeadLettersRouter(t *testing.T) {
+	// Setup the router
+	s := &mockstore.V2MockStore{}
+	cs := new(mockstore.ConfigStore)
+	s.On("GetConfigStore").Return(cs)
+	q := queue.NewMemoryClient()
+	router := NewDeadLettersRouter(s, q)
+	parentRouter := mux.NewRouter().PathPrefix(pipelinev1.URLPrefix).Subrouter()
+	router.Mount(parentRouter)
+
+	empty := &pipelinev1.DeadLetter{Metadata: corev2.ObjectMeta{Namespace: "default"}}
+	fixture := &pipelinev1.DeadLetter{
+		Metadata: corev2.ObjectMeta{Name: "6b4e7b3e", Namespace: "default"},
+		Handler:  "slack",
+		Event:    corev2.FixtureEvent("entity1", "check1"),
+	}
+
+	tests := []routerTestCase{}
+	tests = append(tests, getTestCases[*pipelinev1.DeadLetter](fixture)...)
+	tests = append(tests, listTestCases[*pipelinev1.DeadLetter](empty)...)
+	tests = append(tests, deleteTestCases(fixture)...)
+	tests = append(tests, []routerTestCase{
+		{
+			name:   "it returns 404 if the dead letter to replay does not exist",
+			method: http.MethodPost,
+			path:   fixture.URIPath() + "/replay",
+			storeFunc: func(s *mockstore.V2MockStore) {
+				cs.On("Get", mock.Anything, mock.Anything).Return(nil, &store.ErrNotFound{}).Once()
+			},
+			wantStatusCode: http.StatusNotFound,
+		},
+		{
+			name:   "it replays and deletes the dead letter",
+			method: http.MethodPost,
+			path:   fixture.URIPath() + "/replay",
+			storeFunc: func(s *mockstore.V2MockStore) {
+				cs.On("Get", mock.Anything, mock.Anything).Return(mockstore.Wrapper[*pipelinev1.DeadLetter]{Value: fixture}, nil).Once()
+				cs.On("Delete", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			wantStatusCode: http.StatusAccepted,
+		},
+	}...)
+	for _, tt := range tests {
+		run(t, tt, parentRouter, s)
+	}
+
+	// The replayed dead letter is on the retry queue
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := q.Reserve(ctx, handler.RetryQueueName)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(res.Item().Value), `"handler":"slack"`)
+	}
+}
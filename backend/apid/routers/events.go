@@ -2,9 +2,13 @@ package routers
 
 import (
 	"context"
+	"encoding/base64"
+	"fmt"
 	"net/http"
 	"net/url"
 	"path"
+	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	corev2 "github.com/sensu/core/v2"
@@ -27,6 +31,7 @@ type eventController interface {
 	CreateOrReplace(ctx context.Context, check *corev2.Event) error
 	Delete(ctx context.Context, entity, check string) error
 	Get(ctx context.Context, entity, check string) (*corev2.Event, error)
+	History(ctx context.Context, entity, check string, pred *store.EventHistoryPredicate) ([]*corev2.Event, error)
 	List(ctx context.Context, pred *store.SelectionPredicate) ([]corev3.Resource, error)
 }
 
@@ -50,6 +55,7 @@ func (r *EventsRouter) Mount(parent *mux.Router) {
 	routes.Path("{entity}/{check}", r.get).Methods(http.MethodGet)
 	routes.Path("{entity}/{check}", r.delete).Methods(http.MethodDelete)
 	routes.Path("{entity}/{check}", r.createOrReplace).Methods(http.MethodPost, http.MethodPut)
+	parent.HandleFunc(path.Join(routes.PathPrefix, "{entity}/{check}/history"), r.history).Methods(http.MethodGet)
 
 	// Additionaly allow a subcollection to be specified when listing events,
 	// which correspond to the entity name here
@@ -66,6 +72,61 @@ func (r *EventsRouter) get(req *http.Request) (handlers.HandlerResponse, error)
 	return response, err
 }
 
+// history lists the recorded events of an entity and check, most recent
+// first. The start and end query parameters bound the events timestamps, and
+// accept RFC 3339 dates or unix timestamps.
+func (r *EventsRouter) history(w http.ResponseWriter, req *http.Request) {
+	params := actions.QueryParams(mux.Vars(req))
+	entity := url.PathEscape(params["entity"])
+	check := url.PathEscape(params["check"])
+
+	pred := &store.EventHistoryPredicate{
+		Continue: corev2.PageContinueFromContext(req.Context()),
+		Limit:    int64(corev2.PageSizeFromContext(req.Context())),
+	}
+	query := req.URL.Query()
+	var err error
+	if pred.Start, err = parseHistoryTime(query.Get("start")); err != nil {
+		WriteError(w, actions.NewErrorf(actions.InvalidArgument, "invalid start: %s", err))
+		return
+	}
+	if pred.End, err = parseHistoryTime(query.Get("end")); err != nil {
+		WriteError(w, actions.NewErrorf(actions.InvalidArgument, "invalid end: %s", err))
+		return
+	}
+
+	events, err := r.controller.History(req.Context(), entity, check, pred)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	if pred.Continue != "" {
+		encodedContinue := base64.RawURLEncoding.EncodeToString([]byte(pred.Continue))
+		w.Header().Set(corev2.PaginationContinueHeader, encodedContinue)
+	}
+
+	resources := make([]corev3.Resource, len(events))
+	for i, event := range events {
+		resources[i] = event
+	}
+	RespondWith(w, req, handlers.HandlerResponse{ResourceList: resources})
+}
+
+func parseHistoryTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(unix, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a RFC 3339 date nor a unix timestamp", value)
+	}
+	return t, nil
+}
+
 func (r *EventsRouter) delete(req *http.Request) (handlers.HandlerResponse, error) {
 	params := actions.QueryParams(mux.Vars(req))
 	entity := url.PathEscape(params["entity"])
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	corev2 "github.com/sensu/core/v2"
@@ -33,6 +34,11 @@ func (m *mockEventController) Get(ctx context.Context, entity, check string) (*c
 	return args.Get(0).(*corev2.Event), args.Error(1)
 }
 
+func (m *mockEventController) History(ctx context.Context, entity, check string, pred *store.EventHistoryPredicate) ([]*corev2.Event, error) {
+	args := m.Called(ctx, entity, check, pred)
+	return args.Get(0).([]*corev2.Event), args.Error(1)
+}
+
 func (m *mockEventController) List(ctx context.Context, pred *store.SelectionPredicate) ([]corev3.Resource, error) {
 	args := m.Called(ctx, pred)
 	return args.Get(0).([]corev3.Resource), args.Error(1)
@@ -111,6 +117,36 @@ func TestEventsRouter(t *testing.T) {
 			},
 			wantStatusCode: http.StatusOK,
 		},
+		{
+			name:   "it returns 200 and lists the event history",
+			method: http.MethodGet,
+			path:   fixture.URIPath() + "/history?start=2023-01-01T00:00:00Z&end=1700000000",
+			controllerFunc: func(c *mockEventController) {
+				c.On("History", mock.Anything, "foo", "check-cpu", mock.MatchedBy(func(pred *store.EventHistoryPredicate) bool {
+					return pred.Start.Equal(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)) && pred.End.Equal(time.Unix(1700000000, 0))
+				})).
+					Return([]*corev2.Event{fixture, fixture}, nil).
+					Once()
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "it returns 400 if the event history range is invalid",
+			method:         http.MethodGet,
+			path:           fixture.URIPath() + "/history?start=yesterday",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:   "it returns 500 if the store returns an error while listing the event history",
+			method: http.MethodGet,
+			path:   fixture.URIPath() + "/history",
+			controllerFunc: func(c *mockEventController) {
+				c.On("History", mock.Anything, "foo", "check-cpu", mock.Anything).
+					Return([]*corev2.Event(nil), actions.NewErrorf(actions.InternalErr)).
+					Once()
+			},
+			wantStatusCode: http.StatusInternalServerError,
+		},
 		{
 			name:           "it returns 400 if the payload to create is not decodable",
 			method:         http.MethodPost,
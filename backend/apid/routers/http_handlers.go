@@ -0,0 +1,36 @@
+package routers
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/handlers"
+	pipelinev1 "github.com/sensu/sensu-go/backend/pipeline/v1"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+// HTTPHandlersRouter handles requests for /http-handlers
+type HTTPHandlersRouter struct {
+	store storev2.Interface
+}
+
+// NewHTTPHandlersRouter instantiates a new router for HTTP handlers.
+func NewHTTPHandlersRouter(store storev2.Interface) *HTTPHandlersRouter {
+	return &HTTPHandlersRouter{
+		store: store,
+	}
+}
+
+// Mount the HTTPHandlersRouter to a parent Router
+func (r *HTTPHandlersRouter) Mount(parent *mux.Router) {
+	routes := ResourceRoute{
+		Router:     parent,
+		PathPrefix: "/namespaces/{namespace}/{resource:http-handlers}",
+	}
+	handlers := handlers.NewHandlers[*pipelinev1.HTTPHandler](r.store)
+	routes.Del(handlers.DeleteResource)
+	routes.Get(handlers.GetResource)
+	routes.List(handlers.ListResources, pipelinev1.HTTPHandlerFields)
+	routes.ListAllNamespaces(handlers.ListResources, "/{resource:http-handlers}", pipelinev1.HTTPHandlerFields)
+	routes.Patch(handlers.PatchResource)
+	routes.Post(handlers.CreateResource)
+	routes.Put(handlers.CreateOrUpdateResource)
+}
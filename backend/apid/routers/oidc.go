@@ -0,0 +1,91 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/authentication/jwt"
+	authenticationv2 "github.com/sensu/sensu-go/backend/authentication/v2"
+)
+
+// OIDCClient starts and completes the authorization code flow of the OIDC
+// authentication providers.
+type OIDCClient interface {
+	OIDCAuthCodeURL(context.Context, *authenticationv2.OIDCAuthorizeRequest) (string, error)
+	CreateOIDCAccessToken(context.Context, *authenticationv2.OIDCTokenRequest) (*corev2.Tokens, error)
+}
+
+// OIDCRouter handles the OIDC login requests.
+type OIDCRouter struct {
+	client OIDCClient
+}
+
+// NewOIDCRouter instantiates new router.
+func NewOIDCRouter(client OIDCClient) *OIDCRouter {
+	return &OIDCRouter{client: client}
+}
+
+// Mount the OIDC routes on given mux.Router.
+func (a *OIDCRouter) Mount(r *mux.Router) {
+	r.HandleFunc("/auth/oidc/authorize", a.authorize).Methods(http.MethodGet)
+	r.HandleFunc("/auth/oidc/token", a.token).Methods(http.MethodPost)
+}
+
+// authorize redirects the user to the identity provider
+func (a *OIDCRouter) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := &authenticationv2.OIDCAuthorizeRequest{
+		Provider:      query.Get("provider"),
+		RedirectURI:   query.Get("redirect_uri"),
+		State:         query.Get("state"),
+		Nonce:         query.Get("nonce"),
+		CodeChallenge: query.Get("code_challenge"),
+	}
+	authURL, err := a.client.OIDCAuthCodeURL(r.Context(), req)
+	if err != nil {
+		writeOIDCError(w, err)
+		return
+	}
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// token exchanges an authorization code for access and refresh tokens
+func (a *OIDCRouter) token(w http.ResponseWriter, r *http.Request) {
+	req := &authenticationv2.OIDCTokenRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		http.Error(w, "could not decode the token request", http.StatusBadRequest)
+		return
+	}
+
+	// Determine the URL that serves this request so it can be later used as the
+	// issuer URL
+	ctx := context.WithValue(r.Context(), jwt.IssuerURLKey, issuerURL(r))
+
+	tokens, err := a.client.CreateOIDCAccessToken(ctx, req)
+	if err != nil {
+		writeOIDCError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(tokens); err != nil {
+		logger.WithError(err).Error("couldn't write response body")
+	}
+}
+
+func writeOIDCError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, authenticationv2.ErrInvalidRequest):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case err == corev2.ErrUnauthorized:
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	default:
+		logger.WithError(err).Error("oidc login failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,101 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	authenticationv2 "github.com/sensu/sensu-go/backend/authentication/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockOIDCClient struct {
+	mock.Mock
+}
+
+func (m *mockOIDCClient) OIDCAuthCodeURL(ctx context.Context, req *authenticationv2.OIDCAuthorizeRequest) (string, error) {
+	args := m.Called(ctx, req)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockOIDCClient) CreateOIDCAccessToken(ctx context.Context, req *authenticationv2.OIDCTokenRequest) (*corev2.Tokens, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*corev2.Tokens), args.Error(1)
+}
+
+func TestOIDCAuthorize(t *testing.T) {
+	client := new(mockOIDCClient)
+	client.On("OIDCAuthCodeURL", mock.Anything, &authenticationv2.OIDCAuthorizeRequest{
+		Provider:    "idp",
+		RedirectURI: "http://127.0.0.1:8000/callback",
+		State:       "state",
+	}).Return("https://idp.example.com/authorize?state=state", nil)
+	client.On("OIDCAuthCodeURL", mock.Anything, mock.Anything).Return("", fmt.Errorf("%w: bad redirect", authenticationv2.ErrInvalidRequest))
+	router := NewOIDCRouter(client)
+
+	req, _ := http.NewRequest(http.MethodGet, "/auth/oidc/authorize?provider=idp&state=state&redirect_uri=http%3A%2F%2F127.0.0.1%3A8000%2Fcallback", nil)
+	res := processRequest(router, req)
+	assert.Equal(t, http.StatusFound, res.Code)
+	assert.Equal(t, "https://idp.example.com/authorize?state=state", res.Header().Get("Location"))
+
+	req, _ = http.NewRequest(http.MethodGet, "/auth/oidc/authorize?state=state&redirect_uri=https%3A%2F%2Fexample.com", nil)
+	res = processRequest(router, req)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+}
+
+func TestOIDCToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{
+			name:       "successful exchange",
+			body:       `{"code":"good","redirect_uri":"http://127.0.0.1:8000/callback"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid body",
+			body:       `{`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid request",
+			body:       `{"code":"good"}`,
+			err:        authenticationv2.ErrInvalidRequest,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unauthorized",
+			body:       `{"code":"bad","redirect_uri":"http://127.0.0.1:8000/callback"}`,
+			err:        corev2.ErrUnauthorized,
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := new(mockOIDCClient)
+			tokens := &corev2.Tokens{Access: "access", Refresh: "refresh"}
+			if tt.err != nil {
+				tokens = nil
+			}
+			client.On("CreateOIDCAccessToken", mock.Anything, mock.Anything).Return(tokens, tt.err)
+			router := NewOIDCRouter(client)
+
+			req, _ := http.NewRequest(http.MethodPost, "/auth/oidc/token", strings.NewReader(tt.body))
+			res := processRequest(router, req)
+			assert.Equal(t, tt.wantStatus, res.Code)
+			if tt.wantStatus == http.StatusOK {
+				var got corev2.Tokens
+				assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &got))
+				assert.Equal(t, "access", got.Access)
+			}
+		})
+	}
+}
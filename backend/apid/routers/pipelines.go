@@ -1,22 +1,42 @@
 package routers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+
 	"github.com/gorilla/mux"
 	corev2 "github.com/sensu/core/v2"
 	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/backend/apid/actions"
 	"github.com/sensu/sensu-go/backend/apid/handlers"
+	"github.com/sensu/sensu-go/backend/apid/request"
+	pipelinev1 "github.com/sensu/sensu-go/backend/pipeline/v1"
+	"github.com/sensu/sensu-go/backend/store"
 	storev2 "github.com/sensu/sensu-go/backend/store/v2"
 )
 
+// PipelineTracer runs events through pipelines and records what happens, for
+// the pipeline tests.
+type PipelineTracer interface {
+	Trace(ctx context.Context, ref *corev2.ResourceReference, event *corev2.Event, execute bool) (*pipelinev1.PipelineTrace, error)
+}
+
 // PipelinesRouter handles requests for /pipelines
 type PipelinesRouter struct {
-	store storev2.Interface
+	store  storev2.Interface
+	tracer PipelineTracer
 }
 
 // NewPipelineRouter instantiates new router for controlling pipeline resources
-func NewPipelinesRouter(store storev2.Interface) *PipelinesRouter {
+func NewPipelinesRouter(store storev2.Interface, tracer PipelineTracer) *PipelinesRouter {
 	return &PipelinesRouter{
-		store: store,
+		store:  store,
+		tracer: tracer,
 	}
 }
 
@@ -36,4 +56,80 @@ func (r *PipelinesRouter) Mount(parent *mux.Router) {
 	routes.Post(handlers.CreateResource)
 	routes.Put(handlers.CreateOrUpdateResource)
 	routes.Del(handlers.DeleteResource)
+
+	// handlefunc returns a custom response
+	parent.HandleFunc(path.Join(routes.PathPrefix, "{id}/test"), r.test).Methods(http.MethodPost)
+}
+
+// test runs the event of the request through the pipeline and responds with
+// the trace. The handlers are only executed if the execute query parameter is
+// true.
+func (r *PipelinesRouter) test(w http.ResponseWriter, req *http.Request) {
+	if r.tracer == nil {
+		WriteError(w, actions.NewErrorf(actions.InternalErr, "pipeline tests are not enabled"))
+		return
+	}
+	event, err := request.Resource[*corev2.Event](req)
+	if err != nil {
+		WriteError(w, actions.NewError(actions.InvalidArgument, err))
+		return
+	}
+	params := mux.Vars(req)
+	name, err := url.PathUnescape(params["id"])
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	namespace, err := url.PathUnescape(params["namespace"])
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	execute := false
+	if value := req.URL.Query().Get("execute"); value != "" {
+		if execute, err = strconv.ParseBool(value); err != nil {
+			WriteError(w, actions.NewErrorf(actions.InvalidArgument, "invalid execute: %s", err))
+			return
+		}
+	}
+
+	// The event can only go through the pipelines of its namespace
+	if event.Entity == nil {
+		WriteError(w, actions.NewError(actions.InvalidArgument, errors.New("event must have an entity")))
+		return
+	}
+	event.Namespace = namespace
+	event.Entity.Namespace = namespace
+	if event.Check != nil {
+		event.Check.Namespace = namespace
+	}
+	if err := event.Validate(); err != nil {
+		WriteError(w, actions.NewError(actions.InvalidArgument, err))
+		return
+	}
+
+	ref := &corev2.ResourceReference{
+		APIVersion: "core/v2",
+		Type:       "Pipeline",
+		Name:       name,
+	}
+	trace, err := r.tracer.Trace(req.Context(), ref, event, execute)
+	if err != nil {
+		if _, ok := err.(*store.ErrNotFound); ok {
+			WriteError(w, actions.NewErrorf(actions.NotFound))
+			return
+		}
+		WriteError(w, actions.NewError(actions.InternalErr, err))
+		return
+	}
+
+	body, err := json.Marshal(trace)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(body); err != nil {
+		WriteError(w, err)
+	}
 }
@@ -1,18 +1,40 @@
 package routers
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
 	"testing"
 
 	"github.com/gorilla/mux"
 	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/core/v3/types"
+	pipelinev1 "github.com/sensu/sensu-go/backend/pipeline/v1"
+	"github.com/sensu/sensu-go/backend/store"
 	"github.com/sensu/sensu-go/testing/mockstore"
+	"github.com/stretchr/testify/assert"
 )
 
+type mockPipelineTracer struct {
+	ref     *corev2.ResourceReference
+	event   *corev2.Event
+	execute bool
+	err     error
+}
+
+func (m *mockPipelineTracer) Trace(ctx context.Context, ref *corev2.ResourceReference, event *corev2.Event, execute bool) (*pipelinev1.PipelineTrace, error) {
+	m.ref, m.event, m.execute = ref, event, execute
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &pipelinev1.PipelineTrace{Pipeline: ref.Name, Executed: execute}, nil
+}
+
 func TestPipelinesRouter(t *testing.T) {
 	s := &mockstore.V2MockStore{}
 	cs := new(mockstore.ConfigStore)
 	s.On("GetConfigStore").Return(cs)
-	router := NewPipelinesRouter(s)
+	router := NewPipelinesRouter(s, &mockPipelineTracer{})
 	parentRouter := mux.NewRouter().PathPrefix(corev2.URLPrefix).Subrouter()
 	router.Mount(parentRouter)
 
@@ -29,3 +51,79 @@ func TestPipelinesRouter(t *testing.T) {
 		run(t, tt, parentRouter, s)
 	}
 }
+
+func TestPipelinesRouterTest(t *testing.T) {
+	s := &mockstore.V2MockStore{}
+	tracer := &mockPipelineTracer{}
+	router := NewPipelinesRouter(s, tracer)
+	parentRouter := mux.NewRouter().PathPrefix(corev2.URLPrefix).Subrouter()
+	router.Mount(parentRouter)
+
+	fixture := corev2.FixturePipeline("foo", "default")
+	event, _ := json.Marshal(types.WrapResource(corev2.FixtureEvent("entity1", "check1")))
+	noEntity := corev2.FixtureEvent("entity1", "check1")
+	noEntity.Entity = nil
+	eventWithoutEntity, _ := json.Marshal(types.WrapResource(noEntity))
+
+	tests := []routerTestCase{
+		{
+			name:           "it returns 400 if the body is not an event",
+			method:         http.MethodPost,
+			path:           fixture.URIPath() + "/test",
+			body:           []byte(`{"api_version":"core/v2","type":"Pipeline","spec":{}}`),
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "it returns 400 if the event has no entity",
+			method:         http.MethodPost,
+			path:           fixture.URIPath() + "/test",
+			body:           eventWithoutEntity,
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "it returns 400 if execute is invalid",
+			method:         http.MethodPost,
+			path:           fixture.URIPath() + "/test?execute=maybe",
+			body:           event,
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:   "it returns 404 if the pipeline does not exist",
+			method: http.MethodPost,
+			path:   fixture.URIPath() + "/test",
+			body:   event,
+			storeFunc: func(*mockstore.V2MockStore) {
+				tracer.err = &store.ErrNotFound{}
+			},
+			wantStatusCode: http.StatusNotFound,
+		},
+		{
+			name:   "it returns 500 if the pipeline can't be resolved",
+			method: http.MethodPost,
+			path:   fixture.URIPath() + "/test",
+			body:   event,
+			storeFunc: func(*mockstore.V2MockStore) {
+				tracer.err = &store.ErrInternal{}
+			},
+			wantStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name:   "it returns the trace of the pipeline",
+			method: http.MethodPost,
+			path:   fixture.URIPath() + "/test?execute=true",
+			body:   event,
+			storeFunc: func(*mockstore.V2MockStore) {
+				tracer.err = nil
+			},
+			wantStatusCode: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		run(t, tt, parentRouter, s)
+	}
+
+	assert.Equal(t, "foo", tracer.ref.Name)
+	assert.Equal(t, "Pipeline", tracer.ref.Type)
+	assert.Equal(t, "check1", tracer.event.Check.Name)
+	assert.True(t, tracer.execute)
+}
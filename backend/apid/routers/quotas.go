@@ -0,0 +1,92 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/backend/apid/handlers"
+	quotav1 "github.com/sensu/sensu-go/backend/quota/v1"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+// QuotaUsageGetter reports the usage of the namespace resources against the
+// limits of a quota.
+type QuotaUsageGetter interface {
+	Usage(ctx context.Context, quota *quotav1.Quota) (*quotav1.QuotaUsage, error)
+}
+
+// QuotasRouter handles requests for /quotas
+type QuotasRouter struct {
+	store storev2.Interface
+	usage QuotaUsageGetter
+}
+
+// NewQuotasRouter instantiates a new router for the namespace quotas.
+func NewQuotasRouter(store storev2.Interface, usage QuotaUsageGetter) *QuotasRouter {
+	return &QuotasRouter{
+		store: store,
+		usage: usage,
+	}
+}
+
+// Mount the QuotasRouter to a parent Router
+func (r *QuotasRouter) Mount(parent *mux.Router) {
+	routes := ResourceRoute{
+		Router:     parent,
+		PathPrefix: "/namespaces/{namespace}/{resource:quotas}",
+	}
+
+	// handlefunc returns a custom response
+	parent.HandleFunc(path.Join(routes.PathPrefix, "{id}/usage"), r.getUsage).Methods(http.MethodGet)
+
+	handlers := handlers.NewHandlers[*quotav1.Quota](r.store)
+	routes.Del(handlers.DeleteResource)
+	routes.Get(handlers.GetResource)
+	routes.List(handlers.ListResources, quotav1.QuotaFields)
+	routes.ListAllNamespaces(handlers.ListResources, "/{resource:quotas}", quotav1.QuotaFields)
+	routes.Patch(handlers.PatchResource)
+	routes.Post(handlers.CreateResource)
+	routes.Put(handlers.CreateOrUpdateResource)
+}
+
+// getUsage reports the usage of the namespace resources against the limits
+// of the quota.
+func (r *QuotasRouter) getUsage(w http.ResponseWriter, req *http.Request) {
+	if r.usage == nil {
+		WriteError(w, actions.NewErrorf(actions.InternalErr, "quota usage is not available"))
+		return
+	}
+	params := mux.Vars(req)
+	name, err := url.PathUnescape(params["id"])
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	ctx := req.Context()
+	id := storev2.ID{Namespace: params["namespace"], Name: name}
+
+	quota, err := storev2.Of[*quotav1.Quota](r.store).Get(ctx, id)
+	if err != nil {
+		if _, ok := err.(*store.ErrNotFound); ok {
+			WriteError(w, actions.NewErrorf(actions.NotFound))
+			return
+		}
+		WriteError(w, actions.NewError(actions.InternalErr, err))
+		return
+	}
+	usage, err := r.usage.Usage(ctx, quota)
+	if err != nil {
+		WriteError(w, actions.NewError(actions.InternalErr, err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(usage); err != nil {
+		logger.WithError(err).Error("couldn't write quota usage")
+	}
+}
@@ -0,0 +1,93 @@
+package routers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gorilla/mux"
+	corev2 "github.com/sensu/core/v2"
+	quotav1 "github.com/sensu/sensu-go/backend/quota/v1"
+	"github.com/sensu/sensu-go/backend/store"
+	"github.com/sensu/sensu-go/testing/mockstore"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockQuotaUsageGetter struct {
+	err error
+}
+
+func (m mockQuotaUsageGetter) Usage(_ context.Context, quota *quotav1.Quota) (*quotav1.QuotaUsage, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &quotav1.QuotaUsage{Quota: quota.Metadata.Name, Namespace: quota.Metadata.Namespace}, nil
+}
+
+func TestQuotasRouter(t *testing.T) {
+	// Setup the router
+	s := &mockstore.V2MockStore{}
+	cs := new(mockstore.ConfigStore)
+	s.On("GetConfigStore").Return(cs)
+	router := NewQuotasRouter(s, mockQuotaUsageGetter{})
+	parentRouter := mux.NewRouter().PathPrefix(quotav1.URLPrefix).Subrouter()
+	router.Mount(parentRouter)
+
+	empty := &quotav1.Quota{Metadata: corev2.ObjectMeta{Namespace: "default"}}
+	fixture := &quotav1.Quota{
+		Metadata:  corev2.ObjectMeta{Name: "team-a", Namespace: "default"},
+		Entities:  100,
+		EventRate: 50,
+	}
+
+	tests := []routerTestCase{}
+	tests = append(tests, getTestCases[*quotav1.Quota](fixture)...)
+	tests = append(tests, listTestCases[*quotav1.Quota](empty)...)
+	tests = append(tests, createTestCases(fixture)...)
+	tests = append(tests, updateTestCases(fixture)...)
+	tests = append(tests, deleteTestCases(fixture)...)
+	tests = append(tests, []routerTestCase{
+		{
+			name:   "it returns 404 if the quota does not exist",
+			method: http.MethodGet,
+			path:   fixture.URIPath() + "/usage",
+			storeFunc: func(s *mockstore.V2MockStore) {
+				cs.On("Get", mock.Anything, mock.Anything).Return(nil, &store.ErrNotFound{}).Once()
+			},
+			wantStatusCode: http.StatusNotFound,
+		},
+		{
+			name:   "it returns the usage of the quota",
+			method: http.MethodGet,
+			path:   fixture.URIPath() + "/usage",
+			storeFunc: func(s *mockstore.V2MockStore) {
+				cs.On("Get", mock.Anything, mock.Anything).Return(mockstore.Wrapper[*quotav1.Quota]{Value: fixture}, nil).Once()
+			},
+			wantStatusCode: http.StatusOK,
+		},
+	}...)
+	for _, tt := range tests {
+		run(t, tt, parentRouter, s)
+	}
+}
+
+func TestQuotasRouterUsageError(t *testing.T) {
+	s := &mockstore.V2MockStore{}
+	cs := new(mockstore.ConfigStore)
+	s.On("GetConfigStore").Return(cs)
+	router := NewQuotasRouter(s, mockQuotaUsageGetter{err: errors.New("error")})
+	parentRouter := mux.NewRouter().PathPrefix(quotav1.URLPrefix).Subrouter()
+	router.Mount(parentRouter)
+
+	fixture := &quotav1.Quota{Metadata: corev2.ObjectMeta{Name: "team-a", Namespace: "default"}}
+	run(t, routerTestCase{
+		name:   "it returns 500 if the usage can't be counted",
+		method: http.MethodGet,
+		path:   fixture.URIPath() + "/usage",
+		storeFunc: func(s *mockstore.V2MockStore) {
+			cs.On("Get", mock.Anything, mock.Anything).Return(mockstore.Wrapper[*quotav1.Quota]{Value: fixture}, nil).Once()
+		},
+		wantStatusCode: http.StatusInternalServerError,
+	}, parentRouter, s)
+}
@@ -140,6 +140,8 @@ func HTTPStatusFromCode(code actions.ErrCode) int {
 		return http.StatusGatewayTimeout
 	case actions.Gone:
 		return http.StatusGone
+	case actions.ResourceExhausted:
+		return http.StatusTooManyRequests
 	}
 
 	logger.WithField("code", code).Error("unknown error code")
@@ -0,0 +1,75 @@
+package routers
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/handlers"
+	secretsv1 "github.com/sensu/sensu-go/backend/secrets/v1"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+// SecretsProvidersRouter handles requests for /providers
+type SecretsProvidersRouter struct {
+	store storev2.Interface
+}
+
+// NewSecretsProvidersRouter instantiates a new router for secrets providers.
+func NewSecretsProvidersRouter(store storev2.Interface) *SecretsProvidersRouter {
+	return &SecretsProvidersRouter{
+		store: store,
+	}
+}
+
+// Mount the SecretsProvidersRouter to a parent Router
+func (r *SecretsProvidersRouter) Mount(parent *mux.Router) {
+	envRoutes := ResourceRoute{
+		Router:     parent,
+		PathPrefix: "/{resource:providers}/" + secretsv1.EnvProviderKind,
+	}
+	env := handlers.NewHandlers[*secretsv1.Env](r.store)
+	envRoutes.Del(env.DeleteResource)
+	envRoutes.Get(env.GetResource)
+	envRoutes.List(env.ListResources, secretsv1.ProviderFields)
+	envRoutes.Patch(env.PatchResource)
+	envRoutes.Post(env.CreateResource)
+	envRoutes.Put(env.CreateOrUpdateResource)
+
+	fileRoutes := ResourceRoute{
+		Router:     parent,
+		PathPrefix: "/{resource:providers}/" + secretsv1.FileProviderKind,
+	}
+	file := handlers.NewHandlers[*secretsv1.File](r.store)
+	fileRoutes.Del(file.DeleteResource)
+	fileRoutes.Get(file.GetResource)
+	fileRoutes.List(file.ListResources, secretsv1.ProviderFields)
+	fileRoutes.Patch(file.PatchResource)
+	fileRoutes.Post(file.CreateResource)
+	fileRoutes.Put(file.CreateOrUpdateResource)
+}
+
+// SecretsRouter handles requests for /secrets
+type SecretsRouter struct {
+	store storev2.Interface
+}
+
+// NewSecretsRouter instantiates a new router for secrets.
+func NewSecretsRouter(store storev2.Interface) *SecretsRouter {
+	return &SecretsRouter{
+		store: store,
+	}
+}
+
+// Mount the SecretsRouter to a parent Router
+func (r *SecretsRouter) Mount(parent *mux.Router) {
+	routes := ResourceRoute{
+		Router:     parent,
+		PathPrefix: "/namespaces/{namespace}/{resource:secrets}",
+	}
+	handlers := handlers.NewHandlers[*secretsv1.Secret](r.store)
+	routes.Del(handlers.DeleteResource)
+	routes.Get(handlers.GetResource)
+	routes.List(handlers.ListResources, secretsv1.SecretFields)
+	routes.ListAllNamespaces(handlers.ListResources, "/{resource:secrets}", secretsv1.SecretFields)
+	routes.Patch(handlers.PatchResource)
+	routes.Post(handlers.CreateResource)
+	routes.Put(handlers.CreateOrUpdateResource)
+}
@@ -0,0 +1,65 @@
+package routers
+
+import (
+	"testing"
+
+	"github.com/gorilla/mux"
+	corev2 "github.com/sensu/core/v2"
+	secretsv1 "github.com/sensu/sensu-go/backend/secrets/v1"
+	"github.com/sensu/sensu-go/testing/mockstore"
+)
+
+func TestSecretsProvidersRouter(t *testing.T) {
+	// Setup the router
+	s := &mockstore.V2MockStore{}
+	cs := new(mockstore.ConfigStore)
+	s.On("GetConfigStore").Return(cs)
+	router := NewSecretsProvidersRouter(s)
+	parentRouter := mux.NewRouter().PathPrefix(secretsv1.URLPrefix).Subrouter()
+	router.Mount(parentRouter)
+
+	env := &secretsv1.Env{Metadata: corev2.ObjectMeta{Name: "env"}}
+	file := &secretsv1.File{Metadata: corev2.ObjectMeta{Name: "file"}, Path: "/etc/sensu/secrets"}
+
+	tests := []routerTestCase{}
+	tests = append(tests, getTestCases[*secretsv1.Env](env)...)
+	tests = append(tests, listTestCases[*secretsv1.Env](&secretsv1.Env{})...)
+	tests = append(tests, createTestCases(env)...)
+	tests = append(tests, updateTestCases(env)...)
+	tests = append(tests, deleteTestCases(env)...)
+	tests = append(tests, getTestCases[*secretsv1.File](file)...)
+	tests = append(tests, listTestCases[*secretsv1.File](&secretsv1.File{})...)
+	tests = append(tests, createTestCases(file)...)
+	tests = append(tests, updateTestCases(file)...)
+	tests = append(tests, deleteTestCases(file)...)
+	for _, tt := range tests {
+		run(t, tt, parentRouter, s)
+	}
+}
+
+func TestSecretsRouter(t *testing.T) {
+	// Setup the router
+	s := &mockstore.V2MockStore{}
+	cs := new(mockstore.ConfigStore)
+	s.On("GetConfigStore").Return(cs)
+	router := NewSecretsRouter(s)
+	parentRouter := mux.NewRouter().PathPrefix(secretsv1.URLPrefix).Subrouter()
+	router.Mount(parentRouter)
+
+	empty := &secretsv1.Secret{Metadata: corev2.ObjectMeta{Namespace: "default"}}
+	fixture := &secretsv1.Secret{
+		Metadata: corev2.ObjectMeta{Name: "foo", Namespace: "default"},
+		ID:       "FOO",
+		Provider: "env",
+	}
+
+	tests := []routerTestCase{}
+	tests = append(tests, getTestCases[*secretsv1.Secret](fixture)...)
+	tests = append(tests, listTestCases[*secretsv1.Secret](empty)...)
+	tests = append(tests, createTestCases(fixture)...)
+	tests = append(tests, updateTestCases(fixture)...)
+	tests = append(tests, deleteTestCases(fixture)...)
+	for _, tt := range tests {
+		run(t, tt, parentRouter, s)
+	}
+}
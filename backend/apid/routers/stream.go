@@ -0,0 +1,214 @@
+package routers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/core/v3/types"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/backend/apid/request"
+	"github.com/sensu/sensu-go/backend/authorization"
+	"github.com/sensu/sensu-go/backend/selector"
+	"github.com/sensu/sensu-go/backend/stream"
+)
+
+const (
+	// streamKeepaliveInterval is the interval at which a comment is sent to
+	// keep idle streams open through proxies.
+	streamKeepaliveInterval = 15 * time.Second
+
+	// streamAuthorizationTTL is how long the authorization decisions are
+	// cached by a stream.
+	streamAuthorizationTTL = 30 * time.Second
+)
+
+// StreamRouter handles requests for the server-sent events streams of
+// /events/stream, /entities/stream, /checks/stream and /silenced/stream.
+type StreamRouter struct {
+	hub        *stream.Hub
+	authorizer authorization.Authorizer
+	keepalive  time.Duration
+}
+
+// NewStreamRouter instantiates a new router for the streams of the hub.
+func NewStreamRouter(hub *stream.Hub, authorizer authorization.Authorizer) *StreamRouter {
+	return &StreamRouter{
+		hub:        hub,
+		authorizer: authorizer,
+		keepalive:  streamKeepaliveInterval,
+	}
+}
+
+// Mount the StreamRouter to a parent Router
+func (r *StreamRouter) Mount(parent *mux.Router) {
+	const resources = "{resource:events|entities|checks|silenced}"
+	parent.HandleFunc("/"+resources+"/stream", r.stream).Methods(http.MethodGet)
+	parent.HandleFunc("/namespaces/{namespace}/"+resources+"/stream", r.stream).Methods(http.MethodGet)
+}
+
+// stream sends the changes of a kind of resource as server-sent events, until
+// the client goes away. The name of each event is the action, and its data is
+// the wrapped resource. The changes of every namespace are sent, unless the
+// request is made within a namespace. Label and field selectors filter the
+// resources, and every resource is only sent if the user may get it.
+func (r *StreamRouter) stream(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		WriteError(w, actions.NewErrorf(actions.InternalErr, "streaming is not supported"))
+		return
+	}
+	attrs := authorization.GetAttributes(ctx)
+	if attrs == nil {
+		WriteError(w, actions.NewErrorf(actions.InternalErr, "could not retrieve the request info"))
+		return
+	}
+
+	sub, err := r.hub.Subscribe(stream.Kind(mux.Vars(req)["resource"]))
+	if err != nil {
+		WriteError(w, actions.NewError(actions.InvalidArgument, err))
+		return
+	}
+	defer sub.Cancel()
+
+	// Streams outlive the write timeout of the API
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		logger.WithError(err).Debug("couldn't clear the write deadline of the stream")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	filter := &streamFilter{
+		namespace:  mux.Vars(req)["namespace"],
+		selector:   request.SelectorFromContext(ctx),
+		authorizer: r.authorizer,
+		attrs:      *attrs,
+		decisions:  make(map[string]streamDecision),
+	}
+	keepalive := time.NewTicker(r.keepalive)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case msg, ok := <-sub.C:
+			if !ok {
+				// The subscriber fell behind, the client must reconnect
+				_, _ = fmt.Fprint(w, "event: error\ndata: {\"message\":\"the stream fell behind\"}\n\n")
+				flusher.Flush()
+				return
+			}
+			if !filter.allows(req, msg) {
+				continue
+			}
+			data, err := json.Marshal(types.WrapResource(msg.Resource))
+			if err != nil {
+				logger.WithError(err).Error("couldn't marshal streamed resource")
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", msg.Action, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+type streamDecision struct {
+	allowed bool
+	expires time.Time
+}
+
+// streamFilter decides which messages are sent to a stream.
+type streamFilter struct {
+	namespace  string
+	selector   *selector.Selector
+	authorizer authorization.Authorizer
+	attrs      authorization.Attributes
+	decisions  map[string]streamDecision
+}
+
+func (f *streamFilter) allows(req *http.Request, msg stream.Message) bool {
+	namespace := msg.Namespace()
+	if f.namespace != "" && namespace != f.namespace {
+		return false
+	}
+	if f.selector != nil && !matchesStreamSelector(f.selector, msg.Fields()) {
+		return false
+	}
+	// Users who may list the resources of the namespace may get all of them,
+	// otherwise every resource is authorized on its own.
+	return f.authorized(req, namespace, "") || f.authorized(req, namespace, msg.Name())
+}
+
+// authorized returns whether the user may get the named resource, or list
+// the resources of the namespace if the name is empty. The decisions are
+// cached so that the store isn't queried for every message.
+func (f *streamFilter) authorized(req *http.Request, namespace, name string) bool {
+	key := namespace + "/" + name
+	now := time.Now()
+	if decision, ok := f.decisions[key]; ok && now.Before(decision.expires) {
+		return decision.allowed
+	}
+	attrs := f.attrs
+	attrs.Namespace = namespace
+	attrs.ResourceName = name
+	attrs.Verb = "get"
+	if name == "" {
+		attrs.Verb = "list"
+	}
+	allowed, err := f.authorizer.Authorize(req.Context(), &attrs)
+	if err != nil {
+		logger.WithError(err).Debug("couldn't authorize streamed resource")
+		allowed = false
+	}
+	if len(f.decisions) > 10000 {
+		f.decisions = make(map[string]streamDecision)
+	}
+	f.decisions[key] = streamDecision{allowed: allowed, expires: now.Add(streamAuthorizationTTL)}
+	return allowed
+}
+
+// matchesStreamSelector returns whether the fields of a resource match the
+// selector. Label selectors are matched against the labels of the resource.
+func matchesStreamSelector(sel *selector.Selector, fields map[string]string) bool {
+	return matchesStreamSets(sel, fields, stream.Labels(fields))
+}
+
+func matchesStreamSets(sel *selector.Selector, fields, labels map[string]string) bool {
+	for _, op := range sel.Operations {
+		set := fields
+		if op.OperationType == selector.OperationTypeLabelSelector {
+			set = labels
+		}
+		if !(&selector.Selector{Operations: []selector.Operation{op}}).Matches(set) {
+			return false
+		}
+	}
+	for _, disjunction := range sel.Disjunctions {
+		matches := false
+		for _, alternative := range disjunction {
+			if matchesStreamSets(alternative, fields, labels) {
+				matches = true
+				break
+			}
+		}
+		if !matches {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,163 @@
+package routers
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/apid/request"
+	"github.com/sensu/sensu-go/backend/authorization"
+	"github.com/sensu/sensu-go/backend/selector"
+	"github.com/sensu/sensu-go/backend/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// streamAuthorizer allows the resources of the given namespaces to be
+// listed, and the given resources to be read.
+type streamAuthorizer struct {
+	list map[string]bool
+	get  map[string]bool
+}
+
+func (a *streamAuthorizer) Authorize(ctx context.Context, attrs *authorization.Attributes) (bool, error) {
+	if attrs.Verb == "list" {
+		return a.list[attrs.Namespace], nil
+	}
+	return a.get[attrs.Namespace+"/"+attrs.ResourceName], nil
+}
+
+func newStreamServer(t *testing.T, in chan stream.Message, auth authorization.Authorizer, sel *selector.Selector) *httptest.Server {
+	t.Helper()
+	feed := func(ctx context.Context, out chan<- stream.Message) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-in:
+				out <- msg
+			}
+		}
+	}
+	hub := stream.NewHub(map[stream.Kind]stream.Feed{stream.KindEvents: feed})
+	router := mux.NewRouter()
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := authorization.SetAttributes(r.Context(), &authorization.Attributes{
+				Resource: mux.Vars(r)["resource"],
+				Verb:     "list",
+			})
+			if sel != nil {
+				ctx = request.ContextWithSelector(ctx, sel)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+	NewStreamRouter(hub, auth).Mount(router.PathPrefix("/api/{group:core}/{version:v3}").Subrouter())
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// readStreamEvents reads n server-sent events of the stream.
+func readStreamEvents(t *testing.T, reader *bufio.Reader, n int) []string {
+	t.Helper()
+	var events []string
+	var current []string
+	for len(events) < n {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+		if line == "" {
+			if len(current) > 0 {
+				events = append(events, strings.Join(current, "\n"))
+			}
+			current = nil
+			continue
+		}
+		current = append(current, line)
+	}
+	return events
+}
+
+func TestStreamRouter(t *testing.T) {
+	in := make(chan stream.Message)
+	auth := &streamAuthorizer{
+		list: map[string]bool{"default": true},
+		get:  map[string]bool{"dev/allowed/check": true},
+	}
+	sel, err := selector.ParseLabelSelector("region == west")
+	require.NoError(t, err)
+	server := newStreamServer(t, in, auth, sel)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/core/v3/events/stream", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	newEvent := func(namespace, entity, region string) stream.Message {
+		event := corev2.FixtureEvent(entity, "check")
+		event.Namespace = namespace
+		event.Entity.Namespace = namespace
+		event.Check.Namespace = namespace
+		event.Entity.Labels = map[string]string{"region": region}
+		return stream.Message{Action: stream.ActionUpdate, Resource: event}
+	}
+	// Filtered out by the selector
+	in <- newEvent("default", "east", "east")
+	// Allowed by the namespace
+	in <- newEvent("default", "listed", "west")
+	// Denied
+	in <- newEvent("dev", "denied", "west")
+	// Allowed by name
+	in <- newEvent("dev", "allowed", "west")
+
+	events := readStreamEvents(t, bufio.NewReader(resp.Body), 2)
+	require.Len(t, events, 2)
+	assert.True(t, strings.HasPrefix(events[0], "event: update\ndata: "))
+	assert.Contains(t, events[0], `"name":"listed"`)
+	assert.Contains(t, events[1], `"name":"allowed"`)
+	assert.Contains(t, events[1], `"type":"Event"`)
+}
+
+func TestStreamRouterNamespace(t *testing.T) {
+	in := make(chan stream.Message)
+	auth := &streamAuthorizer{list: map[string]bool{"default": true, "dev": true}}
+	server := newStreamServer(t, in, auth, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/core/v3/namespaces/dev/events/stream", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	for _, namespace := range []string{"default", "dev"} {
+		event := corev2.FixtureEvent(namespace+"-entity", "check")
+		event.Namespace = namespace
+		event.Entity.Namespace = namespace
+		in <- stream.Message{Action: stream.ActionUpdate, Resource: event}
+	}
+	events := readStreamEvents(t, bufio.NewReader(resp.Body), 1)
+	assert.Contains(t, events[0], `"name":"dev-entity"`)
+}
+
+func TestStreamRouterUnknownResource(t *testing.T) {
+	server := newStreamServer(t, make(chan stream.Message), &streamAuthorizer{}, nil)
+	resp, err := http.Get(server.URL + "/api/core/v3/checks/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,47 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"syscall"
+
+	"github.com/sensu/sensu-go/backend/audit"
+	"github.com/sensu/sensu-go/backend/logging"
+	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/sensu/sensu-go/backend/store/postgres"
+)
+
+// newAuditLogger creates the audit logger of the API, writing to the audit
+// log file and to postgresql as configured. It returns nil if the audit log is
+// disabled. The audit log file is reopened on SIGHUP, and closed when the
+// context is canceled.
+func newAuditLogger(ctx context.Context, bus messaging.MessageBus, db postgres.DBI, config *Config) (*audit.Logger, error) {
+	var sinks []audit.Sink
+	if config.AuditLogFile != "" {
+		consumer := fmt.Sprintf("filelogger://%s", config.AuditLogFile)
+		sighup := make(messaging.ChanSubscriber, 1)
+		subscription, err := bus.Subscribe(messaging.SignalTopic(syscall.SIGHUP), consumer, sighup)
+		if err != nil {
+			return nil, fmt.Errorf("unable to subscribe to SIGHUP signal notifications: %s", err)
+		}
+		writer, err := logging.NewRotateWriter(config.AuditLogFile, sighup)
+		if err != nil {
+			_ = subscription.Cancel()
+			return nil, fmt.Errorf("unable to open audit log file: %s", err)
+		}
+		go func() {
+			<-ctx.Done()
+			_ = subscription.Cancel()
+			close(sighup)
+			_ = writer.Close()
+		}()
+		sinks = append(sinks, audit.NewWriterSink(writer))
+	}
+	if config.AuditLogPostgres {
+		sinks = append(sinks, postgres.NewAuditLogStore(db))
+	}
+	if len(sinks) == 0 {
+		return nil, nil
+	}
+	return audit.NewLogger(sinks...), nil
+}
@@ -0,0 +1,255 @@
+// Package audit records the requests that mutate resources: who made them,
+// on what, what they requested and whether they succeeded.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// OutcomeSuccess is the outcome of a request that succeeded.
+	OutcomeSuccess = "success"
+
+	// OutcomeFailure is the outcome of a request that failed or was denied.
+	OutcomeFailure = "failure"
+)
+
+var logger = logrus.WithFields(logrus.Fields{
+	"component": "audit",
+})
+
+// Record is the audit record of a request that mutates a resource.
+type Record struct {
+	// Time is the time at which the request was received.
+	Time time.Time `json:"time"`
+
+	// User is the name of the user who made the request.
+	User string `json:"user"`
+
+	// Groups are the groups of the user.
+	Groups []string `json:"groups"`
+
+	// SourceIP is the IP address the request was made from.
+	SourceIP string `json:"source_ip"`
+
+	// Namespace is the namespace of the resource, empty for cluster-wide
+	// resources.
+	Namespace string `json:"namespace,omitempty"`
+
+	// APIVersion is the API group and version of the resource, e.g. core/v2.
+	APIVersion string `json:"api_version,omitempty"`
+
+	// Resource is the resource type, e.g. checks. It is graphql for GraphQL
+	// mutations.
+	Resource string `json:"resource"`
+
+	// Name is the name of the resource. For GraphQL mutations, it is the
+	// comma-separated list of the mutations.
+	Name string `json:"name,omitempty"`
+
+	// Verb is one of create, update, patch, delete or mutate.
+	Verb string `json:"verb"`
+
+	// Request is the body of the request, as requested by the user whether
+	// or not it was allowed: the definition of the resource when it is
+	// created or updated, and the patch when it is patched. It is empty when
+	// the resource is deleted. For GraphQL mutations, it holds the variables
+	// of the request. Secrets are redacted.
+	Request json.RawMessage `json:"request,omitempty"`
+
+	// Outcome is either success or failure.
+	Outcome string `json:"outcome"`
+
+	// Status is the HTTP status code of the response.
+	Status int `json:"status"`
+}
+
+// Sink is a destination for audit records.
+type Sink interface {
+	// AddRecord adds the record to the sink.
+	AddRecord(ctx context.Context, record *Record) error
+}
+
+// Predicate selects the audit records to retrieve. Empty fields match every
+// record.
+type Predicate struct {
+	// Namespace selects the records of a namespace.
+	Namespace string
+	// User selects the records of a user.
+	User string
+	// Resource selects the records of a resource type.
+	Resource string
+	// Verb selects the records of a verb.
+	Verb string
+	// Start selects records at or after it, if not zero.
+	Start time.Time
+	// End selects records before it, if not zero.
+	End time.Time
+	// Limit indicates the number of records to retrieve
+	Limit int64
+	// Continue provides the position from which the selection should start.
+	// If returned empty from the store, it indicates that there's no
+	// additional records available.
+	Continue string
+}
+
+// Store is a sink that can be queried.
+type Store interface {
+	Sink
+
+	// GetRecords returns the records selected by the predicate, most recent
+	// first.
+	GetRecords(ctx context.Context, pred *Predicate) ([]*Record, error)
+}
+
+// Logger sends audit records to a set of sinks.
+type Logger struct {
+	sinks []Sink
+}
+
+// NewLogger creates a new Logger, sending records to the given sinks.
+func NewLogger(sinks ...Sink) *Logger {
+	return &Logger{sinks: sinks}
+}
+
+// Log adds the record to every sink. Failing to add the record to a sink is
+// logged, and does not prevent adding it to the others.
+func (l *Logger) Log(ctx context.Context, record *Record) {
+	for _, sink := range l.sinks {
+		if err := sink.AddRecord(ctx, record); err != nil {
+			logger.WithError(err).WithFields(logrus.Fields{
+				"user":     record.User,
+				"resource": record.Resource,
+				"name":     record.Name,
+				"verb":     record.Verb,
+			}).Error("couldn't write audit record")
+		}
+	}
+}
+
+// WriterSink writes audit records to a writer, as JSON lines.
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink creates a new WriterSink.
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// AddRecord writes the record as a line of JSON.
+func (s *WriterSink) AddRecord(_ context.Context, record *Record) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(b)
+	return err
+}
+
+// RedactedValue replaces the secrets of the redacted documents.
+const RedactedValue = "REDACTED"
+
+// redactedKeys are the keys of the secrets found in resource definitions,
+// such as the credentials of the LDAP and OIDC providers, the headers of the
+// assets and HTTP handlers, and the environment variables of the checks,
+// hooks, handlers and mutators.
+var redactedKeys = map[string]bool{
+	"password":      true,
+	"password_hash": true,
+	"client_secret": true,
+	"bind_password": true,
+	"headers":       true,
+	"env_vars":      true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+}
+
+// redactSecret redacts a secret value. The values of the maps of secrets, such
+// as headers, are redacted, and so are the values of the lists of environment
+// variables, keeping their names.
+func redactSecret(value interface{}) interface{} {
+	switch value := value.(type) {
+	case nil:
+		return nil
+	case string:
+		if value == "" {
+			return value
+		}
+	case map[string]interface{}:
+		for k, v := range value {
+			value[k] = redactSecret(v)
+		}
+		return value
+	case []interface{}:
+		for i, v := range value {
+			if s, ok := v.(string); ok {
+				if name, _, ok := strings.Cut(s, "="); ok {
+					value[i] = name + "=" + RedactedValue
+					continue
+				}
+			}
+			value[i] = redactSecret(v)
+		}
+		return value
+	}
+	return RedactedValue
+}
+
+// Redact returns the JSON document with the values of its secrets replaced
+// by RedactedValue, at any depth. The JSON documents found in string values,
+// such as the resources of GraphQL mutations, are redacted too. Redact
+// returns nil if the document is not valid JSON.
+func Redact(doc []byte) json.RawMessage {
+	if len(doc) == 0 {
+		return nil
+	}
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil
+	}
+	b, err := json.Marshal(redact(value))
+	if err != nil {
+		return nil
+	}
+	return json.RawMessage(b)
+}
+
+func redact(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for k, v := range value {
+			if redactedKeys[k] {
+				value[k] = redactSecret(v)
+				continue
+			}
+			value[k] = redact(v)
+		}
+	case []interface{}:
+		for i, v := range value {
+			value[i] = redact(v)
+		}
+	case string:
+		if strings.HasPrefix(strings.TrimSpace(value), "{") {
+			if redacted := Redact([]byte(value)); redacted != nil {
+				return string(redacted)
+			}
+		}
+	}
+	return value
+}
@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{
+			name: "no secrets",
+			doc:  `{"metadata":{"name":"check"},"interval":10}`,
+			want: `{"metadata":{"name":"check"},"interval":10}`,
+		},
+		{
+			name: "user",
+			doc:  `{"type":"User","spec":{"username":"alice","password":"P@ssw0rd!","password_hash":"$2a$10$x"}}`,
+			want: `{"type":"User","spec":{"username":"alice","password":"REDACTED","password_hash":"REDACTED"}}`,
+		},
+		{
+			name: "providers",
+			doc:  `[{"client_id":"sensu","client_secret":"hunter2"},{"bind_dn":"cn=sensu","bind_password":"hunter2"}]`,
+			want: `[{"client_id":"sensu","client_secret":"REDACTED"},{"bind_dn":"cn=sensu","bind_password":"REDACTED"}]`,
+		},
+		{
+			name: "handler",
+			doc:  `{"type":"http","headers":{"Authorization":"Bearer s3cr3t"},"env_vars":["API_KEY=s3cr3t","DEBUG"]}`,
+			want: `{"type":"http","headers":{"Authorization":"REDACTED"},"env_vars":["API_KEY=REDACTED","REDACTED"]}`,
+		},
+		{
+			name: "tokens",
+			doc:  `{"access_token":"s3cr3t","refresh_token":"s3cr3t","token":"s3cr3t=="}`,
+			want: `{"access_token":"REDACTED","refresh_token":"REDACTED","token":"REDACTED"}`,
+		},
+		{
+			name: "empty secret",
+			doc:  `{"username":"alice","password":""}`,
+			want: `{"username":"alice","password":""}`,
+		},
+		{
+			name: "graphql variables",
+			doc:  `{"raw":"{\"type\":\"User\",\"spec\":{\"username\":\"alice\",\"password\":\"P@ssw0rd!\"}}","upsert":true}`,
+			want: `{"raw":"{\"spec\":{\"password\":\"REDACTED\",\"username\":\"alice\"},\"type\":\"User\"}","upsert":true}`,
+		},
+		{
+			name: "large numbers",
+			doc:  `{"expire":9007199254740993}`,
+			want: `{"expire":9007199254740993}`,
+		},
+		{
+			name: "invalid",
+			doc:  `{"password":`,
+		},
+		{
+			name: "empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Redact([]byte(tt.doc))
+			if tt.want == "" {
+				assert.Nil(t, got)
+				return
+			}
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWriterSink(&buf)
+	records := []*Record{
+		{Time: time.Unix(1, 0).UTC(), User: "admin", Resource: "checks", Name: "a", Verb: "create", Request: json.RawMessage(`{"interval":10}`)},
+		{Time: time.Unix(2, 0).UTC(), User: "admin", Resource: "checks", Name: "a", Verb: "delete"},
+	}
+	for _, record := range records {
+		require.NoError(t, sink.AddRecord(context.Background(), record))
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	for i, line := range lines {
+		var got Record
+		require.NoError(t, json.Unmarshal(line, &got))
+		assert.Equal(t, records[i].Verb, got.Verb)
+		assert.Equal(t, records[i].Request, got.Request)
+	}
+}
+
+type failingSink struct{}
+
+func (failingSink) AddRecord(context.Context, *Record) error {
+	return errors.New("unavailable")
+}
+
+func TestLoggerFailingSink(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger(failingSink{}, NewWriterSink(&buf))
+	l.Log(context.Background(), &Record{User: "admin", Verb: "create"})
+	assert.Contains(t, buf.String(), `"user":"admin"`)
+}
@@ -0,0 +1,91 @@
+// Package loader keeps an authenticator in sync with the authentication
+// providers stored in the configuration store.
+package loader
+
+import (
+	"context"
+
+	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/backend/authentication"
+	authenticationv2 "github.com/sensu/sensu-go/backend/authentication/v2"
+	"github.com/sensu/sensu-go/backend/providers"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	"github.com/sirupsen/logrus"
+)
+
+var logger = logrus.WithFields(logrus.Fields{
+	"component": "authentication",
+})
+
+// Loader loads the authentication providers found in the store into an
+// authenticator, and keeps the authenticator up to date as providers are
+// created, updated or deleted.
+type Loader struct {
+	Store         storev2.Interface
+	Authenticator *authentication.Authenticator
+}
+
+// New creates a new Loader.
+func New(store storev2.Interface, authenticator *authentication.Authenticator) *Loader {
+	return &Loader{
+		Store:         store,
+		Authenticator: authenticator,
+	}
+}
+
+// Load adds every stored provider to the authenticator.
+func (l *Loader) Load(ctx context.Context) error {
+	return l.loader().Load(ctx)
+}
+
+// Watch applies the changes made to the stored providers to the
+// authenticator, until the context is canceled.
+func (l *Loader) Watch(ctx context.Context) {
+	l.loader().Watch(ctx)
+}
+
+func (l *Loader) loader() *providers.Loader[corev3.AuthProvider] {
+	return &providers.Loader[corev3.AuthProvider]{
+		Store: l.Store,
+		Sources: []providers.Source[corev3.AuthProvider]{
+			providers.NewSource[corev3.AuthProvider, *authenticationv2.OIDC]("oidc authentication"),
+			providers.NewSource[corev3.AuthProvider, *authenticationv2.LDAP]("ldap authentication"),
+		},
+		Add:    l.add,
+		Remove: l.remove,
+		Logger: logger,
+	}
+}
+
+func (l *Loader) add(provider corev3.AuthProvider) {
+	fields := logrus.Fields{
+		"provider": provider.Name(),
+		"type":     provider.Type(),
+	}
+	if err := provider.Validate(); err != nil {
+		// An invalid provider must not keep serving logins with its previous
+		// configuration.
+		logger.WithFields(fields).WithError(err).Error("invalid authentication provider")
+		l.remove(provider)
+		return
+	}
+	l.Authenticator.AddProvider(provider)
+	logger.WithFields(fields).Info("loaded authentication provider")
+}
+
+// remove removes the provider, unless it was replaced by a provider of
+// another type with the same name.
+func (l *Loader) remove(provider corev3.AuthProvider) {
+	existing, ok := l.Authenticator.Providers()[provider.Name()]
+	if !ok || existing.Type() != provider.Type() {
+		return
+	}
+	if err := l.Authenticator.RemoveProvider(provider.Name()); err != nil {
+		logger.WithError(err).Warn("couldn't remove authentication provider")
+		return
+	}
+	logger.WithFields(logrus.Fields{
+		"provider": provider.Name(),
+		"type":     provider.Type(),
+	}).Info("removed authentication provider")
+}
@@ -0,0 +1,440 @@
+package v2
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	sensujwt "github.com/sensu/sensu-go/backend/authentication/jwt"
+	"github.com/sensu/sensu-go/backend/providers"
+)
+
+var _ corev3.AuthProvider = new(LDAP)
+
+const (
+	// LDAPType is the type of the LDAP provider.
+	LDAPType = "ldap"
+
+	// LDAPProviderKind is the URL path element of the LDAP provider.
+	LDAPProviderKind = "ldap"
+
+	// LDAPSecurityTLS connects to the LDAP server with TLS.
+	LDAPSecurityTLS = "tls"
+
+	// LDAPSecurityStartTLS upgrades the connection to the LDAP server to TLS
+	// with the StartTLS operation.
+	LDAPSecurityStartTLS = "starttls"
+
+	// LDAPSecurityInsecure connects to the LDAP server without TLS.
+	LDAPSecurityInsecure = "insecure"
+
+	// DefaultLDAPUserFilter is the user search filter used when none is
+	// configured.
+	DefaultLDAPUserFilter = "(&(objectClass=person)(uid={username}))"
+
+	// DefaultLDAPGroupFilter is the group search filter used when none is
+	// configured.
+	DefaultLDAPGroupFilter = "(&(objectClass=groupOfNames)(member={dn}))"
+
+	// DefaultLDAPGroupNameAttribute is the attribute of the groups used as
+	// group name when none is configured.
+	DefaultLDAPGroupNameAttribute = "cn"
+
+	// DefaultLDAPPrefix is prepended to the usernames and groups when no
+	// prefix is configured.
+	DefaultLDAPPrefix = "ldap:"
+
+	ldapTimeout = 10 * time.Second
+)
+
+// LDAPUserSearch configures the search of the user logging in.
+type LDAPUserSearch struct {
+	// BaseDN is the DN of the subtree searched.
+	BaseDN string `json:"base_dn"`
+
+	// Filter selects the user. {username} is replaced with the escaped
+	// username. Defaults to DefaultLDAPUserFilter.
+	Filter string `json:"filter,omitempty"`
+}
+
+// LDAPGroupSearch configures the search of the groups of the user.
+type LDAPGroupSearch struct {
+	// BaseDN is the DN of the subtree searched.
+	BaseDN string `json:"base_dn"`
+
+	// Filter selects the groups of the user. {dn} is replaced with the
+	// escaped DN of the user, and {username} with the escaped username.
+	// Defaults to DefaultLDAPGroupFilter.
+	Filter string `json:"filter,omitempty"`
+
+	// NameAttribute is the attribute of the groups used as group name.
+	// Defaults to DefaultLDAPGroupNameAttribute.
+	NameAttribute string `json:"name_attribute,omitempty"`
+}
+
+// LDAP is an authentication provider that authenticates users against an
+// LDAP directory. The user entry is searched with the bind DN credentials,
+// then the password is verified by binding as the user. The groups of the
+// user are searched on every login and token refresh, so users removed from
+// the directory lose access when their access token expires.
+type LDAP struct {
+	// Metadata contains the name, labels and annotations of the provider.
+	// Providers are global resources and have no namespace.
+	Metadata corev2.ObjectMeta `json:"metadata"`
+
+	// Host is the host name or address of the LDAP server.
+	Host string `json:"host"`
+
+	// Port is the port of the LDAP server. Defaults to 636 with TLS, and
+	// 389 otherwise.
+	Port int `json:"port,omitempty"`
+
+	// Security is one of tls, starttls or insecure. Defaults to tls.
+	Security string `json:"security,omitempty"`
+
+	// TrustedCAFile is the path of the PEM encoded CA certificates used to
+	// verify the LDAP server certificate. Defaults to the system pool.
+	TrustedCAFile string `json:"trusted_ca_file,omitempty"`
+
+	// InsecureSkipVerify disables the verification of the LDAP server
+	// certificate.
+	InsecureSkipVerify bool `json:"insecure_skip_verify,omitempty"`
+
+	// BindDN is the DN of the account used to search users and groups. The
+	// searches are anonymous when it is empty.
+	BindDN string `json:"bind_dn,omitempty"`
+
+	// BindPassword is the password of the bind DN.
+	BindPassword string `json:"bind_password,omitempty"`
+
+	// UserSearch configures the search of the user logging in.
+	UserSearch LDAPUserSearch `json:"user_search"`
+
+	// GroupSearch configures the search of the groups of the user.
+	GroupSearch LDAPGroupSearch `json:"group_search"`
+
+	// UsernamePrefix is prepended to the username. Defaults to
+	// DefaultLDAPPrefix.
+	UsernamePrefix string `json:"username_prefix,omitempty"`
+
+	// GroupsPrefix is prepended to each group of the user. Defaults to
+	// DefaultLDAPPrefix.
+	GroupsPrefix string `json:"groups_prefix,omitempty"`
+}
+
+// GetMetadata returns the provider metadata.
+func (l *LDAP) GetMetadata() *corev2.ObjectMeta {
+	return &l.Metadata
+}
+
+// SetMetadata sets the provider metadata.
+func (l *LDAP) SetMetadata(meta *corev2.ObjectMeta) {
+	if meta == nil {
+		meta = &corev2.ObjectMeta{}
+	}
+	l.Metadata = *meta
+}
+
+// StoreName returns the store name of the provider.
+func (l *LDAP) StoreName() string {
+	return "authentication/providers/ldap"
+}
+
+// RBACName returns the RBAC name of the provider.
+func (l *LDAP) RBACName() string {
+	return ProvidersResource
+}
+
+// URIPath returns the path of the provider.
+func (l *LDAP) URIPath() string {
+	return providers.Path(URLPrefix, ProvidersResource, LDAPProviderKind, url.PathEscape(l.Metadata.Name))
+}
+
+// GetTypeMeta returns the type metadata of the provider.
+func (l *LDAP) GetTypeMeta() corev2.TypeMeta {
+	return providers.TypeMeta(APIVersion, "LDAP")
+}
+
+// IsGlobalResource returns true, providers are not namespaced.
+func (l *LDAP) IsGlobalResource() bool {
+	return true
+}
+
+// Validate returns an error if the provider is invalid.
+func (l *LDAP) Validate() error {
+	if err := validateMetadata(l.Metadata); err != nil {
+		return err
+	}
+	if l.Host == "" {
+		return errors.New("host must not be empty")
+	}
+	if l.Port < 0 || l.Port > 65535 {
+		return errors.New("port must be between 0 and 65535")
+	}
+	switch l.Security {
+	case "", LDAPSecurityTLS, LDAPSecurityStartTLS, LDAPSecurityInsecure:
+	default:
+		return fmt.Errorf("security must be one of %s, %s or %s", LDAPSecurityTLS, LDAPSecurityStartTLS, LDAPSecurityInsecure)
+	}
+	if l.BindDN == "" && l.BindPassword != "" {
+		return errors.New("bind_password requires a bind_dn")
+	}
+	if l.BindDN != "" && l.BindPassword == "" {
+		return errors.New("bind_dn requires a bind_password")
+	}
+	if l.UserSearch.BaseDN == "" {
+		return errors.New("user_search base_dn must not be empty")
+	}
+	if l.GroupSearch.BaseDN == "" {
+		return errors.New("group_search base_dn must not be empty")
+	}
+	if _, err := ldap.CompileFilter(l.userFilter("user")); err != nil {
+		return fmt.Errorf("invalid user_search filter: %s", err)
+	}
+	if _, err := ldap.CompileFilter(l.groupFilter("cn=user", "user")); err != nil {
+		return fmt.Errorf("invalid group_search filter: %s", err)
+	}
+	return nil
+}
+
+// Redact removes the bind password of the provider.
+func (l *LDAP) Redact() {
+	l.BindPassword = ""
+}
+
+// Name returns the provider name.
+func (l *LDAP) Name() string {
+	return l.Metadata.Name
+}
+
+// Type returns the provider type.
+func (l *LDAP) Type() string {
+	return LDAPType
+}
+
+// Authenticate verifies the password of a user by binding as the user, and
+// returns the claims of the user with its groups.
+func (l *LDAP) Authenticate(ctx context.Context, username, password string) (*corev2.Claims, error) {
+	// an empty password would be an unauthenticated bind, which succeeds
+	if username == "" || password == "" {
+		return nil, errors.New("the username and the password must not be empty")
+	}
+	conn, err := l.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := l.bind(conn); err != nil {
+		return nil, err
+	}
+	userDN, err := l.searchUser(conn, username)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.Bind(userDN, password); err != nil {
+		return nil, fmt.Errorf("couldn't bind as %q: %s", userDN, err)
+	}
+	// the groups are searched with the bind DN credentials
+	if err := l.bind(conn); err != nil {
+		return nil, err
+	}
+	return l.claims(conn, username, userDN)
+}
+
+// Refresh renews the claims of a user. It fails if the user no longer exists
+// in the directory, and updates its groups.
+func (l *LDAP) Refresh(ctx context.Context, claims *corev2.Claims) (*corev2.Claims, error) {
+	conn, err := l.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := l.bind(conn); err != nil {
+		return nil, err
+	}
+	username := claims.Provider.UserID
+	userDN, err := l.searchUser(conn, username)
+	if err != nil {
+		return nil, err
+	}
+	return l.claims(conn, username, userDN)
+}
+
+func (l *LDAP) claims(conn *ldap.Conn, username, userDN string) (*corev2.Claims, error) {
+	groups, err := l.searchGroups(conn, username, userDN)
+	if err != nil {
+		return nil, err
+	}
+	usernamePrefix := l.UsernamePrefix
+	if usernamePrefix == "" {
+		usernamePrefix = DefaultLDAPPrefix
+	}
+	claims, err := sensujwt.NewClaims(&corev2.User{Username: usernamePrefix + username, Groups: groups})
+	if err != nil {
+		return nil, err
+	}
+	claims.Provider = corev2.AuthProviderClaims{
+		ProviderID:   l.Name(),
+		ProviderType: LDAPType,
+		UserID:       username,
+	}
+	return claims, nil
+}
+
+func (l *LDAP) dial(ctx context.Context) (*ldap.Conn, error) {
+	security := l.Security
+	if security == "" {
+		security = LDAPSecurityTLS
+	}
+	port := l.Port
+	if port == 0 {
+		port = 389
+		if security == LDAPSecurityTLS {
+			port = 636
+		}
+	}
+	scheme := "ldap"
+	if security == LDAPSecurityTLS {
+		scheme = "ldaps"
+	}
+	address := fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(l.Host, strconv.Itoa(port)))
+
+	var tlsConfig *tls.Config
+	if security != LDAPSecurityInsecure {
+		var err error
+		if tlsConfig, err = l.tlsConfig(); err != nil {
+			return nil, err
+		}
+	}
+
+	dialer := &net.Dialer{Timeout: ldapTimeout}
+	if deadline, ok := ctx.Deadline(); ok {
+		dialer.Deadline = deadline
+	}
+	conn, err := ldap.DialURL(address, ldap.DialWithDialer(dialer), ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't connect to %s: %s", address, err)
+	}
+	conn.SetTimeout(ldapTimeout)
+	if security == LDAPSecurityStartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("couldn't start tls with %s: %s", address, err)
+		}
+	}
+	return conn, nil
+}
+
+func (l *LDAP) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         l.Host,
+		InsecureSkipVerify: l.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if l.TrustedCAFile != "" {
+		pem, err := os.ReadFile(l.TrustedCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read trusted CA file: %s", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", l.TrustedCAFile)
+		}
+	}
+	return config, nil
+}
+
+// bind binds with the bind DN credentials, or anonymously if there is no bind
+// DN.
+func (l *LDAP) bind(conn *ldap.Conn) error {
+	var err error
+	if l.BindDN == "" {
+		err = conn.UnauthenticatedBind("")
+	} else {
+		err = conn.Bind(l.BindDN, l.BindPassword)
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't bind as %q: %s", l.BindDN, err)
+	}
+	return nil
+}
+
+// searchUser returns the DN of the user.
+func (l *LDAP) searchUser(conn *ldap.Conn, username string) (string, error) {
+	req := ldap.NewSearchRequest(
+		l.UserSearch.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, int(ldapTimeout.Seconds()), false,
+		l.userFilter(username), []string{"dn"}, nil,
+	)
+	result, err := conn.Search(req)
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return "", fmt.Errorf("couldn't search user %q: %s", username, err)
+	}
+	if result == nil || len(result.Entries) == 0 {
+		return "", fmt.Errorf("user %q not found", username)
+	}
+	if len(result.Entries) > 1 {
+		return "", fmt.Errorf("several users match %q", username)
+	}
+	return result.Entries[0].DN, nil
+}
+
+// searchGroups returns the prefixed names of the groups of the user.
+func (l *LDAP) searchGroups(conn *ldap.Conn, username, userDN string) ([]string, error) {
+	nameAttribute := l.GroupSearch.NameAttribute
+	if nameAttribute == "" {
+		nameAttribute = DefaultLDAPGroupNameAttribute
+	}
+	req := ldap.NewSearchRequest(
+		l.GroupSearch.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		0, int(ldapTimeout.Seconds()), false,
+		l.groupFilter(userDN, username), []string{nameAttribute}, nil,
+	)
+	result, err := conn.Search(req)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't search the groups of %q: %s", username, err)
+	}
+	prefix := l.GroupsPrefix
+	if prefix == "" {
+		prefix = DefaultLDAPPrefix
+	}
+	groups := make([]string, 0, len(result.Entries))
+	for _, entry := range result.Entries {
+		if name := entry.GetAttributeValue(nameAttribute); name != "" {
+			groups = append(groups, prefix+name)
+		}
+	}
+	return groups, nil
+}
+
+func (l *LDAP) userFilter(username string) string {
+	filter := l.UserSearch.Filter
+	if filter == "" {
+		filter = DefaultLDAPUserFilter
+	}
+	return strings.ReplaceAll(filter, "{username}", ldap.EscapeFilter(username))
+}
+
+func (l *LDAP) groupFilter(userDN, username string) string {
+	filter := l.GroupSearch.Filter
+	if filter == "" {
+		filter = DefaultLDAPGroupFilter
+	}
+	return strings.NewReplacer(
+		"{dn}", ldap.EscapeFilter(userDN),
+		"{username}", ldap.EscapeFilter(username),
+	).Replace(filter)
+}
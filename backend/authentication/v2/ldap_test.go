@@ -0,0 +1,338 @@
+package v2
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testBindDN       = "cn=sensu,ou=services,dc=example,dc=com"
+	testBindPassword = "s3rvice"
+)
+
+type ldapEntry struct {
+	dn         string
+	attributes map[string][]string
+}
+
+// mockLDAP is a minimal in-process LDAP server. It supports simple binds,
+// StartTLS, and searches whose decompiled filter is a key of the entries map.
+// Searches require to be bound as testBindDN.
+type mockLDAP struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+
+	mu        sync.Mutex
+	passwords map[string]string
+	entries   map[string][]ldapEntry
+}
+
+func newMockLDAP(t *testing.T) (*mockLDAP, string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	cert, caFile := testCertificate(t)
+	server := &mockLDAP{
+		listener:  listener,
+		tlsConfig: &tls.Config{Certificates: []tls.Certificate{cert}},
+		passwords: map[string]string{
+			testBindDN:                             testBindPassword,
+			"uid=jane,ou=people,dc=example,dc=com": "hunter2",
+		},
+		entries: map[string][]ldapEntry{
+			"(&(objectClass=person)(uid=jane))": {{dn: "uid=jane,ou=people,dc=example,dc=com"}},
+			"(&(objectClass=groupOfNames)(member=uid=jane,ou=people,dc=example,dc=com))": {
+				{dn: "cn=ops,ou=groups,dc=example,dc=com", attributes: map[string][]string{"cn": {"ops"}}},
+				{dn: "cn=dev,ou=groups,dc=example,dc=com", attributes: map[string][]string{"cn": {"dev"}}},
+			},
+		},
+	}
+	go server.serve()
+	t.Cleanup(func() { _ = listener.Close() })
+	return server, caFile
+}
+
+func (s *mockLDAP) setEntries(filter string, entries []ldapEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[filter] = entries
+}
+
+func (s *mockLDAP) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *mockLDAP) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *mockLDAP) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	var boundDN string
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		id := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			dn := op.Children[1].Value.(string)
+			password := op.Children[2].Data.String()
+			s.mu.Lock()
+			want, ok := s.passwords[dn]
+			s.mu.Unlock()
+			code := ldap.LDAPResultSuccess
+			if dn != "" && (!ok || want != password) {
+				code = ldap.LDAPResultInvalidCredentials
+			}
+			if code == ldap.LDAPResultSuccess {
+				boundDN = dn
+			}
+			s.write(conn, id, result(ldap.ApplicationBindResponse, code))
+		case ldap.ApplicationSearchRequest:
+			if boundDN != testBindDN {
+				s.write(conn, id, result(ldap.ApplicationSearchResultDone, ldap.LDAPResultInsufficientAccessRights))
+				continue
+			}
+			filter, err := ldap.DecompileFilter(op.Children[6])
+			if err != nil {
+				s.write(conn, id, result(ldap.ApplicationSearchResultDone, ldap.LDAPResultProtocolError))
+				continue
+			}
+			s.mu.Lock()
+			entries := s.entries[filter]
+			s.mu.Unlock()
+			for _, entry := range entries {
+				s.write(conn, id, entry.packet())
+			}
+			s.write(conn, id, result(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess))
+		case ldap.ApplicationExtendedRequest:
+			response := result(ldap.ApplicationExtendedResponse, ldap.LDAPResultSuccess)
+			s.write(conn, id, response)
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+		default:
+			return
+		}
+	}
+}
+
+func (s *mockLDAP) write(conn net.Conn, id int64, op *ber.Packet) {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "MessageID"))
+	packet.AppendChild(op)
+	_, _ = conn.Write(packet.Bytes())
+}
+
+func result(tag ber.Tag, code int) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Response")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "resultCode"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "diagnosticMessage"))
+	return op
+}
+
+func (e ldapEntry) packet() *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, e.dn, "objectName"))
+	attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attributes")
+	for name, values := range e.attributes {
+		attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attribute")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "value"))
+		}
+		attribute.AppendChild(set)
+		attributes.AppendChild(attribute)
+	}
+	op.AppendChild(attributes)
+	return op
+}
+
+// testCertificate returns a self-signed certificate for 127.0.0.1, and the
+// path of its PEM encoding.
+func testCertificate(t *testing.T) (tls.Certificate, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ldap"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, caFile
+}
+
+func testLDAP(server *mockLDAP, caFile string) *LDAP {
+	return &LDAP{
+		Metadata:      corev2.ObjectMeta{Name: "directory"},
+		Host:          "127.0.0.1",
+		Port:          server.port(),
+		Security:      LDAPSecurityStartTLS,
+		TrustedCAFile: caFile,
+		BindDN:        testBindDN,
+		BindPassword:  testBindPassword,
+		UserSearch:    LDAPUserSearch{BaseDN: "ou=people,dc=example,dc=com"},
+		GroupSearch:   LDAPGroupSearch{BaseDN: "ou=groups,dc=example,dc=com"},
+	}
+}
+
+func TestLDAPAuthenticate(t *testing.T) {
+	server, caFile := newMockLDAP(t)
+	provider := testLDAP(server, caFile)
+	require.NoError(t, provider.Validate())
+
+	claims, err := provider.Authenticate(context.Background(), "jane", "hunter2")
+	require.NoError(t, err)
+	assert.Equal(t, "ldap:jane", claims.Subject)
+	assert.ElementsMatch(t, []string{"ldap:ops", "ldap:dev"}, claims.Groups)
+	assert.Equal(t, corev2.AuthProviderClaims{ProviderID: "directory", ProviderType: LDAPType, UserID: "jane"}, claims.Provider)
+
+	provider.UsernamePrefix = "corp-"
+	provider.GroupsPrefix = "corp-"
+	claims, err = provider.Authenticate(context.Background(), "jane", "hunter2")
+	require.NoError(t, err)
+	assert.Equal(t, "corp-jane", claims.Subject)
+	assert.ElementsMatch(t, []string{"corp-ops", "corp-dev"}, claims.Groups)
+	provider.UsernamePrefix = ""
+	provider.GroupsPrefix = ""
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+	}{
+		{name: "wrong password", username: "jane", password: "wrong"},
+		{name: "empty password", username: "jane"},
+		{name: "unknown user", username: "john", password: "hunter2"},
+		{name: "filter injection", username: "*)(uid=*", password: "hunter2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := provider.Authenticate(context.Background(), tt.username, tt.password)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLDAPAuthenticateBadBindCredentials(t *testing.T) {
+	server, caFile := newMockLDAP(t)
+	provider := testLDAP(server, caFile)
+	provider.BindPassword = "wrong"
+
+	_, err := provider.Authenticate(context.Background(), "jane", "hunter2")
+	assert.Error(t, err)
+}
+
+func TestLDAPAuthenticateUntrustedServer(t *testing.T) {
+	server, _ := newMockLDAP(t)
+	provider := testLDAP(server, "")
+
+	_, err := provider.Authenticate(context.Background(), "jane", "hunter2")
+	assert.Error(t, err)
+
+	provider.InsecureSkipVerify = true
+	_, err = provider.Authenticate(context.Background(), "jane", "hunter2")
+	assert.NoError(t, err)
+}
+
+func TestLDAPRefresh(t *testing.T) {
+	server, caFile := newMockLDAP(t)
+	provider := testLDAP(server, caFile)
+	claims, err := provider.Authenticate(context.Background(), "jane", "hunter2")
+	require.NoError(t, err)
+
+	// group membership changes are applied on refresh
+	server.setEntries("(&(objectClass=groupOfNames)(member=uid=jane,ou=people,dc=example,dc=com))", []ldapEntry{
+		{dn: "cn=ops,ou=groups,dc=example,dc=com", attributes: map[string][]string{"cn": {"ops"}}},
+	})
+	refreshed, err := provider.Refresh(context.Background(), claims)
+	require.NoError(t, err)
+	assert.Equal(t, "ldap:jane", refreshed.Subject)
+	assert.Equal(t, []string{"ldap:ops"}, refreshed.Groups)
+
+	// users removed from the directory can't refresh their token
+	server.setEntries("(&(objectClass=person)(uid=jane))", nil)
+	_, err = provider.Refresh(context.Background(), claims)
+	assert.Error(t, err)
+}
+
+func TestLDAPValidate(t *testing.T) {
+	valid := func() *LDAP {
+		return &LDAP{
+			Metadata:    corev2.ObjectMeta{Name: "directory"},
+			Host:        "ldap.example.com",
+			UserSearch:  LDAPUserSearch{BaseDN: "ou=people,dc=example,dc=com"},
+			GroupSearch: LDAPGroupSearch{BaseDN: "ou=groups,dc=example,dc=com"},
+		}
+	}
+	assert.NoError(t, valid().Validate())
+
+	invalid := []func(*LDAP){
+		func(l *LDAP) { l.Metadata.Name = "basic" },
+		func(l *LDAP) { l.Metadata.Namespace = "default" },
+		func(l *LDAP) { l.Host = "" },
+		func(l *LDAP) { l.Port = 65536 },
+		func(l *LDAP) { l.Security = "ssl" },
+		func(l *LDAP) { l.BindPassword = "s3rvice" },
+		func(l *LDAP) { l.BindDN = "cn=sensu,dc=example,dc=com" },
+		func(l *LDAP) { l.UserSearch.BaseDN = "" },
+		func(l *LDAP) { l.GroupSearch.BaseDN = "" },
+		func(l *LDAP) { l.UserSearch.Filter = "(uid={username}" },
+		func(l *LDAP) { l.GroupSearch.Filter = "member={dn}))" },
+	}
+	for i, fn := range invalid {
+		l := valid()
+		fn(l)
+		assert.Error(t, l.Validate(), strconv.Itoa(i))
+	}
+}
+
+func TestLDAPRedact(t *testing.T) {
+	provider := &LDAP{BindDN: testBindDN, BindPassword: testBindPassword}
+	provider.Redact()
+	assert.Equal(t, testBindDN, provider.BindDN)
+	assert.Empty(t, provider.BindPassword)
+}
@@ -0,0 +1,515 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	sensujwt "github.com/sensu/sensu-go/backend/authentication/jwt"
+	"github.com/sensu/sensu-go/backend/providers"
+)
+
+var _ corev3.AuthProvider = new(OIDC)
+
+const (
+	// OIDCType is the type of the OpenID Connect provider.
+	OIDCType = "oidc"
+
+	// OIDCProviderKind is the URL path element of the OIDC provider.
+	OIDCProviderKind = "oidc"
+
+	// DefaultOIDCUsernameClaim is the ID token claim used as username when
+	// none is configured.
+	DefaultOIDCUsernameClaim = "sub"
+
+	// DefaultOIDCGroupsClaim is the ID token claim used as groups when none
+	// is configured.
+	DefaultOIDCGroupsClaim = "groups"
+
+	// DefaultOIDCPrefix is prepended to the usernames and groups when no
+	// prefix is configured.
+	DefaultOIDCPrefix = "oidc:"
+
+	oidcRequestTimeout  = 10 * time.Second
+	maxOIDCResponseSize = 1 << 20
+)
+
+// OIDCAuthorizeRequest is a request to start the authorization code flow of
+// an OIDC provider.
+type OIDCAuthorizeRequest struct {
+	// Provider is the name of the OIDC provider. It can be omitted when a
+	// single OIDC provider is configured.
+	Provider string
+
+	// RedirectURI is the loopback address the identity provider redirects to
+	// once the user is authenticated.
+	RedirectURI string
+
+	// State is an opaque value returned to the redirect URI as is.
+	State string
+
+	// Nonce is an opaque value the identity provider adds to the ID token.
+	Nonce string
+
+	// CodeChallenge is the optional S256 PKCE code challenge.
+	CodeChallenge string
+}
+
+// OIDCTokenRequest is a request to exchange an authorization code for Sensu
+// access and refresh tokens.
+type OIDCTokenRequest struct {
+	// Provider is the name of the OIDC provider. It can be omitted when a
+	// single OIDC provider is configured.
+	Provider string `json:"provider,omitempty"`
+
+	// Code is the authorization code returned to the redirect URI.
+	Code string `json:"code"`
+
+	// RedirectURI is the redirect URI of the authorization request.
+	RedirectURI string `json:"redirect_uri"`
+
+	// CodeVerifier is the PKCE code verifier, if a code challenge was sent.
+	CodeVerifier string `json:"code_verifier,omitempty"`
+
+	// Nonce is the nonce of the authorization request, if any.
+	Nonce string `json:"nonce,omitempty"`
+}
+
+// OIDC is an authentication provider that authenticates users with an
+// OpenID Connect identity provider, using the authorization code flow. The
+// username and groups of the user are read from the claims of the ID token,
+// and prefixed so they can be told apart from those of other providers in
+// role binding subjects.
+type OIDC struct {
+	// Metadata contains the name, labels and annotations of the provider.
+	// Providers are global resources and have no namespace.
+	Metadata corev2.ObjectMeta `json:"metadata"`
+
+	// Server is the issuer URL of the identity provider. Its configuration
+	// is discovered from Server/.well-known/openid-configuration.
+	Server string `json:"server"`
+
+	// ClientID is the OAuth 2.0 client ID of Sensu.
+	ClientID string `json:"client_id"`
+
+	// ClientSecret is the OAuth 2.0 client secret of Sensu.
+	ClientSecret string `json:"client_secret"`
+
+	// AdditionalScopes are requested in addition to the openid scope.
+	AdditionalScopes []string `json:"additional_scopes,omitempty"`
+
+	// UsernameClaim is the ID token claim used as username. Defaults to
+	// DefaultOIDCUsernameClaim.
+	UsernameClaim string `json:"username_claim,omitempty"`
+
+	// UsernamePrefix is prepended to the username. Defaults to
+	// DefaultOIDCPrefix.
+	UsernamePrefix string `json:"username_prefix,omitempty"`
+
+	// GroupsClaim is the ID token claim listing the groups of the user.
+	// Defaults to DefaultOIDCGroupsClaim.
+	GroupsClaim string `json:"groups_claim,omitempty"`
+
+	// GroupsPrefix is prepended to each group of the user. Defaults to
+	// DefaultOIDCPrefix.
+	GroupsPrefix string `json:"groups_prefix,omitempty"`
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]interface{}
+	client    *http.Client
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcTokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// GetMetadata returns the provider metadata.
+func (o *OIDC) GetMetadata() *corev2.ObjectMeta {
+	return &o.Metadata
+}
+
+// SetMetadata sets the provider metadata.
+func (o *OIDC) SetMetadata(meta *corev2.ObjectMeta) {
+	if meta == nil {
+		meta = &corev2.ObjectMeta{}
+	}
+	o.Metadata = *meta
+}
+
+// StoreName returns the store name of the provider.
+func (o *OIDC) StoreName() string {
+	return "authentication/providers/oidc"
+}
+
+// RBACName returns the RBAC name of the provider.
+func (o *OIDC) RBACName() string {
+	return ProvidersResource
+}
+
+// URIPath returns the path of the provider.
+func (o *OIDC) URIPath() string {
+	return providers.Path(URLPrefix, ProvidersResource, OIDCProviderKind, url.PathEscape(o.Metadata.Name))
+}
+
+// GetTypeMeta returns the type metadata of the provider.
+func (o *OIDC) GetTypeMeta() corev2.TypeMeta {
+	return providers.TypeMeta(APIVersion, "OIDC")
+}
+
+// IsGlobalResource returns true, providers are not namespaced.
+func (o *OIDC) IsGlobalResource() bool {
+	return true
+}
+
+// Validate returns an error if the provider is invalid.
+func (o *OIDC) Validate() error {
+	if err := validateMetadata(o.Metadata); err != nil {
+		return err
+	}
+	u, err := url.Parse(o.Server)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return errors.New("server must be an http or https URL")
+	}
+	if o.ClientID == "" {
+		return errors.New("client_id must not be empty")
+	}
+	if o.ClientSecret == "" {
+		return errors.New("client_secret must not be empty")
+	}
+	return nil
+}
+
+// Redact removes the client secret of the provider.
+func (o *OIDC) Redact() {
+	o.ClientSecret = ""
+}
+
+// Name returns the provider name.
+func (o *OIDC) Name() string {
+	return o.Metadata.Name
+}
+
+// Type returns the provider type.
+func (o *OIDC) Type() string {
+	return OIDCType
+}
+
+// Authenticate always fails, OIDC users log in with the authorization code
+// flow.
+func (o *OIDC) Authenticate(ctx context.Context, username, password string) (*corev2.Claims, error) {
+	return nil, errors.New("the oidc provider does not support password authentication")
+}
+
+// Refresh renews the claims of a user. The identity provider is not queried,
+// changes made to the user in the identity provider are applied on its next
+// login. Sessions can't outlive the ID token of the login, as the refresh
+// token issued on login expires with it.
+func (o *OIDC) Refresh(ctx context.Context, claims *corev2.Claims) (*corev2.Claims, error) {
+	groups := make([]string, 0, len(claims.Groups))
+	for _, group := range claims.Groups {
+		// added back by the authentication API
+		if group != "system:users" {
+			groups = append(groups, group)
+		}
+	}
+	newClaims, err := sensujwt.NewClaims(&corev2.User{Username: claims.Subject, Groups: groups})
+	if err != nil {
+		return nil, err
+	}
+	newClaims.Provider = claims.Provider
+	return newClaims, nil
+}
+
+// AuthCodeURL returns the URL of the identity provider the user must visit
+// to log in.
+func (o *OIDC) AuthCodeURL(ctx context.Context, req *OIDCAuthorizeRequest) (string, error) {
+	if err := validateRedirectURI(req.RedirectURI); err != nil {
+		return "", err
+	}
+	if req.State == "" {
+		return "", fmt.Errorf("%w: state must not be empty", ErrInvalidRequest)
+	}
+	discovery, err := o.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %s", err)
+	}
+	query := u.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", o.ClientID)
+	query.Set("redirect_uri", req.RedirectURI)
+	query.Set("scope", strings.Join(append([]string{"openid"}, o.AdditionalScopes...), " "))
+	query.Set("state", req.State)
+	if req.Nonce != "" {
+		query.Set("nonce", req.Nonce)
+	}
+	if req.CodeChallenge != "" {
+		query.Set("code_challenge", req.CodeChallenge)
+		query.Set("code_challenge_method", "S256")
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// Exchange exchanges an authorization code for an ID token, verifies it and
+// returns the claims of the user it identifies. The claims expire with the ID
+// token.
+func (o *OIDC) Exchange(ctx context.Context, req *OIDCTokenRequest) (*corev2.Claims, error) {
+	if err := validateRedirectURI(req.RedirectURI); err != nil {
+		return nil, err
+	}
+	if req.Code == "" {
+		return nil, fmt.Errorf("%w: code must not be empty", ErrInvalidRequest)
+	}
+	discovery, err := o.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", req.Code)
+	form.Set("redirect_uri", req.RedirectURI)
+	if req.CodeVerifier != "" {
+		form.Set("code_verifier", req.CodeVerifier)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	request.SetBasicAuth(url.QueryEscape(o.ClientID), url.QueryEscape(o.ClientSecret))
+	var response oidcTokenResponse
+	if err := o.do(request, &response); err != nil && response.Error == "" {
+		return nil, fmt.Errorf("couldn't exchange authorization code: %s", err)
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("couldn't exchange authorization code: %s: %s", response.Error, response.ErrorDescription)
+	}
+	if response.IDToken == "" {
+		return nil, errors.New("couldn't exchange authorization code: no id_token in response")
+	}
+
+	idClaims, err := o.verify(ctx, discovery, response.IDToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %s", err)
+	}
+	if req.Nonce != "" {
+		if nonce, _ := idClaims["nonce"].(string); nonce != req.Nonce {
+			return nil, errors.New("invalid id_token: nonce mismatch")
+		}
+	}
+	return o.claims(idClaims)
+}
+
+// claims maps the claims of an ID token to the claims of a Sensu user.
+func (o *OIDC) claims(idClaims jwt.MapClaims) (*corev2.Claims, error) {
+	usernameClaim := o.UsernameClaim
+	if usernameClaim == "" {
+		usernameClaim = DefaultOIDCUsernameClaim
+	}
+	username, _ := idClaims[usernameClaim].(string)
+	if username == "" {
+		return nil, fmt.Errorf("id_token has no %q claim", usernameClaim)
+	}
+	subject, _ := idClaims["sub"].(string)
+	usernamePrefix := o.UsernamePrefix
+	if usernamePrefix == "" {
+		usernamePrefix = DefaultOIDCPrefix
+	}
+	groupsPrefix := o.GroupsPrefix
+	if groupsPrefix == "" {
+		groupsPrefix = DefaultOIDCPrefix
+	}
+
+	groupsClaim := o.GroupsClaim
+	if groupsClaim == "" {
+		groupsClaim = DefaultOIDCGroupsClaim
+	}
+	var groups []string
+	switch value := idClaims[groupsClaim].(type) {
+	case string:
+		groups = append(groups, groupsPrefix+value)
+	case []interface{}:
+		for _, group := range value {
+			if group, ok := group.(string); ok && group != "" {
+				groups = append(groups, groupsPrefix+group)
+			}
+		}
+	}
+
+	claims, err := sensujwt.NewClaims(&corev2.User{Username: usernamePrefix + username, Groups: groups})
+	if err != nil {
+		return nil, err
+	}
+	switch exp := idClaims["exp"].(type) {
+	case float64:
+		claims.ExpiresAt = int64(exp)
+	case json.Number:
+		claims.ExpiresAt, _ = exp.Int64()
+	}
+	claims.Provider = corev2.AuthProviderClaims{
+		ProviderID:   o.Name(),
+		ProviderType: OIDCType,
+		UserID:       subject,
+	}
+	return claims, nil
+}
+
+// verify verifies the signature, issuer, audience and validity period of an
+// ID token, and returns its claims.
+func (o *OIDC) verify(ctx context.Context, discovery *oidcDiscovery, idToken string) (jwt.MapClaims, error) {
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}))
+	claims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return o.key(ctx, discovery, kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !claims.VerifyIssuer(discovery.Issuer, true) {
+		return nil, errors.New("unexpected issuer")
+	}
+	if !claims.VerifyAudience(o.ClientID, true) {
+		return nil, errors.New("unexpected audience")
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, errors.New("no expiration")
+	}
+	return claims, nil
+}
+
+// key returns the signing key with the given ID, fetching the key set of the
+// identity provider if the key is unknown, to follow key rotations.
+func (o *OIDC) key(ctx context.Context, discovery *oidcDiscovery, kid string) (interface{}, error) {
+	o.mu.Lock()
+	keys := o.keys
+	o.mu.Unlock()
+	if key, ok := lookupKey(keys, kid); ok {
+		return key, nil
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, discovery.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set jsonWebKeySet
+	if err := o.do(request, &set); err != nil {
+		return nil, fmt.Errorf("couldn't fetch signing keys: %s", err)
+	}
+	keys = set.keys()
+	o.mu.Lock()
+	o.keys = keys
+	o.mu.Unlock()
+	if key, ok := lookupKey(keys, kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func lookupKey(keys map[string]interface{}, kid string) (interface{}, bool) {
+	if kid == "" && len(keys) == 1 {
+		// tokens may omit the key ID when there is a single key
+		for _, key := range keys {
+			return key, true
+		}
+	}
+	key, ok := keys[kid]
+	return key, ok
+}
+
+// discover fetches and caches the configuration of the identity provider.
+func (o *OIDC) discover(ctx context.Context) (*oidcDiscovery, error) {
+	o.mu.Lock()
+	discovery := o.discovery
+	o.mu.Unlock()
+	if discovery != nil {
+		return discovery, nil
+	}
+
+	wellKnown := strings.TrimSuffix(o.Server, "/") + "/.well-known/openid-configuration"
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, wellKnown, nil)
+	if err != nil {
+		return nil, err
+	}
+	discovery = &oidcDiscovery{}
+	if err := o.do(request, discovery); err != nil {
+		return nil, fmt.Errorf("couldn't discover the configuration of %s: %s", o.Server, err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(o.Server, "/") {
+		return nil, fmt.Errorf("issuer %q does not match server %q", discovery.Issuer, o.Server)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("incomplete configuration of %s", o.Server)
+	}
+	o.mu.Lock()
+	o.discovery = discovery
+	o.mu.Unlock()
+	return discovery, nil
+}
+
+// do sends the request and decodes the JSON response into v. The response is
+// decoded even if its status is not 200, for error responses.
+func (o *OIDC) do(request *http.Request, v interface{}) error {
+	client := o.client
+	if client == nil {
+		client = &http.Client{Timeout: oidcRequestTimeout}
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxOIDCResponseSize))
+	if err != nil {
+		return err
+	}
+	decodeErr := json.Unmarshal(body, v)
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", response.Status)
+	}
+	return decodeErr
+}
+
+// validateRedirectURI only accepts loopback redirect URIs, so that
+// authorization codes can't be sent to a third party.
+func validateRedirectURI(redirectURI string) error {
+	u, err := url.Parse(redirectURI)
+	if err != nil || u.Scheme != "http" {
+		return fmt.Errorf("%w: redirect_uri must be a loopback http URL", ErrInvalidRequest)
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("%w: redirect_uri must be a loopback http URL", ErrInvalidRequest)
+}
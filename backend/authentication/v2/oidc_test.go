@@ -0,0 +1,260 @@
+package v2
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockIdP is a minimal OpenID Connect identity provider, which issues an ID
+// token with the configured claims for the code "good".
+type mockIdP struct {
+	*httptest.Server
+	// signer signs the ID tokens, key is published in the key set
+	signer *rsa.PrivateKey
+	claims jwt.MapClaims
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	idp := &mockIdP{signer: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.URL,
+			"authorization_endpoint": idp.URL + "/authorize",
+			"token_endpoint":         idp.URL + "/token",
+			"jwks_uri":               idp.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if id != "sensu" || secret != "hunter2" || r.FormValue("code") != "good" || r.FormValue("redirect_uri") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, idp.claims)
+		token.Header["kid"] = "key1"
+		signed, err := token.SignedString(idp.signer)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": signed, "token_type": "Bearer"})
+	})
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+	idp.claims = jwt.MapClaims{
+		"iss":    idp.URL,
+		"aud":    "sensu",
+		"sub":    "1234",
+		"email":  "jane@example.com",
+		"groups": []string{"ops", "dev"},
+		"nonce":  "n0nce",
+		"exp":    time.Now().Add(time.Minute).Unix(),
+	}
+	return idp
+}
+
+func testOIDC(idp *mockIdP) *OIDC {
+	return &OIDC{
+		Metadata:      corev2.ObjectMeta{Name: "idp"},
+		Server:        idp.URL,
+		ClientID:      "sensu",
+		ClientSecret:  "hunter2",
+		UsernameClaim: "email",
+	}
+}
+
+func TestOIDCAuthCodeURL(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := testOIDC(idp)
+	provider.AdditionalScopes = []string{"groups"}
+
+	authURL, err := provider.AuthCodeURL(context.Background(), &OIDCAuthorizeRequest{
+		RedirectURI:   "http://127.0.0.1:8000/callback",
+		State:         "state",
+		Nonce:         "n0nce",
+		CodeChallenge: "challenge",
+	})
+	require.NoError(t, err)
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, idp.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	query := u.Query()
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, "sensu", query.Get("client_id"))
+	assert.Equal(t, "openid groups", query.Get("scope"))
+	assert.Equal(t, "state", query.Get("state"))
+	assert.Equal(t, "n0nce", query.Get("nonce"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+
+	for _, redirect := range []string{"", "https://127.0.0.1/callback", "http://example.com/callback", "http://localhost.example.com/"} {
+		_, err = provider.AuthCodeURL(context.Background(), &OIDCAuthorizeRequest{RedirectURI: redirect, State: "state"})
+		assert.ErrorIs(t, err, ErrInvalidRequest, redirect)
+	}
+}
+
+func TestOIDCExchange(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := testOIDC(idp)
+	req := &OIDCTokenRequest{
+		Code:        "good",
+		RedirectURI: "http://localhost:8000/callback",
+		Nonce:       "n0nce",
+	}
+
+	claims, err := provider.Exchange(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "oidc:jane@example.com", claims.Subject)
+	assert.Equal(t, []string{"oidc:ops", "oidc:dev"}, claims.Groups)
+	assert.Equal(t, corev2.AuthProviderClaims{ProviderID: "idp", ProviderType: OIDCType, UserID: "1234"}, claims.Provider)
+	// The claims expire with the ID token
+	assert.Equal(t, idp.claims["exp"], claims.ExpiresAt)
+
+	refreshed, err := provider.Refresh(context.Background(), &corev2.Claims{
+		StandardClaims: jwt.StandardClaims{Subject: claims.Subject},
+		Groups:         append(claims.Groups, "system:users"),
+		Provider:       claims.Provider,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, claims.Subject, refreshed.Subject)
+	assert.Equal(t, claims.Groups, refreshed.Groups)
+	assert.Equal(t, claims.Provider, refreshed.Provider)
+
+	provider.UsernamePrefix = "sso-"
+	provider.GroupsPrefix = "sso-"
+	claims, err = provider.Exchange(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "sso-jane@example.com", claims.Subject)
+	assert.Equal(t, []string{"sso-ops", "sso-dev"}, claims.Groups)
+}
+
+func TestOIDCExchangeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		nonce  string
+		claims func(jwt.MapClaims)
+	}{
+		{
+			name: "bad code",
+			code: "bad",
+		},
+		{
+			name:  "nonce mismatch",
+			code:  "good",
+			nonce: "other",
+		},
+		{
+			name:   "wrong audience",
+			code:   "good",
+			claims: func(c jwt.MapClaims) { c["aud"] = "other" },
+		},
+		{
+			name:   "wrong issuer",
+			code:   "good",
+			claims: func(c jwt.MapClaims) { c["iss"] = "https://example.com" },
+		},
+		{
+			name:   "expired",
+			code:   "good",
+			claims: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() },
+		},
+		{
+			name:   "missing username",
+			code:   "good",
+			claims: func(c jwt.MapClaims) { delete(c, "email") },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newMockIdP(t)
+			if tt.claims != nil {
+				tt.claims(idp.claims)
+			}
+			provider := testOIDC(idp)
+			_, err := provider.Exchange(context.Background(), &OIDCTokenRequest{
+				Code:        tt.code,
+				RedirectURI: "http://127.0.0.1:8000/callback",
+				Nonce:       tt.nonce,
+			})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestOIDCExchangeBadSignature(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := testOIDC(idp)
+	// sign the ID token with a key that isn't published by the IdP
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	idp.signer = other
+
+	_, err = provider.Exchange(context.Background(), &OIDCTokenRequest{
+		Code:        "good",
+		RedirectURI: "http://127.0.0.1:8000/callback",
+	})
+	assert.Error(t, err)
+}
+
+func TestOIDCValidate(t *testing.T) {
+	valid := func() *OIDC {
+		return &OIDC{
+			Metadata:     corev2.ObjectMeta{Name: "idp"},
+			Server:       "https://idp.example.com",
+			ClientID:     "sensu",
+			ClientSecret: "hunter2",
+		}
+	}
+	assert.NoError(t, valid().Validate())
+
+	invalid := []func(*OIDC){
+		func(o *OIDC) { o.Metadata.Name = "" },
+		func(o *OIDC) { o.Metadata.Name = "basic" },
+		func(o *OIDC) { o.Metadata.Namespace = "default" },
+		func(o *OIDC) { o.Server = "idp.example.com" },
+		func(o *OIDC) { o.ClientID = "" },
+		func(o *OIDC) { o.ClientSecret = "" },
+	}
+	for i, fn := range invalid {
+		o := valid()
+		fn(o)
+		assert.Error(t, o.Validate(), i)
+	}
+}
+
+func TestOIDCRedact(t *testing.T) {
+	provider := &OIDC{ClientID: "sensu", ClientSecret: "hunter2"}
+	provider.Redact()
+	assert.Equal(t, "sensu", provider.ClientID)
+	assert.Empty(t, provider.ClientSecret)
+}
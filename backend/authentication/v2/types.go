@@ -0,0 +1,66 @@
+// Package v2 contains the authentication/v2 API types: the external
+// authentication providers that can be added to the authenticator next to the
+// built-in basic provider.
+package v2
+
+import (
+	"errors"
+
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	apitools "github.com/sensu/sensu-api-tools"
+	"github.com/sensu/sensu-go/backend/authentication/providers/basic"
+)
+
+const (
+	// APIVersion is the API version of the types in this package.
+	APIVersion = "authentication/v2"
+
+	// URLPrefix is the URL prefix of the authentication/v2 API.
+	URLPrefix = "/api/authentication/v2"
+
+	// ProvidersResource is the RBAC name of the authentication providers.
+	ProvidersResource = "authproviders"
+)
+
+// ErrInvalidRequest is returned when a login request sent to a provider is
+// malformed.
+var ErrInvalidRequest = errors.New("invalid authentication request")
+
+// Redacter is implemented by the providers with secrets, which are not
+// returned by the API.
+type Redacter interface {
+	// Redact removes the secrets of the provider.
+	Redact()
+}
+
+func init() {
+	apitools.RegisterType(APIVersion, new(OIDC))
+	apitools.RegisterType(APIVersion, new(LDAP))
+}
+
+func validateMetadata(meta corev2.ObjectMeta) error {
+	if err := corev2.ValidateName(meta.Name); err != nil {
+		return errors.New("provider name " + err.Error())
+	}
+	if meta.Name == basic.Type {
+		return errors.New("provider name is reserved for the basic provider")
+	}
+	if meta.Namespace != "" {
+		return errors.New("providers cannot be namespaced")
+	}
+	return nil
+}
+
+// ProviderFields returns a set of fields that represent the provider for the
+// purposes of field selectors.
+func ProviderFields(r corev3.Resource) map[string]string {
+	meta := r.GetMetadata()
+	fields := map[string]string{
+		"provider.name": meta.Name,
+	}
+	for k, v := range meta.Labels {
+		fields["provider.labels."+k] = v
+	}
+	return fields
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/lib/pq"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sensu/sensu-go/backend/queue"
+	"github.com/sensu/sensu-go/backend/quota"
 	"github.com/sensu/sensu-go/backend/resource"
 	"github.com/spf13/viper"
 	"golang.org/x/time/rate"
@@ -18,6 +19,7 @@ import (
 	corev2 "github.com/sensu/core/v2"
 	"github.com/sensu/sensu-go/asset"
 	"github.com/sensu/sensu-go/backend/agentd"
+	"github.com/sensu/sensu-go/backend/aggregated"
 	"github.com/sensu/sensu-go/backend/api"
 	"github.com/sensu/sensu-go/backend/apid"
 	"github.com/sensu/sensu-go/backend/apid/actions"
@@ -25,6 +27,7 @@ import (
 	"github.com/sensu/sensu-go/backend/apid/routers"
 	"github.com/sensu/sensu-go/backend/authentication"
 	"github.com/sensu/sensu-go/backend/authentication/jwt"
+	authloader "github.com/sensu/sensu-go/backend/authentication/loader"
 	"github.com/sensu/sensu-go/backend/authentication/providers/basic"
 	"github.com/sensu/sensu-go/backend/authorization/rbac"
 	"github.com/sensu/sensu-go/backend/daemon"
@@ -41,8 +44,10 @@ import (
 	"github.com/sensu/sensu-go/backend/ringv2"
 	"github.com/sensu/sensu-go/backend/schedulerd"
 	"github.com/sensu/sensu-go/backend/secrets"
+	"github.com/sensu/sensu-go/backend/secrets/loader"
 	"github.com/sensu/sensu-go/backend/store/postgres"
 	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	"github.com/sensu/sensu-go/backend/stream"
 	"github.com/sensu/sensu-go/backend/tessend"
 	"github.com/sensu/sensu-go/command"
 	"github.com/sensu/sensu-go/metrics"
@@ -171,15 +176,44 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 	b.Bus = bus
 	b.Daemons = append(b.Daemons, bus)
 
-	b.Store = postgres.NewStore(postgres.StoreConfig{
+	// Initialize the postgres notification bus, which drives the store
+	// watchers and the rings
+	pgDSN := b.Cfg.Store.PostgresStore.DSN
+	listener := pq.NewListener(pgDSN, time.Second, time.Minute, errorReporter)
+	pgBus := postgres.NewBus(ctx, listener)
+
+	// Notify the events processed by this backend to the event streams of
+	// every backend, only while some of them stream the events
+	b.Daemons = append(b.Daemons, postgres.NewEventNotifier(pgdb, bus))
+
+	pgStore := postgres.NewStore(postgres.StoreConfig{
 		DB:                pgdb,
-		WatchInterval:     time.Second,
 		WatchTxnWindow:    5 * time.Second,
 		Bus:               bus,
+		Notifications:     pgBus,
 		MaxTPS:            config.Store.PostgresStore.MaxTPS,
 		DisableEventCache: config.Store.PostgresStore.DisableEventCache,
+		EventHistory:      config.Store.PostgresStore.EventHistory,
 	})
 
+	// The namespace quotas are enforced by the store on the creation of
+	// resources, whichever daemon creates them, and by eventd on the ingest
+	// of events
+	quotas := quota.NewEnforcer(pgStore)
+	b.Store = quota.NewStore(pgStore, quotas)
+	if config.Store.PostgresStore.EventHistory {
+		pruner := &postgres.EventHistoryPruner{
+			Store: postgres.NewEventHistoryStore(pgdb),
+			Executor: &postgres.SynchronizedExecutor{
+				DB:              pgdb,
+				CheckinInterval: 30 * time.Second,
+			},
+			MaxAge:    config.Store.PostgresStore.EventHistoryMaxAge,
+			MaxEvents: config.Store.PostgresStore.EventHistoryMaxEvents,
+		}
+		go pruner.Run(ctx)
+	}
+
 	jwtClient := api.JWT{Store: b.Store}
 	jwtSecret, err := jwtClient.GetSecret(ctx)
 	if err != nil {
@@ -219,6 +253,12 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 
 	// Initialize the secrets provider manager
 	b.SecretsProviderManager = secrets.NewProviderManager(br)
+	b.SecretsProviderManager.Getter = &loader.Getter{Store: b.Store}
+	secretsLoader := loader.New(b.Store, b.SecretsProviderManager)
+	if err := secretsLoader.Load(ctx); err != nil {
+		return nil, fmt.Errorf("error initializing secrets providers: %s", err)
+	}
+	go secretsLoader.Watch(ctx)
 
 	auth := &rbac.Authorizer{Store: b.Store}
 
@@ -248,21 +288,31 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 	hasMetricsFilterAdapter := &filter.HasMetricsAdapter{}
 	isIncidentFilterAdapter := &filter.IsIncidentAdapter{}
 	notSilencedFilterAdapter := &filter.NotSilencedAdapter{}
+	notDependencyFailedFilterAdapter := &filter.NotDependencyFailedAdapter{}
 
 	b.PipelineAdapterV1.FilterAdapters = []pipeline.FilterAdapter{
 		legacyFilterAdapter,
 		hasMetricsFilterAdapter,
 		isIncidentFilterAdapter,
 		notSilencedFilterAdapter,
+		notDependencyFailedFilterAdapter,
 	}
 
-	// Initialize PipelineAdapterV1 mutator adapters
+	// Initialize PipelineAdapterV1 mutator adapters. Javascript mutators are
+	// core/v2 mutators, they are dispatched by the legacy mutator adapter.
+	javascriptMutatorAdapter := &mutator.JavascriptAdapter{
+		AssetGetter:  assetGetter,
+		Store:        b.Store,
+		StoreTimeout: storeTimeout,
+		Timeout:      mutator.DefaultJavascriptTimeout,
+	}
 	legacyMutatorAdapter := &mutator.LegacyAdapter{
 		AssetGetter:            assetGetter,
 		Executor:               command.NewExecutor(),
 		SecretsProviderManager: b.SecretsProviderManager,
 		Store:                  b.Store,
 		StoreTimeout:           storeTimeout,
+		JavascriptAdapter:      javascriptMutatorAdapter,
 	}
 	onlyCheckOutputMutatorAdapter := &mutator.OnlyCheckOutputAdapter{}
 	jsonMutatorAdapter := &mutator.JSONAdapter{}
@@ -274,6 +324,9 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 	}
 
 	// Initialize PipelineAdapterV1 handler adapters
+	// Failed handler executions are retried from a queue shared by all the
+	// backends
+	pgQueue := postgres.NewQueue(pgdb)
 	legacyHandlerAdapter := &handler.LegacyAdapter{
 		AssetGetter:            assetGetter,
 		Executor:               command.NewExecutor(),
@@ -281,10 +334,19 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 		SecretsProviderManager: b.SecretsProviderManager,
 		Store:                  b.Store,
 		StoreTimeout:           storeTimeout,
+		Queue:                  pgQueue,
+	}
+	b.Daemons = append(b.Daemons, handler.NewRetryDaemon(legacyHandlerAdapter))
+
+	httpHandlerAdapter := &handler.HTTPAdapter{
+		SecretsProviderManager: b.SecretsProviderManager,
+		Store:                  b.Store,
+		StoreTimeout:           storeTimeout,
 	}
 
 	b.PipelineAdapterV1.HandlerAdapters = []pipeline.HandlerAdapter{
 		legacyHandlerAdapter,
+		httpHandlerAdapter,
 	}
 
 	pipelineDaemon.AddAdapter(&b.PipelineAdapterV1)
@@ -311,6 +373,7 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 			OperatorMonitor:     pgOPC,
 			OperatorQueryer:     pgOPC,
 			BackendName:         b.Cfg.Name,
+			QuotaEnforcer:       quotas,
 		},
 	)
 	if err != nil {
@@ -319,7 +382,6 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 	b.Daemons = append(b.Daemons, event)
 
 	// Initialize work queue
-	pgQueue := postgres.NewQueue(pgdb)
 	workQueue := queue.NewClusteredQueue(pgQueue, b.Cfg.Name, pgOPC)
 
 	// Initialize schedulerd
@@ -362,6 +424,21 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 	}
 	b.Daemons = append(b.Daemons, keepalive)
 
+	// Initialize aggregated
+	aggregate, err := aggregated.New(aggregated.Config{
+		Store:             b.Store,
+		Bus:               bus,
+		OperatorConcierge: pgOPC,
+		OperatorMonitor:   pgOPC,
+		OperatorQueryer:   pgOPC,
+		BackendName:       b.Cfg.Name,
+		StoreTimeout:      2 * time.Minute,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error initializing %s: %s", aggregate.Name(), err)
+	}
+	b.Daemons = append(b.Daemons, aggregate)
+
 	// Prepare the authentication providers
 	authenticator := &authentication.Authenticator{}
 	provider := &basic.Provider{
@@ -369,6 +446,11 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 		Store:      b.Store,
 	}
 	authenticator.AddProvider(provider)
+	authLoader := authloader.New(b.Store, authenticator)
+	if err := authLoader.Load(ctx); err != nil {
+		return nil, fmt.Errorf("error initializing authentication providers: %s", err)
+	}
+	go authLoader.Watch(ctx)
 
 	var clusterVersion string
 
@@ -382,27 +464,33 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 
 	// Initialize GraphQL service
 	b.GraphQLService, err = graphql.NewService(graphql.ServiceConfig{
-		AssetClient:       api.NewAssetClient(b.Store, auth),
-		CheckClient:       api.NewCheckClient(b.Store, actions.NewCheckController(b.Store, workQueue), auth),
-		EntityClient:      api.NewEntityClient(b.Store, auth),
-		EventClient:       api.NewEventClient(b.Store.GetEventStore(), auth, bus),
-		EventFilterClient: api.NewEventFilterClient(b.Store, auth),
-		HandlerClient:     api.NewHandlerClient(b.Store, auth),
-		HealthController:  actions.HealthController{},
-		MutatorClient:     api.NewMutatorClient(b.Store, auth),
-		SilencedClient:    api.NewSilencedClient(b.Store.GetSilencesStore(), auth),
-		NamespaceClient:   api.NewNamespaceClient(b.Store, auth),
-		HookClient:        api.NewHookConfigClient(b.Store, auth),
-		UserClient:        api.NewUserClient(b.Store, auth),
-		RBACClient:        api.NewRBACClient(b.Store, auth),
-		VersionController: actions.NewVersionController(clusterVersion),
-		MetricGatherer:    prometheus.DefaultGatherer,
-		GenericClient:     &api.GenericClient{Store: b.Store, Auth: auth},
+		AssetClient:        api.NewAssetClient(b.Store, auth),
+		CheckClient:        api.NewCheckClient(b.Store, actions.NewCheckController(b.Store, workQueue), auth),
+		EntityClient:       api.NewEntityClient(b.Store, auth),
+		EventClient:        api.NewEventClient(b.Store.GetEventStore(), auth, bus),
+		EventHistoryClient: api.NewEventHistoryClient(b.Store.GetEventHistoryStore(), auth),
+		EventFilterClient:  api.NewEventFilterClient(b.Store, auth),
+		HandlerClient:      api.NewHandlerClient(b.Store, auth),
+		HealthController:   actions.HealthController{},
+		MutatorClient:      api.NewMutatorClient(b.Store, auth),
+		SilencedClient:     api.NewSilencedClient(b.Store.GetSilencesStore(), auth),
+		NamespaceClient:    api.NewNamespaceClient(b.Store, auth),
+		HookClient:         api.NewHookConfigClient(b.Store, auth),
+		UserClient:         api.NewUserClient(b.Store, auth),
+		RBACClient:         api.NewRBACClient(b.Store, auth),
+		VersionController:  actions.NewVersionController(clusterVersion),
+		MetricGatherer:     prometheus.DefaultGatherer,
+		GenericClient:      &api.GenericClient{Store: b.Store, Auth: auth},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("error initializing graphql.Service: %s", err)
 	}
 
+	auditLogger, err := newAuditLogger(ctx, bus, pgdb, config)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing the audit log: %s", err)
+	}
+
 	// Initialize apid
 	b.APIDConfig = apid.Config{
 		ListenAddress:  config.APIListenAddress,
@@ -416,6 +504,16 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 		ClusterVersion: clusterVersion,
 		GraphQLService: b.GraphQLService,
 		Queue:          workQueue,
+		AuditLogger:    auditLogger,
+		StreamHub:      stream.NewHub(stream.NewFeeds(postgres.NewEventWatcher(pgdb, pgBus), b.Store)),
+		RetryQueue:     pgQueue,
+		PipelineTracer: &b.PipelineAdapterV1,
+		QuotaEnforcer:  quotas,
+	}
+	if config.AuditLogPostgres {
+		// The audit records are only served when they are written to
+		// postgresql.
+		b.APIDConfig.AuditStore = postgres.NewAuditLogStore(pgdb)
 	}
 	newApi, err := apid.New(b.APIDConfig)
 	if err != nil {
@@ -424,10 +522,6 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 	b.Daemons = append(b.Daemons, newApi)
 
 	// Initialize tessend
-	pgDSN := b.Cfg.Store.PostgresStore.DSN
-	listener := pq.NewListener(pgDSN, time.Second, time.Minute, errorReporter)
-	pgBus := postgres.NewBus(ctx, listener)
-
 	ringPool := ringv2.NewRingPool(func(path string) ringv2.Interface {
 		ring, err := postgres.NewRing(pgdb, pgBus, path)
 		if err != nil {
@@ -458,21 +552,25 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 
 	// Initialize agentd
 	agent, err := agentd.New(agentd.Config{
-		Host:          config.AgentHost,
-		Port:          config.AgentPort,
-		Bus:           bus,
-		Store:         b.Store,
-		TLS:           config.AgentTLSOptions,
-		WriteTimeout:  config.AgentWriteTimeout,
-		Watcher:       entityConfigWatcher,
-		HealthRouter:  b.HealthRouter,
-		Authenticator: authenticator,
+		Host:            config.AgentHost,
+		Port:            config.AgentPort,
+		Bus:             bus,
+		Store:           b.Store,
+		TLS:             config.AgentTLSOptions,
+		WriteTimeout:    config.AgentWriteTimeout,
+		Watcher:         entityConfigWatcher,
+		HealthRouter:    b.HealthRouter,
+		Authenticator:   authenticator,
+		CertificateAuth: config.AgentCertificateAuth,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("error initializing %s: %s", agent.Name(), err)
 	}
 	b.Daemons = append(b.Daemons, agent)
 
+	// Secrets are only transmitted to agents authenticated with mutual TLS
+	b.SecretsProviderManager.TLSenabled = agent.MutualTLS()
+
 	return b, nil
 }
 
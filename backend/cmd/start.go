@@ -22,6 +22,7 @@ import (
 	corev2 "github.com/sensu/core/v2"
 	"github.com/sensu/sensu-go/asset"
 	"github.com/sensu/sensu-go/backend"
+	"github.com/sensu/sensu-go/backend/agentd"
 	"github.com/sensu/sensu-go/util/path"
 	stringsutil "github.com/sensu/sensu-go/util/strings"
 	"github.com/sirupsen/logrus"
@@ -38,6 +39,7 @@ var DeprecateDashboardFlags = true
 var (
 	annotations               map[string]string
 	labels                    map[string]string
+	agentAuthGroupFields      map[string]string
 	configFileDefaultLocation = filepath.Join(path.SystemConfigDir(), "backend.yml")
 )
 
@@ -71,10 +73,19 @@ const (
 	flagAnnotations           = "annotations"
 	flagName                  = "name"
 
+	// Agent certificate authentication
+	flagAgentAuthTrustedCAFile = "agent-auth-trusted-ca-file" // CA bundle the agent certificates are verified against
+	flagAgentAuthGroups        = "agent-auth-groups"          // groups of the agents authenticated by certificate
+	flagAgentAuthGroupFields   = "agent-auth-group-fields"    // certificate fields mapped to groups, with their prefixes
+	flagAgentAuthCRLFile       = "agent-auth-crl-file"        // revocation list of the agent certificates
+
 	// Postgres store
-	flagPGDSN                = "pg-dsn"                  // postgresql connection string
-	flagEventCacheWriteLimit = "event-cache-write-limit" // maximum number of tps that event cache will write
-	flagDisableEventCache    = "disable-event-cache"     // don't cache events, always write through to postgresql
+	flagPGDSN                 = "pg-dsn"                   // postgresql connection string
+	flagEventCacheWriteLimit  = "event-cache-write-limit"  // maximum number of tps that event cache will write
+	flagDisableEventCache     = "disable-event-cache"      // don't cache events, always write through to postgresql
+	flagEventHistory          = "event-history"            // record every event in the event history
+	flagEventHistoryMaxAge    = "event-history-max-age"    // maximum age of the events in the event history
+	flagEventHistoryMaxEvents = "event-history-max-events" // maximum number of events in the event history per entity and check
 
 	// Metric logging flags
 	flagDisablePlatformMetrics         = "disable-platform-metrics"
@@ -93,6 +104,12 @@ const (
 	// flagEventLogParallelEncoders used to indicate parallel encoders should be used for event logging
 	flagEventLogParallelEncoders = "event-log-parallel-encoders"
 
+	// flagAuditLogFile indicates the path to the audit log file
+	flagAuditLogFile = "audit-log-file"
+
+	// flagAuditLogPostgres indicates that audit records should be written to postgresql
+	flagAuditLogPostgres = "audit-log-postgres"
+
 	// Default values
 
 	// Start command usage template
@@ -185,12 +202,18 @@ func StartCommand(initialize InitializeFunc) *cobra.Command {
 				EventLogBufferWait:             viper.GetDuration(flagEventLogBufferWait),
 				EventLogFile:                   viper.GetString(flagEventLogFile),
 				EventLogParallelEncoders:       viper.GetBool(flagEventLogParallelEncoders),
+				AuditLogFile:                   viper.GetString(flagAuditLogFile),
+				AuditLogPostgres:               viper.GetBool(flagAuditLogPostgres),
 
 				Store: backend.StoreConfig{
 					PostgresStore: postgres.Config{
 						DSN:               viper.GetString(flagPGDSN),
 						MaxTPS:            viper.GetInt(flagEventCacheWriteLimit),
 						DisableEventCache: viper.GetBool(flagDisableEventCache),
+
+						EventHistory:          viper.GetBool(flagEventHistory),
+						EventHistoryMaxAge:    viper.GetDuration(flagEventHistoryMaxAge),
+						EventHistoryMaxEvents: viper.GetInt(flagEventHistoryMaxEvents),
 					},
 				},
 			}
@@ -227,6 +250,29 @@ func StartCommand(initialize InitializeFunc) *cobra.Command {
 					flagCertFile, flagKeyFile)
 			}
 
+			// Agent certificate authentication, which uses its own CA bundle
+			if agentCAFile := viper.GetString(flagAgentAuthTrustedCAFile); agentCAFile != "" {
+				if cfg.TLS == nil {
+					return fmt.Errorf(
+						"agent certificate authentication requires flags --%s & --%s",
+						flagCertFile, flagKeyFile)
+				}
+				cfg.AgentTLSOptions = &corev2.TLSOptions{
+					CertFile:           certFile,
+					KeyFile:            keyFile,
+					TrustedCAFile:      agentCAFile,
+					InsecureSkipVerify: insecureSkipTLSVerify,
+				}
+				cfg.AgentCertificateAuth = &agentd.CertificateAuth{
+					Groups:      viper.GetStringSlice(flagAgentAuthGroups),
+					GroupFields: viper.GetStringMapString(flagAgentAuthGroupFields),
+					CRLFile:     viper.GetString(flagAgentAuthCRLFile),
+				}
+				if flag := cmd.Flags().Lookup(flagAgentAuthGroupFields); flag != nil && flag.Changed {
+					cfg.AgentCertificateAuth.GroupFields = agentAuthGroupFields
+				}
+			}
+
 			if cf, kf := len(cfg.DashboardTLSCertFile) == 0, len(cfg.DashboardTLSKeyFile) == 0; cf != kf {
 				return fmt.Errorf(
 					"dashboard tls configuration error, both flags --%s and --%s are required",
@@ -334,6 +380,9 @@ func handleConfig(cmd *cobra.Command, arguments []string, server bool) error {
 		viper.SetDefault(flagKeyFile, "")
 		viper.SetDefault(flagTrustedCAFile, "")
 		viper.SetDefault(flagInsecureSkipTLSVerify, false)
+		viper.SetDefault(flagAgentAuthTrustedCAFile, "")
+		viper.SetDefault(flagAgentAuthGroups, agentd.DefaultCertificateGroups)
+		viper.SetDefault(flagAgentAuthCRLFile, "")
 		viper.SetDefault(flagLogLevel, "warn")
 		viper.SetDefault(backend.FlagEventdWorkers, 100)
 		viper.SetDefault(backend.FlagEventdBufferSize, 1000)
@@ -349,8 +398,13 @@ func handleConfig(cmd *cobra.Command, arguments []string, server bool) error {
 		viper.SetDefault(flagEventLogBufferSize, 100000)
 		viper.SetDefault(flagEventLogFile, "")
 		viper.SetDefault(flagEventLogParallelEncoders, false)
+		viper.SetDefault(flagAuditLogFile, "")
+		viper.SetDefault(flagAuditLogPostgres, false)
 		viper.SetDefault(flagEventCacheWriteLimit, 1000)
 		viper.SetDefault(flagDisableEventCache, false)
+		viper.SetDefault(flagEventHistory, false)
+		viper.SetDefault(flagEventHistoryMaxAge, 7*24*time.Hour)
+		viper.SetDefault(flagEventHistoryMaxEvents, 1000)
 
 		backendName, err := os.Hostname()
 		if err != nil {
@@ -414,6 +468,15 @@ func flagSet(server bool) *pflag.FlagSet {
 	flagSet.Bool(flagDisableEventCache, viper.GetBool(flagDisableEventCache), "disable caching events, write events directly to postgresql")
 	_ = flagSet.SetAnnotation(flagDisableEventCache, "categories", []string{"store"})
 
+	flagSet.Bool(flagEventHistory, viper.GetBool(flagEventHistory), "record every event in the postgresql event history")
+	_ = flagSet.SetAnnotation(flagEventHistory, "categories", []string{"store"})
+
+	flagSet.Duration(flagEventHistoryMaxAge, viper.GetDuration(flagEventHistoryMaxAge), "maximum age of the events in the event history, 0 to disable")
+	_ = flagSet.SetAnnotation(flagEventHistoryMaxAge, "categories", []string{"store"})
+
+	flagSet.Int(flagEventHistoryMaxEvents, viper.GetInt(flagEventHistoryMaxEvents), "maximum number of events in the event history for each entity and check, 0 to disable")
+	_ = flagSet.SetAnnotation(flagEventHistoryMaxEvents, "categories", []string{"store"})
+
 	if server {
 		// Main Flags
 		flagSet.String(flagName, viper.GetString(flagName), "backend name")
@@ -436,6 +499,10 @@ func flagSet(server bool) *pflag.FlagSet {
 		flagSet.String(flagKeyFile, viper.GetString(flagKeyFile), "TLS certificate key in PEM format")
 		flagSet.String(flagTrustedCAFile, viper.GetString(flagTrustedCAFile), "TLS CA certificate bundle in PEM format")
 		flagSet.Bool(flagInsecureSkipTLSVerify, viper.GetBool(flagInsecureSkipTLSVerify), "skip TLS verification (not recommended!)")
+		flagSet.String(flagAgentAuthTrustedCAFile, viper.GetString(flagAgentAuthTrustedCAFile), "TLS CA certificate bundle in PEM format, enables the authentication of agents by their client certificates")
+		flagSet.StringSlice(flagAgentAuthGroups, viper.GetStringSlice(flagAgentAuthGroups), "groups of the agents authenticated by certificate")
+		flagSet.StringToStringVar(&agentAuthGroupFields, flagAgentAuthGroupFields, nil, "map of agent certificate fields [cn, o, ou, dns, uri, email] to non-empty group prefixes, each field value adding a group")
+		flagSet.String(flagAgentAuthCRLFile, viper.GetString(flagAgentAuthCRLFile), "agent certificate revocation list in PEM or DER format")
 		flagSet.Bool(flagDebug, false, "enable debugging and profiling features")
 		flagSet.String(flagLogLevel, viper.GetString(flagLogLevel), "logging level [panic, fatal, error, warn, info, debug, trace]")
 		flagSet.Int(backend.FlagEventdWorkers, viper.GetInt(backend.FlagEventdWorkers), "number of workers spawned for processing incoming events")
@@ -456,6 +523,9 @@ func flagSet(server bool) *pflag.FlagSet {
 		_ = flagSet.String(flagEventLogFile, "", "path to the event log file")
 		_ = flagSet.Bool(flagEventLogParallelEncoders, false, "use parallel JSON encoding for the event log")
 
+		_ = flagSet.String(flagAuditLogFile, "", "path to the audit log file of the API requests that mutate resources")
+		_ = flagSet.Bool(flagAuditLogPostgres, false, "write the audit log of the API requests that mutate resources to postgresql")
+
 		// Use a default value of 100,000 messages for the buffer. A serialized event
 		// takes a minimum of around 1300 bytes, so once full the buffer ring could
 		// require about 130MB of memory.
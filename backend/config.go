@@ -4,6 +4,7 @@ import (
 	"time"
 
 	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/agentd"
 	"github.com/sensu/sensu-go/backend/licensing"
 	"github.com/sensu/sensu-go/backend/store/postgres"
 	"golang.org/x/time/rate"
@@ -53,6 +54,10 @@ type Config struct {
 	AgentTLSOptions   *corev2.TLSOptions
 	AgentWriteTimeout int
 
+	// AgentCertificateAuth authenticates the agents by their TLS client
+	// certificates, if not nil.
+	AgentCertificateAuth *agentd.CertificateAuth
+
 	// Apid Configuration
 	APIListenAddress string
 	APIRequestLimit  int64
@@ -96,5 +101,12 @@ type Config struct {
 	EventLogFile             string
 	EventLogParallelEncoders bool
 
+	// AuditLogFile is the path to the audit log file. The audit log is not
+	// written to a file if it is empty.
+	AuditLogFile string
+
+	// AuditLogPostgres enables writing the audit log to postgresql.
+	AuditLogPostgres bool
+
 	Store StoreConfig
 }
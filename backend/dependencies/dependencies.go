@@ -0,0 +1,262 @@
+// Package dependencies evaluates the dependencies declared by checks and
+// entities, so that the events of a check that fails because of a failing
+// parent, like a router, can be told apart and filtered out.
+//
+// Dependencies are declared with annotations on a check or an entity. The
+// sensu.io/dependencies annotation is a comma-separated list of entity/check
+// references; a reference without an entity names a check of the same entity.
+// The sensu.io/dependency_selector annotation is a field selector matched
+// against the events of the namespace, such as
+// "event.labels.role == router && event.check.name == ping".
+package dependencies
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/selector"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+const (
+	// DependenciesAnnotation declares the events a check or an entity depends
+	// on, as a comma-separated list of entity/check references.
+	DependenciesAnnotation = "sensu.io/dependencies"
+
+	// DependencySelectorAnnotation declares the events a check or an entity
+	// depends on, as a field selector on the events of the namespace.
+	DependencySelectorAnnotation = "sensu.io/dependency_selector"
+
+	// FailedDependenciesAnnotation is set on the events whose dependencies
+	// are failing, to the comma-separated list of the failing dependencies.
+	FailedDependenciesAnnotation = "sensu.io/failed_dependencies"
+
+	// DefaultSelectionTTL is the default duration for which an Evaluator
+	// caches the failing dependencies selected by a dependency selector.
+	DefaultSelectionTTL = 5 * time.Second
+
+	// MaxSelectedDependencies is the maximum number of failing dependencies
+	// selected by a dependency selector.
+	MaxSelectedDependencies = 100
+)
+
+// Declaration is the set of dependencies declared by an event's check and
+// entity.
+type Declaration struct {
+	// References are the entity/check names of the dependencies.
+	References []string
+
+	// Selectors select the dependencies among the events of the namespace.
+	Selectors []*selector.Selector
+
+	// selectorInputs are the annotations the selectors were parsed from.
+	selectorInputs []string
+}
+
+// IsEmpty returns whether no dependency is declared.
+func (d Declaration) IsEmpty() bool {
+	return len(d.References) == 0 && len(d.Selectors) == 0
+}
+
+// Declared returns the dependencies declared by the annotations of the check
+// and the entity of an event.
+func Declared(event *corev2.Event) (Declaration, error) {
+	var decl Declaration
+	if !event.HasCheck() || event.Entity == nil {
+		return decl, nil
+	}
+	for _, annotations := range []map[string]string{event.Check.Annotations, event.Entity.Annotations} {
+		for _, ref := range strings.Split(annotations[DependenciesAnnotation], ",") {
+			ref = strings.TrimSpace(ref)
+			if ref == "" {
+				continue
+			}
+			if !strings.Contains(ref, "/") {
+				ref = path.Join(event.Entity.Name, ref)
+			}
+			decl.References = append(decl.References, ref)
+		}
+		if input := strings.TrimSpace(annotations[DependencySelectorAnnotation]); input != "" {
+			sel, err := selector.ParseFieldSelector(input)
+			if err != nil {
+				return decl, fmt.Errorf("invalid %s annotation: %s", DependencySelectorAnnotation, err)
+			}
+			decl.Selectors = append(decl.Selectors, sel)
+			decl.selectorInputs = append(decl.selectorInputs, input)
+		}
+	}
+	return decl, nil
+}
+
+// Evaluate returns the failing dependencies of an event, sorted by name. A
+// dependency is failing when its latest event has a non-zero status. The
+// event itself is never one of its dependencies.
+func Evaluate(ctx context.Context, events store.EventStore, event *corev2.Event) ([]string, error) {
+	return (&Evaluator{Events: events}).Evaluate(ctx, event)
+}
+
+// Evaluator evaluates the dependencies of events. The failing dependencies
+// selected by a dependency selector are cached for TTL, so that the events
+// that share a selector, like the events of the entities behind a router,
+// share its query.
+type Evaluator struct {
+	// Events is the store of the dependencies.
+	Events store.EventStore
+
+	// TTL is the duration for which the selected dependencies are cached.
+	// They aren't cached if it's zero.
+	TTL time.Duration
+
+	mu         sync.Mutex
+	selections map[string]selection
+}
+
+// selection is the failing dependencies selected by a dependency selector.
+type selection struct {
+	names   []string
+	expires time.Time
+}
+
+// NewEvaluator creates an Evaluator that caches the selected dependencies for
+// DefaultSelectionTTL.
+func NewEvaluator(events store.EventStore) *Evaluator {
+	return &Evaluator{
+		Events: events,
+		TTL:    DefaultSelectionTTL,
+	}
+}
+
+// Evaluate returns the failing dependencies of an event, sorted by name. A
+// dependency is failing when its latest event has a non-zero status. The
+// event itself is never one of its dependencies.
+func (e *Evaluator) Evaluate(ctx context.Context, event *corev2.Event) ([]string, error) {
+	decl, err := Declared(event)
+	if err != nil || decl.IsEmpty() {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, corev2.NamespaceKey, event.Entity.Namespace)
+	self := path.Join(event.Entity.Name, event.Check.Name)
+
+	failed := make(map[string]struct{})
+	for _, ref := range decl.References {
+		if ref == self {
+			continue
+		}
+		if _, ok := failed[ref]; ok {
+			continue
+		}
+		entity, check := path.Split(ref)
+		dependency, err := e.Events.GetEventByEntityCheck(ctx, strings.TrimSuffix(entity, "/"), check)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get dependency %s: %s", ref, err)
+		}
+		if isFailing(dependency) {
+			failed[ref] = struct{}{}
+		}
+	}
+	for i, sel := range decl.Selectors {
+		key := event.Entity.Namespace + "\n" + decl.selectorInputs[i]
+		names, err := e.selected(ctx, key, sel)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if name != self {
+				failed[name] = struct{}{}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(failed))
+	for name := range failed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// selected returns the failing dependencies selected by the selector, from
+// the cache if they were selected less than TTL ago. Only the failing events
+// are queried, up to MaxSelectedDependencies of them.
+func (e *Evaluator) selected(ctx context.Context, key string, sel *selector.Selector) ([]string, error) {
+	now := time.Now()
+	if e.TTL > 0 {
+		e.mu.Lock()
+		cached, ok := e.selections[key]
+		e.mu.Unlock()
+		if ok && now.Before(cached.expires) {
+			return cached.names, nil
+		}
+	}
+
+	failing := selector.Merge(sel, &selector.Selector{
+		Operations: []selector.Operation{{
+			LValue:   "event.check.status",
+			Operator: selector.NotEqualOperator,
+			RValues:  []string{"0"},
+		}},
+	})
+	pred := &store.SelectionPredicate{Limit: MaxSelectedDependencies}
+	dependencies, err := e.Events.GetEvents(storev2.EventContextWithSelector(ctx, failing), pred)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't select dependencies: %s", err)
+	}
+	var names []string
+	for _, dependency := range dependencies {
+		if isFailing(dependency) && failing.Matches(dependency.Fields()) {
+			names = append(names, path.Join(dependency.Entity.Name, dependency.Check.Name))
+		}
+	}
+
+	if e.TTL > 0 {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if e.selections == nil {
+			e.selections = make(map[string]selection)
+		}
+		for key, cached := range e.selections {
+			if !now.Before(cached.expires) {
+				delete(e.selections, key)
+			}
+		}
+		e.selections[key] = selection{names: names, expires: now.Add(e.TTL)}
+	}
+	return names, nil
+}
+
+// Annotate records the failing dependencies of an event in its annotations,
+// or removes them if none are failing.
+func Annotate(event *corev2.Event, failed []string) {
+	if len(failed) == 0 {
+		delete(event.Annotations, FailedDependenciesAnnotation)
+		return
+	}
+	event.AddAnnotation(FailedDependenciesAnnotation, strings.Join(failed, ","))
+}
+
+// Failed returns the failing dependencies recorded in the annotations of an
+// event.
+func Failed(event *corev2.Event) []string {
+	value := event.Annotations[FailedDependenciesAnnotation]
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// IsFailed returns whether a dependency of the event was failing when it was
+// processed.
+func IsFailed(event *corev2.Event) bool {
+	return event.Annotations[FailedDependenciesAnnotation] != ""
+}
+
+func isFailing(event *corev2.Event) bool {
+	return event != nil && event.HasCheck() && event.Check.Status != 0
+}
@@ -0,0 +1,120 @@
+package dependencies
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	"github.com/sensu/sensu-go/testing/mockstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func fixtureEvent(entity, check string, status uint32) *corev2.Event {
+	event := corev2.FixtureEvent(entity, check)
+	event.Check.Status = status
+	return event
+}
+
+func TestDeclared(t *testing.T) {
+	event := fixtureEvent("web01", "http", 2)
+	decl, err := Declared(event)
+	require.NoError(t, err)
+	assert.True(t, decl.IsEmpty())
+
+	event.Check.Annotations = map[string]string{DependenciesAnnotation: "router01/ping, disk"}
+	event.Entity.Annotations = map[string]string{DependencySelectorAnnotation: "event.labels.role == router"}
+	decl, err = Declared(event)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"router01/ping", "web01/disk"}, decl.References)
+	require.Len(t, decl.Selectors, 1)
+	assert.True(t, decl.Selectors[0].Matches(map[string]string{"event.labels.role": "router"}))
+
+	event.Entity.Annotations[DependencySelectorAnnotation] = "role =="
+	_, err = Declared(event)
+	assert.Error(t, err)
+}
+
+func TestEvaluate(t *testing.T) {
+	event := fixtureEvent("web01", "http", 2)
+	event.Check.Annotations = map[string]string{DependenciesAnnotation: "router01/ping,db01/postgres,gone/ping,web01/http"}
+	event.Entity.Annotations = map[string]string{DependencySelectorAnnotation: "event.labels.role == switch"}
+
+	switch01 := fixtureEvent("switch01", "ping", 1)
+	switch01.Entity.Labels = map[string]string{"role": "switch"}
+	switch02 := fixtureEvent("switch02", "ping", 0)
+	switch02.Entity.Labels = map[string]string{"role": "switch"}
+	router02 := fixtureEvent("router02", "ping", 2)
+
+	st := &mockstore.MockStore{}
+	st.On("GetEventByEntityCheck", mock.Anything, "router01", "ping").Return(fixtureEvent("router01", "ping", 2), nil)
+	st.On("GetEventByEntityCheck", mock.Anything, "db01", "postgres").Return(fixtureEvent("db01", "postgres", 0), nil)
+	st.On("GetEventByEntityCheck", mock.Anything, "gone", "ping").Return((*corev2.Event)(nil), nil)
+	st.On("GetEvents", mock.Anything, mock.Anything).Return([]*corev2.Event{switch01, switch02, router02}, nil)
+
+	failed, err := Evaluate(context.Background(), st, event)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"router01/ping", "switch01/ping"}, failed)
+	st.AssertNotCalled(t, "GetEventByEntityCheck", mock.Anything, "web01", "http")
+
+	Annotate(event, failed)
+	assert.True(t, IsFailed(event))
+	assert.Equal(t, failed, Failed(event))
+	Annotate(event, nil)
+	assert.False(t, IsFailed(event))
+	assert.Nil(t, Failed(event))
+}
+
+func TestEvaluateError(t *testing.T) {
+	event := fixtureEvent("web01", "http", 2)
+	event.Check.Annotations = map[string]string{DependenciesAnnotation: "router01/ping"}
+
+	st := &mockstore.MockStore{}
+	st.On("GetEventByEntityCheck", mock.Anything, "router01", "ping").Return((*corev2.Event)(nil), errors.New("error"))
+	_, err := Evaluate(context.Background(), st, event)
+	assert.Error(t, err)
+
+	// Events without dependencies don't query the store
+	failed, err := Evaluate(context.Background(), &mockstore.MockStore{}, fixtureEvent("web01", "disk", 2))
+	require.NoError(t, err)
+	assert.Empty(t, failed)
+}
+
+func TestEvaluatorCachesSelections(t *testing.T) {
+	event := fixtureEvent("web01", "http", 2)
+	event.Entity.Annotations = map[string]string{DependencySelectorAnnotation: "event.labels.role == router"}
+	router01 := fixtureEvent("router01", "ping", 2)
+	router01.Entity.Labels = map[string]string{"role": "router"}
+
+	st := &mockstore.MockStore{}
+	st.On("GetEvents", mock.Anything, &store.SelectionPredicate{Limit: MaxSelectedDependencies}).Return([]*corev2.Event{router01}, nil).Once()
+	evaluator := NewEvaluator(st)
+
+	for i := 0; i < 2; i++ {
+		failed, err := evaluator.Evaluate(context.Background(), event)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"router01/ping"}, failed)
+	}
+	st.AssertNumberOfCalls(t, "GetEvents", 1)
+
+	// Only the failing events are selected
+	ctx := st.Calls[0].Arguments.Get(0).(context.Context)
+	sel := storev2.EventSelectorFromContext(ctx)
+	require.NotNil(t, sel)
+	assert.True(t, sel.Matches(router01.Fields()))
+	assert.False(t, sel.Matches(fixtureEvent("router01", "ping", 0).Fields()))
+
+	// The selections of other namespaces aren't shared
+	other := fixtureEvent("web01", "http", 2)
+	other.Entity.Namespace = "dev"
+	other.Entity.Annotations = event.Entity.Annotations
+	st.On("GetEvents", mock.Anything, mock.Anything).Return([]*corev2.Event{}, nil).Once()
+	failed, err := evaluator.Evaluate(context.Background(), other)
+	require.NoError(t, err)
+	assert.Empty(t, failed)
+	st.AssertNumberOfCalls(t, "GetEvents", 2)
+}
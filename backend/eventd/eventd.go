@@ -12,6 +12,7 @@ import (
 
 	corev2 "github.com/sensu/core/v2"
 	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/backend/dependencies"
 	"github.com/sensu/sensu-go/backend/messaging"
 	"github.com/sensu/sensu-go/backend/store"
 	storev2 "github.com/sensu/sensu-go/backend/store/v2"
@@ -175,6 +176,14 @@ type Eventd struct {
 	operatorMonitor     store.OperatorMonitor
 	operatorQueryer     store.OperatorQueryer
 	backendName         string
+	quotas              QuotaEnforcer
+	dependencies        *dependencies.Evaluator
+}
+
+// QuotaEnforcer enforces the namespace quotas on the ingest of events. The
+// proxy entities are enforced by the store.
+type QuotaEnforcer interface {
+	AllowEvent(ctx context.Context, event *corev2.Event) error
 }
 
 // Option is a functional option.
@@ -195,6 +204,10 @@ type Config struct {
 	OperatorMonitor     store.OperatorMonitor
 	OperatorQueryer     store.OperatorQueryer
 	BackendName         string
+
+	// QuotaEnforcer enforces the namespace quotas, if not nil. The events
+	// exceeding a quota are dropped.
+	QuotaEnforcer QuotaEnforcer
 }
 
 // New creates a new Eventd.
@@ -231,6 +244,8 @@ func New(ctx context.Context, c Config, opts ...Option) (*Eventd, error) {
 		operatorConcierge:   c.OperatorConcierge,
 		operatorMonitor:     c.OperatorMonitor,
 		backendName:         c.BackendName,
+		quotas:              c.QuotaEnforcer,
+		dependencies:        dependencies.NewEvaluator(c.Store.GetEventStore()),
 	}
 
 	e.ctx, e.cancel = context.WithCancel(ctx)
@@ -464,6 +479,13 @@ func (e *Eventd) handleMessage(msg interface{}) (fEvent *corev2.Event, fErr erro
 		return event, err
 	}
 
+	if e.quotas != nil {
+		if err := e.quotas.AllowEvent(context.Background(), event); err != nil {
+			EventsProcessed.WithLabelValues(EventsProcessedLabelError, EventsProcessedTypeLabelUnknown).Inc()
+			return event, err
+		}
+	}
+
 	if event.HasMetrics() {
 		MetricPointsProcessed.Add(float64(len(event.Metrics.Points)))
 	}
@@ -485,6 +507,15 @@ func (e *Eventd) handleMessage(msg interface{}) (fEvent *corev2.Event, fErr erro
 		return event, err
 	}
 
+	// Annotate the event with its failing dependencies, so that it can be
+	// filtered out by its handlers. An event whose dependencies can't be
+	// evaluated is still handled.
+	failedDependencies, err := e.dependencies.Evaluate(ctx, event)
+	if err != nil {
+		logger.WithFields(fields).WithError(err).Error("error evaluating the dependencies of the event")
+	}
+	dependencies.Annotate(event, failedDependencies)
+
 	// Add any silenced subscriptions to the event
 	// TODO(eric)
 	//silenced.GetSilenced(ctx, event, e.silencedCache)
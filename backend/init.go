@@ -122,12 +122,14 @@ func InitializeStore(ctx context.Context, db *pgxpool.Pool, config *Config) (*Ba
 	hasMetricsFilterAdapter := &filter.HasMetricsAdapter{}
 	isIncidentFilterAdapter := &filter.IsIncidentAdapter{}
 	notSilencedFilterAdapter := &filter.NotSilencedAdapter{}
+	notDependencyFailedFilterAdapter := &filter.NotDependencyFailedAdapter{}
 
 	b.PipelineAdapterV1.FilterAdapters = []pipeline.FilterAdapter{
 		legacyFilterAdapter,
 		hasMetricsFilterAdapter,
 		isIncidentFilterAdapter,
 		notSilencedFilterAdapter,
+		notDependencyFailedFilterAdapter,
 	}
 
 	// Initialize PipelineAdapterV1 mutator adapters
@@ -245,22 +247,23 @@ func InitializeStore(ctx context.Context, db *pgxpool.Pool, config *Config) (*Ba
 
 	// Initialize GraphQL service
 	b.GraphQLService, err = graphql.NewService(graphql.ServiceConfig{
-		AssetClient:       api.NewAssetClient(b.Store, auth),
-		CheckClient:       api.NewCheckClient(b.Store, actions.NewCheckController(b.Store, nil), auth),
-		EntityClient:      api.NewEntityClient(b.Store, auth),
-		EventClient:       api.NewEventClient(b.Store.GetEventStore(), auth, bus),
-		EventFilterClient: api.NewEventFilterClient(b.Store, auth),
-		HandlerClient:     api.NewHandlerClient(b.Store, auth),
-		HealthController:  actions.HealthController{},
-		MutatorClient:     api.NewMutatorClient(b.Store, auth),
-		SilencedClient:    api.NewSilencedClient(b.Store.GetSilencesStore(), auth),
-		NamespaceClient:   api.NewNamespaceClient(b.Store, auth),
-		HookClient:        api.NewHookConfigClient(b.Store, auth),
-		UserClient:        api.NewUserClient(b.Store, auth),
-		RBACClient:        api.NewRBACClient(b.Store, auth),
-		VersionController: actions.NewVersionController("no version"),
-		MetricGatherer:    prometheus.DefaultGatherer,
-		GenericClient:     &api.GenericClient{Store: b.Store, Auth: auth},
+		AssetClient:        api.NewAssetClient(b.Store, auth),
+		CheckClient:        api.NewCheckClient(b.Store, actions.NewCheckController(b.Store, nil), auth),
+		EntityClient:       api.NewEntityClient(b.Store, auth),
+		EventClient:        api.NewEventClient(b.Store.GetEventStore(), auth, bus),
+		EventHistoryClient: api.NewEventHistoryClient(b.Store.GetEventHistoryStore(), auth),
+		EventFilterClient:  api.NewEventFilterClient(b.Store, auth),
+		HandlerClient:      api.NewHandlerClient(b.Store, auth),
+		HealthController:   actions.HealthController{},
+		MutatorClient:      api.NewMutatorClient(b.Store, auth),
+		SilencedClient:     api.NewSilencedClient(b.Store.GetSilencesStore(), auth),
+		NamespaceClient:    api.NewNamespaceClient(b.Store, auth),
+		HookClient:         api.NewHookConfigClient(b.Store, auth),
+		UserClient:         api.NewUserClient(b.Store, auth),
+		RBACClient:         api.NewRBACClient(b.Store, auth),
+		VersionController:  actions.NewVersionController("no version"),
+		MetricGatherer:     prometheus.DefaultGatherer,
+		GenericClient:      &api.GenericClient{Store: b.Store, Auth: auth},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("error initializing graphql.Service: %s", err)
@@ -2,7 +2,6 @@ package pipeline
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"sort"
 	"time"
@@ -165,6 +164,10 @@ func (a *AdapterV1) Run(ctx context.Context, ref *corev2.ResourceReference, reso
 
 	ctx = context.WithValue(ctx, corev2.NamespaceKey, event.Entity.Namespace)
 
+	if ref.Name == LegacyPipelineName {
+		return a.runLegacyPipeline(ctx, event)
+	}
+
 	pipeline, err := a.resolvePipelineReference(ctx, ref, event)
 	if err != nil {
 		return err
@@ -260,9 +263,33 @@ func (a *AdapterV1) getPipelineFromStore(ctx context.Context, ref *corev2.Resour
 	return store.Get(tctx, id)
 }
 
-// generateLegacyPipeline will build an event pipeline with a pipeline
-// workflow for each event.Check.Handlers & event.Metrics.Handlers
-func (a *AdapterV1) generateLegacyPipeline(ctx context.Context, event *corev2.Event) (*corev2.Pipeline, error) {
+// runLegacyPipeline runs the event through the handlers of the event, with
+// the handler sets expanded. The handlers are fanned out like the members of
+// a handler set, each of them with its own filters and mutator.
+func (a *AdapterV1) runLegacyPipeline(ctx context.Context, event *corev2.Event) (err error) {
+	resolveTimer := prometheus.NewTimer(prometheus.ObserverFunc(func(v float64) {
+		status := metricspkg.StatusLabelSuccess
+		if err != nil {
+			status = metricspkg.StatusLabelError
+		}
+		pipelineResolveDuration.WithLabelValues(status, PipelineTypeLabelLegacy).Observe(v * float64(1000))
+	}))
+	handlers, err := a.legacyHandlers(ctx, event)
+	resolveTimer.ObserveDuration()
+	if err != nil {
+		return err
+	}
+	if len(handlers) < 1 {
+		return &ErrNoWorkflows{}
+	}
+
+	ctx = context.WithValue(ctx, corev2.PipelineKey, LegacyPipelineName)
+	return a.fanOut(ctx, "", handlers, event)
+}
+
+// legacyHandlers returns the handlers of event.Check.Handlers &
+// event.Metrics.Handlers, with the handler sets expanded.
+func (a *AdapterV1) legacyHandlers(ctx context.Context, event *corev2.Event) (HandlerMap, error) {
 	// initialize a list of handler names for storing the names of any legacy
 	// check and/or metrics handlers.
 	legacyHandlerNames := []string{}
@@ -275,7 +302,13 @@ func (a *AdapterV1) generateLegacyPipeline(ctx context.Context, event *corev2.Ev
 		legacyHandlerNames = append(legacyHandlerNames, event.Metrics.Handlers...)
 	}
 
-	handlers, err := a.expandHandlers(ctx, event.Entity.Namespace, legacyHandlerNames, 1)
+	return a.expandHandlers(ctx, event.Entity.Namespace, legacyHandlerNames, nil)
+}
+
+// generateLegacyPipeline will build an event pipeline with a pipeline
+// workflow for each event.Check.Handlers & event.Metrics.Handlers
+func (a *AdapterV1) generateLegacyPipeline(ctx context.Context, event *corev2.Event) (*corev2.Pipeline, error) {
+	handlers, err := a.legacyHandlers(ctx, event)
 	if err != nil {
 		return nil, err
 	}
@@ -306,13 +339,10 @@ func (a *AdapterV1) generateLegacyPipeline(ctx context.Context, event *corev2.Ev
 }
 
 // expandHandlers turns a list of Sensu handler names into a list of
-// handlers, while expanding handler sets with support for some
-// nesting. Handlers are fetched from etcd.
-func (a *AdapterV1) expandHandlers(ctx context.Context, namespace string, handlers []string, level int) (HandlerMap, error) {
-	if level > 3 {
-		return nil, errors.New("handler sets cannot be deeply nested")
-	}
-
+// handlers, while recursively expanding handler sets. The sets being expanded
+// are passed as parents, so that a set including itself, directly or through
+// another set, is detected and skipped. Handlers are fetched from the store.
+func (a *AdapterV1) expandHandlers(ctx context.Context, namespace string, handlers []string, parents []string) (HandlerMap, error) {
 	expandedHandlers := HandlerMap{}
 
 	// Prepare log entry
@@ -333,7 +363,7 @@ func (a *AdapterV1) expandHandlers(ctx context.Context, namespace string, handle
 
 		if err != nil {
 			if _, ok := err.(*store.ErrNotFound); ok {
-				if level > 1 {
+				if len(parents) > 0 {
 					logger.WithFields(fields).Error("set handler specified a handler that does not exist")
 				} else {
 					logger.WithFields(fields).Info("handler does not exist, will be ignored")
@@ -351,8 +381,15 @@ func (a *AdapterV1) expandHandlers(ctx context.Context, namespace string, handle
 			continue
 		}
 
-		if handler.Type == "set" {
-			setHandlers, err := a.expandHandlers(ctx, namespace, handler.Handlers, level+1)
+		if handler.Type == corev2.HandlerSetType {
+			if containsString(parents, handler.Name) {
+				logger.WithFields(fields).
+					WithField("handler_sets", append(parents, handler.Name)).
+					Error("handler set cycle detected, set will be ignored")
+				continue
+			}
+			setParents := append(append(make([]string, 0, len(parents)+1), parents...), handler.Name)
+			setHandlers, err := a.expandHandlers(ctx, namespace, handler.Handlers, setParents)
 			if err != nil {
 				logger.
 					WithFields(fields).
@@ -377,3 +414,12 @@ func (a *AdapterV1) expandHandlers(ctx context.Context, namespace string, handle
 
 	return expandedHandlers, nil
 }
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
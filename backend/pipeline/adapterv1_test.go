@@ -929,7 +929,6 @@ func TestAdapterV1_expandHandlers(t *testing.T) {
 	type args struct {
 		ctx      context.Context
 		handlers []string
-		level    int
 	}
 	tests := []struct {
 		name       string
@@ -1016,7 +1015,7 @@ func TestAdapterV1_expandHandlers(t *testing.T) {
 			},
 		},
 		{
-			name: "skips expanding any sets that are nested too deeply",
+			name: "skips expanding sets that include themselves",
 			args: args{
 				ctx:      context.Background(),
 				handlers: []string{"recursiveLoopHandler"},
@@ -1068,7 +1067,7 @@ func TestAdapterV1_expandHandlers(t *testing.T) {
 				MutatorAdapters: tt.fields.MutatorAdapters,
 				HandlerAdapters: tt.fields.HandlerAdapters,
 			}
-			got, err := a.expandHandlers(tt.args.ctx, "default", tt.args.handlers, tt.args.level)
+			got, err := a.expandHandlers(tt.args.ctx, "default", tt.args.handlers, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("AdapterV1.expandHandlers() error = %v, wantErr %v", err, tt.wantErr)
 				return
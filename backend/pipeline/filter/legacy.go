@@ -3,6 +3,7 @@ package filter
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/robertkrimen/otto"
@@ -26,6 +27,7 @@ var (
 		"is_incident",
 		"has_metrics",
 		"not_silenced",
+		"not_dependency_failed",
 	}
 
 	errCouldNotRetrieveFilter = errors.New("could not retrieve filter")
@@ -65,6 +67,13 @@ func (l *LegacyAdapter) CanFilter(ref *corev2.ResourceReference) bool {
 // Sensu pipeline. It returns whether or not the event was filtered and if any
 // error was encountered.
 func (l *LegacyAdapter) Filter(ctx context.Context, ref *corev2.ResourceReference, event *corev2.Event) (bool, error) {
+	filtered, _, err := l.Explain(ctx, ref, event)
+	return filtered, err
+}
+
+// Explain filters a Sensu event like Filter, and also returns the reason of
+// the decision, for pipeline traces.
+func (l *LegacyAdapter) Explain(ctx context.Context, ref *corev2.ResourceReference, event *corev2.Event) (bool, string, error) {
 	// Prepare log entry
 	fields := event.LogFields(false)
 	fields["pipeline"] = corev2.ContextPipeline(ctx)
@@ -79,7 +88,7 @@ func (l *LegacyAdapter) Filter(ctx context.Context, ref *corev2.ResourceReferenc
 	cancel()
 	if err != nil {
 		logger.WithFields(fields).WithError(err).Warning(errCouldNotRetrieveFilter.Error())
-		return false, err
+		return false, "", err
 	}
 
 	// Execute the filter, evaluating each of its
@@ -92,21 +101,28 @@ func (l *LegacyAdapter) Filter(ctx context.Context, ref *corev2.ResourceReferenc
 		logger.WithFields(fields).WithError(err).Error("failed to retrieve assets for filter")
 		if _, ok := err.(*store.ErrInternal); ok {
 			// Fatal error
-			return false, err
+			return false, "", err
 		}
 	}
-	filtered := evaluateEventFilter(ctx, event, filter, assets)
+	filtered, reason := explainEventFilter(ctx, event, filter, assets)
 	if filtered {
 		logger.WithFields(fields).Debug("denying event with custom filter")
-		return true, nil
+		return true, reason, nil
 	}
 
 	logger.WithFields(fields).Debug("allowing event")
-	return false, nil
+	return false, reason, nil
 }
 
 // Returns true if the event should be filtered/denied.
 func evaluateEventFilter(ctx context.Context, event *corev2.Event, filter *corev2.EventFilter, assets asset.RuntimeAssetSet) bool {
+	filtered, _ := explainEventFilter(ctx, event, filter, assets)
+	return filtered
+}
+
+// explainEventFilter returns true if the event should be filtered/denied, and
+// the reason of the decision.
+func explainEventFilter(ctx context.Context, event *corev2.Event, filter *corev2.EventFilter, assets asset.RuntimeAssetSet) (bool, string) {
 	// Redact the entity to avoid leaking sensitive information
 	event.Entity = event.Entity.GetRedactedEntity()
 
@@ -122,17 +138,17 @@ func evaluateEventFilter(ctx context.Context, event *corev2.Event, filter *corev
 		if err != nil {
 			logger.WithFields(fields).WithError(err).
 				Error("denying event - unable to determine if time is in specified window")
-			return false
+			return false, fmt.Sprintf("unable to determine if time is in specified window: %s", err)
 		}
 
 		if filter.Action == corev2.EventFilterActionAllow && !inWindows {
 			logger.WithFields(fields).Debug("denying event outside of filtering window")
-			return true
+			return true, "event is outside of the filtering window"
 		}
 
 		if filter.Action == corev2.EventFilterActionDeny && inWindows {
 			logger.WithFields(fields).Debug("denying event inside of filtering window")
-			return true
+			return true, "event is inside of the filtering window"
 		}
 	}
 
@@ -180,13 +196,13 @@ func evaluateEventFilter(ctx context.Context, event *corev2.Event, filter *corev
 			// One of the expressions did not match, filter the event
 			if !match {
 				logger.WithFields(fields).Debug("denying event that does not match filter")
-				return true
+				return true, fmt.Sprintf("expression %q does not match", expression)
 			}
 		}
 
 		// All the expressions matched, do not filter the event
 		logger.WithFields(fields).Debug("allowing event that matches filter")
-		return false
+		return false, "all the expressions match"
 
 	// Exclusive "Deny" filters let events through when the OR'd combination of
 	// their expressions is true. That is, events that match one or more of the
@@ -203,17 +219,17 @@ func evaluateEventFilter(ctx context.Context, event *corev2.Event, filter *corev
 			// One of the expressions matched, filter the event
 			if match {
 				logger.WithFields(fields).Debug("denying event that matches filter")
-				return true
+				return true, fmt.Sprintf("expression %q matches", expression)
 			}
 		}
 
 		// None of the expressions matched, do not filter the event
 		logger.WithFields(fields).Debug("allowing event that does not match filter")
-		return false
+		return false, "none of the expressions match"
 
 	default:
 		logger.WithFields(fields).Error("unrecognized filter action")
-		return false
+		return false, fmt.Sprintf("unrecognized filter action %q", filter.Action)
 	}
 }
 
@@ -446,6 +446,60 @@ func Test_evaluateEventFilter(t *testing.T) {
 	}
 }
 
+func Test_explainEventFilter(t *testing.T) {
+	tests := []struct {
+		name       string
+		action     string
+		check      string
+		wantDenied bool
+		wantReason string
+	}{
+		{
+			name:       "allow filter with an expression that does not match",
+			action:     corev2.EventFilterActionAllow,
+			check:      "check-bar",
+			wantDenied: true,
+			wantReason: `expression "event.check.name == 'check-foo'" does not match`,
+		},
+		{
+			name:       "allow filter with matching expressions",
+			action:     corev2.EventFilterActionAllow,
+			check:      "check-foo",
+			wantReason: "all the expressions match",
+		},
+		{
+			name:       "deny filter with a matching expression",
+			action:     corev2.EventFilterActionDeny,
+			check:      "check-foo",
+			wantDenied: true,
+			wantReason: `expression "event.check.name == 'check-foo'" matches`,
+		},
+		{
+			name:       "deny filter without matching expressions",
+			action:     corev2.EventFilterActionDeny,
+			check:      "check-bar",
+			wantReason: "none of the expressions match",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := &corev2.EventFilter{
+				ObjectMeta:  corev2.ObjectMeta{Name: "filter"},
+				Action:      tt.action,
+				Expressions: []string{"event.check.name == 'check-foo'"},
+			}
+			event := corev2.FixtureEvent("entity1", tt.check)
+			denied, reason := explainEventFilter(context.Background(), event, filter, nil)
+			if denied != tt.wantDenied {
+				t.Errorf("explainEventFilter() denied = %v, want %v", denied, tt.wantDenied)
+			}
+			if reason != tt.wantReason {
+				t.Errorf("explainEventFilter() reason = %q, want %q", reason, tt.wantReason)
+			}
+		})
+	}
+}
+
 func TestJavascriptStoreAccess(t *testing.T) {
 	st := new(mockstore.MockStore)
 	sv2 := new(mockstore.V2MockStore)
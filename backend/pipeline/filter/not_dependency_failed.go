@@ -0,0 +1,49 @@
+package filter
+
+import (
+	"context"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/dependencies"
+	utillogging "github.com/sensu/sensu-go/util/logging"
+)
+
+const (
+	// NotDependencyFailedAdapterName is the name of the filter adapter.
+	NotDependencyFailedAdapterName = "NotDependencyFailedAdapter"
+)
+
+// NotDependencyFailedAdapter is a filter adapter which will filter events
+// whose dependencies are failing.
+type NotDependencyFailedAdapter struct{}
+
+// Name returns the name of the filter adapter.
+func (n *NotDependencyFailedAdapter) Name() string {
+	return NotDependencyFailedAdapterName
+}
+
+// CanFilter determines whether NotDependencyFailedAdapter can filter the
+// resource being referenced.
+func (n *NotDependencyFailedAdapter) CanFilter(ref *corev2.ResourceReference) bool {
+	if ref.APIVersion == "core/v2" && ref.Type == "EventFilter" && ref.Name == "not_dependency_failed" {
+		return true
+	}
+	return false
+}
+
+// Filter will evaluate the event and determine whether or not to filter it.
+func (n *NotDependencyFailedAdapter) Filter(ctx context.Context, ref *corev2.ResourceReference, event *corev2.Event) (bool, error) {
+	// Prepare log entry
+	fields := utillogging.EventFields(event, false)
+	fields["pipeline"] = corev2.ContextPipeline(ctx)
+	fields["pipeline_workflow"] = corev2.ContextPipelineWorkflow(ctx)
+
+	// Deny an event if one of its dependencies is failing
+	if dependencies.IsFailed(event) {
+		fields["failed_dependencies"] = dependencies.Failed(event)
+		logger.WithFields(fields).Debug("denying event whose dependencies are failing")
+		return true, nil
+	}
+
+	return false, nil
+}
@@ -0,0 +1,124 @@
+package filter
+
+import (
+	"context"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+)
+
+func TestNotDependencyFailedAdapter_Name(t *testing.T) {
+	o := &NotDependencyFailedAdapter{}
+	want := "NotDependencyFailedAdapter"
+
+	if got := o.Name(); want != got {
+		t.Errorf("NotDependencyFailedAdapter.Name() = %v, want %v", got, want)
+	}
+}
+
+func TestNotDependencyFailedAdapter_CanFilter(t *testing.T) {
+	type args struct {
+		ref *corev2.ResourceReference
+	}
+	tests := []struct {
+		name string
+		i    *NotDependencyFailedAdapter
+		args args
+		want bool
+	}{
+		{
+			name: "returns false when resource reference is not a core/v2.EventFilter",
+			args: args{
+				ref: &corev2.ResourceReference{
+					APIVersion: "core/v2",
+					Type:       "Handler",
+				},
+			},
+			want: false,
+		},
+		{
+			name: "returns false when resource reference is a core/v2.EventFilter and its name is not not_dependency_failed",
+			args: args{
+				ref: &corev2.ResourceReference{
+					APIVersion: "core/v2",
+					Type:       "EventFilter",
+					Name:       "is_incident",
+				},
+			},
+			want: false,
+		},
+		{
+			name: "returns true when resource reference is a core/v2.EventFilter and its name is not_dependency_failed",
+			args: args{
+				ref: &corev2.ResourceReference{
+					APIVersion: "core/v2",
+					Type:       "EventFilter",
+					Name:       "not_dependency_failed",
+				},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := &NotDependencyFailedAdapter{}
+			if got := i.CanFilter(tt.args.ref); got != tt.want {
+				t.Errorf("NotDependencyFailedAdapter.CanFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotDependencyFailedAdapter_Filter(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		ref   *corev2.ResourceReference
+		event *corev2.Event
+	}
+	tests := []struct {
+		name    string
+		i       *NotDependencyFailedAdapter
+		args    args
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "event is denied when a dependency is failing",
+			args: args{
+				ctx: context.Background(),
+				event: func() *corev2.Event {
+					event := corev2.FixtureEvent("default", "default")
+					event.AddAnnotation("sensu.io/failed_dependencies", "router01/ping")
+					return event
+				}(),
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "event is allowed when no dependency is failing",
+			args: args{
+				ctx: context.Background(),
+				event: func() *corev2.Event {
+					event := corev2.FixtureEvent("default", "default")
+					return event
+				}(),
+			},
+			want:    false,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := &NotDependencyFailedAdapter{}
+			got, err := i.Filter(tt.args.ctx, tt.args.ref, tt.args.event)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NotDependencyFailedAdapter.Filter() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("NotDependencyFailedAdapter.Filter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,10 +2,17 @@ package pipeline
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/gogo/protobuf/proto"
 	"github.com/prometheus/client_golang/prometheus"
 	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/pipeline/handler"
 	metricspkg "github.com/sensu/sensu-go/metrics"
 )
 
@@ -13,6 +20,16 @@ const (
 	// HandlerDuration is the name of the prometheus summary vec used to track
 	// average latencies of pipeline handler execution.
 	HandlerDuration = "sensu_go_pipeline_handler_duration"
+
+	// HandlerSetLabelName is the name of a label which describes the handler
+	// set a handler was executed for. It is empty for handlers that are
+	// directly referenced by a pipeline workflow.
+	HandlerSetLabelName = "handler_set"
+
+	// DefaultHandlerSetMemberTimeout is the maximum execution time of a
+	// handler set member, or of a handler of the legacy pipeline, that does
+	// not specify a timeout.
+	DefaultHandlerSetMemberTimeout = 60 * time.Second
 )
 
 var (
@@ -22,7 +39,7 @@ var (
 			Help:       "pipeline handler execution latency distribution",
 			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
 		},
-		[]string{metricspkg.StatusLabelName, metricspkg.ResourceReferenceLabelName},
+		[]string{metricspkg.StatusLabelName, metricspkg.ResourceReferenceLabelName, HandlerSetLabelName},
 	)
 )
 
@@ -44,16 +61,147 @@ func (a *AdapterV1) processHandler(ctx context.Context, ref *corev2.ResourceRefe
 		if fErr != nil {
 			status = metricspkg.StatusLabelError
 		}
-		handlerDuration.WithLabelValues(status, ref.ResourceID()).Observe(v * float64(1000))
+		handlerDuration.WithLabelValues(status, ref.ResourceID(), "").Observe(v * float64(1000))
 	}))
 	defer handlerTimer.ObserveDuration()
 
-	handler, err := a.getHandlerAdapterForResource(ctx, ref)
+	adapter, err := a.getHandlerAdapterForResource(ctx, ref)
+	if err != nil {
+		return err
+	}
+
+	err = adapter.Handle(ctx, ref, event, mutatedData)
+	var set *handler.ErrHandlerSet
+	if errors.As(err, &set) {
+		return a.processHandlerSet(ctx, set.Handler, event)
+	}
+	return err
+}
+
+// processHandlerSet fans the event out to every member of a handler set,
+// nested sets included.
+func (a *AdapterV1) processHandlerSet(ctx context.Context, set *corev2.Handler, event *corev2.Event) error {
+	members, err := a.expandHandlers(ctx, set.Namespace, set.Handlers, []string{set.Name})
+	if err != nil {
+		return err
+	}
+	return a.fanOut(ctx, set.Name, members, event)
+}
+
+// fanOut runs the event through the handlers concurrently. Each handler runs
+// with its own filters and mutator, on its own copy of the event and within
+// its own timeout, so that a failing or slow handler does not hold back the
+// others. The set is the name of the handler set the handlers belong to, if
+// any.
+func (a *AdapterV1) fanOut(ctx context.Context, set string, handlers HandlerMap, event *corev2.Event) error {
+	names := make([]string, 0, len(handlers))
+	for name := range handlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, member *corev2.Handler, event *corev2.Event) {
+			defer wg.Done()
+			errs[i] = a.processHandlerSetMember(ctx, set, member, event)
+		}(i, handlers[name], proto.Clone(event).(*corev2.Event))
+	}
+	wg.Wait()
+
+	var failed []string
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, names[i])
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	if set == "" {
+		return fmt.Errorf("%d of %d handlers failed: %s", len(failed), len(names), strings.Join(failed, ", "))
+	}
+	return fmt.Errorf("%d of %d handlers in set %q failed: %s", len(failed), len(names), set, strings.Join(failed, ", "))
+}
+
+// processHandlerSetMember runs the event through the filters, the mutator and
+// the handler of a member of a fan-out, within the timeout of the member. The
+// handler adapters, filters and mutators honor the context, so the member
+// returns once its timeout expires.
+func (a *AdapterV1) processHandlerSetMember(ctx context.Context, set string, member *corev2.Handler, event *corev2.Event) (fErr error) {
+	workflow := corev2.PipelineWorkflowFromHandler(ctx, fmt.Sprintf(LegacyPipelineWorkflowName, member.Name), member)
+	if set == "" {
+		ctx = context.WithValue(ctx, corev2.PipelineWorkflowKey, workflow.Name)
+	}
+
+	// Prepare log entry
+	fields := event.LogFields(false)
+	fields["pipeline"] = corev2.ContextPipeline(ctx)
+	fields["pipeline_workflow"] = corev2.ContextPipelineWorkflow(ctx)
+	fields["handler_set"] = set
+	fields["handler"] = member.Name
+
+	begin := time.Now()
+	filtered := false
+	defer func() {
+		if filtered {
+			return
+		}
+		duration := time.Since(begin)
+		status := metricspkg.StatusLabelSuccess
+		if fErr != nil {
+			status = metricspkg.StatusLabelError
+		}
+		handlerDuration.
+			WithLabelValues(status, workflow.Handler.ResourceID(), set).
+			Observe(float64(duration) / float64(time.Millisecond))
+		fields["status"] = status
+		fields["duration"] = duration.String()
+		if fErr != nil {
+			logger.WithFields(fields).WithError(fErr).Error("handler failed")
+		} else {
+			logger.WithFields(fields).Info("handler executed")
+		}
+	}()
+
+	timeout := time.Duration(member.Timeout) * time.Second
+	if timeout == 0 {
+		timeout = DefaultHandlerSetMemberTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	filtered, err := a.processFilters(ctx, workflow.Filters, event)
+	if err != nil || filtered {
+		return err
+	}
+
+	mutatorRef := workflow.Mutator
+	if mutatorRef == nil {
+		mutatorRef = &corev2.ResourceReference{
+			APIVersion: "core/v2",
+			Type:       "Mutator",
+			Name:       "json",
+		}
+	}
+	mutatedData, err := a.processMutator(ctx, mutatorRef, event)
 	if err != nil {
 		return err
 	}
 
-	return handler.Handle(ctx, ref, event, mutatedData)
+	adapter, err := a.getHandlerAdapterForResource(ctx, workflow.Handler)
+	if err != nil {
+		return err
+	}
+	handlerRequestsTotalCounter.Inc()
+	err = adapter.Handle(ctx, workflow.Handler, event, mutatedData)
+	incrementCounter(workflow.Handler, err)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("handler %q timed out after %s: %w", member.Name, timeout, err)
+	}
+	return err
 }
 
 func (a *AdapterV1) getHandlerAdapterForResource(ctx context.Context, ref *corev2.ResourceReference) (HandlerAdapter, error) {
@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev2 "github.com/sensu/core/v2"
+	pipelinev1 "github.com/sensu/sensu-go/backend/pipeline/v1"
+	"github.com/sensu/sensu-go/backend/secrets"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	"github.com/sensu/sensu-go/dynamic"
+	metricspkg "github.com/sensu/sensu-go/metrics"
+	"github.com/sensu/sensu-go/token"
+	utillogging "github.com/sensu/sensu-go/util/logging"
+	"github.com/sensu/sensu-go/util/retry"
+)
+
+const (
+	// HTTPAdapterName is the name of the handler adapter.
+	HTTPAdapterName = "HTTPAdapter"
+
+	// HTTPHandlerRequests is the name of the prometheus counter vec used to
+	// count the requests sent by HTTP handlers.
+	HTTPHandlerRequests = "sensu_go_pipeline_http_handler_requests"
+
+	// HTTPStatusCodeLabelName is the name of the label holding the response
+	// status code of an HTTP handler request, or "error" if no response was
+	// received.
+	HTTPStatusCodeLabelName = "code"
+
+	// maxHTTPResponseLog is the maximum number of bytes of a failed response
+	// body that are logged.
+	maxHTTPResponseLog = 1024
+)
+
+var (
+	// httpRetryDelayUnit is the unit of HTTPHandler.RetryDelay, shortened by
+	// tests.
+	httpRetryDelayUnit = time.Second
+
+	httpHandlerRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: HTTPHandlerRequests,
+			Help: "number of requests sent by http handlers",
+		},
+		[]string{metricspkg.ResourceReferenceLabelName, HTTPStatusCodeLabelName},
+	)
+)
+
+func init() {
+	if err := prometheus.Register(httpHandlerRequests); err != nil {
+		panic(fmt.Errorf("error registering %s: %s", HTTPHandlerRequests, err))
+	}
+}
+
+// HTTPAdapter is a handler adapter that supports the pipeline/v1.HTTPHandler
+// type.
+type HTTPAdapter struct {
+	SecretsProviderManager secrets.ProviderManagerer
+	Store                  storev2.Interface
+	StoreTimeout           time.Duration
+}
+
+// Name returns the name of the handler adapter.
+func (h *HTTPAdapter) Name() string {
+	return HTTPAdapterName
+}
+
+// CanHandle determines whether HTTPAdapter can handle the resource being
+// referenced.
+func (h *HTTPAdapter) CanHandle(ref *corev2.ResourceReference) bool {
+	return ref.APIVersion == pipelinev1.APIVersion && ref.Type == "HTTPHandler"
+}
+
+// Handle sends a Sensu event to the HTTP endpoint of the referenced handler.
+// Requests failing with a 5xx status or a transport error are retried with
+// exponential backoff.
+func (h *HTTPAdapter) Handle(ctx context.Context, ref *corev2.ResourceReference, event *corev2.Event, mutatedData []byte) error {
+	// Prepare log entry
+	fields := utillogging.EventFields(event, false)
+	fields["pipeline"] = corev2.ContextPipeline(ctx)
+	fields["pipeline_workflow"] = corev2.ContextPipelineWorkflow(ctx)
+	fields["handler"] = ref.Name
+
+	tctx, cancel := context.WithTimeout(ctx, h.StoreTimeout)
+	hstore := storev2.Of[*pipelinev1.HTTPHandler](h.Store)
+	handler, err := hstore.Get(tctx, storev2.ID{Namespace: event.Entity.Namespace, Name: ref.Name})
+	cancel()
+	if err != nil {
+		if _, ok := err.(*store.ErrNotFound); ok {
+			logger.WithFields(fields).
+				Error("handler not found, skipping handler execution")
+			return nil
+		}
+		return fmt.Errorf("failed to fetch handler from store: %v", err)
+	}
+	ctx = context.WithValue(ctx, corev2.NamespaceKey, handler.Metadata.Namespace)
+
+	headers, err := h.headers(ctx, handler)
+	if err != nil {
+		logger.WithFields(fields).WithError(err).Error("failed to retrieve secrets for handler")
+		return err
+	}
+
+	body := mutatedData
+	if handler.Body != "" {
+		body, err = renderBody(handler.Body, event)
+		if err != nil {
+			logger.WithFields(fields).WithError(err).Error("failed to render http handler body")
+			return err
+		}
+	}
+
+	client, err := httpClient(handler)
+	if err != nil {
+		logger.WithFields(fields).WithError(err).Error("failed to configure http handler client")
+		return err
+	}
+	defer client.CloseIdleConnections()
+
+	backoff := retry.ExponentialBackoff{
+		Ctx:                  ctx,
+		InitialDelayInterval: time.Duration(handler.GetRetryDelay()) * httpRetryDelayUnit,
+		MaxRetryAttempts:     int(handler.MaxRetries) + 1,
+	}
+	var lastErr error
+	err = backoff.Retry(func(attempt int) (bool, error) {
+		retryable, err := h.send(ctx, client, handler, ref, headers, body)
+		if err == nil {
+			return true, nil
+		}
+		lastErr = err
+		if !retryable {
+			return false, err
+		}
+		logger.WithFields(fields).WithError(err).WithField("attempt", attempt+1).Warn("http handler request failed")
+		return false, nil
+	})
+	if err == retry.ErrMaxRetryAttempts {
+		err = lastErr
+	}
+	if err != nil {
+		logger.WithFields(fields).WithError(err).Error("failed to execute event http handler")
+		return err
+	}
+
+	logger.WithFields(fields).Info("event http handler executed")
+	return nil
+}
+
+// send performs a single request, and returns whether it should be retried
+// if it failed.
+func (h *HTTPAdapter) send(ctx context.Context, client *http.Client, handler *pipelinev1.HTTPHandler, ref *corev2.ResourceReference, headers http.Header, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, handler.GetMethod(), handler.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header = headers.Clone()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		httpHandlerRequests.WithLabelValues(ref.ResourceID(), metricspkg.StatusLabelError).Inc()
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	httpHandlerRequests.WithLabelValues(ref.ResourceID(), strconv.Itoa(resp.StatusCode)).Inc()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return false, nil
+	}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, maxHTTPResponseLog))
+	err = fmt.Errorf("http handler request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	return resp.StatusCode >= 500, err
+}
+
+// headers returns the request headers of the handler, with the references to
+// the handler secrets substituted.
+func (h *HTTPAdapter) headers(ctx context.Context, handler *pipelinev1.HTTPHandler) (http.Header, error) {
+	values := map[string]string{}
+	if len(handler.Secrets) > 0 {
+		if h.SecretsProviderManager == nil {
+			return nil, errors.New("secrets are not available")
+		}
+		substituted, err := h.SecretsProviderManager.SubSecrets(ctx, handler.Secrets)
+		if err != nil {
+			return nil, err
+		}
+		for _, kv := range substituted {
+			if idx := strings.Index(kv, "="); idx > 0 {
+				values[kv[:idx]] = kv[idx+1:]
+			}
+		}
+	}
+
+	headers := make(http.Header, len(handler.Headers)+1)
+	headers.Set("Content-Type", "application/json")
+	for key, value := range handler.Headers {
+		headers.Set(key, os.Expand(value, func(name string) string {
+			if secret, ok := values[name]; ok {
+				return secret
+			}
+			// leave anything that isn't a secret untouched
+			return "$" + name
+		}))
+	}
+	return headers, nil
+}
+
+// renderBody evaluates the body template of a handler against the event.
+func renderBody(body string, event *corev2.Event) ([]byte, error) {
+	b, err := token.Substitution(dynamic.Synthesize(event), body)
+	if err != nil {
+		return nil, err
+	}
+	var rendered string
+	if err := json.Unmarshal(b, &rendered); err != nil {
+		return nil, fmt.Errorf("could not unmarshal the rendered body: %s", err)
+	}
+	return []byte(rendered), nil
+}
+
+func httpClient(handler *pipelinev1.HTTPHandler) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if handler.TLS != nil {
+		tlsConfig, err := handler.TLS.ToClientTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   time.Duration(handler.GetTimeout()) * time.Second,
+	}, nil
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	pipelinev1 "github.com/sensu/sensu-go/backend/pipeline/v1"
+	"github.com/sensu/sensu-go/backend/store"
+	"github.com/sensu/sensu-go/testing/mocksecrets"
+	"github.com/sensu/sensu-go/testing/mockstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func httpAdapterWithHandler(handler *pipelinev1.HTTPHandler) *HTTPAdapter {
+	stor := new(mockstore.V2MockStore)
+	cs := new(mockstore.ConfigStore)
+	stor.On("GetConfigStore").Return(cs)
+	cs.On("Get", mock.Anything, mock.Anything).Return(mockstore.Wrapper[*pipelinev1.HTTPHandler]{Value: handler}, nil)
+	return &HTTPAdapter{Store: stor, StoreTimeout: time.Second}
+}
+
+func httpHandlerRef() *corev2.ResourceReference {
+	return &corev2.ResourceReference{APIVersion: "pipeline/v1", Type: "HTTPHandler", Name: "webhook"}
+}
+
+func TestHTTPAdapter_CanHandle(t *testing.T) {
+	adapter := &HTTPAdapter{}
+	assert.True(t, adapter.CanHandle(httpHandlerRef()))
+	assert.False(t, adapter.CanHandle(&corev2.ResourceReference{APIVersion: "core/v2", Type: "Handler"}))
+}
+
+func TestHTTPAdapter_Handle(t *testing.T) {
+	var gotBody, gotAuth, gotMethod string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		gotAuth = r.Header.Get("Authorization")
+		gotMethod = r.Method
+	}))
+	defer server.Close()
+
+	handler := &pipelinev1.HTTPHandler{
+		Metadata: corev2.ObjectMeta{Name: "webhook", Namespace: "default"},
+		URL:      server.URL,
+		Method:   http.MethodPut,
+		Headers:  map[string]string{"Authorization": "Bearer $TOKEN", "X-Other": "$NOT_A_SECRET"},
+		Body:     `{"text": "{{ .check.name }} on {{ .entity.name }}"}`,
+		Secrets:  []*corev2.Secret{{Name: "TOKEN", Secret: "sensu-token"}},
+	}
+	adapter := httpAdapterWithHandler(handler)
+	manager := &mocksecrets.ProviderManager{}
+	manager.On("SubSecrets", mock.Anything, mock.Anything).Return([]string{"TOKEN=hunter2"}, nil)
+	adapter.SecretsProviderManager = manager
+
+	event := corev2.FixtureEvent("entity1", "check1")
+	require.NoError(t, adapter.Handle(context.Background(), httpHandlerRef(), event, []byte("mutated")))
+	assert.Equal(t, `{"text": "check1 on entity1"}`, gotBody)
+	assert.Equal(t, "Bearer hunter2", gotAuth)
+	assert.Equal(t, http.MethodPut, gotMethod)
+
+	// without a body template, the mutated data is sent
+	handler.Body = ""
+	require.NoError(t, adapter.Handle(context.Background(), httpHandlerRef(), event, []byte("mutated")))
+	assert.Equal(t, "mutated", gotBody)
+}
+
+func TestHTTPAdapter_HandleRetries(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	handler := &pipelinev1.HTTPHandler{
+		Metadata:   corev2.ObjectMeta{Name: "webhook", Namespace: "default"},
+		URL:        server.URL,
+		MaxRetries: 2,
+	}
+	adapter := httpAdapterWithHandler(handler)
+	event := corev2.FixtureEvent("entity1", "check1")
+	httpRetryDelayUnit = time.Millisecond
+	defer func() { httpRetryDelayUnit = time.Second }()
+	require.NoError(t, adapter.Handle(context.Background(), httpHandlerRef(), event, nil))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+}
+
+func TestHTTPAdapter_HandleRetriesExhausted(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte("upstream down"))
+	}))
+	defer server.Close()
+
+	handler := &pipelinev1.HTTPHandler{
+		Metadata: corev2.ObjectMeta{Name: "webhook", Namespace: "default"},
+		URL:      server.URL,
+	}
+	adapter := httpAdapterWithHandler(handler)
+	err := adapter.Handle(context.Background(), httpHandlerRef(), corev2.FixtureEvent("entity1", "check1"), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 502: upstream down")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestHTTPAdapter_HandleClientErrorNotRetried(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	handler := &pipelinev1.HTTPHandler{
+		Metadata:   corev2.ObjectMeta{Name: "webhook", Namespace: "default"},
+		URL:        server.URL,
+		MaxRetries: 3,
+	}
+	adapter := httpAdapterWithHandler(handler)
+	err := adapter.Handle(context.Background(), httpHandlerRef(), corev2.FixtureEvent("entity1", "check1"), nil)
+	require.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestHTTPAdapter_HandleNotFound(t *testing.T) {
+	stor := new(mockstore.V2MockStore)
+	cs := new(mockstore.ConfigStore)
+	stor.On("GetConfigStore").Return(cs)
+	cs.On("Get", mock.Anything, mock.Anything).Return(nil, &store.ErrNotFound{})
+	adapter := &HTTPAdapter{Store: stor, StoreTimeout: time.Second}
+	err := adapter.Handle(context.Background(), httpHandlerRef(), corev2.FixtureEvent("entity1", "check1"), nil)
+	assert.NoError(t, err)
+}
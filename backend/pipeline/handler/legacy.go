@@ -11,6 +11,7 @@ import (
 	corev2 "github.com/sensu/core/v2"
 	"github.com/sensu/sensu-go/asset"
 	"github.com/sensu/sensu-go/backend/licensing"
+	"github.com/sensu/sensu-go/backend/queue"
 	"github.com/sensu/sensu-go/backend/secrets"
 	"github.com/sensu/sensu-go/backend/store"
 	storev2 "github.com/sensu/sensu-go/backend/store/v2"
@@ -28,6 +29,17 @@ const (
 	LegacyAdapterName = "LegacyAdapter"
 )
 
+// ErrHandlerSet is returned by LegacyAdapter when the referenced handler is a
+// handler set. Sets are not handled by an adapter, the pipeline fans the
+// event out to their members instead.
+type ErrHandlerSet struct {
+	Handler *corev2.Handler
+}
+
+func (e *ErrHandlerSet) Error() string {
+	return fmt.Sprintf("handler %q is a handler set", e.Handler.Name)
+}
+
 // LegacyAdapter is a handler adapter that supports the legacy core.v2/Handler
 // type.
 type LegacyAdapter struct {
@@ -37,6 +49,10 @@ type LegacyAdapter struct {
 	SecretsProviderManager secrets.ProviderManagerer
 	Store                  storev2.Interface
 	StoreTimeout           time.Duration
+
+	// Queue is the queue of the failed executions of the handlers that have
+	// a retry policy. Retries are disabled when it is nil.
+	Queue queue.Client
 }
 
 // Name returns the name of the handler adapter.
@@ -82,6 +98,7 @@ func (l *LegacyAdapter) Handle(ctx context.Context, ref *corev2.ResourceReferenc
 			logger.WithFields(fields).
 				WithError(err).
 				Error("failed to execute event pipe handler")
+			l.handleFailure(ctx, handler, event, mutatedData, err, fields)
 			return err
 		}
 		fields["status"] = result.Status
@@ -90,13 +107,18 @@ func (l *LegacyAdapter) Handle(ctx context.Context, ref *corev2.ResourceReferenc
 			logger.WithFields(fields).Info("event pipe handler executed")
 		} else {
 			logger.WithFields(fields).Error("event pipe handler returned non ok status code")
+			err := fmt.Errorf("pipe handler exited with status %d: %s", result.Status, result.Output)
+			l.handleFailure(ctx, handler, event, mutatedData, err, fields)
 		}
 	case "tcp", "udp":
 		err := l.socketHandler(ctx, handler, event, mutatedData)
 		if err != nil {
 			logger.WithFields(fields).Error(err)
+			l.handleFailure(ctx, handler, event, mutatedData, err, fields)
 			return err
 		}
+	case corev2.HandlerSetType:
+		return &ErrHandlerSet{Handler: handler}
 	default:
 		return errors.New("unknown handler type")
 	}
@@ -104,6 +126,21 @@ func (l *LegacyAdapter) Handle(ctx context.Context, ref *corev2.ResourceReferenc
 	return nil
 }
 
+// handleFailure queues the failed execution of a handler for a retry, if the
+// handler has a retry policy and retries aren't disabled in the context.
+func (l *LegacyAdapter) handleFailure(ctx context.Context, handler *corev2.Handler, event *corev2.Event, mutatedData []byte, cause error, fields map[string]interface{}) {
+	if l.Queue == nil || retriesDisabled(ctx) {
+		return
+	}
+	policy := l.retryPolicy(handler, fields)
+	if policy == nil {
+		return
+	}
+	if err := l.retryLater(ctx, policy, handler.Name, event, mutatedData, 1, cause); err != nil {
+		logger.WithFields(fields).WithError(err).Error("failed to queue handler retry")
+	}
+}
+
 // pipeHandler fork/executes a child process for a Sensu pipe handler command
 // and writes the mutated data to it via STDIN.
 func (l *LegacyAdapter) pipeHandler(ctx context.Context, handler *corev2.Handler, event *corev2.Event, mutatedData []byte) (*command.ExecutionResponse, error) {
@@ -195,7 +232,12 @@ func (l *LegacyAdapter) socketHandler(ctx context.Context, handler *corev2.Handl
 	logger.WithFields(fields).Debug("sending event to socket handler")
 
 	deadline := time.Now().Add(timeoutDuration)
-	conn, cerr := net.DialTimeout(protocol, address, timeoutDuration)
+	// The context of a handler set member has its own deadline
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	dialer := net.Dialer{Deadline: deadline}
+	conn, cerr := dialer.DialContext(ctx, protocol, address)
 	if cerr != nil {
 		return cerr
 	}
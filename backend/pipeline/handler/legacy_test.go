@@ -604,3 +604,22 @@ func TestLegacyAdapter_socketHandlerUDP(t *testing.T) {
 
 	<-done
 }
+
+func TestLegacyAdapter_HandleSet(t *testing.T) {
+	set := &corev2.Handler{
+		ObjectMeta: corev2.NewObjectMeta("set", "default"),
+		Type:       corev2.HandlerSetType,
+		Handlers:   []string{"handler1"},
+	}
+	mockStore := new(mockstore.V2MockStore)
+	cs := new(mockstore.ConfigStore)
+	mockStore.On("GetConfigStore").Return(cs)
+	cs.On("Get", mock.Anything, mock.Anything).Return(mockstore.Wrapper[*corev2.Handler]{Value: set}, nil)
+	adapter := &LegacyAdapter{
+		Store: mockStore,
+	}
+	err := adapter.Handle(context.Background(), &corev2.ResourceReference{Name: "set"}, corev2.FixtureEvent("foo", "bar"), nil)
+	var setErr *ErrHandlerSet
+	require.True(t, errors.As(err, &setErr))
+	assert.Equal(t, "set", setErr.Handler.Name)
+}
@@ -0,0 +1,417 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	corev2 "github.com/sensu/core/v2"
+	pipelinev1 "github.com/sensu/sensu-go/backend/pipeline/v1"
+	"github.com/sensu/sensu-go/backend/queue"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	utillogging "github.com/sensu/sensu-go/util/logging"
+	utilretry "github.com/sensu/sensu-go/util/retry"
+)
+
+const (
+	// RetryQueueName is the name of the queue of the handler executions
+	// waiting to be retried. It is shared by all the backends.
+	RetryQueueName = "handler-retries"
+
+	// RetryMaxAttemptsAnnotation is the handler annotation that enables
+	// retries. It is the number of times a failing handler is executed for
+	// an event, the first execution included, before the event is
+	// dead-lettered.
+	RetryMaxAttemptsAnnotation = "sensu.io/retry_max_attempts"
+
+	// RetryBackoffAnnotation is the handler annotation that sets the delay,
+	// in seconds, before the first retry. The delay doubles with every
+	// retry.
+	RetryBackoffAnnotation = "sensu.io/retry_backoff"
+
+	// RetryMaxBackoffAnnotation is the handler annotation that caps the
+	// delay between retries, in seconds.
+	RetryMaxBackoffAnnotation = "sensu.io/retry_max_backoff"
+
+	// DefaultRetryBackoff is the delay before the first retry of handlers
+	// that do not specify one.
+	DefaultRetryBackoff = 10 * time.Second
+
+	// DefaultRetryMaxBackoff is the maximum delay between retries of
+	// handlers that do not specify one.
+	DefaultRetryMaxBackoff = 10 * time.Minute
+
+	// reserveBackoff and reserveMaxBackoff are the initial and maximum
+	// delays before reserving a retry again after an error.
+	reserveBackoff    = time.Second
+	reserveMaxBackoff = time.Minute
+)
+
+// RetryPolicy is the retry policy of a handler, declared with its
+// annotations.
+type RetryPolicy struct {
+	// MaxAttempts is the number of executions of the handler for an event,
+	// the first execution included.
+	MaxAttempts uint32
+
+	// Backoff is the delay before the first retry.
+	Backoff time.Duration
+
+	// MaxBackoff is the maximum delay between retries.
+	MaxBackoff time.Duration
+}
+
+// GetRetryPolicy returns the retry policy of the handler, or nil if retries
+// are not enabled for it.
+func GetRetryPolicy(handler *corev2.Handler) (*RetryPolicy, error) {
+	value, ok := handler.Annotations[RetryMaxAttemptsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	maxAttempts, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || maxAttempts == 0 {
+		return nil, fmt.Errorf("invalid %s annotation %q: must be a positive integer", RetryMaxAttemptsAnnotation, value)
+	}
+	policy := &RetryPolicy{MaxAttempts: uint32(maxAttempts)}
+	if policy.Backoff, err = annotationSeconds(handler, RetryBackoffAnnotation, DefaultRetryBackoff); err != nil {
+		return nil, err
+	}
+	if policy.MaxBackoff, err = annotationSeconds(handler, RetryMaxBackoffAnnotation, DefaultRetryMaxBackoff); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func annotationSeconds(handler *corev2.Handler, key string, defaultValue time.Duration) (time.Duration, error) {
+	value, ok := handler.Annotations[key]
+	if !ok {
+		return defaultValue, nil
+	}
+	seconds, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s annotation %q: must be a number of seconds", key, value)
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// Delay returns the delay before the retry that follows the given number of
+// attempts.
+func (p *RetryPolicy) Delay(attempts uint32) time.Duration {
+	delay := p.Backoff
+	for i := uint32(1); i < attempts && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+// Retry is a failed handler execution waiting in the retry queue.
+type Retry struct {
+	// ID identifies the retry across the queue items that carry it.
+	ID string `json:"id"`
+
+	// Handler is the name of the handler, in the namespace of the event
+	// entity.
+	Handler string `json:"handler"`
+
+	// Event is the handled event.
+	Event *corev2.Event `json:"event"`
+
+	// MutatedData is the output of the workflow mutator.
+	MutatedData []byte `json:"mutated_data,omitempty"`
+
+	// Attempts is the number of times the handler was executed.
+	Attempts uint32 `json:"attempts"`
+
+	// NotBefore is the time from which the handler can be executed again.
+	NotBefore time.Time `json:"not_before"`
+
+	// Error is the error of the last execution.
+	Error string `json:"error,omitempty"`
+}
+
+// Replay queues the dead letter for an immediate retry. The handler is then
+// retried according to its current retry policy, as for a new event.
+func Replay(ctx context.Context, q queue.Client, letter *pipelinev1.DeadLetter) error {
+	return enqueueRetry(ctx, q, &Retry{
+		ID:          uuid.New().String(),
+		Handler:     letter.Handler,
+		Event:       letter.Event,
+		MutatedData: letter.MutatedData,
+		NotBefore:   time.Now(),
+	})
+}
+
+func enqueueRetry(ctx context.Context, q queue.Client, retry *Retry) error {
+	value, err := json.Marshal(retry)
+	if err != nil {
+		return err
+	}
+	return q.Enqueue(ctx, queue.Item{Queue: RetryQueueName, Value: value, NotBefore: retry.NotBefore})
+}
+
+type noRetriesKey struct{}
+
+// WithoutRetries returns a context in which the failed handler executions are
+// neither retried nor dead-lettered, e.g. for the pipeline traces.
+func WithoutRetries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetriesKey{}, true)
+}
+
+func retriesDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noRetriesKey{}).(bool)
+	return disabled
+}
+
+// retryPolicy returns the retry policy of the handler, logging invalid
+// policies, which disable retries.
+func (l *LegacyAdapter) retryPolicy(handler *corev2.Handler, fields map[string]interface{}) *RetryPolicy {
+	policy, err := GetRetryPolicy(handler)
+	if err != nil {
+		logger.WithFields(fields).WithError(err).Error("invalid handler retry policy, retries disabled")
+	}
+	return policy
+}
+
+// retryLater queues a failed handler execution for a retry, or dead-letters
+// it if the retry policy is exhausted.
+func (l *LegacyAdapter) retryLater(ctx context.Context, policy *RetryPolicy, handler string, event *corev2.Event, mutatedData []byte, attempts uint32, cause error) error {
+	if attempts >= policy.MaxAttempts {
+		return l.deadLetter(ctx, handler, event, mutatedData, attempts, cause)
+	}
+	return enqueueRetry(ctx, l.Queue, &Retry{
+		ID:          uuid.New().String(),
+		Handler:     handler,
+		Event:       event,
+		MutatedData: mutatedData,
+		Attempts:    attempts,
+		NotBefore:   time.Now().Add(policy.Delay(attempts)),
+		Error:       cause.Error(),
+	})
+}
+
+func (l *LegacyAdapter) deadLetter(ctx context.Context, handler string, event *corev2.Event, mutatedData []byte, attempts uint32, cause error) error {
+	letter := &pipelinev1.DeadLetter{
+		Metadata:    corev2.NewObjectMeta(uuid.New().String(), event.Entity.Namespace),
+		Handler:     handler,
+		Event:       event,
+		MutatedData: mutatedData,
+		Attempts:    attempts,
+		Error:       cause.Error(),
+		FailedAt:    time.Now().Unix(),
+	}
+	tctx, cancel := context.WithTimeout(ctx, l.StoreTimeout)
+	defer cancel()
+	if err := storev2.Of[*pipelinev1.DeadLetter](l.Store).CreateOrUpdate(tctx, letter); err != nil {
+		return fmt.Errorf("couldn't store dead letter: %w", err)
+	}
+	fields := utillogging.EventFields(event, false)
+	fields["handler"] = handler
+	fields["attempts"] = attempts
+	fields["dead_letter"] = letter.Metadata.Name
+	logger.WithFields(fields).WithError(cause).Warn("handler retries exhausted, event dead-lettered")
+	return nil
+}
+
+// ProcessRetries executes the queued handler retries until the context is
+// canceled. The retries are shared by all the backends: each one is
+// executed by the first backend that reserves it once it is due. The
+// reservation is only acknowledged once the outcome of the execution is
+// recorded, i.e. once the next retry is queued or the event is
+// dead-lettered, so that a retry whose execution is interrupted, by the
+// backend shutdown or a crash, returns to the queue.
+func (l *LegacyAdapter) ProcessRetries(ctx context.Context) {
+	for {
+		res, err := l.reserveRetry(ctx)
+		if err != nil {
+			return
+		}
+		if !l.processRetry(ctx, res) {
+			// Wait before reserving the released retry again
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(reserveBackoff):
+			}
+		}
+	}
+}
+
+// processRetry executes a reserved retry, and acknowledges its reservation
+// once the outcome is recorded. The reservation is released instead if the
+// execution is interrupted or its outcome can't be recorded, so that the
+// retry is reserved again. It returns false if the reservation is released.
+func (l *LegacyAdapter) processRetry(ctx context.Context, res queue.Reservation) bool {
+	item := res.Item()
+	var retry Retry
+	if err := json.Unmarshal(item.Value, &retry); err != nil {
+		logger.WithError(err).WithField("queue_item_id", item.ID).Error("error unmarshaling handler retry, dropping it")
+		if err := res.Ack(ctx); err != nil {
+			logger.WithError(err).WithField("queue_item_id", item.ID).Error("error acknowledging handler retry")
+		}
+		return true
+	}
+	// The outcome is recorded even if the context is canceled once the
+	// handler is executed
+	rctx, cancel := context.WithTimeout(context.Background(), l.StoreTimeout)
+	defer cancel()
+	if err := l.retry(ctx, &retry); err != nil {
+		logger.WithError(err).WithField("retry", retry.ID).Error("error retrying handler, releasing it")
+		if err := res.Nack(rctx); err != nil {
+			logger.WithError(err).WithField("retry", retry.ID).Error("error releasing handler retry")
+		}
+		return false
+	}
+	if err := res.Ack(rctx); err != nil {
+		// The retry stays queued, to be executed again
+		logger.WithError(err).WithField("retry", retry.ID).Error("error acknowledging handler retry")
+	}
+	return true
+}
+
+// reserveRetry reserves the next due retry. Errors are retried with an
+// exponential backoff until the context is canceled.
+func (l *LegacyAdapter) reserveRetry(ctx context.Context) (queue.Reservation, error) {
+	var res queue.Reservation
+	backoff := utilretry.ExponentialBackoff{
+		Ctx:                  ctx,
+		InitialDelayInterval: reserveBackoff,
+		MaxDelayInterval:     reserveMaxBackoff,
+	}
+	err := backoff.Retry(func(attempt int) (bool, error) {
+		var err error
+		res, err = l.Queue.Reserve(ctx, RetryQueueName)
+		if err != nil {
+			if ctx.Err() != nil {
+				return false, ctx.Err()
+			}
+			logger.WithError(err).Errorf("error reserving handler retry on attempt %d", attempt)
+			return false, nil
+		}
+		return true, nil
+	})
+	return res, err
+}
+
+// RetryDaemon is the daemon that executes the queued handler retries of an
+// adapter.
+type RetryDaemon struct {
+	adapter *LegacyAdapter
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	errChan chan error
+}
+
+// NewRetryDaemon creates a new RetryDaemon.
+func NewRetryDaemon(adapter *LegacyAdapter) *RetryDaemon {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &RetryDaemon{
+		adapter: adapter,
+		ctx:     ctx,
+		cancel:  cancel,
+		errChan: make(chan error, 1),
+	}
+}
+
+// Start starts the daemon.
+func (d *RetryDaemon) Start() error {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.adapter.ProcessRetries(d.ctx)
+	}()
+	return nil
+}
+
+// Stop stops the daemon. The execution of the retry in progress, if any, is
+// canceled and the retry returns to the queue.
+func (d *RetryDaemon) Stop() error {
+	d.cancel()
+	d.wg.Wait()
+	close(d.errChan)
+	return nil
+}
+
+// Err returns a channel that the caller can use to listen for terminal errors
+// indicating a premature shutdown of the Daemon.
+func (d *RetryDaemon) Err() <-chan error {
+	return d.errChan
+}
+
+// Name returns the daemon name.
+func (d *RetryDaemon) Name() string {
+	return "handler-retries"
+}
+
+// retry executes the handler of a retry, and queues another retry, or
+// dead-letters the event, if it fails again. It returns an error if the
+// retry must be executed again, i.e. if its execution is interrupted or its
+// outcome can't be recorded.
+func (l *LegacyAdapter) retry(ctx context.Context, retry *Retry) error {
+	if retry.Event == nil || retry.Event.Entity == nil {
+		logger.WithField("retry", retry.ID).Error("handler retry has no event, dropping it")
+		return nil
+	}
+	event := retry.Event
+	fields := utillogging.EventFields(event, false)
+	fields["handler"] = retry.Handler
+	fields["attempt"] = retry.Attempts + 1
+
+	tctx, cancel := context.WithTimeout(ctx, l.StoreTimeout)
+	handler, err := storev2.Of[*corev2.Handler](l.Store).Get(tctx, storev2.ID{Namespace: event.Entity.Namespace, Name: retry.Handler})
+	cancel()
+	if err != nil {
+		if _, ok := err.(*store.ErrNotFound); ok {
+			logger.WithFields(fields).Error("handler not found, dropping handler retry")
+			return nil
+		}
+		return fmt.Errorf("failed to fetch handler from store: %v", err)
+	}
+
+	err = l.execute(ctx, handler, event, retry.MutatedData)
+	if err == nil {
+		logger.WithFields(fields).Info("handler retry succeeded")
+		return nil
+	}
+	if ctx.Err() != nil {
+		// The execution was interrupted, it doesn't count as an attempt
+		return fmt.Errorf("handler retry interrupted: %w", ctx.Err())
+	}
+	logger.WithFields(fields).WithError(err).Error("handler retry failed")
+
+	policy := l.retryPolicy(handler, fields)
+	if policy == nil {
+		// The retries of a handler without retry policy, e.g. replayed
+		// dead letters, are not retried again.
+		policy = &RetryPolicy{}
+	}
+	return l.retryLater(ctx, policy, handler.Name, event, retry.MutatedData, retry.Attempts+1, err)
+}
+
+// execute executes a pipe, tcp or udp handler. Pipe handlers that exit with
+// a non-zero status fail.
+func (l *LegacyAdapter) execute(ctx context.Context, handler *corev2.Handler, event *corev2.Event, mutatedData []byte) error {
+	switch handler.Type {
+	case "pipe":
+		result, err := l.pipeHandler(ctx, handler, event, mutatedData)
+		if err != nil {
+			return err
+		}
+		if result.Status != 0 {
+			return fmt.Errorf("pipe handler exited with status %d: %s", result.Status, result.Output)
+		}
+		return nil
+	case "tcp", "udp":
+		return l.socketHandler(ctx, handler, event, mutatedData)
+	default:
+		return fmt.Errorf("handler type %q can't be retried", handler.Type)
+	}
+}
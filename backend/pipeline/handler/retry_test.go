@@ -0,0 +1,292 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	pipelinev1 "github.com/sensu/sensu-go/backend/pipeline/v1"
+	"github.com/sensu/sensu-go/backend/queue"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	"github.com/sensu/sensu-go/command"
+	"github.com/sensu/sensu-go/testing/mockexecutor"
+	"github.com/sensu/sensu-go/testing/mockstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRetryPolicy(t *testing.T) {
+	handler := corev2.FixtureHandler("handler1")
+	policy, err := GetRetryPolicy(handler)
+	require.NoError(t, err)
+	assert.Nil(t, policy)
+
+	handler.Annotations = map[string]string{RetryMaxAttemptsAnnotation: "3"}
+	policy, err = GetRetryPolicy(handler)
+	require.NoError(t, err)
+	assert.Equal(t, &RetryPolicy{MaxAttempts: 3, Backoff: DefaultRetryBackoff, MaxBackoff: DefaultRetryMaxBackoff}, policy)
+
+	handler.Annotations[RetryBackoffAnnotation] = "5"
+	handler.Annotations[RetryMaxBackoffAnnotation] = "30"
+	policy, err = GetRetryPolicy(handler)
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, policy.Delay(1))
+	assert.Equal(t, 10*time.Second, policy.Delay(2))
+	assert.Equal(t, 20*time.Second, policy.Delay(3))
+	assert.Equal(t, 30*time.Second, policy.Delay(4))
+	assert.Equal(t, 30*time.Second, policy.Delay(40))
+
+	for _, annotations := range []map[string]string{
+		{RetryMaxAttemptsAnnotation: "0"},
+		{RetryMaxAttemptsAnnotation: "many"},
+		{RetryMaxAttemptsAnnotation: "3", RetryBackoffAnnotation: "1m"},
+	} {
+		handler.Annotations = annotations
+		_, err = GetRetryPolicy(handler)
+		assert.Error(t, err)
+	}
+}
+
+// recordingQueue records the items enqueued to its client.
+type recordingQueue struct {
+	queue.Client
+	items []queue.Item
+}
+
+func (q *recordingQueue) Enqueue(ctx context.Context, item queue.Item) error {
+	q.items = append(q.items, item)
+	return q.Client.Enqueue(ctx, item)
+}
+
+func retryingAdapter(handler *corev2.Handler, status int) (*LegacyAdapter, *mockstore.ConfigStore, *recordingQueue) {
+	st := new(mockstore.V2MockStore)
+	cs := new(mockstore.ConfigStore)
+	st.On("GetConfigStore").Return(cs)
+	cs.On("Get", mock.Anything, mock.Anything).Return(mockstore.Wrapper[*corev2.Handler]{Value: handler}, nil)
+	cs.On("CreateOrUpdate", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	executor := &mockexecutor.MockExecutor{}
+	executor.Return(command.FixtureExecutionResponse(status, "output"), nil)
+	q := &recordingQueue{Client: queue.NewMemoryClient()}
+	return &LegacyAdapter{
+		Executor:     executor,
+		Store:        st,
+		StoreTimeout: time.Second,
+		Queue:        q,
+	}, cs, q
+}
+
+func enqueuedRetry(t *testing.T, q *recordingQueue) (*Retry, queue.Item) {
+	t.Helper()
+	require.Len(t, q.items, 1)
+	item := q.items[0]
+	assert.Equal(t, RetryQueueName, item.Queue)
+	var retry Retry
+	require.NoError(t, json.Unmarshal(item.Value, &retry))
+	return &retry, item
+}
+
+func TestLegacyAdapterRetries(t *testing.T) {
+	handler := corev2.FixtureHandler("handler1")
+	handler.Annotations = map[string]string{
+		RetryMaxAttemptsAnnotation: "2",
+		RetryBackoffAnnotation:     "60",
+	}
+	adapter, cs, q := retryingAdapter(handler, 2)
+	event := corev2.FixtureEvent("entity1", "check1")
+	ref := &corev2.ResourceReference{APIVersion: "core/v2", Type: "Handler", Name: "handler1"}
+
+	// The failed execution is queued for a retry
+	before := time.Now()
+	require.NoError(t, adapter.Handle(context.Background(), ref, event, []byte("data")))
+	retry, item := enqueuedRetry(t, q)
+	assert.Equal(t, "handler1", retry.Handler)
+	assert.Equal(t, uint32(1), retry.Attempts)
+	assert.Equal(t, []byte("data"), retry.MutatedData)
+	assert.Contains(t, retry.Error, "status 2")
+	assert.False(t, retry.NotBefore.Before(before.Add(time.Minute)))
+
+	// The retry isn't due before its backoff
+	assert.True(t, item.NotBefore.Equal(retry.NotBefore))
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := q.Reserve(ctx, RetryQueueName)
+	assert.Error(t, err)
+
+	// The retry fails too and exhausts the policy
+	require.NoError(t, adapter.retry(context.Background(), retry))
+	cs.AssertCalled(t, "CreateOrUpdate", mock.Anything, mock.Anything, mock.Anything)
+	req := cs.Calls[len(cs.Calls)-1].Arguments.Get(1).(storev2.ResourceRequest)
+	assert.Equal(t, "pipeline/dead-letters", req.StoreName)
+	assert.Equal(t, "default", req.Namespace)
+}
+
+func TestLegacyAdapterNoRetryPolicy(t *testing.T) {
+	adapter, cs, q := retryingAdapter(corev2.FixtureHandler("handler1"), 2)
+	event := corev2.FixtureEvent("entity1", "check1")
+	ref := &corev2.ResourceReference{APIVersion: "core/v2", Type: "Handler", Name: "handler1"}
+	require.NoError(t, adapter.Handle(context.Background(), ref, event, nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := q.Reserve(ctx, RetryQueueName)
+	assert.Error(t, err)
+	cs.AssertNotCalled(t, "CreateOrUpdate", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestProcessRetries(t *testing.T) {
+	handler := corev2.FixtureHandler("handler1")
+	adapter, _, q := retryingAdapter(handler, 0)
+	executed := make(chan command.ExecutionRequest, 1)
+	adapter.Executor.(*mockexecutor.MockExecutor).SetRequestFunc(func(_ context.Context, req command.ExecutionRequest) {
+		executed <- req
+	})
+
+	letter := &pipelinev1.DeadLetter{
+		Handler:     "handler1",
+		Event:       corev2.FixtureEvent("entity1", "check1"),
+		MutatedData: []byte("data"),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, Replay(ctx, q, letter))
+	go adapter.ProcessRetries(ctx)
+
+	select {
+	case req := <-executed:
+		assert.Equal(t, "data", req.Input)
+	case <-time.After(5 * time.Second):
+		t.Fatal("replayed dead letter not executed")
+	}
+}
+
+// failingQueue is a queue client whose reservations fail.
+type failingQueue struct {
+	queue.Client
+	reserves int32
+}
+
+func (q *failingQueue) Reserve(ctx context.Context, name string) (queue.Reservation, error) {
+	atomic.AddInt32(&q.reserves, 1)
+	return nil, errors.New("reserve error")
+}
+
+func TestProcessRetriesBackoff(t *testing.T) {
+	adapter, _, _ := retryingAdapter(corev2.FixtureHandler("handler1"), 0)
+	q := &failingQueue{}
+	adapter.Queue = q
+
+	daemon := NewRetryDaemon(adapter)
+	require.NoError(t, daemon.Start())
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, daemon.Stop())
+
+	// The reservation is retried after a backoff, not in a busy loop
+	assert.Equal(t, int32(1), atomic.LoadInt32(&q.reserves))
+	_, ok := <-daemon.Err()
+	assert.False(t, ok)
+}
+
+// ackingQueue signals the acknowledgement of its reservations.
+type ackingQueue struct {
+	queue.Client
+	acked chan struct{}
+}
+
+func (q *ackingQueue) Reserve(ctx context.Context, name string) (queue.Reservation, error) {
+	res, err := q.Client.Reserve(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return &ackingReservation{Reservation: res, acked: q.acked}, nil
+}
+
+type ackingReservation struct {
+	queue.Reservation
+	acked chan struct{}
+}
+
+func (r *ackingReservation) Ack(ctx context.Context) error {
+	close(r.acked)
+	return r.Reservation.Ack(ctx)
+}
+
+func TestProcessRetriesAcksAfterExecuting(t *testing.T) {
+	adapter, _, q := retryingAdapter(corev2.FixtureHandler("handler1"), 0)
+	acked := make(chan struct{})
+	adapter.Queue = &ackingQueue{Client: q, acked: acked}
+	executed := make(chan bool, 1)
+	adapter.Executor.(*mockexecutor.MockExecutor).SetRequestFunc(func(context.Context, command.ExecutionRequest) {
+		select {
+		case <-acked:
+			executed <- true
+		default:
+			executed <- false
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, Replay(ctx, q, &pipelinev1.DeadLetter{Handler: "handler1", Event: corev2.FixtureEvent("entity1", "check1")}))
+	go adapter.ProcessRetries(ctx)
+
+	select {
+	case wasAcked := <-executed:
+		assert.False(t, wasAcked, "retry acknowledged before its execution")
+	case <-time.After(5 * time.Second):
+		t.Fatal("retry not executed")
+	}
+	select {
+	case <-acked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("retry not acknowledged")
+	}
+}
+
+func TestProcessRetriesInterrupted(t *testing.T) {
+	adapter, _, q := retryingAdapter(corev2.FixtureHandler("handler1"), 2)
+	started := make(chan struct{})
+	adapter.Executor.(*mockexecutor.MockExecutor).SetRequestFunc(func(ctx context.Context, _ command.ExecutionRequest) {
+		// The execution lasts until the backend shuts down
+		close(started)
+		<-ctx.Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, Replay(ctx, q, &pipelinev1.DeadLetter{Handler: "handler1", Event: corev2.FixtureEvent("entity1", "check1")}))
+	replayed, _ := enqueuedRetry(t, q)
+	done := make(chan struct{})
+	go func() {
+		adapter.ProcessRetries(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("retry not executed")
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("retries still processed")
+	}
+
+	// The interrupted retry is back in the queue, and isn't counted as an
+	// attempt
+	assert.Len(t, q.items, 1)
+	rctx, rcancel := context.WithTimeout(context.Background(), time.Second)
+	defer rcancel()
+	res, err := q.Reserve(rctx, RetryQueueName)
+	require.NoError(t, err)
+	var retry Retry
+	require.NoError(t, json.Unmarshal(res.Item().Value, &retry))
+	assert.Equal(t, replayed.ID, retry.ID)
+	assert.Equal(t, uint32(0), retry.Attempts)
+}
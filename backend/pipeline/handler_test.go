@@ -3,13 +3,18 @@ package pipeline
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
 	"time"
 
 	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/pipeline/handler"
 	storev2 "github.com/sensu/sensu-go/backend/store/v2"
 	"github.com/sensu/sensu-go/testing/mockpipeline"
+	"github.com/sensu/sensu-go/testing/mockstore"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAdapterV1_processHandler(t *testing.T) {
@@ -225,3 +230,139 @@ func TestAdapterV1_getHandlerAdapterForResource(t *testing.T) {
 		})
 	}
 }
+
+// setHandlerAdapter is a handler adapter that behaves like the legacy
+// handler adapter for handler sets, and records the handlers it executes
+// with their mutated data.
+type setHandlerAdapter struct {
+	sets    map[string]*corev2.Handler
+	slow    string
+	failing string
+
+	mu      sync.Mutex
+	handled []string
+}
+
+func (s *setHandlerAdapter) Name() string {
+	return "setHandlerAdapter"
+}
+
+func (s *setHandlerAdapter) CanHandle(*corev2.ResourceReference) bool {
+	return true
+}
+
+func (s *setHandlerAdapter) Handle(ctx context.Context, ref *corev2.ResourceReference, event *corev2.Event, data []byte) error {
+	if set, ok := s.sets[ref.Name]; ok {
+		return &handler.ErrHandlerSet{Handler: set}
+	}
+	if ref.Name == s.slow {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if ref.Name == s.failing {
+		return errors.New("handler error")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handled = append(s.handled, ref.Name+":"+string(data))
+	return nil
+}
+
+// namedMutatorAdapter mutates the events into the name of the mutator.
+type namedMutatorAdapter struct{}
+
+func (namedMutatorAdapter) Name() string {
+	return "namedMutatorAdapter"
+}
+
+func (namedMutatorAdapter) CanMutate(*corev2.ResourceReference) bool {
+	return true
+}
+
+func (namedMutatorAdapter) Mutate(ctx context.Context, ref *corev2.ResourceReference, event *corev2.Event) ([]byte, error) {
+	return []byte(ref.Name), nil
+}
+
+// denyFilterAdapter denies the events with the filter named deny.
+type denyFilterAdapter struct{}
+
+func (denyFilterAdapter) Name() string {
+	return "denyFilterAdapter"
+}
+
+func (denyFilterAdapter) CanFilter(*corev2.ResourceReference) bool {
+	return true
+}
+
+func (denyFilterAdapter) Filter(ctx context.Context, ref *corev2.ResourceReference, event *corev2.Event) (bool, error) {
+	return ref.Name == "deny", nil
+}
+
+func newHandlerSetAdapter(t *testing.T) (*AdapterV1, *setHandlerAdapter) {
+	t.Helper()
+	handlerSet := func(name string, members ...string) *corev2.Handler {
+		return &corev2.Handler{
+			ObjectMeta: corev2.NewObjectMeta(name, "default"),
+			Type:       corev2.HandlerSetType,
+			Handlers:   members,
+		}
+	}
+	outer := handlerSet("outer", "inner", "slow", "failing", "pipe1")
+	inner := handlerSet("inner", "pipe2", "outer", "pipe3")
+	slow := corev2.FixtureHandler("slow")
+	slow.Timeout = 1
+	pipe1 := corev2.FixtureHandler("pipe1")
+	pipe1.Filters = []string{"allow", "deny"}
+	pipe2 := corev2.FixtureHandler("pipe2")
+	pipe2.Mutator = "upper"
+
+	stor := &mockstore.V2MockStore{}
+	cs := new(mockstore.ConfigStore)
+	stor.On("GetConfigStore").Return(cs)
+	for _, h := range []*corev2.Handler{outer, inner, slow, pipe1, pipe2, corev2.FixtureHandler("failing"), corev2.FixtureHandler("pipe3")} {
+		h.Namespace = "default"
+		cs.On("Get", mock.Anything, storev2.NewResourceRequestFromResource(h)).
+			Return(mockstore.Wrapper[*corev2.Handler]{Value: h}, nil)
+	}
+
+	adapter := &setHandlerAdapter{
+		sets:    map[string]*corev2.Handler{"outer": outer, "inner": inner},
+		slow:    "slow",
+		failing: "failing",
+	}
+	return &AdapterV1{
+		Store:           stor,
+		StoreTimeout:    time.Second,
+		FilterAdapters:  []FilterAdapter{denyFilterAdapter{}},
+		MutatorAdapters: []MutatorAdapter{namedMutatorAdapter{}},
+		HandlerAdapters: []HandlerAdapter{adapter},
+	}, adapter
+}
+
+func TestAdapterV1_processHandlerSet(t *testing.T) {
+	a, adapter := newHandlerSetAdapter(t)
+	ref := &corev2.ResourceReference{APIVersion: "core/v2", Type: "Handler", Name: "outer"}
+	ctx := context.WithValue(context.Background(), corev2.NamespaceKey, "default")
+
+	start := time.Now()
+	err := a.processHandler(ctx, ref, corev2.FixtureEvent("entity1", "check1"), nil)
+	require.Error(t, err)
+	assert.Equal(t, `2 of 5 handlers in set "outer" failed: failing, slow`, err.Error())
+	assert.Less(t, time.Since(start), 10*time.Second)
+
+	// Every member runs with its own filters and mutator: pipe1 is filtered
+	assert.ElementsMatch(t, []string{"pipe2:upper", "pipe3:json"}, adapter.handled)
+}
+
+func TestAdapterV1_RunLegacyHandlerSet(t *testing.T) {
+	a, adapter := newHandlerSetAdapter(t)
+	event := corev2.FixtureEvent("entity1", "check1")
+	event.Check.Handlers = []string{"outer", "pipe3"}
+
+	err := a.Run(context.Background(), LegacyPipelineReference(), event)
+	require.Error(t, err)
+	assert.Equal(t, "2 of 5 handlers failed: failing, slow", err.Error())
+
+	// The handlers referenced by several sets run once
+	assert.ElementsMatch(t, []string{"pipe2:upper", "pipe3:json"}, adapter.handled)
+}
@@ -12,13 +12,19 @@ import (
 	"github.com/robertkrimen/otto"
 	corev2 "github.com/sensu/core/v2"
 	"github.com/sensu/sensu-go/asset"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
 	"github.com/sensu/sensu-go/js"
+	utillogging "github.com/sensu/sensu-go/util/logging"
 	"github.com/sirupsen/logrus"
 )
 
 const (
 	// JavascriptAdapterName is the name of the mutator adapter.
 	JavascriptAdapterName = "JavascriptAdapter"
+
+	// DefaultJavascriptTimeout is the maximum execution time of a javascript
+	// mutator that does not specify a timeout.
+	DefaultJavascriptTimeout = 10 * time.Second
 )
 
 var (
@@ -26,9 +32,16 @@ var (
 )
 
 // JavascriptAdapter is a mutator adapter which mutates an event using
-// javascript.
+// javascript. The value returned by the mutator expression replaces the event
+// payload; if nothing is returned, the mutated event is serialized instead.
 type JavascriptAdapter struct {
-	AssetGetter asset.Getter
+	AssetGetter  asset.Getter
+	Store        storev2.Interface
+	StoreTimeout time.Duration
+
+	// Timeout is the maximum execution time of mutators that do not specify
+	// one. Defaults to DefaultJavascriptTimeout.
+	Timeout time.Duration
 }
 
 // Name returns the name of the mutator adapter.
@@ -37,31 +50,69 @@ func (j *JavascriptAdapter) Name() string {
 }
 
 // CanMutate determines whether JavascriptAdapter can mutate the resource
-// being referenced.
-// TODO: update this function if/when we implement a proper JavascriptMutator type
+// being referenced. Javascript mutators are core/v2 mutators and can only be
+// told apart by their type once retrieved, so they are routed to this adapter
+// by LegacyAdapter.
 func (j *JavascriptAdapter) CanMutate(ref *corev2.ResourceReference) bool {
 	return false
 }
 
-// Mutate will mutate the event and return it as bytes.
-// TODO: update this function if/when we implement a proper JavascriptMutator type
+// Mutate retrieves the referenced javascript mutator and uses it to mutate
+// the event, returning the resulting payload.
 func (j *JavascriptAdapter) Mutate(ctx context.Context, ref *corev2.ResourceReference, event *corev2.Event) ([]byte, error) {
-	// eventData, err := j.run(ctx, mutator, event, assets)
-	// if err != nil {
-	// 	return nil, err
-	// }
-	return nil, fmt.Errorf("mutator adapter cannot be used directly at this time")
+	if j.Store == nil {
+		return nil, errors.New("javascript mutator adapter has no store")
+	}
+	fields := utillogging.EventFields(event, false)
+	fields["pipeline"] = corev2.ContextPipeline(ctx)
+	fields["pipeline_workflow"] = corev2.ContextPipelineWorkflow(ctx)
+
+	ctx = context.WithValue(ctx, corev2.NamespaceKey, event.Entity.Namespace)
+	tctx, cancel := context.WithTimeout(ctx, j.StoreTimeout)
+	mstore := storev2.Of[*corev2.Mutator](j.Store)
+	id := storev2.ID{Namespace: event.Entity.Namespace, Name: ref.Name}
+	mutator, err := mstore.Get(tctx, id)
+	cancel()
+	if err != nil {
+		// Warning: do not wrap this error
+		logger.WithFields(fields).WithError(err).Error("failed to retrieve mutator")
+		return nil, err
+	}
+	if mutator == nil {
+		return nil, fmt.Errorf("mutator %q does not exist", ref.Name)
+	}
+	if mutator.Type != corev2.JavascriptMutator {
+		return nil, fmt.Errorf("mutator %q is not a javascript mutator", ref.Name)
+	}
+
+	var assets asset.RuntimeAssetSet
+	if len(mutator.RuntimeAssets) > 0 {
+		matchedAssets := asset.GetAssets(ctx, j.Store, mutator.RuntimeAssets)
+		assets, err = asset.GetAll(ctx, j.AssetGetter, matchedAssets)
+		if err != nil {
+			logger.WithFields(fields).WithError(err).Error("failed to retrieve assets for mutator")
+			return nil, err
+		}
+	}
+
+	return j.run(ctx, mutator, event, assets)
 }
 
 func (j *JavascriptAdapter) run(ctx context.Context, mutator *corev2.Mutator, event *corev2.Event, assets js.JavascriptAssets) ([]byte, error) {
 	ctx = corev2.SetContextFromResource(ctx, mutator)
 
-	// Use a context with a timeout if the mutator has a timeout set
-	var cancel context.CancelFunc
-	if mutator.Timeout > 0 {
-		ctx, cancel = context.WithTimeout(ctx, time.Second*time.Duration(mutator.Timeout))
-		defer cancel()
+	// Bound the execution time with the mutator timeout, or the adapter
+	// default if the mutator has none
+	timeout := time.Duration(mutator.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = j.Timeout
+	}
+	if timeout <= 0 {
+		timeout = DefaultJavascriptTimeout
 	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	// Prepare log entry
 	fields := logrus.Fields{
 		"namespace":         mutator.Namespace,
@@ -71,6 +122,13 @@ func (j *JavascriptAdapter) run(ctx context.Context, mutator *corev2.Mutator, ev
 		"pipeline_workflow": corev2.ContextPipelineWorkflow(ctx),
 	}
 
+	// The mutator works on a copy of the event, so that its changes only
+	// affect the payload of this workflow.
+	event, err := copyEvent(event)
+	if err != nil {
+		return nil, err
+	}
+
 	// Guard against nil metadata labels and annotations to improve the user
 	// experience of querying these them.
 	if event.ObjectMeta.Annotations == nil {
@@ -79,23 +137,27 @@ func (j *JavascriptAdapter) run(ctx context.Context, mutator *corev2.Mutator, ev
 	if event.ObjectMeta.Labels == nil {
 		event.ObjectMeta.Labels = make(map[string]string)
 	}
-	if event.Check.ObjectMeta.Annotations == nil {
-		event.Check.ObjectMeta.Annotations = make(map[string]string)
-	}
-	if event.Check.ObjectMeta.Labels == nil {
-		event.Check.ObjectMeta.Labels = make(map[string]string)
-	}
-	if event.Entity.ObjectMeta.Annotations == nil {
-		event.Entity.ObjectMeta.Annotations = make(map[string]string)
+	if event.Check != nil {
+		if event.Check.ObjectMeta.Annotations == nil {
+			event.Check.ObjectMeta.Annotations = make(map[string]string)
+		}
+		if event.Check.ObjectMeta.Labels == nil {
+			event.Check.ObjectMeta.Labels = make(map[string]string)
+		}
 	}
-	if event.Entity.ObjectMeta.Labels == nil {
-		event.Entity.ObjectMeta.Labels = make(map[string]string)
+	if event.Entity != nil {
+		if event.Entity.ObjectMeta.Annotations == nil {
+			event.Entity.ObjectMeta.Annotations = make(map[string]string)
+		}
+		if event.Entity.ObjectMeta.Labels == nil {
+			event.Entity.ObjectMeta.Labels = make(map[string]string)
+		}
 	}
 
 	env := MutatorExecutionEnvironment{
 		Event:   event,
 		Env:     mutator.EnvVars,
-		Timeout: time.Duration(mutator.Timeout) * time.Second,
+		Timeout: timeout,
 		Assets:  assets,
 	}
 
@@ -104,6 +166,18 @@ func (j *JavascriptAdapter) run(ctx context.Context, mutator *corev2.Mutator, ev
 	return env.Eval(ctx, mutator.Eval)
 }
 
+func copyEvent(event *corev2.Event) (*corev2.Event, error) {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't copy event: %s", err)
+	}
+	var result corev2.Event
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, fmt.Errorf("couldn't copy event: %s", err)
+	}
+	return &result, nil
+}
+
 type MutatorExecutionEnvironment struct {
 	// Env is the "environment" of the mutator
 	Env []string
@@ -150,22 +224,28 @@ func (m *MutatorExecutionEnvironment) Eval(ctx context.Context, expression strin
 			}
 		}()
 		done := make(chan struct{})
+		defer close(done)
+		var timeout <-chan time.Time
 		if m.Timeout > 0 {
-			go func() {
-				select {
-				case <-time.After(m.Timeout):
-					vm.Interrupt <- func() {
-						panic(errHalt)
-					}
-				case <-done:
-				}
-			}()
+			timer := time.NewTimer(m.Timeout)
+			defer timer.Stop()
+			timeout = timer.C
 		}
+		go func() {
+			select {
+			case <-timeout:
+			case <-ctx.Done():
+			case <-done:
+				return
+			}
+			vm.Interrupt <- func() {
+				panic(errHalt)
+			}
+		}()
 		value, err := vm.Run(fmt.Sprintf("(function () { %s }())", expression))
 		if err != nil {
 			return err
 		}
-		close(done)
 		if value.IsUndefined() || value.IsNull() {
 			result, err = json.Marshal(m.Event)
 		} else if value.IsString() {
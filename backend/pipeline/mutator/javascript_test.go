@@ -15,7 +15,9 @@ import (
 	"github.com/sensu/sensu-go/asset"
 	"github.com/sensu/sensu-go/backend/store"
 	"github.com/sensu/sensu-go/js"
+	"github.com/sensu/sensu-go/testing/mockstore"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 type mutatorAssetSet struct{}
@@ -308,3 +310,91 @@ func TestJavascriptMutatorWithOSEnv(t *testing.T) {
 		t.Error("expected non-nil error")
 	}
 }
+
+func TestJavascriptAdapterMutateFromStore(t *testing.T) {
+	mutator := &corev2.Mutator{
+		ObjectMeta: corev2.ObjectMeta{
+			Namespace: "default",
+			Name:      "my_mutator",
+		},
+		Eval: `return JSON.stringify({"status": event.check.status});`,
+		Type: corev2.JavascriptMutator,
+	}
+	stor := &mockstore.V2MockStore{}
+	cs := new(mockstore.ConfigStore)
+	stor.On("GetConfigStore").Return(cs)
+	cs.On("Get", mock.Anything, mock.Anything).Return(mockstore.Wrapper[*corev2.Mutator]{Value: mutator}, nil)
+
+	adapter := &JavascriptAdapter{Store: stor, StoreTimeout: time.Second}
+	event := corev2.FixtureEvent("default", "default")
+	event.Check.Status = 2
+	ref := &corev2.ResourceReference{APIVersion: "core/v2", Type: "Mutator", Name: "my_mutator"}
+	got, err := adapter.Mutate(context.Background(), ref, event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.JSONEq(t, `{"status": 2}`, string(got))
+
+	mutator.Type = corev2.PipeMutator
+	if _, err := adapter.Mutate(context.Background(), ref, event); err == nil {
+		t.Error("expected non-nil error")
+	}
+}
+
+func TestJavascriptAdapterDoesNotModifyEvent(t *testing.T) {
+	adapter := &JavascriptAdapter{}
+	mutator := &corev2.Mutator{
+		ObjectMeta: corev2.ObjectMeta{
+			Namespace: "default",
+			Name:      "my_mutator",
+		},
+		Eval: `event.check.output = "mutated"`,
+		Type: corev2.JavascriptMutator,
+	}
+	event := corev2.FixtureEvent("default", "default")
+	event.Check.Output = "original"
+	got, err := adapter.run(context.Background(), mutator, event, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mutated corev2.Event
+	if err := json.Unmarshal(got, &mutated); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "mutated", mutated.Check.Output)
+	assert.Equal(t, "original", event.Check.Output)
+}
+
+func TestJavascriptAdapterDefaultTimeout(t *testing.T) {
+	adapter := &JavascriptAdapter{Timeout: 100 * time.Millisecond}
+	mutator := &corev2.Mutator{
+		ObjectMeta: corev2.ObjectMeta{
+			Namespace: "default",
+			Name:      "my_mutator",
+		},
+		Eval: `while(true){}`,
+		Type: corev2.JavascriptMutator,
+	}
+	_, err := adapter.run(context.Background(), mutator, corev2.FixtureEvent("default", "default"), nil)
+	if err == nil || err.Error() != "mutator timeout reached, execution halted" {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+}
+
+func TestJavascriptAdapterContextCanceled(t *testing.T) {
+	adapter := &JavascriptAdapter{}
+	mutator := &corev2.Mutator{
+		ObjectMeta: corev2.ObjectMeta{
+			Namespace: "default",
+			Name:      "my_mutator",
+		},
+		Eval: `while(true){}`,
+		Type: corev2.JavascriptMutator,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := adapter.run(ctx, mutator, corev2.FixtureEvent("default", "default"), nil)
+	if err == nil {
+		t.Error("expected non-nil error")
+	}
+}
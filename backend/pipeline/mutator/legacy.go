@@ -37,6 +37,10 @@ type LegacyAdapter struct {
 	SecretsProviderManager *secrets.ProviderManager
 	Store                  storev2.Interface
 	StoreTimeout           time.Duration
+
+	// JavascriptAdapter runs the mutators of type javascript. If nil, a
+	// JavascriptAdapter with default settings is used.
+	JavascriptAdapter *JavascriptAdapter
 }
 
 // Name returns the name of the mutator adapter.
@@ -107,8 +111,13 @@ func (l *LegacyAdapter) Mutate(ctx context.Context, ref *corev2.ResourceReferenc
 		}
 		eventData, err = pipeMutator.run(ctx, mutator, event, assets)
 	} else if mutator.Type == corev2.JavascriptMutator {
-		javascriptMutator := JavascriptAdapter{
-			AssetGetter: l.AssetGetter,
+		javascriptMutator := l.JavascriptAdapter
+		if javascriptMutator == nil {
+			javascriptMutator = &JavascriptAdapter{
+				AssetGetter:  l.AssetGetter,
+				Store:        l.Store,
+				StoreTimeout: l.StoreTimeout,
+			}
 		}
 		eventData, err = javascriptMutator.run(ctx, mutator, event, assets)
 	}
@@ -0,0 +1,202 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/pipeline/handler"
+	pipelinev1 "github.com/sensu/sensu-go/backend/pipeline/v1"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+// FilterExplainer is implemented by the filter adapters that can explain why
+// they allow or deny events, for pipeline traces.
+type FilterExplainer interface {
+	Explain(context.Context, *corev2.ResourceReference, *corev2.Event) (bool, string, error)
+}
+
+// Trace runs the event through the referenced pipeline like Run, and records
+// every filter, mutator and handler the event goes through. Handlers are only
+// executed if execute is true, without retries nor dead letters; otherwise
+// the trace describes what they would run. The returned error is only about resolving the pipeline: the errors of
+// its workflows are recorded in the trace.
+func (a *AdapterV1) Trace(ctx context.Context, ref *corev2.ResourceReference, event *corev2.Event, execute bool) (*pipelinev1.PipelineTrace, error) {
+	ctx = context.WithValue(ctx, corev2.NamespaceKey, event.Entity.Namespace)
+	ctx = handler.WithoutRetries(ctx)
+
+	pipeline, err := a.resolvePipelineReference(ctx, ref, event)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, corev2.PipelineKey, pipeline.Name)
+
+	trace := &pipelinev1.PipelineTrace{
+		Pipeline:  pipeline.Name,
+		Executed:  execute,
+		Workflows: []*pipelinev1.WorkflowTrace{},
+	}
+	if len(pipeline.Workflows) < 1 {
+		trace.Error = (&ErrNoWorkflows{}).Error()
+		return trace, nil
+	}
+
+	for _, workflow := range pipeline.Workflows {
+		ctx := context.WithValue(ctx, corev2.PipelineWorkflowKey, workflow.Name)
+		workflowTrace := &pipelinev1.WorkflowTrace{Name: workflow.Name}
+		trace.Workflows = append(trace.Workflows, workflowTrace)
+
+		// The workflow errors stop the pipeline, as they do in Run
+		if err := a.traceWorkflow(ctx, workflow, event, execute, workflowTrace); err != nil {
+			trace.Error = err.Error()
+			break
+		}
+	}
+
+	return trace, nil
+}
+
+func (a *AdapterV1) traceWorkflow(ctx context.Context, workflow *corev2.PipelineWorkflow, event *corev2.Event, execute bool, trace *pipelinev1.WorkflowTrace) error {
+	for _, ref := range workflow.Filters {
+		filterTrace := a.traceFilter(ctx, ref, event)
+		trace.Filters = append(trace.Filters, filterTrace)
+		if filterTrace.Error != "" {
+			return errors.New(filterTrace.Error)
+		}
+		if filterTrace.Denied {
+			trace.Filtered = true
+			return nil
+		}
+	}
+
+	mutatorRef := workflow.Mutator
+	if mutatorRef == nil {
+		mutatorRef = &corev2.ResourceReference{
+			APIVersion: "core/v2",
+			Type:       "Mutator",
+			Name:       "json",
+		}
+	}
+	trace.Mutator = &pipelinev1.MutatorTrace{Reference: mutatorRef}
+	mutator, err := a.getMutatorAdapterForResource(ctx, mutatorRef)
+	if err != nil {
+		trace.Mutator.Error = err.Error()
+		return err
+	}
+	trace.Mutator.Adapter = mutator.Name()
+	mutatedData, err := mutator.Mutate(ctx, mutatorRef, event)
+	if err != nil {
+		trace.Mutator.Error = err.Error()
+		return err
+	}
+	trace.Mutator.Output = string(mutatedData)
+
+	trace.Handler = a.describeHandler(ctx, workflow.Handler, event.Entity.Namespace)
+	if !execute || trace.Handler.Error != "" {
+		return nil
+	}
+	err = a.processHandler(ctx, workflow.Handler, event, mutatedData)
+	trace.Handler.Executed = true
+	for _, member := range trace.Handler.Members {
+		member.Executed = true
+	}
+	if err != nil {
+		trace.Handler.Error = err.Error()
+		return err
+	}
+	return nil
+}
+
+func (a *AdapterV1) traceFilter(ctx context.Context, ref *corev2.ResourceReference, event *corev2.Event) *pipelinev1.FilterTrace {
+	trace := &pipelinev1.FilterTrace{Reference: ref}
+	filter, err := a.getFilterAdapterForResource(ctx, ref)
+	if err != nil {
+		trace.Error = err.Error()
+		return trace
+	}
+	trace.Adapter = filter.Name()
+
+	if explainer, ok := filter.(FilterExplainer); ok {
+		trace.Denied, trace.Reason, err = explainer.Explain(ctx, ref, event)
+	} else {
+		trace.Denied, err = filter.Filter(ctx, ref, event)
+		if trace.Denied {
+			trace.Reason = fmt.Sprintf("denied by the %s filter", ref.Name)
+		}
+	}
+	if err != nil {
+		trace.Error = err.Error()
+	}
+	return trace
+}
+
+// describeHandler describes what the handler does, without executing it. The
+// core/v2 handlers are fetched from the store, and the handler sets are
+// expanded.
+func (a *AdapterV1) describeHandler(ctx context.Context, ref *corev2.ResourceReference, namespace string) *pipelinev1.HandlerTrace {
+	trace := &pipelinev1.HandlerTrace{Reference: ref}
+	adapter, err := a.getHandlerAdapterForResource(ctx, ref)
+	if err != nil {
+		trace.Error = err.Error()
+		return trace
+	}
+	trace.Adapter = adapter.Name()
+	if adapter.Name() != handler.LegacyAdapterName {
+		return trace
+	}
+
+	tctx, cancel := context.WithTimeout(ctx, a.StoreTimeout)
+	h, err := storev2.Of[*corev2.Handler](a.Store).Get(tctx, storev2.ID{Namespace: namespace, Name: ref.Name})
+	cancel()
+	if err != nil {
+		if _, ok := err.(*store.ErrNotFound); ok {
+			trace.Error = "handler not found, it would be skipped"
+		} else {
+			trace.Error = fmt.Sprintf("failed to fetch handler from store: %v", err)
+		}
+		return trace
+	}
+	trace.Type = h.Type
+
+	switch h.Type {
+	case "pipe":
+		trace.Command = h.Command
+	case "tcp", "udp":
+		if h.Socket != nil {
+			trace.Address = net.JoinHostPort(h.Socket.Host, fmt.Sprint(h.Socket.Port))
+		}
+	case corev2.HandlerSetType:
+		members, err := a.expandHandlers(ctx, namespace, h.Handlers, []string{h.Name})
+		if err != nil {
+			trace.Error = err.Error()
+			return trace
+		}
+		names := make([]string, 0, len(members))
+		for name := range members {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			member := members[name]
+			memberTrace := &pipelinev1.HandlerTrace{
+				Reference: &corev2.ResourceReference{
+					APIVersion: "core/v2",
+					Type:       "Handler",
+					Name:       member.Name,
+				},
+				Adapter: adapter.Name(),
+				Type:    member.Type,
+				Command: member.Command,
+			}
+			if member.Socket != nil {
+				memberTrace.Address = net.JoinHostPort(member.Socket.Host, fmt.Sprint(member.Socket.Port))
+			}
+			trace.Members = append(trace.Members, memberTrace)
+		}
+	}
+	return trace
+}
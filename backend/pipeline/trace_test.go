@@ -0,0 +1,198 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/pipeline/filter"
+	"github.com/sensu/sensu-go/backend/pipeline/handler"
+	"github.com/sensu/sensu-go/backend/pipeline/mutator"
+	"github.com/sensu/sensu-go/backend/queue"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	"github.com/sensu/sensu-go/command"
+	"github.com/sensu/sensu-go/testing/mockexecutor"
+	"github.com/sensu/sensu-go/testing/mockstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func traceAdapter(t *testing.T, pipeline *corev2.Pipeline, handlers ...*corev2.Handler) (*AdapterV1, *bool) {
+	t.Helper()
+	stor := &mockstore.V2MockStore{}
+	cs := new(mockstore.ConfigStore)
+	stor.On("GetConfigStore").Return(cs)
+	isPipeline := func(req storev2.ResourceRequest) bool {
+		return req.Type == "Pipeline"
+	}
+	if pipeline != nil {
+		cs.On("Get", mock.Anything, mock.MatchedBy(isPipeline)).Return(mockstore.Wrapper[*corev2.Pipeline]{Value: pipeline}, nil)
+	} else {
+		cs.On("Get", mock.Anything, mock.MatchedBy(isPipeline)).Return(nil, &store.ErrNotFound{})
+	}
+	for _, h := range handlers {
+		name := h.Name
+		cs.On("Get", mock.Anything, mock.MatchedBy(func(req storev2.ResourceRequest) bool {
+			return !isPipeline(req) && req.Name == name
+		})).Return(mockstore.Wrapper[*corev2.Handler]{Value: h}, nil)
+	}
+	cs.On("Get", mock.Anything, mock.Anything).Return(nil, &store.ErrNotFound{})
+
+	executed := false
+	ex := &mockexecutor.MockExecutor{}
+	ex.Return(command.FixtureExecutionResponse(0, "ok"), nil)
+	ex.SetRequestFunc(func(context.Context, command.ExecutionRequest) {
+		executed = true
+	})
+
+	return &AdapterV1{
+		Store:           stor,
+		FilterAdapters:  []FilterAdapter{&filter.IsIncidentAdapter{}},
+		MutatorAdapters: []MutatorAdapter{&mutator.JSONAdapter{}},
+		HandlerAdapters: []HandlerAdapter{&handler.LegacyAdapter{Store: stor, Executor: ex}},
+	}, &executed
+}
+
+func tracePipeline(filters ...*corev2.ResourceReference) *corev2.Pipeline {
+	return &corev2.Pipeline{
+		ObjectMeta: corev2.NewObjectMeta("pipeline1", "default"),
+		Workflows: []*corev2.PipelineWorkflow{
+			{
+				Name:    "workflow1",
+				Filters: filters,
+				Handler: &corev2.ResourceReference{
+					APIVersion: "core/v2",
+					Type:       "Handler",
+					Name:       "handler1",
+				},
+			},
+		},
+	}
+}
+
+func TestAdapterV1_TraceFiltered(t *testing.T) {
+	isIncident := &corev2.ResourceReference{APIVersion: "core/v2", Type: "EventFilter", Name: "is_incident"}
+	a, executed := traceAdapter(t, tracePipeline(isIncident), corev2.FixtureHandler("handler1"))
+
+	event := corev2.FixtureEvent("entity1", "check1")
+	trace, err := a.Trace(context.Background(), corev2.FixturePipelineReference("pipeline1"), event, true)
+	require.NoError(t, err)
+
+	require.Len(t, trace.Workflows, 1)
+	workflow := trace.Workflows[0]
+	assert.True(t, workflow.Filtered)
+	require.Len(t, workflow.Filters, 1)
+	assert.True(t, workflow.Filters[0].Denied)
+	assert.Equal(t, filter.IsIncidentAdapterName, workflow.Filters[0].Adapter)
+	assert.Contains(t, workflow.Filters[0].Reason, "is_incident")
+	assert.Nil(t, workflow.Mutator)
+	assert.Nil(t, workflow.Handler)
+	assert.False(t, *executed)
+}
+
+func TestAdapterV1_TraceDryRun(t *testing.T) {
+	h := corev2.FixtureHandler("handler1")
+	h.Command = "notify --urgent"
+	a, executed := traceAdapter(t, tracePipeline(), h)
+
+	event := corev2.FixtureEvent("entity1", "check1")
+	trace, err := a.Trace(context.Background(), corev2.FixturePipelineReference("pipeline1"), event, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, "pipeline1", trace.Pipeline)
+	assert.False(t, trace.Executed)
+	assert.Empty(t, trace.Error)
+	require.Len(t, trace.Workflows, 1)
+	workflow := trace.Workflows[0]
+	assert.False(t, workflow.Filtered)
+	require.NotNil(t, workflow.Mutator)
+	assert.Equal(t, "json", workflow.Mutator.Reference.Name)
+	assert.Contains(t, workflow.Mutator.Output, `"name":"check1"`)
+	require.NotNil(t, workflow.Handler)
+	assert.Equal(t, "pipe", workflow.Handler.Type)
+	assert.Equal(t, "notify --urgent", workflow.Handler.Command)
+	assert.False(t, workflow.Handler.Executed)
+	assert.False(t, *executed)
+}
+
+func TestAdapterV1_TraceExecute(t *testing.T) {
+	a, executed := traceAdapter(t, tracePipeline(), corev2.FixtureHandler("handler1"))
+
+	event := corev2.FixtureEvent("entity1", "check1")
+	trace, err := a.Trace(context.Background(), corev2.FixturePipelineReference("pipeline1"), event, true)
+	require.NoError(t, err)
+
+	require.Len(t, trace.Workflows, 1)
+	assert.True(t, trace.Workflows[0].Handler.Executed)
+	assert.Empty(t, trace.Workflows[0].Handler.Error)
+	assert.True(t, *executed)
+}
+
+func TestAdapterV1_TraceExecuteWithoutRetries(t *testing.T) {
+	h := corev2.FixtureHandler("handler1")
+	h.Annotations = map[string]string{
+		handler.RetryMaxAttemptsAnnotation: "2",
+		handler.RetryBackoffAnnotation:     "0",
+	}
+	a, executed := traceAdapter(t, tracePipeline(), h)
+	legacy := a.HandlerAdapters[0].(*handler.LegacyAdapter)
+	legacy.Executor.(*mockexecutor.MockExecutor).Return(command.FixtureExecutionResponse(2, "failed"), nil)
+	q := queue.NewMemoryClient()
+	legacy.Queue = q
+
+	event := corev2.FixtureEvent("entity1", "check1")
+	_, err := a.Trace(context.Background(), corev2.FixturePipelineReference("pipeline1"), event, true)
+	require.NoError(t, err)
+	assert.True(t, *executed)
+
+	// The failed execution isn't queued for a retry
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = q.Reserve(ctx, handler.RetryQueueName)
+	assert.Error(t, err)
+}
+
+func TestAdapterV1_TraceHandlerSet(t *testing.T) {
+	set := corev2.FixtureSetHandler("handler1", "member1", "member2")
+	set.Type = corev2.HandlerSetType
+	member1 := corev2.FixtureHandler("member1")
+	member1.Command = "member1"
+	member2 := corev2.FixtureSocketHandler("member2", "tcp")
+	a, _ := traceAdapter(t, tracePipeline(), set, member1, member2)
+
+	event := corev2.FixtureEvent("entity1", "check1")
+	trace, err := a.Trace(context.Background(), corev2.FixturePipelineReference("pipeline1"), event, false)
+	require.NoError(t, err)
+
+	handlerTrace := trace.Workflows[0].Handler
+	assert.Equal(t, corev2.HandlerSetType, handlerTrace.Type)
+	require.Len(t, handlerTrace.Members, 2)
+	assert.Equal(t, "member1", handlerTrace.Members[0].Command)
+	assert.Equal(t, "tcp", handlerTrace.Members[1].Type)
+	assert.NotEmpty(t, handlerTrace.Members[1].Address)
+}
+
+func TestAdapterV1_TraceFilterError(t *testing.T) {
+	unknown := &corev2.ResourceReference{APIVersion: "core/v2", Type: "EventFilter", Name: "unknown"}
+	a, executed := traceAdapter(t, tracePipeline(unknown), corev2.FixtureHandler("handler1"))
+
+	event := corev2.FixtureEvent("entity1", "check1")
+	trace, err := a.Trace(context.Background(), corev2.FixturePipelineReference("pipeline1"), event, true)
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, trace.Error)
+	require.Len(t, trace.Workflows[0].Filters, 1)
+	assert.NotEmpty(t, trace.Workflows[0].Filters[0].Error)
+	assert.False(t, *executed)
+}
+
+func TestAdapterV1_TracePipelineNotFound(t *testing.T) {
+	a, _ := traceAdapter(t, nil)
+
+	event := corev2.FixtureEvent("entity1", "check1")
+	_, err := a.Trace(context.Background(), corev2.FixturePipelineReference("pipeline1"), event, false)
+	assert.IsType(t, &store.ErrNotFound{}, err)
+}
@@ -0,0 +1,94 @@
+package v1
+
+import (
+	"errors"
+	"net/url"
+	"path"
+
+	corev2 "github.com/sensu/core/v2"
+)
+
+// DeadLettersResource is the RBAC name of the dead letters.
+const DeadLettersResource = "dead-letters"
+
+// DeadLetter is a handler execution that failed after exhausting the retry
+// policy of its handler. It can be replayed, which retries the handler with
+// the same event, or deleted.
+type DeadLetter struct {
+	// Metadata contains the name and namespace of the dead letter. The name
+	// is generated by sensu-backend.
+	Metadata corev2.ObjectMeta `json:"metadata"`
+
+	// Handler is the name of the failed handler.
+	Handler string `json:"handler"`
+
+	// Event is the handled event.
+	Event *corev2.Event `json:"event"`
+
+	// MutatedData is the output of the workflow mutator, as passed to the
+	// handler.
+	MutatedData []byte `json:"mutated_data,omitempty"`
+
+	// Attempts is the number of times the handler was executed.
+	Attempts uint32 `json:"attempts"`
+
+	// Error is the error of the last execution.
+	Error string `json:"error,omitempty"`
+
+	// FailedAt is the time of the last execution, in seconds since the Unix
+	// epoch.
+	FailedAt int64 `json:"failed_at"`
+}
+
+// GetMetadata returns the dead letter metadata.
+func (d *DeadLetter) GetMetadata() *corev2.ObjectMeta {
+	return &d.Metadata
+}
+
+// SetMetadata sets the dead letter metadata.
+func (d *DeadLetter) SetMetadata(meta *corev2.ObjectMeta) {
+	if meta == nil {
+		meta = &corev2.ObjectMeta{}
+	}
+	d.Metadata = *meta
+}
+
+// StoreName returns the store name of the dead letter.
+func (d *DeadLetter) StoreName() string {
+	return "pipeline/dead-letters"
+}
+
+// RBACName returns the RBAC name of the dead letter.
+func (d *DeadLetter) RBACName() string {
+	return DeadLettersResource
+}
+
+// URIPath returns the path of the dead letter.
+func (d *DeadLetter) URIPath() string {
+	if d.Metadata.Namespace == "" {
+		return path.Join(URLPrefix, DeadLettersResource, url.PathEscape(d.Metadata.Name))
+	}
+	return path.Join(URLPrefix, "namespaces", url.PathEscape(d.Metadata.Namespace), DeadLettersResource, url.PathEscape(d.Metadata.Name))
+}
+
+// GetTypeMeta returns the type metadata of the dead letter.
+func (d *DeadLetter) GetTypeMeta() corev2.TypeMeta {
+	return typeMeta("DeadLetter")
+}
+
+// Validate returns an error if the dead letter is invalid.
+func (d *DeadLetter) Validate() error {
+	if d.Metadata.Name == "" {
+		return errors.New("dead letter name must be set")
+	}
+	if d.Metadata.Namespace == "" {
+		return errors.New("namespace must be set")
+	}
+	if err := corev2.ValidateName(d.Handler); err != nil {
+		return errors.New("handler name " + err.Error())
+	}
+	if d.Event == nil {
+		return errors.New("event must be set")
+	}
+	return nil
+}
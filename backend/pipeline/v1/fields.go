@@ -0,0 +1,29 @@
+package v1
+
+import (
+	corev3 "github.com/sensu/core/v3"
+)
+
+// HTTPHandlerFields returns a set of fields that represent the handler for
+// the purposes of field selectors.
+func HTTPHandlerFields(r corev3.Resource) map[string]string {
+	resource := r.(*HTTPHandler)
+	fields := map[string]string{
+		"http_handler.name":      resource.Metadata.Name,
+		"http_handler.namespace": resource.Metadata.Namespace,
+		"http_handler.method":    resource.GetMethod(),
+	}
+	mergeLabels(fields, resource.Metadata.Labels, "http_handler.labels.")
+	return fields
+}
+
+// DeadLetterFields returns a set of fields that represent the dead letter for
+// the purposes of field selectors.
+func DeadLetterFields(r corev3.Resource) map[string]string {
+	resource := r.(*DeadLetter)
+	return map[string]string{
+		"dead_letter.name":      resource.Metadata.Name,
+		"dead_letter.namespace": resource.Metadata.Namespace,
+		"dead_letter.handler":   resource.Handler,
+	}
+}
@@ -0,0 +1,162 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	corev2 "github.com/sensu/core/v2"
+)
+
+const (
+	// HTTPHandlersResource is the RBAC name of the HTTP handlers.
+	HTTPHandlersResource = "http-handlers"
+
+	// DefaultHTTPHandlerMethod is the method used by HTTP handlers that do
+	// not specify one.
+	DefaultHTTPHandlerMethod = http.MethodPost
+
+	// DefaultHTTPHandlerTimeout is the request timeout, in seconds, of HTTP
+	// handlers that do not specify one.
+	DefaultHTTPHandlerTimeout uint32 = 10
+
+	// DefaultHTTPHandlerRetryDelay is the initial delay, in seconds, between
+	// the retries of HTTP handlers that do not specify one.
+	DefaultHTTPHandlerRetryDelay uint32 = 1
+)
+
+// HTTPHandler is a handler that sends events to an HTTP endpoint, without
+// forking a process for every event.
+type HTTPHandler struct {
+	// Metadata contains the name, namespace, labels and annotations of the
+	// handler.
+	Metadata corev2.ObjectMeta `json:"metadata"`
+
+	// URL is the URL the events are sent to.
+	URL string `json:"url"`
+
+	// Method is the HTTP method of the requests. Defaults to POST.
+	Method string `json:"method,omitempty"`
+
+	// Headers are the headers added to the requests. Header values can
+	// reference the handler secrets by name, e.g. "Bearer $API_TOKEN".
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Body is a template of the request body, evaluated against the event
+	// with the same syntax as token substitution, e.g.
+	// {"text": "{{ .check.output }}"}. When empty, the output of the
+	// workflow mutator is sent as is.
+	Body string `json:"body,omitempty"`
+
+	// Secrets is the list of Sensu secrets available to the headers.
+	Secrets []*corev2.Secret `json:"secrets,omitempty"`
+
+	// TLS configures the trusted CA, client certificate and certificate
+	// verification of HTTPS requests.
+	TLS *corev2.TLSOptions `json:"tls,omitempty"`
+
+	// Timeout is the timeout of a single request, in seconds. Defaults to
+	// DefaultHTTPHandlerTimeout.
+	Timeout uint32 `json:"timeout,omitempty"`
+
+	// MaxRetries is the number of times a request is retried when it fails
+	// with a 5xx status or a transport error.
+	MaxRetries uint32 `json:"max_retries,omitempty"`
+
+	// RetryDelay is the delay before the first retry, in seconds. It grows
+	// exponentially with every retry. Defaults to
+	// DefaultHTTPHandlerRetryDelay.
+	RetryDelay uint32 `json:"retry_delay,omitempty"`
+}
+
+// GetMetadata returns the handler metadata.
+func (h *HTTPHandler) GetMetadata() *corev2.ObjectMeta {
+	return &h.Metadata
+}
+
+// SetMetadata sets the handler metadata.
+func (h *HTTPHandler) SetMetadata(meta *corev2.ObjectMeta) {
+	if meta == nil {
+		meta = &corev2.ObjectMeta{}
+	}
+	h.Metadata = *meta
+}
+
+// StoreName returns the store name of the handler.
+func (h *HTTPHandler) StoreName() string {
+	return "pipeline/http-handlers"
+}
+
+// RBACName returns the RBAC name of the handler.
+func (h *HTTPHandler) RBACName() string {
+	return HTTPHandlersResource
+}
+
+// URIPath returns the path of the handler.
+func (h *HTTPHandler) URIPath() string {
+	if h.Metadata.Namespace == "" {
+		return path.Join(URLPrefix, HTTPHandlersResource, url.PathEscape(h.Metadata.Name))
+	}
+	return path.Join(URLPrefix, "namespaces", url.PathEscape(h.Metadata.Namespace), HTTPHandlersResource, url.PathEscape(h.Metadata.Name))
+}
+
+// GetTypeMeta returns the type metadata of the handler.
+func (h *HTTPHandler) GetTypeMeta() corev2.TypeMeta {
+	return typeMeta("HTTPHandler")
+}
+
+// Validate returns an error if the handler is invalid.
+func (h *HTTPHandler) Validate() error {
+	if err := corev2.ValidateName(h.Metadata.Name); err != nil {
+		return errors.New("handler name " + err.Error())
+	}
+	if h.Metadata.Namespace == "" {
+		return errors.New("namespace must be set")
+	}
+	u, err := url.Parse(h.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %s", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("url scheme must be http or https")
+	}
+	if u.Host == "" {
+		return errors.New("url host undefined")
+	}
+	if h.Method != "" && strings.ToUpper(h.Method) != h.Method {
+		return errors.New("method must be uppercase")
+	}
+	for _, secret := range h.Secrets {
+		if secret == nil || secret.Name == "" || secret.Secret == "" {
+			return errors.New("secrets must have a name and a secret")
+		}
+	}
+	return nil
+}
+
+// GetMethod returns the HTTP method of the requests.
+func (h *HTTPHandler) GetMethod() string {
+	if h.Method == "" {
+		return DefaultHTTPHandlerMethod
+	}
+	return h.Method
+}
+
+// GetTimeout returns the request timeout, in seconds.
+func (h *HTTPHandler) GetTimeout() uint32 {
+	if h.Timeout == 0 {
+		return DefaultHTTPHandlerTimeout
+	}
+	return h.Timeout
+}
+
+// GetRetryDelay returns the initial delay between retries, in seconds.
+func (h *HTTPHandler) GetRetryDelay() uint32 {
+	if h.RetryDelay == 0 {
+		return DefaultHTTPHandlerRetryDelay
+	}
+	return h.RetryDelay
+}
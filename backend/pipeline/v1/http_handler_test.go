@@ -0,0 +1,85 @@
+package v1
+
+import (
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func fixtureHTTPHandler() *HTTPHandler {
+	return &HTTPHandler{
+		Metadata: corev2.ObjectMeta{Name: "webhook", Namespace: "default"},
+		URL:      "https://example.com/hook",
+	}
+}
+
+func TestHTTPHandlerValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(*HTTPHandler)
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			mutate: func(*HTTPHandler) {},
+		},
+		{
+			name:    "missing namespace",
+			mutate:  func(h *HTTPHandler) { h.Metadata.Namespace = "" },
+			wantErr: true,
+		},
+		{
+			name:    "invalid scheme",
+			mutate:  func(h *HTTPHandler) { h.URL = "ftp://example.com" },
+			wantErr: true,
+		},
+		{
+			name:    "missing host",
+			mutate:  func(h *HTTPHandler) { h.URL = "http:///hook" },
+			wantErr: true,
+		},
+		{
+			name:    "lowercase method",
+			mutate:  func(h *HTTPHandler) { h.Method = "put" },
+			wantErr: true,
+		},
+		{
+			name:    "invalid secret",
+			mutate:  func(h *HTTPHandler) { h.Secrets = []*corev2.Secret{{Name: "TOKEN"}} },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := fixtureHTTPHandler()
+			tt.mutate(handler)
+			err := handler.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestHTTPHandlerDefaults(t *testing.T) {
+	handler := fixtureHTTPHandler()
+	assert.Equal(t, "POST", handler.GetMethod())
+	assert.Equal(t, DefaultHTTPHandlerTimeout, handler.GetTimeout())
+	assert.Equal(t, DefaultHTTPHandlerRetryDelay, handler.GetRetryDelay())
+	assert.Equal(t, "/api/pipeline/v1/namespaces/default/http-handlers/webhook", handler.URIPath())
+}
+
+func TestHTTPHandlerWorkflowReference(t *testing.T) {
+	workflow := &corev2.PipelineWorkflow{
+		Name: "webhook",
+		Handler: &corev2.ResourceReference{
+			APIVersion: APIVersion,
+			Type:       "HTTPHandler",
+			Name:       "webhook",
+		},
+	}
+	assert.NoError(t, workflow.Validate())
+}
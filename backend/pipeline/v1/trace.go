@@ -0,0 +1,108 @@
+package v1
+
+import (
+	corev2 "github.com/sensu/core/v2"
+)
+
+// PipelineTrace is the record of an event run through a pipeline by the
+// pipeline test endpoint. Each workflow records the filters, mutator and
+// handler it went through, and where it stopped.
+type PipelineTrace struct {
+	// Pipeline is the name of the traced pipeline.
+	Pipeline string `json:"pipeline"`
+
+	// Executed is whether the handlers were executed. Otherwise, the trace
+	// only describes what they would do.
+	Executed bool `json:"executed"`
+
+	// Workflows are the traces of the pipeline workflows, in order.
+	Workflows []*WorkflowTrace `json:"workflows"`
+
+	// Error is the error that stopped the pipeline, as it would stop the
+	// processing of the event by sensu-backend.
+	Error string `json:"error,omitempty"`
+}
+
+// WorkflowTrace is the record of an event run through a pipeline workflow.
+type WorkflowTrace struct {
+	// Name is the name of the workflow.
+	Name string `json:"name"`
+
+	// Filters are the traces of the workflow filters, up to the first one
+	// that denied the event.
+	Filters []*FilterTrace `json:"filters,omitempty"`
+
+	// Filtered is whether a filter denied the event.
+	Filtered bool `json:"filtered"`
+
+	// Mutator is the trace of the workflow mutator, unless the event was
+	// filtered.
+	Mutator *MutatorTrace `json:"mutator,omitempty"`
+
+	// Handler is the trace of the workflow handler, unless the event was
+	// filtered.
+	Handler *HandlerTrace `json:"handler,omitempty"`
+}
+
+// FilterTrace is the record of a filter evaluation.
+type FilterTrace struct {
+	// Reference is the filter reference of the workflow.
+	Reference *corev2.ResourceReference `json:"reference"`
+
+	// Adapter is the name of the filter adapter that evaluated the filter.
+	Adapter string `json:"adapter,omitempty"`
+
+	// Denied is whether the filter denied the event.
+	Denied bool `json:"denied"`
+
+	// Reason explains the decision of the filter, when the filter adapter
+	// can explain it.
+	Reason string `json:"reason,omitempty"`
+
+	// Error is the error of the filter evaluation.
+	Error string `json:"error,omitempty"`
+}
+
+// MutatorTrace is the record of an event mutation.
+type MutatorTrace struct {
+	// Reference is the mutator reference of the workflow.
+	Reference *corev2.ResourceReference `json:"reference"`
+
+	// Adapter is the name of the mutator adapter that mutated the event.
+	Adapter string `json:"adapter,omitempty"`
+
+	// Output is the mutated event, as passed to the handler.
+	Output string `json:"output,omitempty"`
+
+	// Error is the error of the mutation.
+	Error string `json:"error,omitempty"`
+}
+
+// HandlerTrace is the record of a handler, executed or not.
+type HandlerTrace struct {
+	// Reference is the handler reference of the workflow, or of the handler
+	// set member.
+	Reference *corev2.ResourceReference `json:"reference"`
+
+	// Adapter is the name of the handler adapter that handles the event.
+	Adapter string `json:"adapter,omitempty"`
+
+	// Type is the type of core/v2 handlers.
+	Type string `json:"type,omitempty"`
+
+	// Command is the command that pipe handlers run.
+	Command string `json:"command,omitempty"`
+
+	// Address is the address tcp and udp handlers send the event to.
+	Address string `json:"address,omitempty"`
+
+	// Members are the handlers of handler sets, nested sets expanded.
+	Members []*HandlerTrace `json:"members,omitempty"`
+
+	// Executed is whether the handler was executed.
+	Executed bool `json:"executed"`
+
+	// Error is the error of the handler execution, or the reason why the
+	// handler can't be executed.
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,39 @@
+// Package v1 contains the pipeline/v1 API types: handlers that are built into
+// sensu-backend and can be referenced by pipeline workflows, and the dead
+// letters of the handler executions that failed.
+package v1
+
+import (
+	corev2 "github.com/sensu/core/v2"
+	apitools "github.com/sensu/sensu-api-tools"
+)
+
+const (
+	// APIVersion is the API version of the types in this package.
+	APIVersion = "pipeline/v1"
+
+	// URLPrefix is the URL prefix of the pipeline/v1 API.
+	URLPrefix = "/api/pipeline/v1"
+)
+
+func init() {
+	apitools.RegisterType(APIVersion, new(HTTPHandler))
+	apitools.RegisterType(APIVersion, new(DeadLetter))
+	corev2.AddValidPipelineWorkflowHandlerReference(corev2.ResourceReference{
+		APIVersion: APIVersion,
+		Type:       "HTTPHandler",
+	})
+}
+
+func mergeLabels(fields map[string]string, labels map[string]string, prefix string) {
+	for k, v := range labels {
+		fields[prefix+k] = v
+	}
+}
+
+func typeMeta(typ string) corev2.TypeMeta {
+	return corev2.TypeMeta{
+		Type:       typ,
+		APIVersion: APIVersion,
+	}
+}
@@ -47,6 +47,9 @@ type Poller struct {
 	TxnWindow time.Duration
 	// Table implements the access methods required by the poller.
 	Table Table
+	// Notify, when set, triggers polls before the end of the interval. The
+	// interval then only acts as a fallback for missed notifications.
+	Notify <-chan struct{}
 
 	start    time.Time
 	nextPoll time.Time
@@ -64,7 +67,8 @@ func (p *Poller) Initialize(ctx context.Context) error {
 	return err
 }
 
-// Next blocks until the next polling interval, then returns any changed rows.
+// Next blocks until the next polling interval, or notification, then returns
+// any changed rows.
 func (p *Poller) Next(ctx context.Context) ([]RowChange, error) {
 	nextInterval := time.NewTimer(time.Until(p.nextPoll))
 	defer nextInterval.Stop()
@@ -74,6 +78,8 @@ func (p *Poller) Next(ctx context.Context) ([]RowChange, error) {
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	case <-nextInterval.C:
+	case <-p.Notify:
+		p.nextPoll = time.Now().Add(p.Interval)
 	}
 
 	updates, err := p.Table.Since(ctx, p.start)
@@ -103,6 +103,31 @@ func TestPolling(t *testing.T) {
 	}
 }
 
+func TestPollingNotify(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	table := &stubTable{
+		TInit:   now,
+		Results: [][]Row{{forgeRow(now.Add(time.Second))}},
+	}
+	notify := make(chan struct{}, 1)
+	pollerUnderTest := &Poller{
+		Interval: time.Hour,
+		Table:    table,
+		Notify:   notify,
+	}
+	assert.NoError(t, pollerUnderTest.Initialize(ctx))
+
+	// The notification triggers the poll long before the end of the interval
+	notify <- struct{}{}
+	changes, err := pollerUnderTest.Next(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, 1, table.calls)
+}
+
 type stubTable struct {
 	TInit    time.Time
 	Results  [][]Row
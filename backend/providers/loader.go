@@ -0,0 +1,165 @@
+// Package providers contains what the secrets and authentication providers
+// have in common: the loader that keeps the providers in use in sync with the
+// provider resources of the configuration store, and the helpers of their API
+// types.
+package providers
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	corev2 "github.com/sensu/core/v2"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// Path returns the URI path of the provider of the given kind and name, in
+// the API found at urlPrefix.
+func Path(urlPrefix, resource, kind, name string) string {
+	return path.Join(urlPrefix, resource, kind, name)
+}
+
+// TypeMeta returns the type metadata of the type typ of the API version.
+func TypeMeta(apiVersion, typ string) corev2.TypeMeta {
+	return corev2.TypeMeta{
+		Type:       typ,
+		APIVersion: apiVersion,
+	}
+}
+
+// A Source lists and watches the stored providers of a single type, as
+// providers of type P.
+type Source[P any] struct {
+	kind    string
+	request storev2.ResourceRequest
+	list    func(context.Context, storev2.Interface) ([]P, error)
+	read    func(storev2.WatchEvent) (P, bool, error)
+}
+
+// NewSource returns the source of the providers stored as resources of type
+// R, which must implement P. The kind names the providers in errors.
+func NewSource[P any, R storev2.Resource[T], T any](kind string) Source[P] {
+	var resource R = new(T)
+	return Source[P]{
+		kind:    kind,
+		request: storev2.NewResourceRequestFromResource(resource),
+		list: func(ctx context.Context, store storev2.Interface) ([]P, error) {
+			values, err := storev2.Of[R](store).List(ctx, storev2.ID{}, nil)
+			if err != nil {
+				return nil, err
+			}
+			result := make([]P, 0, len(values))
+			for _, value := range values {
+				provider, err := as[P](value)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, provider)
+			}
+			return result, nil
+		},
+		read: func(event storev2.WatchEvent) (P, bool, error) {
+			var provider P
+			value, err := storev2.ReadEventValue[R](event)
+			if err != nil || value == nil {
+				return provider, false, err
+			}
+			provider, err = as[P](value)
+			return provider, err == nil, err
+		},
+	}
+}
+
+func as[P any](value any) (P, error) {
+	provider, ok := value.(P)
+	if !ok {
+		return provider, fmt.Errorf("%T is not a provider", value)
+	}
+	return provider, nil
+}
+
+// Loader loads the providers of its sources, and applies the changes made to
+// them with Add and Remove.
+type Loader[P any] struct {
+	// Store is the store of the providers.
+	Store storev2.Interface
+
+	// Sources are the sources of the providers.
+	Sources []Source[P]
+
+	// Add adds a provider that was created or updated.
+	Add func(P)
+
+	// Remove removes a provider that was deleted.
+	Remove func(P)
+
+	// Logger logs the watch errors.
+	Logger *logrus.Entry
+}
+
+// Load adds every stored provider.
+func (l *Loader[P]) Load(ctx context.Context) error {
+	for _, source := range l.Sources {
+		providers, err := source.list(ctx, l.Store)
+		if err != nil {
+			return fmt.Errorf("couldn't load %s providers: %s", source.kind, err)
+		}
+		for _, provider := range providers {
+			l.Add(provider)
+		}
+	}
+	return nil
+}
+
+// Watch applies the changes made to the stored providers, until the context
+// is canceled.
+func (l *Loader[P]) Watch(ctx context.Context) {
+	for i := range l.Sources {
+		if i == len(l.Sources)-1 {
+			l.watch(ctx, l.Sources[i])
+			return
+		}
+		go l.watch(ctx, l.Sources[i])
+	}
+}
+
+func (l *Loader[P]) watch(ctx context.Context, source Source[P]) {
+	watchChan := l.Store.GetConfigStore().Watch(ctx, source.request)
+	for {
+		select {
+		case events, ok := <-watchChan:
+			if !ok {
+				if ctx.Err() != nil {
+					return
+				}
+				// The watchChan has closed. Restart the watcher.
+				l.Logger.WithField("type", source.kind).Info("restarting provider watcher")
+				watchChan = l.Store.GetConfigStore().Watch(ctx, source.request)
+				continue
+			}
+			for _, event := range events {
+				l.handleWatchEvent(source, event)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (l *Loader[P]) handleWatchEvent(source Source[P], event storev2.WatchEvent) {
+	provider, ok, err := source.read(event)
+	if err != nil {
+		l.Logger.WithField("type", source.kind).WithError(err).Error("couldn't read provider watch event")
+		return
+	}
+	if !ok {
+		return
+	}
+	switch event.Type {
+	case storev2.WatchCreate, storev2.WatchUpdate:
+		l.Add(provider)
+	case storev2.WatchDelete:
+		l.Remove(provider)
+	}
+}
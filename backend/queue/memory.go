@@ -62,7 +62,8 @@ func (m *memory) Reserve(ctx context.Context, queue string) (Reservation, error)
 	var val Item
 	for {
 		m.Lock()
-		if len(m.data[queue]) == 0 {
+		idx := m.firstDue(queue, time.Now())
+		if idx < 0 {
 			m.Unlock()
 			select {
 			case <-ctx.Done():
@@ -71,8 +72,9 @@ func (m *memory) Reserve(ctx context.Context, queue string) (Reservation, error)
 				continue
 			}
 		}
-		val = m.data[queue][0]
-		m.data[queue] = m.data[queue][1:]
+		items := m.data[queue]
+		val = items[idx]
+		m.data[queue] = append(items[:idx:idx], items[idx+1:]...)
 		m.Unlock()
 		break
 	}
@@ -81,3 +83,14 @@ func (m *memory) Reserve(ctx context.Context, queue string) (Reservation, error)
 		value: val,
 	}, nil
 }
+
+// firstDue returns the index of the first item of the queue that can be
+// reserved, or -1 if there are none.
+func (m *memory) firstDue(queue string, now time.Time) int {
+	for i, item := range m.data[queue] {
+		if !item.NotBefore.After(now) {
+			return i
+		}
+	}
+	return -1
+}
@@ -38,6 +38,9 @@ type Item struct {
 	Queue string
 	// Value of queue item
 	Value []byte
+	// NotBefore is the time from which the item can be reserved. The item
+	// can be reserved immediately if it is zero.
+	NotBefore time.Time
 }
 
 // Reservation for a Queue Item.
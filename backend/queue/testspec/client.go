@@ -22,6 +22,9 @@ func RunClientTestSuite(t *testing.T, ctx context.Context, clientUnderTest queue
 	t.Run("reservation timeout", func(t *testing.T) {
 		runReservationTimeout(t, ctx, clientUnderTest)
 	})
+	t.Run("not before", func(t *testing.T) {
+		runNotBefore(t, ctx, clientUnderTest)
+	})
 }
 
 // runIntegrationSuite attempts to simulate heavy utilization of a queue.
@@ -176,3 +179,34 @@ func runReservationTimeout(t *testing.T, ctx context.Context, clientUnderTest qu
 		}
 	}
 }
+
+// runNotBefore checks that the items are only reserved once they are due.
+func runNotBefore(t *testing.T, ctx context.Context, clientUnderTest queue.Client) {
+	items := []queue.Item{
+		{Queue: "postponed-queue", Value: []byte("later"), NotBefore: time.Now().Add(time.Hour)},
+		{Queue: "postponed-queue", Value: []byte("now")},
+	}
+	for _, item := range items {
+		if err := clientUnderTest.Enqueue(ctx, item); err != nil {
+			t.Fatalf("unexpected enqueue error: %v", err)
+		}
+	}
+
+	res, err := clientUnderTest.Reserve(ctx, "postponed-queue")
+	if err != nil {
+		t.Fatalf("unexpected error reserving queue item: %v", err)
+	}
+	if got, want := string(res.Item().Value), "now"; got != want {
+		t.Errorf("unexpected item reserved: got %q, want %q", got, want)
+	}
+	if err := res.Ack(ctx); err != nil {
+		t.Errorf("unexpected ack error: %v", err)
+	}
+
+	tCtx, cancel := context.WithTimeout(ctx, time.Millisecond*100)
+	defer cancel()
+	if res, err := clientUnderTest.Reserve(tCtx, "postponed-queue"); err == nil {
+		t.Errorf("unexpected reservation of a postponed item: %q", res.Item().Value)
+		_ = res.Nack(ctx)
+	}
+}
@@ -0,0 +1,337 @@
+// Package quota enforces the namespace quotas of the quota/v1 API.
+package quota
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	quotav1 "github.com/sensu/sensu-go/backend/quota/v1"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+// DefaultCacheTTL is the default duration the quotas of the namespaces are
+// cached for.
+const DefaultCacheTTL = 10 * time.Second
+
+// Enforcer enforces the namespace quotas on the creation of resources and on
+// the ingest of events. The quotas are cached, so their changes take up to
+// CacheTTL to be enforced.
+//
+// The event rates are counted by each backend, over one second windows, and
+// aren't shared across the cluster: a cluster of N backends ingests up to N
+// times the event rate of a quota, depending on how the agents are spread.
+type Enforcer struct {
+	// Store is the store of the quotas and limited resources.
+	Store storev2.Interface
+
+	// CacheTTL is the duration the quotas of the namespaces are cached for.
+	// Defaults to DefaultCacheTTL.
+	CacheTTL time.Duration
+
+	mu     sync.Mutex
+	quotas map[string]cachedQuotas
+	rates  map[string]*rateWindow
+
+	// now returns the current time, time.Now if nil.
+	now func() time.Time
+}
+
+type cachedQuotas struct {
+	quotas  []*quotav1.Quota
+	expires time.Time
+}
+
+// rateWindow counts the events ingested by a namespace during the current
+// second, and the previous one.
+type rateWindow struct {
+	second   int64
+	count    uint64
+	previous uint64
+}
+
+func (w *rateWindow) advance(now time.Time) {
+	second := now.Unix()
+	switch {
+	case second == w.second:
+		return
+	case second == w.second+1:
+		w.previous = w.count
+	default:
+		w.previous = 0
+	}
+	w.second = second
+	w.count = 0
+}
+
+// NewEnforcer creates a new Enforcer.
+func NewEnforcer(store storev2.Interface) *Enforcer {
+	return &Enforcer{
+		Store: store,
+	}
+}
+
+func (e *Enforcer) time() time.Time {
+	if e.now == nil {
+		return time.Now()
+	}
+	return e.now()
+}
+
+// Quotas returns the quotas of the namespace.
+func (e *Enforcer) Quotas(ctx context.Context, namespace string) ([]*quotav1.Quota, error) {
+	now := e.time()
+	e.mu.Lock()
+	cached, ok := e.quotas[namespace]
+	e.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.quotas, nil
+	}
+
+	quotas, err := storev2.Of[*quotav1.Quota](e.Store).List(ctx, storev2.ID{Namespace: namespace}, nil)
+	if err != nil {
+		return nil, err
+	}
+	ttl := e.CacheTTL
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.quotas == nil {
+		e.quotas = make(map[string]cachedQuotas)
+	}
+	e.quotas[namespace] = cachedQuotas{quotas: quotas, expires: now.Add(ttl)}
+	return quotas, nil
+}
+
+// AllowCreate returns a *quotav1.ErrQuotaExceeded error if creating the
+// named resource would exceed a quota of the namespace. The name is optional;
+// when it's known, the updates of existing resources are always allowed.
+// The events are also checked against the event rates, without being
+// counted, as they are counted when ingested.
+func (e *Enforcer) AllowCreate(ctx context.Context, namespace, resource, name string) error {
+	if resource == quotav1.ResourceEvents {
+		if err := e.checkEventRate(ctx, namespace, false); err != nil {
+			return err
+		}
+	}
+	quotas, err := e.limiting(ctx, namespace, resource)
+	if err != nil || len(quotas) == 0 {
+		return err
+	}
+	return e.checkCount(ctx, namespace, resource, name, quotas)
+}
+
+// AllowEvent returns a *quotav1.ErrQuotaExceeded error if ingesting the
+// event would exceed a quota of its namespace. Otherwise, the event is
+// counted against the event rates.
+func (e *Enforcer) AllowEvent(ctx context.Context, event *corev2.Event) error {
+	namespace := event.Entity.Namespace
+	if err := e.checkEventRate(ctx, namespace, true); err != nil {
+		return err
+	}
+	if !event.HasCheck() {
+		// metrics events are not stored
+		return nil
+	}
+	quotas, err := e.limiting(ctx, namespace, quotav1.ResourceEvents)
+	if err != nil || len(quotas) == 0 {
+		return err
+	}
+	entity := event.Entity.Name
+	if event.Check.ProxyEntityName != "" {
+		entity = event.Check.ProxyEntityName
+	}
+	return e.checkCount(ctx, namespace, quotav1.ResourceEvents, path.Join(entity, event.Check.Name), quotas)
+}
+
+// Usage returns the usage of the resources of the namespace against the
+// limits of the quota.
+func (e *Enforcer) Usage(ctx context.Context, quota *quotav1.Quota) (*quotav1.QuotaUsage, error) {
+	namespace := quota.Metadata.Namespace
+	usage := &quotav1.QuotaUsage{
+		Quota:     quota.Metadata.Name,
+		Namespace: namespace,
+	}
+	for _, resource := range quotav1.Resources {
+		var used uint64
+		if resource == quotav1.ResourceEventRate {
+			used = e.eventRate(namespace)
+		} else {
+			var err error
+			if used, err = e.count(ctx, namespace, resource); err != nil {
+				return nil, err
+			}
+		}
+		usage.Resources = append(usage.Resources, &quotav1.ResourceUsage{
+			Resource: resource,
+			Used:     used,
+			Limit:    quota.Limit(resource),
+		})
+	}
+	return usage, nil
+}
+
+// limiting returns the quotas of the namespace that limit the resource.
+func (e *Enforcer) limiting(ctx context.Context, namespace, resource string) ([]*quotav1.Quota, error) {
+	if namespace == "" {
+		return nil, nil
+	}
+	quotas, err := e.Quotas(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	var limiting []*quotav1.Quota
+	for _, quota := range quotas {
+		if quota.Limit(resource) > 0 {
+			limiting = append(limiting, quota)
+		}
+	}
+	return limiting, nil
+}
+
+func (e *Enforcer) checkCount(ctx context.Context, namespace, resource, name string, quotas []*quotav1.Quota) error {
+	// The resource only counts against the quotas if it doesn't exist yet.
+	// Checking it first spares counting the resources on every update.
+	if name != "" {
+		exists, err := e.exists(ctx, namespace, resource, name)
+		if err != nil || exists {
+			return err
+		}
+	}
+	used, err := e.count(ctx, namespace, resource)
+	if err != nil {
+		return err
+	}
+	var exceeded *quotav1.Quota
+	for _, quota := range quotas {
+		if used >= quota.Limit(resource) {
+			exceeded = quota
+			break
+		}
+	}
+	if exceeded == nil {
+		return nil
+	}
+	return &quotav1.ErrQuotaExceeded{
+		Quota:    exceeded.Metadata.Name,
+		Resource: resource,
+		Limit:    exceeded.Limit(resource),
+	}
+}
+
+// checkEventRate returns an error if the namespace already ingested as many
+// events as allowed during the current second. The event is counted if take
+// is true and no quota is exceeded.
+func (e *Enforcer) checkEventRate(ctx context.Context, namespace string, take bool) error {
+	quotas, err := e.limiting(ctx, namespace, quotav1.ResourceEventRate)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.rates == nil {
+		e.rates = make(map[string]*rateWindow)
+	}
+	window, ok := e.rates[namespace]
+	if !ok {
+		window = &rateWindow{}
+		e.rates[namespace] = window
+	}
+	window.advance(e.time())
+	for _, quota := range quotas {
+		if window.count >= quota.EventRate {
+			return &quotav1.ErrQuotaExceeded{
+				Quota:    quota.Metadata.Name,
+				Resource: quotav1.ResourceEventRate,
+				Limit:    quota.EventRate,
+			}
+		}
+	}
+	if take {
+		window.count++
+	}
+	return nil
+}
+
+// eventRate returns the number of events ingested by the namespace during
+// the last second.
+func (e *Enforcer) eventRate(namespace string) uint64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	window, ok := e.rates[namespace]
+	if !ok {
+		return 0
+	}
+	window.advance(e.time())
+	return window.previous
+}
+
+func (e *Enforcer) count(ctx context.Context, namespace, resource string) (uint64, error) {
+	var count int
+	var err error
+	switch resource {
+	case quotav1.ResourceEntities:
+		count, err = e.Store.GetEntityConfigStore().Count(ctx, namespace, "")
+	case quotav1.ResourceChecks:
+		count, err = storev2.Of[*corev2.CheckConfig](e.Store).Count(ctx, storev2.ID{Namespace: namespace})
+	case quotav1.ResourceHandlers:
+		count, err = storev2.Of[*corev2.Handler](e.Store).Count(ctx, storev2.ID{Namespace: namespace})
+	case quotav1.ResourceSilenced:
+		var silences []*corev2.Silenced
+		silences, err = e.Store.GetSilencesStore().GetSilences(ctx, namespace)
+		count = len(silences)
+	case quotav1.ResourceEvents:
+		var events int64
+		ctx = store.NamespaceContext(ctx, namespace)
+		events, err = e.Store.GetEventStore().CountEvents(ctx, &store.SelectionPredicate{})
+		count = int(events)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return uint64(count), nil
+}
+
+func (e *Enforcer) exists(ctx context.Context, namespace, resource, name string) (bool, error) {
+	var err error
+	switch resource {
+	case quotav1.ResourceEntities:
+		return e.Store.GetEntityConfigStore().Exists(ctx, namespace, name)
+	case quotav1.ResourceChecks:
+		return storev2.Of[*corev2.CheckConfig](e.Store).Exists(ctx, storev2.ID{Namespace: namespace, Name: name})
+	case quotav1.ResourceHandlers:
+		return storev2.Of[*corev2.Handler](e.Store).Exists(ctx, storev2.ID{Namespace: namespace, Name: name})
+	case quotav1.ResourceSilenced:
+		var silenced *corev2.Silenced
+		silenced, err = e.Store.GetSilencesStore().GetSilenceByName(ctx, namespace, name)
+		if err == nil && silenced == nil {
+			return false, nil
+		}
+	case quotav1.ResourceEvents:
+		entity, check, ok := strings.Cut(name, "/")
+		if !ok {
+			return false, nil
+		}
+		ctx = store.NamespaceContext(ctx, namespace)
+		var event *corev2.Event
+		event, err = e.Store.GetEventStore().GetEventByEntityCheck(ctx, entity, check)
+		if err == nil && event == nil {
+			return false, nil
+		}
+	}
+	if err != nil {
+		var notFound *store.ErrNotFound
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
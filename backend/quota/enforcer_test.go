@@ -0,0 +1,219 @@
+package quota
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	quotav1 "github.com/sensu/sensu-go/backend/quota/v1"
+	"github.com/sensu/sensu-go/backend/store"
+	"github.com/sensu/sensu-go/testing/mockstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func fixtureQuota(name string) *quotav1.Quota {
+	return &quotav1.Quota{Metadata: corev2.ObjectMeta{Name: name, Namespace: "default"}}
+}
+
+func newTestEnforcer(quotas ...*quotav1.Quota) (*Enforcer, *mockstore.V2MockStore) {
+	stor := &mockstore.V2MockStore{}
+	cs := new(mockstore.ConfigStore)
+	stor.On("GetConfigStore").Return(cs)
+	cs.On("List", mock.Anything, mock.Anything, mock.Anything).Return(mockstore.WrapList[*quotav1.Quota](quotas), nil)
+	return NewEnforcer(stor), stor
+}
+
+func TestEnforcerAllowCreate(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    uint64
+		count    int
+		entity   string
+		exists   bool
+		exceeded bool
+	}{
+		{
+			name:  "under limit",
+			limit: 10,
+			count: 9,
+		},
+		{
+			name:     "limit reached",
+			limit:    10,
+			count:    10,
+			exceeded: true,
+		},
+		{
+			name:   "update of an existing entity",
+			limit:  10,
+			count:  10,
+			entity: "entity1",
+			exists: true,
+		},
+		{
+			name:     "creation of a new entity",
+			limit:    10,
+			count:    10,
+			entity:   "entity1",
+			exceeded: true,
+		},
+		{
+			name:  "no limit",
+			count: 10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quota := fixtureQuota("team-a")
+			quota.Entities = tt.limit
+			enforcer, stor := newTestEnforcer(quota)
+			ecs := new(mockstore.EntityConfigStore)
+			stor.On("GetEntityConfigStore").Return(ecs)
+			ecs.On("Count", mock.Anything, "default", "").Return(tt.count, nil)
+			ecs.On("Exists", mock.Anything, "default", tt.entity).Return(tt.exists, nil)
+
+			err := enforcer.AllowCreate(context.Background(), "default", quotav1.ResourceEntities, tt.entity)
+			if !tt.exceeded {
+				assert.NoError(t, err)
+				return
+			}
+			require.IsType(t, &quotav1.ErrQuotaExceeded{}, err)
+			exceeded := err.(*quotav1.ErrQuotaExceeded)
+			assert.Equal(t, "team-a", exceeded.Quota)
+			assert.Equal(t, quotav1.ResourceEntities, exceeded.Resource)
+			assert.Equal(t, tt.limit, exceeded.Limit)
+			assert.False(t, exceeded.IsRateLimit())
+		})
+	}
+}
+
+func TestEnforcerAllowCreateSilence(t *testing.T) {
+	quota := fixtureQuota("team-a")
+	quota.Silenced = 1
+	enforcer, stor := newTestEnforcer(quota)
+	ss := new(mockstore.SilencesStore)
+	stor.On("GetSilencesStore").Return(ss)
+	ss.On("GetSilences", mock.Anything, "default").Return([]*corev2.Silenced{corev2.FixtureSilenced("*:check1")}, nil)
+	ss.On("GetSilenceByName", mock.Anything, "default", "*:check1").Return(corev2.FixtureSilenced("*:check1"), nil)
+	ss.On("GetSilenceByName", mock.Anything, "default", "*:check2").Return((*corev2.Silenced)(nil), &store.ErrNotFound{Key: "*:check2"})
+
+	// The existing silence can be updated, but no silence can be created
+	assert.NoError(t, enforcer.AllowCreate(context.Background(), "default", quotav1.ResourceSilenced, "*:check1"))
+	err := enforcer.AllowCreate(context.Background(), "default", quotav1.ResourceSilenced, "*:check2")
+	assert.IsType(t, &quotav1.ErrQuotaExceeded{}, err)
+}
+
+func TestEnforcerAllowCreateUnlimitedResource(t *testing.T) {
+	quota := fixtureQuota("team-a")
+	quota.Entities = 1
+	enforcer, _ := newTestEnforcer(quota)
+
+	// The store isn't queried for the resources that the quotas don't limit
+	assert.NoError(t, enforcer.AllowCreate(context.Background(), "default", quotav1.ResourceHandlers, ""))
+	assert.NoError(t, enforcer.AllowCreate(context.Background(), "default", "assets", ""))
+}
+
+func TestEnforcerAllowEventRate(t *testing.T) {
+	quota := fixtureQuota("team-a")
+	quota.EventRate = 2
+	enforcer, _ := newTestEnforcer(quota)
+	enforcer.now = func() time.Time { return time.Unix(100, 0) }
+	ctx := context.Background()
+
+	// Metrics events count against the event rate, but aren't stored
+	event := corev2.FixtureEvent("entity1", "check1")
+	event.Check = nil
+	event.Metrics = corev2.FixtureMetrics()
+
+	assert.NoError(t, enforcer.AllowEvent(ctx, event))
+	assert.NoError(t, enforcer.AllowEvent(ctx, event))
+	err := enforcer.AllowEvent(ctx, event)
+	require.IsType(t, &quotav1.ErrQuotaExceeded{}, err)
+	assert.True(t, err.(*quotav1.ErrQuotaExceeded).IsRateLimit())
+
+	// The API checks the rate of the events it receives without counting
+	// them, as eventd does
+	err = enforcer.AllowCreate(ctx, "default", quotav1.ResourceEvents, "entity1/check1")
+	require.IsType(t, &quotav1.ErrQuotaExceeded{}, err)
+}
+
+func TestEnforcerAllowEventCount(t *testing.T) {
+	quota := fixtureQuota("team-a")
+	quota.Events = 1
+	enforcer, stor := newTestEnforcer(quota)
+	es := &mockstore.MockStore{}
+	stor.On("GetEventStore").Return(es)
+	es.On("CountEvents", mock.Anything, mock.Anything).Return(int64(1), nil)
+	es.On("GetEventByEntityCheck", mock.Anything, "entity1", "check1").Return(corev2.FixtureEvent("entity1", "check1"), nil)
+	es.On("GetEventByEntityCheck", mock.Anything, "entity1", "check2").Return((*corev2.Event)(nil), nil)
+	ctx := context.Background()
+
+	// The existing events are updated, without counting the events
+	assert.NoError(t, enforcer.AllowEvent(ctx, corev2.FixtureEvent("entity1", "check1")))
+	es.AssertNotCalled(t, "CountEvents", mock.Anything, mock.Anything)
+
+	// The new events would exceed the quota
+	err := enforcer.AllowEvent(ctx, corev2.FixtureEvent("entity1", "check2"))
+	require.IsType(t, &quotav1.ErrQuotaExceeded{}, err)
+	assert.Equal(t, quotav1.ResourceEvents, err.(*quotav1.ErrQuotaExceeded).Resource)
+}
+
+func TestEnforcerUsage(t *testing.T) {
+	quota := fixtureQuota("team-a")
+	quota.Entities = 100
+	quota.EventRate = 50
+	enforcer, stor := newTestEnforcer(quota)
+	ecs := new(mockstore.EntityConfigStore)
+	stor.On("GetEntityConfigStore").Return(ecs)
+	ecs.On("Count", mock.Anything, "default", "").Return(42, nil)
+	cs := stor.GetConfigStore().(*mockstore.ConfigStore)
+	cs.On("Count", mock.Anything, mock.Anything).Return(3, nil)
+	ss := new(mockstore.SilencesStore)
+	stor.On("GetSilencesStore").Return(ss)
+	ss.On("GetSilences", mock.Anything, "default").Return([]*corev2.Silenced{corev2.FixtureSilenced("*:check1")}, nil)
+	es := &mockstore.MockStore{}
+	stor.On("GetEventStore").Return(es)
+	es.On("CountEvents", mock.Anything, mock.Anything).Return(int64(7), nil)
+
+	usage, err := enforcer.Usage(context.Background(), quota)
+	require.NoError(t, err)
+	assert.Equal(t, "team-a", usage.Quota)
+	assert.Equal(t, "default", usage.Namespace)
+	require.Len(t, usage.Resources, len(quotav1.Resources))
+	assert.Equal(t, &quotav1.ResourceUsage{Resource: quotav1.ResourceEntities, Used: 42, Limit: 100}, usage.Resources[0])
+	assert.Equal(t, &quotav1.ResourceUsage{Resource: quotav1.ResourceChecks, Used: 3}, usage.Resources[1])
+	assert.Equal(t, &quotav1.ResourceUsage{Resource: quotav1.ResourceSilenced, Used: 1}, usage.Resources[3])
+	assert.Equal(t, &quotav1.ResourceUsage{Resource: quotav1.ResourceEvents, Used: 7}, usage.Resources[4])
+	assert.Equal(t, &quotav1.ResourceUsage{Resource: quotav1.ResourceEventRate, Limit: 50}, usage.Resources[5])
+}
+
+func TestEnforcerCachesQuotas(t *testing.T) {
+	enforcer, stor := newTestEnforcer(fixtureQuota("team-a"))
+	ctx := context.Background()
+
+	_, err := enforcer.Quotas(ctx, "default")
+	require.NoError(t, err)
+	_, err = enforcer.Quotas(ctx, "default")
+	require.NoError(t, err)
+	stor.GetConfigStore().(*mockstore.ConfigStore).AssertNumberOfCalls(t, "List", 1)
+}
+
+func TestRateWindow(t *testing.T) {
+	window := &rateWindow{}
+	now := time.Unix(100, 0)
+	window.advance(now)
+	window.count = 5
+
+	window.advance(now.Add(500 * time.Millisecond))
+	assert.Equal(t, uint64(5), window.count)
+
+	window.advance(now.Add(time.Second))
+	assert.Equal(t, uint64(0), window.count)
+	assert.Equal(t, uint64(5), window.previous)
+
+	window.advance(now.Add(3 * time.Second))
+	assert.Equal(t, uint64(0), window.previous)
+}
@@ -0,0 +1,136 @@
+package quota
+
+import (
+	"context"
+
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	quotav1 "github.com/sensu/sensu-go/backend/quota/v1"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+type enforcedKey struct{}
+
+// EnforcedContext returns a context in which the quotas were already
+// enforced, for instance by the apid middleware, so that the Store doesn't
+// enforce them again.
+func EnforcedContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, enforcedKey{}, true)
+}
+
+func enforced(ctx context.Context) bool {
+	ok, _ := ctx.Value(enforcedKey{}).(bool)
+	return ok
+}
+
+// Store is a storev2.Interface that enforces the namespace quotas on the
+// creation of entities, checks, handlers and silences, whichever API or
+// daemon creates them. The events are enforced by eventd, as they are
+// ingested.
+type Store struct {
+	storev2.Interface
+
+	// Enforcer enforces the quotas. It must not use the Store itself.
+	Enforcer *Enforcer
+}
+
+// NewStore creates a new Store, that enforces the quotas on the creation of
+// the resources of the store.
+func NewStore(store storev2.Interface, enforcer *Enforcer) *Store {
+	return &Store{
+		Interface: store,
+		Enforcer:  enforcer,
+	}
+}
+
+// GetConfigStore returns a ConfigStore that enforces the quotas on the
+// creation of checks and handlers.
+func (s *Store) GetConfigStore() storev2.ConfigStore {
+	return &configStore{ConfigStore: s.Interface.GetConfigStore(), enforcer: s.Enforcer}
+}
+
+// GetEntityConfigStore returns an EntityConfigStore that enforces the quotas
+// on the creation of entities.
+func (s *Store) GetEntityConfigStore() storev2.EntityConfigStore {
+	return &entityConfigStore{EntityConfigStore: s.Interface.GetEntityConfigStore(), enforcer: s.Enforcer}
+}
+
+// GetSilencesStore returns a SilencesStore that enforces the quotas on the
+// creation of silences.
+func (s *Store) GetSilencesStore() storev2.SilencesStore {
+	return &silencesStore{SilencesStore: s.Interface.GetSilencesStore(), enforcer: s.Enforcer}
+}
+
+func (e *Enforcer) allowCreate(ctx context.Context, namespace, resource, name string) error {
+	if resource == "" || enforced(ctx) {
+		return nil
+	}
+	return e.AllowCreate(ctx, namespace, resource, name)
+}
+
+type configStore struct {
+	storev2.ConfigStore
+	enforcer *Enforcer
+}
+
+// configResource returns the name of the quota resource of the request, if
+// it's limited by quotas.
+func configResource(req storev2.ResourceRequest) string {
+	if req.APIVersion != "core/v2" {
+		return ""
+	}
+	switch req.Type {
+	case "CheckConfig":
+		return quotav1.ResourceChecks
+	case "Handler":
+		return quotav1.ResourceHandlers
+	}
+	return ""
+}
+
+func (s *configStore) CreateOrUpdate(ctx context.Context, req storev2.ResourceRequest, w storev2.Wrapper) error {
+	if err := s.enforcer.allowCreate(ctx, req.Namespace, configResource(req), req.Name); err != nil {
+		return err
+	}
+	return s.ConfigStore.CreateOrUpdate(ctx, req, w)
+}
+
+func (s *configStore) CreateIfNotExists(ctx context.Context, req storev2.ResourceRequest, w storev2.Wrapper) error {
+	if err := s.enforcer.allowCreate(ctx, req.Namespace, configResource(req), req.Name); err != nil {
+		return err
+	}
+	return s.ConfigStore.CreateIfNotExists(ctx, req, w)
+}
+
+type entityConfigStore struct {
+	storev2.EntityConfigStore
+	enforcer *Enforcer
+}
+
+func (s *entityConfigStore) CreateOrUpdate(ctx context.Context, config *corev3.EntityConfig) error {
+	meta := config.GetMetadata()
+	if err := s.enforcer.allowCreate(ctx, meta.Namespace, quotav1.ResourceEntities, meta.Name); err != nil {
+		return err
+	}
+	return s.EntityConfigStore.CreateOrUpdate(ctx, config)
+}
+
+func (s *entityConfigStore) CreateIfNotExists(ctx context.Context, config *corev3.EntityConfig) error {
+	meta := config.GetMetadata()
+	if err := s.enforcer.allowCreate(ctx, meta.Namespace, quotav1.ResourceEntities, meta.Name); err != nil {
+		return err
+	}
+	return s.EntityConfigStore.CreateIfNotExists(ctx, config)
+}
+
+type silencesStore struct {
+	storev2.SilencesStore
+	enforcer *Enforcer
+}
+
+func (s *silencesStore) UpdateSilence(ctx context.Context, silenced *corev2.Silenced) error {
+	if err := s.enforcer.allowCreate(ctx, silenced.Namespace, quotav1.ResourceSilenced, silenced.Name); err != nil {
+		return err
+	}
+	return s.SilencesStore.UpdateSilence(ctx, silenced)
+}
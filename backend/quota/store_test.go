@@ -0,0 +1,63 @@
+package quota
+
+import (
+	"context"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	quotav1 "github.com/sensu/sensu-go/backend/quota/v1"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	"github.com/sensu/sensu-go/testing/mockstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreEnforcesEntities(t *testing.T) {
+	quota := fixtureQuota("team-a")
+	quota.Entities = 1
+	enforcer, stor := newTestEnforcer(quota)
+	ecs := new(mockstore.EntityConfigStore)
+	stor.On("GetEntityConfigStore").Return(ecs)
+	ecs.On("Count", mock.Anything, "default", "").Return(1, nil)
+	ecs.On("Exists", mock.Anything, "default", "existing").Return(true, nil)
+	ecs.On("Exists", mock.Anything, "default", "new").Return(false, nil)
+	ecs.On("CreateOrUpdate", mock.Anything, mock.Anything).Return(nil)
+	ecs.On("CreateIfNotExists", mock.Anything, mock.Anything).Return(nil)
+	store := NewStore(stor, enforcer)
+	ctx := context.Background()
+
+	// existing entities can be updated
+	assert.NoError(t, store.GetEntityConfigStore().CreateOrUpdate(ctx, corev3.FixtureEntityConfig("existing")))
+
+	// new entities would exceed the quota, whichever daemon creates them
+	err := store.GetEntityConfigStore().CreateIfNotExists(ctx, corev3.FixtureEntityConfig("new"))
+	require.IsType(t, &quotav1.ErrQuotaExceeded{}, err)
+	ecs.AssertNumberOfCalls(t, "CreateIfNotExists", 0)
+
+	// unless the quotas were enforced already
+	assert.NoError(t, store.GetEntityConfigStore().CreateIfNotExists(EnforcedContext(ctx), corev3.FixtureEntityConfig("new")))
+	ecs.AssertNumberOfCalls(t, "CreateIfNotExists", 1)
+}
+
+func TestStoreEnforcesChecks(t *testing.T) {
+	quota := fixtureQuota("team-a")
+	quota.Checks = 1
+	enforcer, stor := newTestEnforcer(quota)
+	cs := stor.GetConfigStore().(*mockstore.ConfigStore)
+	cs.On("Count", mock.Anything, mock.Anything).Return(1, nil)
+	cs.On("Exists", mock.Anything, mock.Anything).Return(false, nil)
+	cs.On("CreateOrUpdate", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	store := NewStore(stor, enforcer)
+	ctx := context.Background()
+
+	check := corev2.FixtureCheckConfig("check")
+	err := storev2.Of[*corev2.CheckConfig](store).CreateOrUpdate(ctx, check)
+	require.IsType(t, &quotav1.ErrQuotaExceeded{}, err)
+	assert.Equal(t, quotav1.ResourceChecks, err.(*quotav1.ErrQuotaExceeded).Resource)
+
+	// the resources that the quotas don't limit are created
+	asset := corev2.FixtureAsset("asset")
+	assert.NoError(t, storev2.Of[*corev2.Asset](store).CreateOrUpdate(ctx, asset))
+}
@@ -0,0 +1,17 @@
+package v1
+
+import (
+	corev3 "github.com/sensu/core/v3"
+)
+
+// QuotaFields returns a set of fields that represent the quota for the
+// purposes of field selectors.
+func QuotaFields(r corev3.Resource) map[string]string {
+	resource := r.(*Quota)
+	fields := map[string]string{
+		"quota.name":      resource.Metadata.Name,
+		"quota.namespace": resource.Metadata.Namespace,
+	}
+	mergeLabels(fields, resource.Metadata.Labels, "quota.labels.")
+	return fields
+}
@@ -0,0 +1,195 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+
+	corev2 "github.com/sensu/core/v2"
+)
+
+const (
+	// QuotasResource is the RBAC name of the quotas.
+	QuotasResource = "quotas"
+
+	// ResourceEntities is the name of the entities in quota usages and
+	// errors.
+	ResourceEntities = "entities"
+
+	// ResourceChecks is the name of the checks in quota usages and errors.
+	ResourceChecks = "checks"
+
+	// ResourceHandlers is the name of the handlers in quota usages and
+	// errors.
+	ResourceHandlers = "handlers"
+
+	// ResourceSilenced is the name of the silences in quota usages and
+	// errors.
+	ResourceSilenced = "silenced"
+
+	// ResourceEvents is the name of the events in quota usages and errors.
+	ResourceEvents = "events"
+
+	// ResourceEventRate is the name of the event ingest rate in quota usages
+	// and errors.
+	ResourceEventRate = "event_rate"
+)
+
+// Resources are the resources that quotas can limit, in the order of the
+// quota usages.
+var Resources = []string{
+	ResourceEntities,
+	ResourceChecks,
+	ResourceHandlers,
+	ResourceSilenced,
+	ResourceEvents,
+	ResourceEventRate,
+}
+
+// Quota limits the resources of its namespace, e.g. to 1000 entities and 50
+// events per second. A limit of 0 means no limit. When a namespace has several
+// quotas, all of them are enforced.
+type Quota struct {
+	// Metadata contains the name, namespace, labels and annotations of the
+	// quota.
+	Metadata corev2.ObjectMeta `json:"metadata"`
+
+	// Entities is the maximum number of entities.
+	Entities uint64 `json:"entities,omitempty"`
+
+	// Checks is the maximum number of checks.
+	Checks uint64 `json:"checks,omitempty"`
+
+	// Handlers is the maximum number of handlers.
+	Handlers uint64 `json:"handlers,omitempty"`
+
+	// Silenced is the maximum number of silences.
+	Silenced uint64 `json:"silenced,omitempty"`
+
+	// Events is the maximum number of events.
+	Events uint64 `json:"events,omitempty"`
+
+	// EventRate is the maximum number of events ingested per second,
+	// including the metrics events, by each backend of the cluster.
+	EventRate uint64 `json:"event_rate,omitempty"`
+}
+
+// GetMetadata returns the quota metadata.
+func (q *Quota) GetMetadata() *corev2.ObjectMeta {
+	return &q.Metadata
+}
+
+// SetMetadata sets the quota metadata.
+func (q *Quota) SetMetadata(meta *corev2.ObjectMeta) {
+	if meta == nil {
+		meta = &corev2.ObjectMeta{}
+	}
+	q.Metadata = *meta
+}
+
+// StoreName returns the store name of the quota.
+func (q *Quota) StoreName() string {
+	return "quota/quotas"
+}
+
+// RBACName returns the RBAC name of the quota.
+func (q *Quota) RBACName() string {
+	return QuotasResource
+}
+
+// URIPath returns the path of the quota.
+func (q *Quota) URIPath() string {
+	if q.Metadata.Namespace == "" {
+		return path.Join(URLPrefix, QuotasResource, url.PathEscape(q.Metadata.Name))
+	}
+	return path.Join(URLPrefix, "namespaces", url.PathEscape(q.Metadata.Namespace), QuotasResource, url.PathEscape(q.Metadata.Name))
+}
+
+// GetTypeMeta returns the type metadata of the quota.
+func (q *Quota) GetTypeMeta() corev2.TypeMeta {
+	return typeMeta("Quota")
+}
+
+// Validate returns an error if the quota is invalid.
+func (q *Quota) Validate() error {
+	if err := corev2.ValidateName(q.Metadata.Name); err != nil {
+		return errors.New("quota name " + err.Error())
+	}
+	if q.Metadata.Namespace == "" {
+		return errors.New("namespace must be set")
+	}
+	return nil
+}
+
+// Limit returns the limit of the quota on the resource, 0 if the resource is
+// not limited.
+func (q *Quota) Limit(resource string) uint64 {
+	switch resource {
+	case ResourceEntities:
+		return q.Entities
+	case ResourceChecks:
+		return q.Checks
+	case ResourceHandlers:
+		return q.Handlers
+	case ResourceSilenced:
+		return q.Silenced
+	case ResourceEvents:
+		return q.Events
+	case ResourceEventRate:
+		return q.EventRate
+	}
+	return 0
+}
+
+// QuotaUsage is the usage of the resources of a namespace, against the
+// limits of one of its quotas.
+type QuotaUsage struct {
+	// Quota is the name of the quota.
+	Quota string `json:"quota"`
+
+	// Namespace is the namespace of the quota.
+	Namespace string `json:"namespace"`
+
+	// Resources are the usages of the resources, in the order of Resources.
+	Resources []*ResourceUsage `json:"resources"`
+}
+
+// ResourceUsage is the usage of a resource against its limit.
+type ResourceUsage struct {
+	// Resource is the name of the resource, e.g. entities.
+	Resource string `json:"resource"`
+
+	// Used is the number of resources, or the number of events ingested
+	// during the last second for the event rate.
+	Used uint64 `json:"used"`
+
+	// Limit is the limit of the quota, 0 if the resource is not limited.
+	Limit uint64 `json:"limit"`
+}
+
+// ErrQuotaExceeded is returned when a write would exceed a quota.
+type ErrQuotaExceeded struct {
+	// Quota is the name of the exceeded quota.
+	Quota string
+
+	// Resource is the name of the limited resource.
+	Resource string
+
+	// Limit is the limit of the quota on the resource.
+	Limit uint64
+}
+
+// Error implements error.
+func (e *ErrQuotaExceeded) Error() string {
+	if e.Resource == ResourceEventRate {
+		return fmt.Sprintf("quota %q exceeded: limit of %d events per second reached", e.Quota, e.Limit)
+	}
+	return fmt.Sprintf("quota %q exceeded: limit of %d %s reached", e.Quota, e.Limit, e.Resource)
+}
+
+// IsRateLimit returns whether the exceeded limit is a rate, that can be
+// retried later, rather than a number of resources.
+func (e *ErrQuotaExceeded) IsRateLimit() bool {
+	return e.Resource == ResourceEventRate
+}
@@ -0,0 +1,61 @@
+package v1
+
+import (
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotaValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		quota   *Quota
+		wantErr bool
+	}{
+		{
+			name:  "valid",
+			quota: &Quota{Metadata: corev2.ObjectMeta{Name: "team-a", Namespace: "default"}, Entities: 1000},
+		},
+		{
+			name:  "no limits",
+			quota: &Quota{Metadata: corev2.ObjectMeta{Name: "team-a", Namespace: "default"}},
+		},
+		{
+			name:    "missing namespace",
+			quota:   &Quota{Metadata: corev2.ObjectMeta{Name: "team-a"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid name",
+			quota:   &Quota{Metadata: corev2.ObjectMeta{Name: "team a", Namespace: "default"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.quota.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQuotaLimit(t *testing.T) {
+	quota := &Quota{Entities: 1, Checks: 2, Handlers: 3, Silenced: 4, Events: 5, EventRate: 6}
+	for i, resource := range Resources {
+		assert.Equal(t, uint64(i+1), quota.Limit(resource), resource)
+	}
+	assert.Equal(t, uint64(0), quota.Limit("assets"))
+}
+
+func TestErrQuotaExceeded(t *testing.T) {
+	err := &ErrQuotaExceeded{Quota: "team-a", Resource: ResourceEntities, Limit: 1000}
+	assert.Equal(t, `quota "team-a" exceeded: limit of 1000 entities reached`, err.Error())
+	assert.False(t, err.IsRateLimit())
+
+	err = &ErrQuotaExceeded{Quota: "team-a", Resource: ResourceEventRate, Limit: 50}
+	assert.Equal(t, `quota "team-a" exceeded: limit of 50 events per second reached`, err.Error())
+	assert.True(t, err.IsRateLimit())
+}
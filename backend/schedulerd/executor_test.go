@@ -7,13 +7,24 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	corev2 "github.com/sensu/core/v2"
 	corev3 "github.com/sensu/core/v3"
 	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/sensu/sensu-go/backend/secrets"
+	secretsv1 "github.com/sensu/sensu-go/backend/secrets/v1"
 )
 
+// envSecretGetter resolves every secret to the env provider, with the secret
+// name as its ID.
+type envSecretGetter struct{}
+
+func (envSecretGetter) Get(ctx context.Context, name string) (string, string, error) {
+	return "env", name, nil
+}
+
 func TestPublishProxyCheckRequest(t *testing.T) {
 	t.Parallel()
 
@@ -248,3 +259,34 @@ func TestCheckBuildRequestCron(t *testing.T) {
 
 	assert.NoError(scheduler.msgBus.Stop())
 }
+
+func TestCheckBuildRequestSecrets(t *testing.T) {
+	t.Setenv("SENSU_TEST_TOKEN", "s3cr3t")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	scheduler := newIntervalScheduler(ctx, t, "check")
+	defer func() { _ = scheduler.msgBus.Stop() }()
+
+	receiver := &mockEventReceiver{}
+	receiver.On("GenerateBackendEvent", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	pm := secrets.NewProviderManager(receiver)
+	scheduler.exec.secretsProviderManager = pm
+	pm.Getter = envSecretGetter{}
+	provider := &secretsv1.Env{}
+	provider.Metadata.Name = "env"
+	pm.AddProvider(provider)
+
+	check := scheduler.check
+	check.Secrets = []*corev2.Secret{{Name: "TOKEN", Secret: "SENSU_TEST_TOKEN"}}
+
+	// The secrets are only sent to agents authenticated with mutual TLS
+	request, err := scheduler.exec.buildRequest(check)
+	require.NoError(t, err)
+	assert.Empty(t, request.Secrets)
+
+	pm.TLSenabled = true
+	request, err = scheduler.exec.buildRequest(check)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TOKEN=s3cr3t"}, request.Secrets)
+}
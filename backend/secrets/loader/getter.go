@@ -0,0 +1,28 @@
+package loader
+
+import (
+	"context"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/secrets"
+	secretsv1 "github.com/sensu/sensu-go/backend/secrets/v1"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+var _ secrets.Getter = new(Getter)
+
+// Getter resolves Sensu secrets with the secrets/v1.Secret resources of the
+// namespace found in the context.
+type Getter struct {
+	Store storev2.Interface
+}
+
+// Get returns the provider name and the secret ID of the named secret.
+func (g *Getter) Get(ctx context.Context, name string) (string, string, error) {
+	id := storev2.ID{Namespace: corev2.ContextNamespace(ctx), Name: name}
+	secret, err := storev2.Of[*secretsv1.Secret](g.Store).Get(ctx, id)
+	if err != nil {
+		return "", "", err
+	}
+	return secret.Provider, secret.ID, nil
+}
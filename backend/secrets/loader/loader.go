@@ -0,0 +1,109 @@
+// Package loader keeps a secrets provider manager in sync with the secrets
+// providers stored in the configuration store, and resolves Sensu secrets
+// with the secrets/v1.Secret resources.
+package loader
+
+import (
+	"context"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/providers"
+	"github.com/sensu/sensu-go/backend/secrets"
+	secretsv1 "github.com/sensu/sensu-go/backend/secrets/v1"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	"github.com/sirupsen/logrus"
+)
+
+var logger = logrus.WithFields(logrus.Fields{
+	"component": "secrets",
+})
+
+// Loader loads the secrets providers found in the store into a provider
+// manager, and keeps the manager up to date as providers are created,
+// updated or deleted.
+type Loader struct {
+	Store   storev2.Interface
+	Manager secrets.ProviderManagerer
+}
+
+// New creates a new Loader.
+func New(store storev2.Interface, manager secrets.ProviderManagerer) *Loader {
+	return &Loader{
+		Store:   store,
+		Manager: manager,
+	}
+}
+
+// Load adds every stored provider to the provider manager.
+func (l *Loader) Load(ctx context.Context) error {
+	return l.loader().Load(ctx)
+}
+
+// Watch applies the changes made to the stored providers to the provider
+// manager, until the context is canceled.
+func (l *Loader) Watch(ctx context.Context) {
+	l.loader().Watch(ctx)
+}
+
+func (l *Loader) loader() *providers.Loader[secrets.Provider] {
+	return &providers.Loader[secrets.Provider]{
+		Store: l.Store,
+		Sources: []providers.Source[secrets.Provider]{
+			providers.NewSource[secrets.Provider, *secretsv1.Env]("env secrets"),
+			providers.NewSource[secrets.Provider, *secretsv1.File]("file secrets"),
+		},
+		Add:    l.add,
+		Remove: l.remove,
+		Logger: logger,
+	}
+}
+
+func (l *Loader) add(provider secrets.Provider) {
+	name := provider.GetMetadata().Name
+	typeMeta := typeMetaOf(provider)
+	fields := logrus.Fields{
+		"provider": name,
+		"type":     typeMeta.Type,
+	}
+	if err := provider.Validate(); err != nil {
+		// Keep the provider around so that secrets lookups report why it
+		// can't be used.
+		logger.WithFields(fields).WithError(err).Error("invalid secrets provider")
+		provider = &secrets.BrokenProvider{
+			TypeMeta: typeMeta,
+			Metadata: *provider.GetMetadata(),
+			Err:      err,
+		}
+	}
+	if existing, ok := l.Manager.Providers()[name]; ok && typeMetaOf(existing).Type != typeMeta.Type {
+		logger.WithFields(fields).Warn("secrets provider replaces a provider of another type with the same name")
+	}
+	l.Manager.AddProvider(provider)
+	logger.WithFields(fields).Info("loaded secrets provider")
+}
+
+// remove removes the provider, unless it was replaced by a provider of
+// another type with the same name.
+func (l *Loader) remove(provider secrets.Provider) {
+	name := provider.GetMetadata().Name
+	existing, ok := l.Manager.Providers()[name]
+	if !ok || typeMetaOf(existing).Type != typeMetaOf(provider).Type {
+		return
+	}
+	if err := l.Manager.RemoveProvider(name); err != nil {
+		logger.WithError(err).Warn("couldn't remove secrets provider")
+		return
+	}
+	logger.WithField("provider", name).Info("removed secrets provider")
+}
+
+func typeMetaOf(provider secrets.Provider) corev2.TypeMeta {
+	switch provider := provider.(type) {
+	case interface{ GetTypeMeta() corev2.TypeMeta }:
+		return provider.GetTypeMeta()
+	case *secrets.BrokenProvider:
+		return provider.TypeMeta
+	default:
+		return corev2.TypeMeta{}
+	}
+}
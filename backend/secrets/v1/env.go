@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"errors"
+	"net/url"
+	"os"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/providers"
+	"github.com/sensu/sensu-go/backend/secrets"
+)
+
+var _ secrets.Provider = new(Env)
+
+// EnvProviderKind is the URL path element of the Env provider.
+const EnvProviderKind = "env"
+
+// Env is a secrets provider that reads secrets from the environment of the
+// sensu-backend process. The secret ID is the name of the environment
+// variable.
+type Env struct {
+	// Metadata contains the name, labels and annotations of the provider.
+	// Providers are global resources and have no namespace.
+	Metadata corev2.ObjectMeta `json:"metadata"`
+}
+
+// Get returns the value of the environment variable named id.
+func (e *Env) Get(id string) (string, error) {
+	value, ok := os.LookupEnv(id)
+	if !ok {
+		return "", secrets.ErrSecretNotFound(id)
+	}
+	return value, nil
+}
+
+// GetMetadata returns the provider metadata.
+func (e *Env) GetMetadata() *corev2.ObjectMeta {
+	return &e.Metadata
+}
+
+// SetMetadata sets the provider metadata.
+func (e *Env) SetMetadata(meta *corev2.ObjectMeta) {
+	if meta == nil {
+		meta = &corev2.ObjectMeta{}
+	}
+	e.Metadata = *meta
+}
+
+// StoreName returns the store name of the provider.
+func (e *Env) StoreName() string {
+	return "secrets/providers/env"
+}
+
+// RBACName returns the RBAC name of the provider.
+func (e *Env) RBACName() string {
+	return ProvidersResource
+}
+
+// URIPath returns the path of the provider.
+func (e *Env) URIPath() string {
+	return providers.Path(URLPrefix, ProvidersResource, EnvProviderKind, url.PathEscape(e.Metadata.Name))
+}
+
+// GetTypeMeta returns the type metadata of the provider.
+func (e *Env) GetTypeMeta() corev2.TypeMeta {
+	return providers.TypeMeta(APIVersion, "Env")
+}
+
+// IsGlobalResource returns true, providers are not namespaced.
+func (e *Env) IsGlobalResource() bool {
+	return true
+}
+
+// Validate returns an error if the provider is invalid.
+func (e *Env) Validate() error {
+	if err := corev2.ValidateName(e.Metadata.Name); err != nil {
+		return errors.New("provider name " + err.Error())
+	}
+	if e.Metadata.Namespace != "" {
+		return errors.New("providers cannot be namespaced")
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package v1
+
+import (
+	corev3 "github.com/sensu/core/v3"
+)
+
+// ProviderFields returns a set of fields that represent the provider for the
+// purposes of field selectors.
+func ProviderFields(r corev3.Resource) map[string]string {
+	meta := r.GetMetadata()
+	fields := map[string]string{
+		"provider.name": meta.Name,
+	}
+	mergeLabels(fields, meta.Labels, "provider.labels.")
+	return fields
+}
+
+// SecretFields returns a set of fields that represent the secret for the
+// purposes of field selectors.
+func SecretFields(r corev3.Resource) map[string]string {
+	resource := r.(*Secret)
+	fields := map[string]string{
+		"secret.name":      resource.Metadata.Name,
+		"secret.namespace": resource.Metadata.Namespace,
+		"secret.provider":  resource.Provider,
+	}
+	mergeLabels(fields, resource.Metadata.Labels, "secret.labels.")
+	return fields
+}
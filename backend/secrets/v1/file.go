@@ -0,0 +1,116 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/providers"
+	"github.com/sensu/sensu-go/backend/secrets"
+)
+
+var _ secrets.Provider = new(File)
+
+// FileProviderKind is the URL path element of the File provider.
+const FileProviderKind = "file"
+
+// File is a secrets provider that reads secrets from the files of a
+// directory on the sensu-backend host. The secret ID is the name of the file,
+// relative to the directory. Trailing newlines are stripped from the secret.
+type File struct {
+	// Metadata contains the name, labels and annotations of the provider.
+	// Providers are global resources and have no namespace.
+	Metadata corev2.ObjectMeta `json:"metadata"`
+
+	// Path is the absolute path of the directory containing the secrets.
+	Path string `json:"path"`
+}
+
+// Get returns the content of the file named id. The file may be a symbolic
+// link, as long as it links to a file of the provider directory.
+func (f *File) Get(id string) (string, error) {
+	// secrets must be contained in the provider directory
+	if id == "" || filepath.IsAbs(id) || !isLocal(id) {
+		return "", secrets.ErrInvalidSecretInfo(id)
+	}
+	base, err := filepath.EvalSymlinks(f.Path)
+	if err != nil {
+		return "", secrets.ErrProviderNotAvailable(fmt.Sprintf("%s: %s", f.Metadata.Name, err))
+	}
+	path, err := filepath.EvalSymlinks(filepath.Join(base, id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", secrets.ErrSecretNotFound(id)
+		}
+		return "", err
+	}
+	if rel, err := filepath.Rel(base, path); err != nil || !isLocal(rel) {
+		return "", secrets.ErrInvalidSecretInfo(id)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// isLocal returns true if the relative path stays within its directory.
+func isLocal(path string) bool {
+	return filepath.Clean(path) == path && path != ".." && !strings.HasPrefix(path, ".."+string(filepath.Separator))
+}
+
+// GetMetadata returns the provider metadata.
+func (f *File) GetMetadata() *corev2.ObjectMeta {
+	return &f.Metadata
+}
+
+// SetMetadata sets the provider metadata.
+func (f *File) SetMetadata(meta *corev2.ObjectMeta) {
+	if meta == nil {
+		meta = &corev2.ObjectMeta{}
+	}
+	f.Metadata = *meta
+}
+
+// StoreName returns the store name of the provider.
+func (f *File) StoreName() string {
+	return "secrets/providers/file"
+}
+
+// RBACName returns the RBAC name of the provider.
+func (f *File) RBACName() string {
+	return ProvidersResource
+}
+
+// URIPath returns the path of the provider.
+func (f *File) URIPath() string {
+	return providers.Path(URLPrefix, ProvidersResource, FileProviderKind, url.PathEscape(f.Metadata.Name))
+}
+
+// GetTypeMeta returns the type metadata of the provider.
+func (f *File) GetTypeMeta() corev2.TypeMeta {
+	return providers.TypeMeta(APIVersion, "File")
+}
+
+// IsGlobalResource returns true, providers are not namespaced.
+func (f *File) IsGlobalResource() bool {
+	return true
+}
+
+// Validate returns an error if the provider is invalid.
+func (f *File) Validate() error {
+	if err := corev2.ValidateName(f.Metadata.Name); err != nil {
+		return errors.New("provider name " + err.Error())
+	}
+	if f.Metadata.Namespace != "" {
+		return errors.New("providers cannot be namespaced")
+	}
+	if !filepath.IsAbs(f.Path) {
+		return errors.New("provider path must be an absolute path")
+	}
+	return nil
+}
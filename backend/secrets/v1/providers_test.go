@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvGet(t *testing.T) {
+	t.Setenv("SENSU_TEST_SECRET", "hunter2")
+	env := &Env{Metadata: corev2.ObjectMeta{Name: "env"}}
+	require.NoError(t, env.Validate())
+
+	value, err := env.Get("SENSU_TEST_SECRET")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", value)
+
+	_, err = env.Get("SENSU_TEST_SECRET_MISSING")
+	assert.IsType(t, secrets.ErrSecretNotFound(""), err)
+}
+
+func TestFileGet(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "token"), []byte("hunter2\n"), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "nested"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nested", "token"), []byte("nested"), 0600))
+
+	file := &File{Metadata: corev2.ObjectMeta{Name: "file"}, Path: dir}
+	require.NoError(t, file.Validate())
+
+	value, err := file.Get("token")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", value)
+
+	value, err = file.Get("nested/token")
+	require.NoError(t, err)
+	assert.Equal(t, "nested", value)
+
+	_, err = file.Get("missing")
+	assert.IsType(t, secrets.ErrSecretNotFound(""), err)
+
+	for _, id := range []string{"", "../token", "..", "/etc/passwd", "nested/../../token"} {
+		_, err = file.Get(id)
+		assert.IsType(t, secrets.ErrInvalidSecretInfo(""), err, id)
+	}
+
+	// Links are followed within the provider directory only
+	outside := filepath.Join(t.TempDir(), "outside")
+	require.NoError(t, os.WriteFile(outside, []byte("outside"), 0600))
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "outside")))
+	require.NoError(t, os.Symlink(filepath.Dir(outside), filepath.Join(dir, "linkdir")))
+	require.NoError(t, os.Symlink("nested", filepath.Join(dir, "data")))
+	for _, id := range []string{"outside", "linkdir/outside"} {
+		_, err = file.Get(id)
+		assert.IsType(t, secrets.ErrInvalidSecretInfo(""), err, id)
+	}
+	value, err = file.Get("data/token")
+	require.NoError(t, err)
+	assert.Equal(t, "nested", value)
+
+	// The provider directory itself may be a link
+	link := filepath.Join(t.TempDir(), "secrets")
+	require.NoError(t, os.Symlink(dir, link))
+	value, err = (&File{Metadata: corev2.ObjectMeta{Name: "file"}, Path: link}).Get("token")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", value)
+
+	file.Path = filepath.Join(dir, "missing")
+	_, err = file.Get("token")
+	assert.IsType(t, secrets.ErrProviderNotAvailable(""), err)
+}
+
+func TestValidate(t *testing.T) {
+	assert.Error(t, (&Env{}).Validate())
+	assert.Error(t, (&Env{Metadata: corev2.ObjectMeta{Name: "env", Namespace: "default"}}).Validate())
+	assert.Error(t, (&File{Metadata: corev2.ObjectMeta{Name: "file"}, Path: "relative"}).Validate())
+	assert.Error(t, (&Secret{Metadata: corev2.ObjectMeta{Name: "secret", Namespace: "default"}, ID: "foo"}).Validate())
+	assert.NoError(t, (&Secret{Metadata: corev2.ObjectMeta{Name: "secret", Namespace: "default"}, ID: "foo", Provider: "env"}).Validate())
+}
+
+func TestURIPath(t *testing.T) {
+	assert.Equal(t, "/api/secrets/v1/providers/env/env", (&Env{Metadata: corev2.ObjectMeta{Name: "env"}}).URIPath())
+	assert.Equal(t, "/api/secrets/v1/providers/file", (&File{}).URIPath())
+	assert.Equal(t, "/api/secrets/v1/namespaces/default/secrets/foo", (&Secret{Metadata: corev2.ObjectMeta{Name: "foo", Namespace: "default"}}).URIPath())
+	assert.Equal(t, "/api/secrets/v1/secrets", (&Secret{}).URIPath())
+}
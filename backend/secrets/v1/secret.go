@@ -0,0 +1,79 @@
+package v1
+
+import (
+	"errors"
+	"net/url"
+	"path"
+
+	corev2 "github.com/sensu/core/v2"
+)
+
+// Secret associates a Sensu secret name, as referenced by the secrets of
+// checks, handlers and mutators, with a secret stored by a provider.
+type Secret struct {
+	// Metadata contains the name, namespace, labels and annotations of the
+	// secret.
+	Metadata corev2.ObjectMeta `json:"metadata"`
+
+	// ID is the identifier of the secret in the provider.
+	ID string `json:"id"`
+
+	// Provider is the name of the provider storing the secret.
+	Provider string `json:"provider"`
+}
+
+// GetMetadata returns the secret metadata.
+func (s *Secret) GetMetadata() *corev2.ObjectMeta {
+	return &s.Metadata
+}
+
+// SetMetadata sets the secret metadata.
+func (s *Secret) SetMetadata(meta *corev2.ObjectMeta) {
+	if meta == nil {
+		meta = &corev2.ObjectMeta{}
+	}
+	s.Metadata = *meta
+}
+
+// StoreName returns the store name of the secret.
+func (s *Secret) StoreName() string {
+	return "secrets/secrets"
+}
+
+// RBACName returns the RBAC name of the secret.
+func (s *Secret) RBACName() string {
+	return SecretsResource
+}
+
+// URIPath returns the path of the secret.
+func (s *Secret) URIPath() string {
+	if s.Metadata.Namespace == "" {
+		return path.Join(URLPrefix, SecretsResource, url.PathEscape(s.Metadata.Name))
+	}
+	return path.Join(URLPrefix, "namespaces", url.PathEscape(s.Metadata.Namespace), SecretsResource, url.PathEscape(s.Metadata.Name))
+}
+
+// GetTypeMeta returns the type metadata of the secret.
+func (s *Secret) GetTypeMeta() corev2.TypeMeta {
+	return corev2.TypeMeta{
+		Type:       "Secret",
+		APIVersion: APIVersion,
+	}
+}
+
+// Validate returns an error if the secret is invalid.
+func (s *Secret) Validate() error {
+	if err := corev2.ValidateName(s.Metadata.Name); err != nil {
+		return errors.New("secret name " + err.Error())
+	}
+	if s.Metadata.Namespace == "" {
+		return errors.New("secret namespace must be set")
+	}
+	if s.ID == "" {
+		return errors.New("secret id must be set")
+	}
+	if s.Provider == "" {
+		return errors.New("secret provider must be set")
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+// Package v1 contains the secrets/v1 API types: the Env and File secrets
+// providers, and the Secret resource which maps a Sensu secret name to a
+// provider and a secret ID.
+package v1
+
+import (
+	apitools "github.com/sensu/sensu-api-tools"
+)
+
+const (
+	// APIVersion is the API version of the types in this package.
+	APIVersion = "secrets/v1"
+
+	// URLPrefix is the URL prefix of the secrets/v1 API.
+	URLPrefix = "/api/secrets/v1"
+
+	// ProvidersResource is the RBAC name of the secrets providers.
+	ProvidersResource = "providers"
+
+	// SecretsResource is the RBAC name of the secrets.
+	SecretsResource = "secrets"
+)
+
+func init() {
+	apitools.RegisterType(APIVersion, new(Env))
+	apitools.RegisterType(APIVersion, new(File))
+	apitools.RegisterType(APIVersion, new(Secret))
+}
+
+func mergeLabels(fields map[string]string, labels map[string]string, prefix string) {
+	for k, v := range labels {
+		fields[prefix+k] = v
+	}
+}
@@ -60,6 +60,34 @@ const (
 
 	// matchesToken represents matches
 	matchesToken
+
+	// doublePipeToken represents ||
+	doublePipeToken
+
+	// leftParenToken represents (
+	leftParenToken
+
+	// rightParenToken represents )
+	rightParenToken
+
+	// lessThanToken represents <
+	lessThanToken
+
+	// lessThanOrEqualToken represents <=
+	lessThanOrEqualToken
+
+	// greaterThanToken represents >
+	greaterThanToken
+
+	// greaterThanOrEqualToken represents >=
+	greaterThanOrEqualToken
+
+	// existsToken represents exists
+	existsToken
+
+	// numberToken represents a decimal number, e.g. -1.5 or 1e3, that matches
+	// NumberPattern
+	numberToken
 )
 
 var reservedWords = map[string]Token{
@@ -68,6 +96,7 @@ var reservedWords = map[string]Token{
 	"true":    Token{Type: boolToken, Value: "true"},
 	"false":   Token{Type: boolToken, Value: "false"},
 	"matches": Token{Type: matchesToken, Value: "matches"},
+	"exists":  Token{Type: existsToken, Value: "exists"},
 }
 
 func newLexer(input string) *lexer {
@@ -88,6 +117,29 @@ func identTail(r rune) bool {
 	return r == '_' || r == '.' || r == '/' || unicode.IsDigit(r) || unicode.IsLetter(r)
 }
 
+// isNumberRune returns whether r can be part of a number.
+func isNumberRune(r rune) bool {
+	return unicode.IsDigit(r) || r == '.' || r == 'e' || r == 'E' || r == '+' || r == '-'
+}
+
+// isDelimiter returns whether r ends the identifier or number before it.
+func isDelimiter(r rune) bool {
+	switch r {
+	case '[', ']', '(', ')', '!', '&', '|', '=', '<', '>', ',', '"', '\'':
+		return true
+	}
+	return false
+}
+
+// identifier returns the token of an identifier, which can be a reserved word.
+func identifier(buf []rune) Token {
+	value := string(buf)
+	if tok, ok := reservedWords[strings.ToLower(value)]; ok {
+		return tok
+	}
+	return Token{Type: identifierToken, Value: value}
+}
+
 // Tokenize returns the next token found in the input stream
 func (l *lexer) Tokenize() Token {
 	// Yep, it's a state machine. 1-rune lookahead.
@@ -106,7 +158,7 @@ func (l *lexer) Tokenize() Token {
 					return Token{Type: errorToken, Value: fmt.Sprintf("end of input while scanning identifier: %q", string(buf))}
 				}
 				return Token{Type: endOfStringToken}
-			case identifierToken:
+			case identifierToken, numberToken, lessThanToken, greaterThanToken:
 			default:
 				return Token{Type: errorToken}
 			}
@@ -121,6 +173,19 @@ func (l *lexer) Tokenize() Token {
 				return Token{Type: leftSquareToken, Value: "["}
 			case ']':
 				return Token{Type: rightSquareToken, Value: "]"}
+			case '(':
+				return Token{Type: leftParenToken, Value: "("}
+			case ')':
+				return Token{Type: rightParenToken, Value: ")"}
+			case '<':
+				state = lessThanToken
+				buf = append(buf, r)
+			case '>':
+				state = greaterThanToken
+				buf = append(buf, r)
+			case '|':
+				state = doublePipeToken
+				buf = append(buf, r)
 			case '=':
 				state = doubleEqualSignToken
 				buf = append(buf, r)
@@ -135,6 +200,11 @@ func (l *lexer) Tokenize() Token {
 			case '"', '\'':
 				state = stringToken
 			default:
+				if len(buf) == 0 && (r == '-' || r == '+' || unicode.IsDigit(r)) {
+					state = numberToken
+					buf = append(buf, r)
+					continue
+				}
 				if !identStart(r) {
 					if len(buf) > 0 {
 						return Token{Type: errorToken, Value: fmt.Sprintf("invalid identifier: %q", string(append(buf, r)))}
@@ -152,31 +222,49 @@ func (l *lexer) Tokenize() Token {
 				errmsg := fmt.Sprintf("at %d, looking for %q but got %q", l.position, "=", string(append(buf, r)))
 				return Token{Type: errorToken, Value: errmsg}
 			}
-		case doubleAmpersandToken:
+		case doubleAmpersandToken, doublePipeToken:
 			switch r {
-			case '&':
+			case buf[0]:
 				return Token{Type: state, Value: string(append(buf, r))}
 			default:
-				errmsg := fmt.Sprintf("at %d, looking for %q but got %q", l.position, "&", string(append(buf, r)))
+				errmsg := fmt.Sprintf("at %d, looking for %q but got %q", l.position, string(buf[0]), string(append(buf, r)))
 				return Token{Type: errorToken, Value: errmsg}
 			}
+		case lessThanToken, greaterThanToken:
+			if r == '=' && err != io.EOF {
+				if state == lessThanToken {
+					return Token{Type: lessThanOrEqualToken, Value: "<="}
+				}
+				return Token{Type: greaterThanOrEqualToken, Value: ">="}
+			}
+			_ = l.input.UnreadRune()
+			return Token{Type: state, Value: string(buf)}
+		case numberToken:
+			if unicode.IsSpace(r) || err == io.EOF || isDelimiter(r) {
+				if err == nil && !unicode.IsSpace(r) {
+					_ = l.input.UnreadRune()
+				}
+				if !numberRegexp.MatchString(string(buf)) {
+					return Token{Type: errorToken, Value: fmt.Sprintf("invalid number: %q", string(buf))}
+				}
+				return Token{Type: state, Value: string(buf)}
+			}
+			if !isNumberRune(r) {
+				return Token{Type: errorToken, Value: fmt.Sprintf("invalid number: %q", string(append(buf, r)))}
+			}
+			buf = append(buf, r)
 		case identifierToken:
 			if unicode.IsSpace(r) || err == io.EOF {
 				if buf[len(buf)-1] == '.' {
 					return Token{Type: errorToken, Value: fmt.Sprintf("invalid identifier: %q", string(buf))}
 				}
-				buf := string(buf)
-				lbuf := strings.ToLower(buf)
-				if tok, ok := reservedWords[lbuf]; ok {
-					return tok
-				}
-				return Token{Type: state, Value: buf}
+				return identifier(buf)
 			}
-			switch r {
-			case '[', ']', '!', '&', ',', '"', '\'':
+			if isDelimiter(r) {
 				_ = l.input.UnreadRune()
-				return Token{Type: state, Value: string(buf)}
-			case '.':
+				return identifier(buf)
+			}
+			if r == '.' {
 				state = start
 			}
 			if !identTail(r) {
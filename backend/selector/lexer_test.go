@@ -207,6 +207,61 @@ func TestLexerTokenize(t *testing.T) {
 			input: "matches",
 			want:  Token{Type: matchesToken, Value: "matches"},
 		},
+		{
+			name:  "exists operator",
+			input: "exists",
+			want:  Token{Type: existsToken, Value: "exists"},
+		},
+		{
+			name:  "or operator",
+			input: "|| foo",
+			want:  Token{Type: doublePipeToken, Value: "||"},
+		},
+		{
+			name:  "left parenthesis",
+			input: "(foo)",
+			want:  Token{Type: leftParenToken, Value: "("},
+		},
+		{
+			name:  "less than operator",
+			input: "< 1",
+			want:  Token{Type: lessThanToken, Value: "<"},
+		},
+		{
+			name:  "less than or equal operator",
+			input: "<=1",
+			want:  Token{Type: lessThanOrEqualToken, Value: "<="},
+		},
+		{
+			name:  "greater than operator at end of input",
+			input: ">",
+			want:  Token{Type: greaterThanToken, Value: ">"},
+		},
+		{
+			name:  "greater than or equal operator",
+			input: ">= 1",
+			want:  Token{Type: greaterThanOrEqualToken, Value: ">="},
+		},
+		{
+			name:  "number",
+			input: "-12.5)",
+			want:  Token{Type: numberToken, Value: "-12.5"},
+		},
+		{
+			name:  "number with a sign and an exponent",
+			input: "+1.5e-3 ",
+			want:  Token{Type: numberToken, Value: "+1.5e-3"},
+		},
+		{
+			name:  "invalid number",
+			input: "1.2.3",
+			want:  Token{Type: errorToken, Value: `invalid number: "1.2.3"`},
+		},
+		{
+			name:  "reserved word before a delimiter",
+			input: "exists)",
+			want:  Token{Type: existsToken, Value: "exists"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -272,7 +327,7 @@ func TestLexIdentifiers(t *testing.T) {
 		{
 			name:  "bad identifier 2",
 			input: "0asdf",
-			want:  Token{Type: errorToken, Value: `invalid rune: "0"`},
+			want:  Token{Type: errorToken, Value: `invalid number: "0a"`},
 		},
 		{
 			name:  "bad identifier 3",
@@ -15,6 +15,16 @@ const (
 	NotInOperator Operator = "notin"
 	// MatchesOperator represents matches
 	MatchesOperator Operator = "matches"
+	// LessThanOperator represents <
+	LessThanOperator Operator = "<"
+	// LessThanOrEqualOperator represents <=
+	LessThanOrEqualOperator Operator = "<="
+	// GreaterThanOperator represents >
+	GreaterThanOperator Operator = ">"
+	// GreaterThanOrEqualOperator represents >=
+	GreaterThanOrEqualOperator Operator = ">="
+	// ExistsOperator represents exists
+	ExistsOperator Operator = "exists"
 )
 
 type OperationType int
@@ -32,7 +42,7 @@ type parser struct {
 }
 
 // Operation represents a computation, operation, on an LValue and a set of
-// RValues.
+// RValues
 type Operation struct {
 	LValue        string
 	Operator      Operator
@@ -46,13 +56,11 @@ func Parse(input string) (*Selector, error) {
 
 	parser.tokenize()
 
-	operations, err := parser.operations()
+	selector, err := parser.selector()
 	if err != nil {
 		return nil, err
 	}
 
-	selector := &Selector{Operations: operations}
-
 	return selector, nil
 }
 
@@ -62,9 +70,7 @@ func ParseFieldSelector(input string) (*Selector, error) {
 	if err != nil {
 		return nil, err
 	}
-	for i := range sel.Operations {
-		sel.Operations[i].OperationType = OperationTypeFieldSelector
-	}
+	setOperationType(sel, OperationTypeFieldSelector)
 	return sel, nil
 }
 
@@ -74,10 +80,21 @@ func ParseLabelSelector(input string) (*Selector, error) {
 	if err != nil {
 		return nil, err
 	}
+	setOperationType(sel, OperationTypeLabelSelector)
+	return sel, nil
+}
+
+// setOperationType sets the type of the operations of the selector, and of
+// the selectors of its disjunctions.
+func setOperationType(sel *Selector, typ OperationType) {
 	for i := range sel.Operations {
-		sel.Operations[i].OperationType = OperationTypeLabelSelector
+		sel.Operations[i].OperationType = typ
+	}
+	for _, disjunction := range sel.Disjunctions {
+		for _, alternative := range disjunction {
+			setOperationType(alternative, typ)
+		}
 	}
-	return sel, nil
 }
 
 // backtrack returns the position to its original place before the last read
@@ -103,6 +120,16 @@ func (p *parser) parseOperator() (Operator, error) {
 		return NotInOperator, nil
 	case matchesToken:
 		return MatchesOperator, nil
+	case lessThanToken:
+		return LessThanOperator, nil
+	case lessThanOrEqualToken:
+		return LessThanOrEqualOperator, nil
+	case greaterThanToken:
+		return GreaterThanOperator, nil
+	case greaterThanOrEqualToken:
+		return GreaterThanOrEqualOperator, nil
+	case existsToken:
+		return ExistsOperator, nil
 	default:
 		return "", fmt.Errorf("unexpected operator '%s' found", result.Value)
 	}
@@ -130,10 +157,12 @@ func (p *parser) parseOperation() (Operation, error) {
 		if err != nil {
 			return r, err
 		}
+	case ExistsOperator:
+		// exists has no value
 	default:
 		result := p.read()
 		switch result.Type {
-		case identifierToken, stringToken, boolToken, matchesToken:
+		case identifierToken, stringToken, boolToken, matchesToken, existsToken, numberToken:
 			r.RValues = []string{result.Value}
 		default:
 			return r, fmt.Errorf("unexpected token '%s': expected an identifier or literal value", result.Value)
@@ -144,26 +173,32 @@ func (p *parser) parseOperation() (Operation, error) {
 }
 
 // parseValues parses values found in an array used by the 'in' & 'notin'
-// operators, e.g. (a,b,c)
+// operators, e.g. [a,b,c] or (a,b,c)
 func (p *parser) parseValues() ([]string, error) {
 	var values []string
-	// The first token should be '[' or a selector
+	// The first token should be '[', '(' or a selector
 	result := p.read()
 	if result.Type == identifierToken {
 		return []string{result.Value}, nil
 	}
-	if result.Type != leftSquareToken {
-		return values, fmt.Errorf("found '%s', expected '['", result.Value)
+	var end TokenT
+	switch result.Type {
+	case leftSquareToken:
+		end = rightSquareToken
+	case leftParenToken:
+		end = rightParenToken
+	default:
+		return values, fmt.Errorf("found '%s', expected '[' or '('", result.Value)
 	}
 
 	for {
 		result = p.read()
 		switch result.Type {
-		case identifierToken, stringToken:
+		case identifierToken, stringToken, numberToken:
 			values = append(values, result.Value)
 		case commaToken:
 			continue
-		case rightSquareToken:
+		case end:
 			return values, nil
 		default:
 			return values, fmt.Errorf("unexpected token '%s', expected a comma or an identifier", result.Value)
@@ -185,10 +220,48 @@ func (p *parser) read() Token {
 	return p.results[p.position-1]
 }
 
-// operations analyzes the results and determines the list of operations
-func (p *parser) operations() ([]Operation, error) {
-	var operations []Operation
+// selector analyzes the results and determines the selector
+func (p *parser) selector() (*Selector, error) {
+	if p.peek().Type == endOfStringToken {
+		return &Selector{}, nil
+	}
+	selector, err := p.disjunction()
+	if err != nil {
+		return nil, err
+	}
+	result := p.peek()
+	if result.Type != endOfStringToken {
+		return nil, fmt.Errorf("unexpected token '%s', expected '&&', '||' or end of string", result.Value)
+	}
+	return selector, nil
+}
 
+// disjunction parses selectors separated by the '||' operator. As '&&' binds
+// tighter than '||', each of them is a conjunction.
+func (p *parser) disjunction() (*Selector, error) {
+	var alternatives []*Selector
+	for {
+		selector, err := p.conjunction()
+		if err != nil {
+			return nil, err
+		}
+		alternatives = append(alternatives, selector)
+		if p.peek().Type != doublePipeToken {
+			break
+		}
+		// Move the position forward
+		_ = p.read()
+	}
+	if len(alternatives) == 1 {
+		return alternatives[0], nil
+	}
+	return &Selector{Disjunctions: [][]*Selector{alternatives}}, nil
+}
+
+// conjunction parses operations, or groups of operations in parentheses,
+// separated by the '&&' operator.
+func (p *parser) conjunction() (*Selector, error) {
+	selector := &Selector{}
 	for {
 		result := p.peek()
 		switch result.Type {
@@ -198,31 +271,28 @@ func (p *parser) operations() ([]Operation, error) {
 				return nil, fmt.Errorf("could not parse the operations: %s", err)
 			}
 			// We found a valid operation, append it to our list of operations
-			operations = append(operations, operation)
-		case doubleAmpersandToken:
+			selector.Operations = append(selector.Operations, operation)
+		case leftParenToken:
 			// Move the position forward
 			_ = p.read()
-
-			// Make sure we already have a operation before accepting the '&&'
-			// operator
-			if len(operations) == 0 {
-				return operations, fmt.Errorf("unexpected '&&' operator found, expected a operation first")
+			group, err := p.disjunction()
+			if err != nil {
+				return nil, err
 			}
-
-			// Make sure the next token is an identifier or a string, which are
-			// the 2 types of tokens that can start a new expression.
-			result = p.peek()
-			if result.Type == identifierToken || result.Type == stringToken {
-				continue
-			} else {
-				return nil, fmt.Errorf("unexpected token '%s', expected an identifier or string after '&&'", result.Value)
+			if result := p.read(); result.Type != rightParenToken {
+				return nil, fmt.Errorf("unexpected token '%s', expected ')'", result.Value)
 			}
-		case endOfStringToken:
-			return operations, nil
+			selector.Operations = append(selector.Operations, group.Operations...)
+			selector.Disjunctions = append(selector.Disjunctions, group.Disjunctions...)
 		default:
-			return nil, fmt.Errorf("unexpected token '%s', expected an identifier or end of string", result.Value)
+			return nil, fmt.Errorf("unexpected token '%s', expected an identifier, a string or '('", result.Value)
 		}
 
+		if p.peek().Type != doubleAmpersandToken {
+			return selector, nil
+		}
+		// Move the position forward
+		_ = p.read()
 	}
 }
 
@@ -115,6 +115,94 @@ func TestParse(t *testing.T) {
 				{LValue: "my sub", Operator: InOperator, RValues: []string{"check.subscriptions"}},
 			}},
 		},
+		{
+			name:  "comparison operators",
+			input: "check.status > 0 && check.status <= 2 && load < -1.5 && load >= 10",
+			want: &Selector{Operations: []Operation{
+				{LValue: "check.status", Operator: GreaterThanOperator, RValues: []string{"0"}},
+				{LValue: "check.status", Operator: LessThanOrEqualOperator, RValues: []string{"2"}},
+				{LValue: "load", Operator: LessThanOperator, RValues: []string{"-1.5"}},
+				{LValue: "load", Operator: GreaterThanOrEqualOperator, RValues: []string{"10"}},
+			}},
+		},
+		{
+			name:  "comparison operators without spaces",
+			input: "check.status>0&&check.status<=2",
+			want: &Selector{Operations: []Operation{
+				{LValue: "check.status", Operator: GreaterThanOperator, RValues: []string{"0"}},
+				{LValue: "check.status", Operator: LessThanOrEqualOperator, RValues: []string{"2"}},
+			}},
+		},
+		{
+			name:  "exists operator",
+			input: "labels.owner exists && foo == bar",
+			want: &Selector{Operations: []Operation{
+				{LValue: "labels.owner", Operator: ExistsOperator},
+				{LValue: "foo", Operator: DoubleEqualSignOperator, RValues: []string{"bar"}},
+			}},
+		},
+		{
+			name:  "in parentheses",
+			input: "foo in (foo, bar)",
+			want: &Selector{Operations: []Operation{
+				{LValue: "foo", Operator: InOperator, RValues: []string{"foo", "bar"}},
+			}},
+		},
+		{
+			name:  "or operator",
+			input: "foo == bar || baz == qux && quux exists",
+			want: &Selector{Disjunctions: [][]*Selector{{
+				{Operations: []Operation{
+					{LValue: "foo", Operator: DoubleEqualSignOperator, RValues: []string{"bar"}},
+				}},
+				{Operations: []Operation{
+					{LValue: "baz", Operator: DoubleEqualSignOperator, RValues: []string{"qux"}},
+					{LValue: "quux", Operator: ExistsOperator},
+				}},
+			}}},
+		},
+		{
+			name:  "grouping",
+			input: "(foo == bar || baz == qux) && (quux exists)",
+			want: &Selector{
+				Operations: []Operation{
+					{LValue: "quux", Operator: ExistsOperator},
+				},
+				Disjunctions: [][]*Selector{{
+					{Operations: []Operation{
+						{LValue: "foo", Operator: DoubleEqualSignOperator, RValues: []string{"bar"}},
+					}},
+					{Operations: []Operation{
+						{LValue: "baz", Operator: DoubleEqualSignOperator, RValues: []string{"qux"}},
+					}},
+				}},
+			},
+		},
+		{
+			name:    "missing closing parenthesis",
+			input:   "(foo == bar || baz == qux",
+			wantErr: true,
+		},
+		{
+			name:    "empty group",
+			input:   "foo == bar && ()",
+			wantErr: true,
+		},
+		{
+			name:    "missing operation after '||'",
+			input:   "foo == bar ||",
+			wantErr: true,
+		},
+		{
+			name:    "single pipe",
+			input:   "foo == bar | baz == qux",
+			wantErr: true,
+		},
+		{
+			name:    "comparison without value",
+			input:   "foo >",
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -197,3 +285,27 @@ func TestParserParseValues(t *testing.T) {
 		})
 	}
 }
+
+func TestParseLabelSelectorSetsNestedOperationTypes(t *testing.T) {
+	sel, err := ParseLabelSelector("region == west && (tier == web || tier == api)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var check func(*Selector)
+	check = func(sel *Selector) {
+		for _, op := range sel.Operations {
+			if op.OperationType != OperationTypeLabelSelector {
+				t.Errorf("operation %v is not a label selector", op)
+			}
+		}
+		for _, disjunction := range sel.Disjunctions {
+			for _, s := range disjunction {
+				check(s)
+			}
+		}
+	}
+	check(sel)
+	if len(sel.Disjunctions) != 1 {
+		t.Errorf("expected 1 disjunction, got %d", len(sel.Disjunctions))
+	}
+}
@@ -1,17 +1,41 @@
 package selector
 
 import (
+	"errors"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
+// NumberPattern is the pattern of the values that are compared as numbers by
+// the <, <=, > and >= operators.
+const NumberPattern = `^[-+]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][-+]?[0-9]{1,3})?$`
+
+var numberRegexp = regexp.MustCompile(NumberPattern)
+
+// ParseNumber parses the value as a number, if it matches NumberPattern.
+// Numbers out of the range of a float64 are parsed as infinities.
+func ParseNumber(value string) (float64, bool) {
+	if !numberRegexp.MatchString(value) {
+		return 0, false
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return 0, false
+	}
+	return number, true
+}
+
 // Selector represents a field or label selector that declares one or more
-// operations.
+// operations, and disjunctions of selectors separated by the || operator.
 type Selector struct {
-	Operations []Operation
+	Operations   []Operation
+	Disjunctions [][]*Selector
 }
 
 // Matches returns the logical intersection of the evaluations of each of the
-// operations in s.
+// operations and disjunctions in s. A disjunction matches if any of its
+// selectors matches.
 func (s *Selector) Matches(set map[string]string) bool {
 	for i := range s.Operations {
 		if matches := matches(s.Operations[i], set); !matches {
@@ -19,9 +43,30 @@ func (s *Selector) Matches(set map[string]string) bool {
 		}
 	}
 
+	for _, disjunction := range s.Disjunctions {
+		if !matchesAny(disjunction, set) {
+			return false
+		}
+	}
+
 	return true
 }
 
+// IsEmpty returns true if the selector has no operation nor disjunction.
+func (s *Selector) IsEmpty() bool {
+	return len(s.Operations) == 0 && len(s.Disjunctions) == 0
+}
+
+// matchesAny determines if any of the selectors matches the given set
+func matchesAny(selectors []*Selector, set map[string]string) bool {
+	for _, selector := range selectors {
+		if selector.Matches(set) {
+			return true
+		}
+	}
+	return false
+}
+
 // match determines if an operation matches the given set
 func matches(r Operation, set map[string]string) bool {
 	switch r.Operator {
@@ -65,11 +110,43 @@ func matches(r Operation, set map[string]string) bool {
 		//  Make sure the set's value for the operation's l-value matches
 		//  the operation r-values
 		return matchesValue(set[r.LValue], r.RValues)
+	case LessThanOperator, LessThanOrEqualOperator, GreaterThanOperator, GreaterThanOrEqualOperator:
+		// Make sure the r-value set has the specified l-value
+		if !hasKey(set, r.LValue) || len(r.RValues) != 1 {
+			return false
+		}
+		return compare(set[r.LValue], r.Operator, r.RValues[0])
+	case ExistsOperator:
+		return hasKey(set, r.LValue)
 	default:
 		return false
 	}
 }
 
+// compare compares the set value to the operation value, as numbers. Values
+// that are not numbers do not match.
+func compare(value string, operator Operator, operand string) bool {
+	x, ok := ParseNumber(value)
+	if !ok {
+		return false
+	}
+	y, ok := ParseNumber(operand)
+	if !ok {
+		return false
+	}
+	switch operator {
+	case LessThanOperator:
+		return x < y
+	case LessThanOrEqualOperator:
+		return x <= y
+	case GreaterThanOperator:
+		return x > y
+	case GreaterThanOrEqualOperator:
+		return x >= y
+	}
+	return false
+}
+
 // hasKey determines if the given set has a key with the specified name
 func hasKey(set map[string]string, key string) bool {
 	_, ok := set[key]
@@ -129,6 +206,7 @@ func Merge(selectors ...*Selector) *Selector {
 			continue
 		}
 		selector.Operations = append(selector.Operations, s.Operations...)
+		selector.Disjunctions = append(selector.Disjunctions, s.Disjunctions...)
 	}
 	return &selector
 }
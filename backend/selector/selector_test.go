@@ -162,6 +162,114 @@ func TestSelector_Matches(t *testing.T) {
 			set:   nil,
 			want:  false,
 		},
+		{
+			name:  "greater than matches",
+			input: "check.status > 0",
+			set:   map[string]string{"check.status": "2"},
+			want:  true,
+		},
+		{
+			name:  "greater than doesn't match",
+			input: "check.status > 0",
+			set:   map[string]string{"check.status": "0"},
+			want:  false,
+		},
+		{
+			name:  "greater than or equal matches",
+			input: "check.status >= 2",
+			set:   map[string]string{"check.status": "2"},
+			want:  true,
+		},
+		{
+			name:  "less than with decimals matches",
+			input: "object.load < 1.5",
+			set:   map[string]string{"object.load": "0.75"},
+			want:  true,
+		},
+		{
+			name:  "less than or equal with a negative number doesn't match",
+			input: "object.offset <= -1",
+			set:   map[string]string{"object.offset": "0"},
+			want:  false,
+		},
+		{
+			name:  "greater than with an exponent matches",
+			input: "object.size > 1e3",
+			set:   map[string]string{"object.size": "1500"},
+			want:  true,
+		},
+		{
+			name:  "less than with a signed number and a leading dot matches",
+			input: "object.load < +1",
+			set:   map[string]string{"object.load": ".5"},
+			want:  true,
+		},
+		{
+			name:  "comparison with an infinity doesn't match",
+			input: "object.load < 1",
+			set:   map[string]string{"object.load": "-Inf"},
+			want:  false,
+		},
+		{
+			name:  "comparison with a hexadecimal number doesn't match",
+			input: "object.load > 1",
+			set:   map[string]string{"object.load": "0x10"},
+			want:  false,
+		},
+		{
+			name:  "comparison with a value that is not a number doesn't match",
+			input: "object.name > 1",
+			set:   map[string]string{"object.name": "foo"},
+			want:  false,
+		},
+		{
+			name:  "comparison with a missing key doesn't match",
+			input: "check.status > 0",
+			set:   map[string]string{},
+			want:  false,
+		},
+		{
+			name:  "exists matches",
+			input: "labels.owner exists",
+			set:   map[string]string{"labels.owner": ""},
+			want:  true,
+		},
+		{
+			name:  "exists doesn't match",
+			input: "labels.owner exists",
+			set:   map[string]string{"labels.team": "ops"},
+			want:  false,
+		},
+		{
+			name:  "or matches the second alternative",
+			input: "entity.labels.tier in (web) || entity.labels.tier in (api)",
+			set:   map[string]string{"entity.labels.tier": "api"},
+			want:  true,
+		},
+		{
+			name:  "or doesn't match",
+			input: "entity.labels.tier in (web) || entity.labels.tier in (api)",
+			set:   map[string]string{"entity.labels.tier": "db"},
+			want:  false,
+		},
+		{
+			name:  "and binds tighter than or",
+			input: "object.name == foo || object.name == bar && object.namespace == acme",
+			set:   map[string]string{"object.name": "foo", "object.namespace": "dev"},
+			want:  true,
+		},
+		{
+			name:  "parentheses group operations",
+			input: "(object.name == foo || object.name == bar) && object.namespace == acme",
+			set:   map[string]string{"object.name": "foo", "object.namespace": "dev"},
+			want:  false,
+		},
+		{
+			name:  "nested parentheses",
+			input: "check.status > 0 && (labels.owner exists || (object.name == foo && object.namespace == acme))",
+			set:   map[string]string{"check.status": "1", "object.name": "foo", "object.namespace": "acme"},
+			want:  true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -176,3 +284,34 @@ func TestSelector_Matches(t *testing.T) {
 		})
 	}
 }
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   float64
+		wantOK bool
+	}{
+		{value: "42", want: 42, wantOK: true},
+		{value: "-1.5", want: -1.5, wantOK: true},
+		{value: "+1", want: 1, wantOK: true},
+		{value: ".5", want: 0.5, wantOK: true},
+		{value: "5.", want: 5, wantOK: true},
+		{value: "1e3", want: 1000, wantOK: true},
+		{value: "2.5E-2", want: 0.025, wantOK: true},
+		{value: "1e1000"},
+		{value: "Inf"},
+		{value: "NaN"},
+		{value: "0x10"},
+		{value: "1_000"},
+		{value: "."},
+		{value: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := ParseNumber(tt.value)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("ParseNumber() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -21,6 +21,8 @@ func (o OperatorType) String() string {
 		return "backend"
 	case 3:
 		return "check"
+	case 4:
+		return "aggregate"
 	}
 	return "null"
 }
@@ -37,6 +39,9 @@ const (
 
 	// CheckOperator is the operator type for check TTLs functionality.
 	CheckOperator OperatorType = 3
+
+	// AggregateOperator is the operator type for the evaluation of aggregates.
+	AggregateOperator OperatorType = 4
 )
 
 // OperatorKey holds the key fields of an operator, for identifying a unique
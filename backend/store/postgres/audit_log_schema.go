@@ -0,0 +1,49 @@
+package postgres
+
+// Migration 30
+//
+// The namespace of the records is not a reference to the namespaces table, as
+// the records must outlive the namespaces.
+const auditLogSchema = `
+CREATE TABLE IF NOT EXISTS audit_log (
+	id                  bigserial     PRIMARY KEY,
+	record_time         timestamptz   NOT NULL,
+	username            text          NOT NULL,
+	groups              text[]        NOT NULL,
+	source_ip           text          NOT NULL,
+	namespace           text          NOT NULL,
+	api_version         text          NOT NULL,
+	resource            text          NOT NULL,
+	name                text          NOT NULL,
+	verb                text          NOT NULL,
+	request             jsonb,
+	outcome             text          NOT NULL,
+	status              integer       NOT NULL
+);
+CREATE INDEX ON audit_log ( record_time DESC, id DESC );
+CREATE INDEX ON audit_log ( username, record_time DESC );
+`
+
+const addAuditRecordQuery = `
+INSERT INTO audit_log ( record_time, username, groups, source_ip, namespace, api_version, resource, name, verb, request, outcome, status )
+VALUES ( $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12 );
+`
+
+// getAuditRecordsQuery selects the audit records, most recent first. The
+// namespace ($1), user ($2), resource ($3) and verb ($4) filters, the time
+// range ($5, $6), the position of the last record of the previous page ($7,
+// $8) and the limit ($9) are optional.
+const getAuditRecordsQuery = `
+SELECT id, record_time, username, groups, source_ip, namespace, api_version, resource, name, verb, request, outcome, status
+FROM audit_log
+WHERE
+	( $1::text IS NULL OR namespace = $1 ) AND
+	( $2::text IS NULL OR username = $2 ) AND
+	( $3::text IS NULL OR resource = $3 ) AND
+	( $4::text IS NULL OR verb = $4 ) AND
+	( $5::timestamptz IS NULL OR record_time >= $5 ) AND
+	( $6::timestamptz IS NULL OR record_time < $6 ) AND
+	( $7::timestamptz IS NULL OR ( record_time, id ) < ( $7, $8::bigint ) )
+ORDER BY record_time DESC, id DESC
+LIMIT $9;
+`
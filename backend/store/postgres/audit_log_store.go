@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sensu/sensu-go/backend/audit"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+var (
+	_ audit.Store = &AuditLogStore{}
+)
+
+// AuditLogStore records audit records in the append-only audit_log table, and
+// retrieves them.
+type AuditLogStore struct {
+	db DBI
+}
+
+// NewAuditLogStore creates a new AuditLogStore.
+func NewAuditLogStore(db DBI) *AuditLogStore {
+	return &AuditLogStore{db: db}
+}
+
+// AddRecord adds the record to the audit log.
+func (s *AuditLogStore) AddRecord(ctx context.Context, record *audit.Record) error {
+	groups := record.Groups
+	if groups == nil {
+		groups = []string{}
+	}
+	var request any
+	if len(record.Request) > 0 {
+		request = []byte(record.Request)
+	}
+	_, err := s.db.Exec(ctx, addAuditRecordQuery,
+		record.Time, record.User, groups, record.SourceIP, record.Namespace, record.APIVersion,
+		record.Resource, record.Name, record.Verb, request, record.Outcome, record.Status)
+	if err != nil {
+		return &store.ErrInternal{Message: fmt.Sprintf("couldn't add audit record: %s", err)}
+	}
+	return nil
+}
+
+type auditLogToken struct {
+	Time time.Time `json:"time"`
+	ID   int64     `json:"id"`
+}
+
+// GetRecords returns the records selected by the predicate, most recent
+// first.
+func (s *AuditLogStore) GetRecords(ctx context.Context, pred *audit.Predicate) ([]*audit.Record, error) {
+	if pred == nil {
+		pred = &audit.Predicate{}
+	}
+
+	var namespace, user, resource, verb sql.NullString
+	var start, end, after sql.NullTime
+	var afterID, limit sql.NullInt64
+	for _, filter := range []struct {
+		value string
+		param *sql.NullString
+	}{
+		{pred.Namespace, &namespace},
+		{pred.User, &user},
+		{pred.Resource, &resource},
+		{pred.Verb, &verb},
+	} {
+		if filter.value != "" {
+			filter.param.String, filter.param.Valid = filter.value, true
+		}
+	}
+	if !pred.Start.IsZero() {
+		start.Time, start.Valid = pred.Start, true
+	}
+	if !pred.End.IsZero() {
+		end.Time, end.Valid = pred.End, true
+	}
+	if pred.Continue != "" {
+		var token auditLogToken
+		if err := json.Unmarshal([]byte(pred.Continue), &token); err != nil {
+			return nil, &store.ErrNotValid{Err: fmt.Errorf("couldn't get audit records: error decoding token: %s", err)}
+		}
+		after.Time, after.Valid = token.Time, true
+		afterID.Int64, afterID.Valid = token.ID, true
+	}
+	if pred.Limit > 0 {
+		// fetch one more record to know if there is another page
+		limit.Int64, limit.Valid = pred.Limit+1, true
+	}
+
+	rows, err := s.db.Query(ctx, getAuditRecordsQuery, namespace, user, resource, verb, start, end, after, afterID, limit)
+	if err != nil {
+		return nil, &store.ErrInternal{Message: fmt.Sprintf("couldn't get audit records: %s", err)}
+	}
+	defer rows.Close()
+
+	records := []*audit.Record{}
+	var last auditLogToken
+	pred.Continue = ""
+	for rows.Next() {
+		if pred.Limit > 0 && int64(len(records)) == pred.Limit {
+			b, _ := json.Marshal(last)
+			pred.Continue = string(b)
+			break
+		}
+		var record audit.Record
+		var request []byte
+		err := rows.Scan(&last.ID, &last.Time, &record.User, &record.Groups, &record.SourceIP,
+			&record.Namespace, &record.APIVersion, &record.Resource, &record.Name, &record.Verb,
+			&request, &record.Outcome, &record.Status)
+		if err != nil {
+			return nil, &store.ErrNotValid{Err: fmt.Errorf("error reading audit records: %s", err)}
+		}
+		record.Time = last.Time
+		if request != nil {
+			record.Request = json.RawMessage(request)
+		}
+		records = append(records, &record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &store.ErrInternal{Message: fmt.Sprintf("error reading audit records: %s", err)}
+	}
+	return records, nil
+}
@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sensu/sensu-go/backend/audit"
+)
+
+func testWithAuditLogStore(t *testing.T, fn func(context.Context, *AuditLogStore)) {
+	t.Helper()
+	withPostgres(t, func(ctx context.Context, db *pgxpool.Pool, dsn string) {
+		fn(ctx, NewAuditLogStore(db))
+	})
+}
+
+func addAuditRecords(t *testing.T, ctx context.Context, s *AuditLogStore, records ...*audit.Record) {
+	t.Helper()
+	for _, record := range records {
+		if err := s.AddRecord(ctx, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAuditLogStoreFilters(t *testing.T) {
+	testWithAuditLogStore(t, func(ctx context.Context, s *AuditLogStore) {
+		addAuditRecords(t, ctx, s,
+			&audit.Record{Time: time.Unix(100, 0), User: "alice", Groups: []string{"ops"}, Namespace: "default", Resource: "checks", Name: "a", Verb: "create", Request: json.RawMessage(`{"interval":10}`), Outcome: audit.OutcomeSuccess, Status: 201},
+			&audit.Record{Time: time.Unix(200, 0), User: "bob", Namespace: "default", Resource: "checks", Name: "a", Verb: "delete", Outcome: audit.OutcomeSuccess, Status: 204},
+			&audit.Record{Time: time.Unix(300, 0), User: "alice", Resource: "namespaces", Name: "dev", Verb: "create", Outcome: audit.OutcomeFailure, Status: 403},
+		)
+
+		records, err := s.GetRecords(ctx, &audit.Predicate{User: "alice"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(records), 2; got != want {
+			t.Fatalf("bad number of records: got %d, want %d", got, want)
+		}
+		if got, want := records[0].Resource, "namespaces"; got != want {
+			t.Errorf("bad first record: got %q, want %q", got, want)
+		}
+		if records[0].Request != nil {
+			t.Errorf("expected no request, got %s", records[0].Request)
+		}
+		if got, want := records[1].Groups, []string{"ops"}; len(got) != 1 || got[0] != want[0] {
+			t.Errorf("bad groups: got %v, want %v", got, want)
+		}
+
+		records, err = s.GetRecords(ctx, &audit.Predicate{Namespace: "default", Verb: "delete"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(records), 1; got != want {
+			t.Fatalf("bad number of records: got %d, want %d", got, want)
+		}
+		if records[0].Request != nil {
+			t.Errorf("expected no request, got %s", records[0].Request)
+		}
+
+		records, err = s.GetRecords(ctx, &audit.Predicate{Start: time.Unix(150, 0), End: time.Unix(300, 0)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(records), 1; got != want {
+			t.Fatalf("bad number of records: got %d, want %d", got, want)
+		}
+	})
+}
+
+func TestAuditLogStorePagination(t *testing.T) {
+	testWithAuditLogStore(t, func(ctx context.Context, s *AuditLogStore) {
+		for _, ts := range []int64{100, 200, 300} {
+			addAuditRecords(t, ctx, s, &audit.Record{Time: time.Unix(ts, 0), User: "alice", Resource: "checks", Verb: "update"})
+		}
+
+		pred := &audit.Predicate{Limit: 2}
+		records, err := s.GetRecords(ctx, pred)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(records), 2; got != want {
+			t.Fatalf("bad number of records: got %d, want %d", got, want)
+		}
+		if pred.Continue == "" {
+			t.Fatal("expected a continue token")
+		}
+
+		records, err = s.GetRecords(ctx, pred)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(records), 1; got != want {
+			t.Fatalf("bad number of records: got %d, want %d", got, want)
+		}
+		if got, want := records[0].Time.Unix(), int64(100); got != want {
+			t.Errorf("bad last record: got %d, want %d", got, want)
+		}
+		if pred.Continue != "" {
+			t.Errorf("expected no continue token, got %q", pred.Continue)
+		}
+	})
+}
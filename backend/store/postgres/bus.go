@@ -6,13 +6,14 @@ import (
 	"encoding/base64"
 	"path"
 	"sync"
-	"time"
 
 	"github.com/lib/pq"
 )
 
 // Bus is a postgresql message bus. It demultiplexes asynchronous notifications
-// based on channel name to subscribers.
+// based on channel name to subscribers. The notifications are buffered per
+// subscriber, and the subscribers that fall further behind are dropped, so
+// that they can't hold the others up.
 //
 // There can only be one subscriber per unique (namespace, name) subscription.
 type Bus struct {
@@ -75,20 +76,29 @@ func (b *Bus) demux(notification *pq.Notification) {
 	if notification == nil {
 		return
 	}
-	channels := b.getNotificationChans(notification.Channel)
-	for _, ch := range channels {
+	set := b.getSubscriptionSet(notification.Channel)
+	if set == nil {
+		return
+	}
+	for _, ch := range set.Channels() {
 		select {
 		case ch <- notification:
-		case <-time.After(time.Minute):
-			logger.Error("postgres notification not handled after 1 minute!")
+		default:
+			// The subscriber fell behind. Its channel is closed so that it
+			// subscribes again, and catches up with the notifications it
+			// missed.
+			if set.Remove(ch) {
+				close(ch)
+				logger.WithField("channel", notification.Channel).Warn("postgres notification subscriber fell behind, dropping its subscription")
+			}
 		}
 	}
 }
 
-func (b *Bus) getNotificationChans(chanName string) []chan *pq.Notification {
+func (b *Bus) getSubscriptionSet(chanName string) *subscriptionSet {
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	return b.subscriptions[chanName].Channels()
+	return b.subscriptions[chanName]
 }
 
 func (b *Bus) newNotificationChan(ctx context.Context, namespace, name string) (chan *pq.Notification, error) {
@@ -102,7 +112,7 @@ func (b *Bus) newNotificationChan(ctx context.Context, namespace, name string) (
 		}
 		b.subscriptions[key] = &subscriptionSet{}
 	}
-	ch := make(chan *pq.Notification, 8)
+	ch := make(chan *pq.Notification, subscriptionBufferSize)
 	b.subscriptions[key].Append(subscription{Notification: ch, Ctx: ctx})
 	return ch, nil
 }
@@ -110,10 +120,18 @@ func (b *Bus) newNotificationChan(ctx context.Context, namespace, name string) (
 // Subscribe starts listening for notifications for the (namespace, name)
 // combination provided. Any number of goroutines can listen concurrently to a
 // given namespace and name.
+//
+// The notification channel is closed if the subscriber falls more than
+// subscriptionBufferSize notifications behind. The subscriber must then
+// subscribe again, and catch up with the notifications it missed.
 func (b *Bus) Subscribe(ctx context.Context, namespace, name string) (<-chan *pq.Notification, error) {
 	return b.newNotificationChan(ctx, namespace, name)
 }
 
+// subscriptionBufferSize is the number of notifications buffered for each
+// subscriber.
+const subscriptionBufferSize = 64
+
 type subscription struct {
 	Notification chan *pq.Notification
 	Ctx          context.Context
@@ -143,16 +161,19 @@ func (s *subscriptionSet) Channels() []chan *pq.Notification {
 
 func (s *subscriptionSet) removeWhenDone(sub subscription) {
 	<-sub.Ctx.Done()
+	s.Remove(sub.Notification)
+}
+
+// Remove removes the subscription of the channel, and returns whether it was
+// still subscribed.
+func (s *subscriptionSet) Remove(ch chan *pq.Notification) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	idx := -1
-	for i, ss := range s.subscriptions {
-		if sub == ss {
-			idx = i
-			break
+	for i, sub := range s.subscriptions {
+		if sub.Notification == ch {
+			s.subscriptions = append(s.subscriptions[:i], s.subscriptions[i+1:]...)
+			return true
 		}
 	}
-	if idx >= 0 {
-		s.subscriptions = append(s.subscriptions[:idx], s.subscriptions[idx+1:]...)
-	}
+	return false
 }
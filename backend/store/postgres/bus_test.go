@@ -120,3 +120,43 @@ func TestBusDemuxTimeout(t *testing.T) {
 	}
 
 }
+
+func TestBusDemuxDropsSlowSubscriber(t *testing.T) {
+	listener := new(mockListener)
+	listener.On("Listen", ListenChannelName("default", "foo")).Return(nil)
+	ch := make(chan *pq.Notification)
+	listener.On("NotificationChannel").Return(ch)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bus := NewBus(ctx, listener)
+	slow, err := bus.Subscribe(ctx, "default", "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fast, err := bus.Subscribe(ctx, "default", "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the slow subscriber doesn't hold the fast one up
+	for i := 0; i < subscriptionBufferSize+1; i++ {
+		select {
+		case ch <- &pq.Notification{Channel: ListenChannelName("default", "foo")}:
+		case <-time.After(3 * time.Second):
+			t.Fatal("demux blocked by the slow subscriber")
+		}
+		<-fast
+	}
+
+	// the slow subscriber receives the buffered notifications, then its
+	// channel is closed
+	for i := 0; i < subscriptionBufferSize; i++ {
+		if _, ok := <-slow; !ok {
+			t.Fatalf("channel closed after %d notifications", i)
+		}
+	}
+	if _, ok := <-slow; ok {
+		t.Fatal("expected the slow subscriber channel to be closed")
+	}
+}
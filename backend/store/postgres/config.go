@@ -1,7 +1,20 @@
 package postgres
 
+import "time"
+
 type Config struct {
 	DSN               string
 	MaxTPS            int
 	DisableEventCache bool
+
+	// EventHistory enables recording every event in the event history.
+	EventHistory bool
+
+	// EventHistoryMaxAge is the maximum age of the events in the event
+	// history. Zero disables pruning by age.
+	EventHistoryMaxAge time.Duration
+
+	// EventHistoryMaxEvents is the maximum number of events kept in the event
+	// history for each entity and check pair. Zero disables pruning by count.
+	EventHistoryMaxEvents int
 }
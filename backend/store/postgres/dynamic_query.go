@@ -116,7 +116,7 @@ func getTemplateData(namespace, entity, check string, s *selector.Selector, pred
 		data.CheckCond = fmt.Sprintf("check_name = $%d", ctr.Next())
 		args = append(args, check)
 	}
-	if s != nil && len(s.Operations) > 0 {
+	if s != nil && !s.IsEmpty() {
 		builder := NewEventSelectorSQLBuilder(s)
 		sc, sargs, err := builder.GetSelectorCond(&ctr)
 		if err != nil {
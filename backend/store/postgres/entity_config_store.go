@@ -25,7 +25,8 @@ var (
 type uniqueEntityConfigs map[uniqueResource]*corev3.EntityConfig
 
 type EntityConfigStore struct {
-	db DBI
+	db            DBI
+	notifications *Bus
 }
 
 func NewEntityConfigStore(db DBI) *EntityConfigStore {
@@ -447,6 +448,9 @@ func (s *EntityConfigStore) Watch(ctx context.Context, namespace, name string) <
 		Type:       "EntityConfig",
 		StoreName:  "entity_configs",
 	}
+	if s.notifications != nil {
+		return NewNotifiedWatcher(s, s.notifications, entityConfigNotifyChannel, 0, 0).Watch(ctx, req)
+	}
 	return NewWatcher(s, 0, 0).Watch(ctx, req)
 }
 
@@ -0,0 +1,61 @@
+package postgres
+
+// Migration 29
+const eventHistorySchema = `
+CREATE TABLE IF NOT EXISTS event_history (
+	id                  bigserial     PRIMARY KEY,
+	namespace           bigint        NOT NULL REFERENCES namespaces (id) ON DELETE CASCADE,
+	entity_name         text          NOT NULL,
+	check_name          text          NOT NULL,
+	event_time          timestamptz   NOT NULL,
+	serialized          bytea         NOT NULL
+);
+CREATE INDEX ON event_history ( namespace, entity_name, check_name, event_time DESC, id DESC );
+CREATE INDEX ON event_history ( event_time );
+`
+
+const addEventHistoryQuery = `
+WITH ns AS (
+	SELECT id
+	FROM namespaces
+	WHERE name = $1
+	LIMIT 1
+)
+INSERT INTO event_history ( namespace, entity_name, check_name, event_time, serialized )
+SELECT ns.id, $2, $3, to_timestamp($4::bigint), $5 FROM ns;
+`
+
+// getEventHistoryQuery selects the recorded events of an entity and check
+// pair, most recent first. The time range ($4, $5), the position of the last
+// event of the previous page ($6, $7) and the limit ($8) are optional.
+const getEventHistoryQuery = `
+SELECT event_history.id, event_history.event_time, event_history.serialized
+FROM event_history
+JOIN namespaces ON event_history.namespace = namespaces.id
+WHERE namespaces.name = $1 AND
+	event_history.entity_name = $2 AND
+	event_history.check_name = $3 AND
+	( $4::timestamptz IS NULL OR event_history.event_time >= $4 ) AND
+	( $5::timestamptz IS NULL OR event_history.event_time < $5 ) AND
+	( $6::timestamptz IS NULL OR ( event_history.event_time, event_history.id ) < ( $6, $7::bigint ) )
+ORDER BY event_history.event_time DESC, event_history.id DESC
+LIMIT $8;
+`
+
+const pruneEventHistoryByAgeQuery = `
+DELETE FROM event_history WHERE event_time < $1;
+`
+
+const pruneEventHistoryByCountQuery = `
+DELETE FROM event_history
+WHERE id IN (
+	SELECT id FROM (
+		SELECT id, row_number() OVER (
+			PARTITION BY namespace, entity_name, check_name
+			ORDER BY event_time DESC, id DESC
+		) AS position
+		FROM event_history
+	) AS ranked
+	WHERE ranked.position > $1
+);
+`
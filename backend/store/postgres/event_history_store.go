@@ -0,0 +1,348 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/jackc/pgx/v5"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+// DefaultEventHistoryPruneInterval is the interval at which the event history
+// is pruned when the pruner has no interval set.
+const DefaultEventHistoryPruneInterval = 10 * time.Minute
+
+var (
+	_ store.EventHistoryStore = &EventHistoryStore{}
+)
+
+// EventHistoryStore records events in the append-only event_history table,
+// and retrieves them.
+type EventHistoryStore struct {
+	db DBI
+}
+
+// NewEventHistoryStore creates a new EventHistoryStore.
+func NewEventHistoryStore(db DBI) *EventHistoryStore {
+	return &EventHistoryStore{db: db}
+}
+
+// eventHistoryRecord is an event encoded for the event history.
+type eventHistoryRecord struct {
+	namespace  string
+	entity     string
+	check      string
+	timestamp  int64
+	serialized []byte
+}
+
+// newEventHistoryRecord encodes the event for the event history, without its
+// metrics.
+func newEventHistoryRecord(event *corev2.Event) (eventHistoryRecord, error) {
+	if event == nil || !event.HasCheck() || event.Entity == nil {
+		return eventHistoryRecord{}, &store.ErrNotValid{Err: errors.New("event has no entity or check")}
+	}
+	if event.HasMetrics() {
+		// Taking pains to not modify our input
+		newEvent := *event
+		newEvent.Metrics = nil
+		event = &newEvent
+	}
+	timestamp := event.Timestamp
+	if timestamp == 0 {
+		timestamp = time.Now().Unix()
+	}
+	b, err := proto.Marshal(event)
+	if err != nil {
+		return eventHistoryRecord{}, &store.ErrEncode{Err: err}
+	}
+	return eventHistoryRecord{
+		namespace:  event.Entity.Namespace,
+		entity:     event.Entity.Name,
+		check:      event.Check.Name,
+		timestamp:  timestamp,
+		serialized: snappy.Encode(nil, b),
+	}, nil
+}
+
+// AddEvent records the event in the event history. Metrics are not recorded.
+func (s *EventHistoryStore) AddEvent(ctx context.Context, event *corev2.Event) error {
+	record, err := newEventHistoryRecord(event)
+	if err != nil {
+		return err
+	}
+	tag, err := s.db.Exec(ctx, addEventHistoryQuery, record.namespace, record.entity, record.check, record.timestamp, record.serialized)
+	if err != nil {
+		return &store.ErrInternal{Message: fmt.Sprintf("couldn't add event to history: %s", err)}
+	}
+	if tag.RowsAffected() == 0 {
+		return &store.ErrNamespaceMissing{Namespace: record.namespace}
+	}
+	return nil
+}
+
+// addRecords records the encoded events in a single batch. The records of
+// missing namespaces are skipped.
+func (s *EventHistoryStore) addRecords(ctx context.Context, records []eventHistoryRecord) error {
+	batch := new(pgx.Batch)
+	for _, record := range records {
+		batch.Queue(addEventHistoryQuery, record.namespace, record.entity, record.check, record.timestamp, record.serialized)
+	}
+	results := s.db.SendBatch(ctx, batch)
+	for range records {
+		if _, err := results.Exec(); err != nil {
+			_ = results.Close()
+			return &store.ErrInternal{Message: fmt.Sprintf("couldn't add events to history: %s", err)}
+		}
+	}
+	if err := results.Close(); err != nil {
+		return &store.ErrInternal{Message: fmt.Sprintf("couldn't add events to history: %s", err)}
+	}
+	return nil
+}
+
+type eventHistoryToken struct {
+	Time time.Time `json:"time"`
+	ID   int64     `json:"id"`
+}
+
+// GetEventHistory returns the recorded events for the given entity and check,
+// within the namespace stored in ctx, most recent first.
+func (s *EventHistoryStore) GetEventHistory(ctx context.Context, entity, check string, pred *store.EventHistoryPredicate) ([]*corev2.Event, error) {
+	ns, err := getNamespace(ctx)
+	if err != nil {
+		// Warning: do not wrap this error
+		return nil, err
+	}
+	if entity == "" || check == "" {
+		return nil, &store.ErrNotValid{Err: errors.New("must specify entity and check name")}
+	}
+	if pred == nil {
+		pred = &store.EventHistoryPredicate{}
+	}
+
+	var start, end, after sql.NullTime
+	var afterID, limit sql.NullInt64
+	if !pred.Start.IsZero() {
+		start.Time, start.Valid = pred.Start, true
+	}
+	if !pred.End.IsZero() {
+		end.Time, end.Valid = pred.End, true
+	}
+	if pred.Continue != "" {
+		var token eventHistoryToken
+		if err := json.Unmarshal([]byte(pred.Continue), &token); err != nil {
+			return nil, &store.ErrNotValid{Err: fmt.Errorf("couldn't get event history: error decoding token: %s", err)}
+		}
+		after.Time, after.Valid = token.Time, true
+		afterID.Int64, afterID.Valid = token.ID, true
+	}
+	if pred.Limit > 0 {
+		// fetch one more event to know if there is another page
+		limit.Int64, limit.Valid = pred.Limit+1, true
+	}
+
+	rows, err := s.db.Query(ctx, getEventHistoryQuery, ns, entity, check, start, end, after, afterID, limit)
+	if err != nil {
+		return nil, &store.ErrInternal{Message: fmt.Sprintf("couldn't get event history: %s", err)}
+	}
+	defer rows.Close()
+
+	var events []*corev2.Event
+	var last eventHistoryToken
+	pred.Continue = ""
+	for rows.Next() {
+		if pred.Limit > 0 && int64(len(events)) == pred.Limit {
+			b, _ := json.Marshal(last)
+			pred.Continue = string(b)
+			break
+		}
+		var serialized []byte
+		if err := rows.Scan(&last.ID, &last.Time, &serialized); err != nil {
+			return nil, &store.ErrNotValid{Err: fmt.Errorf("error reading event history: %s", err)}
+		}
+		decompressed, err := snappy.Decode(nil, serialized)
+		if err != nil {
+			return nil, &store.ErrNotValid{Err: err}
+		}
+		var event corev2.Event
+		if err := proto.Unmarshal(decompressed, &event); err != nil {
+			return nil, &store.ErrDecode{Err: fmt.Errorf("error reading event history: %s", err)}
+		}
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &store.ErrInternal{Message: fmt.Sprintf("error reading event history: %s", err)}
+	}
+	return events, nil
+}
+
+// Prune deletes the events recorded before now minus maxAge, and the events
+// beyond the maxEvents most recent ones of each entity and check pair. A zero
+// maxAge or maxEvents disables the corresponding pruning. It returns the
+// number of deleted events.
+func (s *EventHistoryStore) Prune(ctx context.Context, maxAge time.Duration, maxEvents int) (int64, error) {
+	var deleted int64
+	if maxAge > 0 {
+		tag, err := s.db.Exec(ctx, pruneEventHistoryByAgeQuery, time.Now().Add(-maxAge))
+		if err != nil {
+			return deleted, &store.ErrInternal{Message: fmt.Sprintf("couldn't prune event history: %s", err)}
+		}
+		deleted += tag.RowsAffected()
+	}
+	if maxEvents > 0 {
+		tag, err := s.db.Exec(ctx, pruneEventHistoryByCountQuery, maxEvents)
+		if err != nil {
+			return deleted, &store.ErrInternal{Message: fmt.Sprintf("couldn't prune event history: %s", err)}
+		}
+		deleted += tag.RowsAffected()
+	}
+	return deleted, nil
+}
+
+// EventHistoryPruner periodically prunes the event history by age and by
+// number of events per entity and check pair. A single backend of the cluster
+// prunes the event history at a time.
+type EventHistoryPruner struct {
+	Store *EventHistoryStore
+
+	// Executor runs the pruner while it holds the event history pruner
+	// mutex.
+	Executor store.SynchronizedExecutor
+
+	// MaxAge is the maximum age of the recorded events. Zero disables pruning
+	// by age.
+	MaxAge time.Duration
+
+	// MaxEvents is the maximum number of recorded events per entity and check
+	// pair. Zero disables pruning by count.
+	MaxEvents int
+
+	// Interval is the interval between prunes. Defaults to
+	// DefaultEventHistoryPruneInterval.
+	Interval time.Duration
+}
+
+// Run prunes the event history while it holds the event history pruner mutex,
+// until the context is canceled.
+func (p *EventHistoryPruner) Run(ctx context.Context) {
+	if p.MaxAge <= 0 && p.MaxEvents <= 0 {
+		return
+	}
+	for ctx.Err() == nil {
+		err := p.Executor.Execute(ctx, store.MutexEventHistoryPruner, p.prune)
+		if err != nil && ctx.Err() == nil {
+			logger.WithError(err).Warn("event history pruner lost its mutex")
+		}
+	}
+}
+
+func (p *EventHistoryPruner) prune(ctx context.Context) error {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = DefaultEventHistoryPruneInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		deleted, err := p.Store.Prune(ctx, p.MaxAge, p.MaxEvents)
+		if err != nil {
+			logger.WithError(err).Error("error pruning event history")
+		} else if deleted > 0 {
+			logger.WithField("deleted", deleted).Debug("pruned event history")
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// DefaultEventHistoryQueueSize is the number of updated events waiting to be
+// recorded in the event history, beyond which they are dropped.
+const DefaultEventHistoryQueueSize = 10000
+
+// DefaultEventHistoryBatchSize is the maximum number of events recorded in
+// the event history at once.
+const DefaultEventHistoryBatchSize = 500
+
+// DefaultEventHistoryFlushInterval is the maximum time an updated event waits
+// before it is recorded in the event history.
+const DefaultEventHistoryFlushInterval = time.Second
+
+// historyEventStore is an event store that records every updated event in
+// the event history.
+type historyEventStore struct {
+	store.EventStore
+	history *EventHistoryStore
+	queue   chan eventHistoryRecord
+}
+
+func newHistoryEventStore(ctx context.Context, events store.EventStore, history *EventHistoryStore) *historyEventStore {
+	h := &historyEventStore{
+		EventStore: events,
+		history:    history,
+		queue:      make(chan eventHistoryRecord, DefaultEventHistoryQueueSize),
+	}
+	go h.record(ctx, DefaultEventHistoryBatchSize, DefaultEventHistoryFlushInterval)
+	return h
+}
+
+// UpdateEvent updates the event in the wrapped event store, then queues the
+// updated event to be recorded in the event history. Failing to record the
+// event is logged but does not fail the update.
+func (h *historyEventStore) UpdateEvent(ctx context.Context, event *corev2.Event) (*corev2.Event, *corev2.Event, error) {
+	updated, prev, err := h.EventStore.UpdateEvent(ctx, event)
+	if err != nil {
+		return updated, prev, err
+	}
+	record, err := newEventHistoryRecord(updated)
+	if err != nil {
+		logger.WithError(err).Error("couldn't record event history")
+		return updated, prev, nil
+	}
+	select {
+	case h.queue <- record:
+	default:
+		logger.Warn("event history queue is full, dropping event")
+	}
+	return updated, prev, nil
+}
+
+// record records the queued events in batches of up to batchSize events, at
+// least every interval, until the context is canceled.
+func (h *historyEventStore) record(ctx context.Context, batchSize int, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	records := make([]eventHistoryRecord, 0, batchSize)
+	flush := func() {
+		if len(records) == 0 {
+			return
+		}
+		if err := h.history.addRecords(ctx, records); err != nil {
+			logger.WithError(err).WithField("events", len(records)).Error("couldn't record event history")
+		}
+		records = records[:0]
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case record := <-h.queue:
+			records = append(records, record)
+			if len(records) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
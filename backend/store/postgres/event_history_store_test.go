@@ -0,0 +1,179 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+func testWithEventHistoryStore(t *testing.T, fn func(context.Context, *EventHistoryStore)) {
+	t.Helper()
+	withPostgres(t, func(ctx context.Context, db *pgxpool.Pool, dsn string) {
+		if err := NewNamespaceStore(db).CreateIfNotExists(ctx, corev3.FixtureNamespace("default")); err != nil {
+			t.Fatal(err)
+		}
+		fn(store.NamespaceContext(ctx, "default"), NewEventHistoryStore(db))
+	})
+}
+
+func addHistoryEvents(t *testing.T, ctx context.Context, s *EventHistoryStore, timestamps ...int64) {
+	t.Helper()
+	for _, ts := range timestamps {
+		event := corev2.FixtureEvent("entity1", "check1")
+		event.Timestamp = ts
+		event.Check.Executed = ts
+		if err := s.AddEvent(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestEventHistoryStorePagination(t *testing.T) {
+	testWithEventHistoryStore(t, func(ctx context.Context, s *EventHistoryStore) {
+		addHistoryEvents(t, ctx, s, 100, 200, 300)
+
+		pred := &store.EventHistoryPredicate{Limit: 2}
+		events, err := s.GetEventHistory(ctx, "entity1", "check1", pred)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(events), 2; got != want {
+			t.Fatalf("bad number of events: got %d, want %d", got, want)
+		}
+		if got, want := events[0].Timestamp, int64(300); got != want {
+			t.Errorf("bad first event: got %d, want %d", got, want)
+		}
+		if pred.Continue == "" {
+			t.Fatal("expected a continue token")
+		}
+
+		events, err = s.GetEventHistory(ctx, "entity1", "check1", pred)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(events), 1; got != want {
+			t.Fatalf("bad number of events: got %d, want %d", got, want)
+		}
+		if got, want := events[0].Timestamp, int64(100); got != want {
+			t.Errorf("bad last event: got %d, want %d", got, want)
+		}
+		if pred.Continue != "" {
+			t.Errorf("expected no continue token, got %q", pred.Continue)
+		}
+	})
+}
+
+func TestEventHistoryStoreTimeRange(t *testing.T) {
+	testWithEventHistoryStore(t, func(ctx context.Context, s *EventHistoryStore) {
+		addHistoryEvents(t, ctx, s, 100, 200, 300)
+
+		pred := &store.EventHistoryPredicate{Start: time.Unix(200, 0), End: time.Unix(300, 0)}
+		events, err := s.GetEventHistory(ctx, "entity1", "check1", pred)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(events), 1; got != want {
+			t.Fatalf("bad number of events: got %d, want %d", got, want)
+		}
+		if got, want := events[0].Timestamp, int64(200); got != want {
+			t.Errorf("bad event: got %d, want %d", got, want)
+		}
+	})
+}
+
+func TestEventHistoryStorePrune(t *testing.T) {
+	testWithEventHistoryStore(t, func(ctx context.Context, s *EventHistoryStore) {
+		now := time.Now().Unix()
+		addHistoryEvents(t, ctx, s, now-7200, now-60, now-30, now)
+
+		deleted, err := s.Prune(ctx, time.Hour, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := deleted, int64(2); got != want {
+			t.Errorf("bad number of pruned events: got %d, want %d", got, want)
+		}
+		events, err := s.GetEventHistory(ctx, "entity1", "check1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(events), 2; got != want {
+			t.Fatalf("bad number of events: got %d, want %d", got, want)
+		}
+		if got, want := events[1].Timestamp, now-30; got != want {
+			t.Errorf("bad oldest event: got %d, want %d", got, want)
+		}
+	})
+}
+
+func TestEventHistoryStoreMissingNamespace(t *testing.T) {
+	testWithEventHistoryStore(t, func(ctx context.Context, s *EventHistoryStore) {
+		event := corev2.FixtureEvent("entity1", "check1")
+		event.Entity.Namespace = "missing"
+		if err := s.AddEvent(ctx, event); err == nil {
+			t.Fatal("expected non-nil error")
+		}
+	})
+}
+
+func TestEventHistoryStoreNoEvents(t *testing.T) {
+	testWithEventHistoryStore(t, func(ctx context.Context, s *EventHistoryStore) {
+		events, err := s.GetEventHistory(ctx, "entity1", "check1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if events != nil {
+			t.Errorf("expected nil events, got %v", events)
+		}
+	})
+}
+
+// updatedEventStore is an event store that updates every event.
+type updatedEventStore struct {
+	store.EventStore
+}
+
+func (updatedEventStore) UpdateEvent(ctx context.Context, event *corev2.Event) (*corev2.Event, *corev2.Event, error) {
+	return event, nil, nil
+}
+
+func TestHistoryEventStoreBatches(t *testing.T) {
+	testWithEventHistoryStore(t, func(ctx context.Context, s *EventHistoryStore) {
+		h := &historyEventStore{
+			EventStore: updatedEventStore{},
+			history:    s,
+			queue:      make(chan eventHistoryRecord, 10),
+		}
+		recordCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go h.record(recordCtx, 2, 10*time.Millisecond)
+
+		for _, ts := range []int64{100, 200, 300} {
+			event := corev2.FixtureEvent("entity1", "check1")
+			event.Timestamp = ts
+			if _, _, err := h.UpdateEvent(ctx, event); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			events, err := s.GetEventHistory(ctx, "entity1", "check1", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(events) == 3 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("bad number of events: got %d, want 3", len(events))
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+}
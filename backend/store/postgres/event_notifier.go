@@ -0,0 +1,208 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/messaging"
+)
+
+const (
+	// EventStreamLeaseTTL is the time to live of the event stream leases.
+	EventStreamLeaseTTL = 30 * time.Second
+
+	// EventStreamLeaseInterval is the interval at which the event stream
+	// leases are renewed.
+	EventStreamLeaseInterval = 10 * time.Second
+
+	// EventStreamCheckInterval is the interval at which the EventNotifier
+	// checks whether the events are streamed.
+	EventStreamCheckInterval = 5 * time.Second
+
+	// maxNotificationSize is the maximum size of the postgres notification
+	// payloads.
+	maxNotificationSize = 8000
+
+	// eventNotifierBufferSize is the number of events buffered by the
+	// EventNotifier. The events are dropped when it falls further behind.
+	eventNotifierBufferSize = 1000
+)
+
+// holdEventStreamLease holds the event stream lease named id until the
+// context is canceled, and then deletes it.
+func holdEventStreamLease(ctx context.Context, db DBI, id string) {
+	ticker := time.NewTicker(EventStreamLeaseInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := db.Exec(ctx, holdEventStreamLeaseQuery, id, EventStreamLeaseTTL.Seconds()); err != nil && ctx.Err() == nil {
+			logger.WithError(err).Error("couldn't hold the event stream lease")
+		}
+		select {
+		case <-ctx.Done():
+			dctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if _, err := db.Exec(dctx, deleteEventStreamLeaseQuery, id); err != nil {
+				logger.WithError(err).Error("couldn't delete the event stream lease")
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// EventNotifier notifies the EventWatchers of every backend of the events
+// published on the message bus by eventd and keepalived, only while one of
+// them holds an event stream lease. The events are notified with their
+// content, unless they are too large, so that the watchers don't have to read
+// them.
+type EventNotifier struct {
+	db      DBI
+	bus     messaging.MessageBus
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	errChan chan error
+
+	// streaming caches whether the events are streamed, as of checkedAt
+	streaming bool
+	checkedAt time.Time
+}
+
+// NewEventNotifier creates a new EventNotifier.
+func NewEventNotifier(db DBI, bus messaging.MessageBus) *EventNotifier {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &EventNotifier{
+		db:      db,
+		bus:     bus,
+		ctx:     ctx,
+		cancel:  cancel,
+		errChan: make(chan error, 1),
+	}
+}
+
+// Start subscribes to the events published on the message bus.
+func (n *EventNotifier) Start() error {
+	ch := make(messaging.ChanSubscriber, eventNotifierBufferSize)
+	sub, err := n.bus.Subscribe(messaging.TopicEvent, n.Name(), ch)
+	if err != nil {
+		return err
+	}
+	// the message bus blocks until the events are received, so they are
+	// dropped instead if they can't be notified fast enough
+	events := make(chan *corev2.Event, eventNotifierBufferSize)
+	n.wg.Add(2)
+	go func() {
+		defer n.wg.Done()
+		defer func() {
+			if err := sub.Cancel(); err != nil {
+				logger.WithError(err).Error("couldn't unsubscribe from the events")
+			}
+		}()
+		for {
+			select {
+			case <-n.ctx.Done():
+				return
+			case msg := <-ch:
+				event, ok := msg.(*corev2.Event)
+				if !ok {
+					continue
+				}
+				select {
+				case events <- event:
+				default:
+					logger.Warn("event notifier is too slow, dropping event")
+				}
+			}
+		}
+	}()
+	go func() {
+		defer n.wg.Done()
+		for {
+			select {
+			case <-n.ctx.Done():
+				return
+			case event := <-events:
+				if !n.isStreaming(n.ctx) {
+					continue
+				}
+				if err := n.notify(n.ctx, event); err != nil && n.ctx.Err() == nil {
+					logger.WithError(err).Error("couldn't notify event")
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops the notifier.
+func (n *EventNotifier) Stop() error {
+	n.cancel()
+	n.wg.Wait()
+	close(n.errChan)
+	return nil
+}
+
+// Err returns a channel that the caller can use to listen for terminal errors
+// indicating a premature shutdown of the Daemon.
+func (n *EventNotifier) Err() <-chan error {
+	return n.errChan
+}
+
+// Name returns the daemon name.
+func (n *EventNotifier) Name() string {
+	return "event-notifier"
+}
+
+// isStreaming returns whether an event stream lease is held, checking it at
+// most once per EventStreamCheckInterval.
+func (n *EventNotifier) isStreaming(ctx context.Context) bool {
+	if time.Since(n.checkedAt) < EventStreamCheckInterval {
+		return n.streaming
+	}
+	if err := n.db.QueryRow(ctx, hasEventStreamsQuery).Scan(&n.streaming); err != nil {
+		logger.WithError(err).Error("couldn't check whether the events are streamed")
+		n.streaming = false
+	}
+	n.checkedAt = time.Now()
+	return n.streaming
+}
+
+func (n *EventNotifier) notify(ctx context.Context, event *corev2.Event) error {
+	payload, err := eventNotification(event, time.Now())
+	if err != nil {
+		return err
+	}
+	_, err = n.db.Exec(ctx, "SELECT pg_notify($1, $2)", eventNotifyChannel, string(payload))
+	return err
+}
+
+// eventNotification returns the payload of the notification of an event. The
+// events that don't fit in a notification are notified without their content.
+func eventNotification(event *corev2.Event, now time.Time) ([]byte, error) {
+	notification := watchNotification{
+		APIVersion: "core/v2",
+		Type:       "Event",
+		Operation:  "UPDATE",
+		SentAt:     float64(now.UnixNano()) / float64(time.Second),
+	}
+	if event.Entity != nil {
+		notification.Namespace = event.Entity.Namespace
+		notification.Entity = event.Entity.Name
+	}
+	if event.HasCheck() {
+		notification.Name = event.Check.Name
+	}
+	var err error
+	if notification.Event, err = json.Marshal(event); err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(notification)
+	if err != nil || len(payload) < maxNotificationSize {
+		return payload, err
+	}
+	notification.Event = nil
+	return json.Marshal(notification)
+}
@@ -176,6 +176,36 @@ var Migrations = []migration.Migrator{
 		_, err := tx.Exec(context.Background(), "UPDATE configuration SET etag = digest(resource::text, 'sha1')")
 		return err
 	},
+	// Migration 29
+	func(tx migration.LimitedTx) error {
+		_, err := tx.Exec(context.Background(), eventHistorySchema)
+		return err
+	},
+	// Migration 30
+	func(tx migration.LimitedTx) error {
+		_, err := tx.Exec(context.Background(), auditLogSchema)
+		return err
+	},
+	// Migration 31
+	func(tx migration.LimitedTx) error {
+		_, err := tx.Exec(context.Background(), watchNotifySchema)
+		return err
+	},
+	// Migration 32
+	func(tx migration.LimitedTx) error {
+		_, err := tx.Exec(context.Background(), eventSilenceNotifySchema)
+		return err
+	},
+	// Migration 33
+	func(tx migration.LimitedTx) error {
+		_, err := tx.Exec(context.Background(), queueNotBeforeSchema)
+		return err
+	},
+	// Migration 34
+	func(tx migration.LimitedTx) error {
+		_, err := tx.Exec(context.Background(), eventStreamsSchema)
+		return err
+	},
 }
 
 type eventRecord struct {
@@ -0,0 +1,221 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	"github.com/sensu/sensu-go/backend/store/v2/wrap"
+	"github.com/sensu/sensu-go/util/retry"
+)
+
+// notificationReader reads the resource of a notification. It returns a nil
+// resource if the notification is to be ignored, for instance when the
+// resource was deleted since.
+type notificationReader func(context.Context, *watchNotification) (corev3.Resource, storev2.WatchActionType, error)
+
+// notificationWatch watches the resources notified on the bus channel, in the
+// namespace, or in every namespace if it's empty. Unlike the Watcher, which
+// polls the tables for the changes it is notified of, the resources are read
+// as they are notified, as their tables don't record when they changed. The
+// watch channel is closed when the context is canceled, or when the bus
+// subscription ends.
+func notificationWatch(ctx context.Context, bus *Bus, channel, namespace string, read notificationReader) <-chan []storev2.WatchEvent {
+	eventChan := make(chan []storev2.WatchEvent, 32)
+
+	var notifications <-chan *pq.Notification
+	backoff := retry.ExponentialBackoff{
+		Ctx: ctx,
+	}
+	err := backoff.Retry(func(retry int) (bool, error) {
+		var err error
+		notifications, err = bus.Subscribe(ctx, "", channel)
+		if err != nil {
+			logger.Errorf("watcher failed to listen for notifications on retry %d: %v", retry, err)
+			return false, err
+		}
+		return true, nil
+	})
+	if err != nil {
+		logger.Errorf("watcher failed to start: %v", err)
+		close(eventChan)
+		return eventChan
+	}
+
+	go func() {
+		defer close(eventChan)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case notification, ok := <-notifications:
+				if !ok {
+					return
+				}
+				var payload watchNotification
+				if err := json.Unmarshal([]byte(notification.Extra), &payload); err != nil {
+					logger.WithError(err).Error("invalid watch notification")
+					continue
+				}
+				if payload.Namespace == "" || (namespace != "" && payload.Namespace != namespace) {
+					continue
+				}
+				event, ok := readNotification(ctx, &payload, read)
+				if !ok {
+					continue
+				}
+				select {
+				case eventChan <- []storev2.WatchEvent{event}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return eventChan
+}
+
+func readNotification(ctx context.Context, payload *watchNotification, read notificationReader) (storev2.WatchEvent, bool) {
+	event := storev2.WatchEvent{
+		Key: storev2.ResourceRequest{
+			Namespace:  payload.Namespace,
+			Name:       payload.Name,
+			APIVersion: payload.APIVersion,
+			Type:       payload.Type,
+		},
+	}
+	resource, action, err := read(ctx, payload)
+	if err != nil {
+		if ctx.Err() != nil {
+			return event, false
+		}
+		event.Type = storev2.WatchError
+		event.Err = err
+		return event, true
+	}
+	if resource == nil {
+		return event, false
+	}
+	event.Key.StoreName = resource.StoreName()
+	event.Type = action
+	event.Value, event.Err = wrap.ResourceWithoutValidation(resource, wrap.CompressNone)
+	return event, true
+}
+
+// Watch watches the silences of the namespace, or of every namespace if it's
+// empty. The silences can only be watched with notifications.
+func (s *SilenceStore) Watch(ctx context.Context, namespace string) <-chan []storev2.WatchEvent {
+	if s.notifications == nil {
+		logger.Error("silences can't be watched without notifications")
+		return nil
+	}
+	return notificationWatch(ctx, s.notifications, silenceNotifyChannel, namespace, s.readNotification)
+}
+
+func (s *SilenceStore) readNotification(ctx context.Context, payload *watchNotification) (corev3.Resource, storev2.WatchActionType, error) {
+	if payload.Operation == "DELETE" {
+		meta := corev2.NewObjectMeta(payload.Name, payload.Namespace)
+		return &corev2.Silenced{ObjectMeta: meta}, storev2.WatchDelete, nil
+	}
+	silenced, err := s.GetSilenceByName(ctx, payload.Namespace, payload.Name)
+	var notFound *store.ErrNotFound
+	if errors.As(err, &notFound) {
+		// deleted since, the deletion is notified too
+		return nil, storev2.WatchUnknown, nil
+	}
+	if err != nil {
+		return nil, storev2.WatchError, err
+	}
+	if payload.Operation == "INSERT" {
+		return silenced, storev2.WatchCreate, nil
+	}
+	return silenced, storev2.WatchUpdate, nil
+}
+
+// EventWatcher watches the events processed by every backend, as they are
+// notified by their EventNotifier. While the events are watched, the watcher
+// holds a lease in the event_streams table, so that the backends only notify
+// the events while they are watched.
+type EventWatcher struct {
+	db     DBI
+	events *EventStore
+	bus    *Bus
+
+	mu        sync.Mutex
+	watches   int
+	stopLease context.CancelFunc
+}
+
+// NewEventWatcher creates a new EventWatcher.
+func NewEventWatcher(db DBI, bus *Bus) *EventWatcher {
+	return &EventWatcher{
+		db:     db,
+		events: &EventStore{db: db},
+		bus:    bus,
+	}
+}
+
+// Watch watches the events of the namespace, or of every namespace if it's
+// empty. The events are always notified as updated. The backends may take up
+// to EventStreamCheckInterval to notice the first watch of the cluster.
+func (w *EventWatcher) Watch(ctx context.Context, namespace string) <-chan []storev2.WatchEvent {
+	w.acquireLease()
+	go func() {
+		<-ctx.Done()
+		w.releaseLease()
+	}()
+	return notificationWatch(ctx, w.bus, eventNotifyChannel, namespace, w.readNotification)
+}
+
+// acquireLease starts holding a lease on the event streams with the first
+// watch.
+func (w *EventWatcher) acquireLease() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.watches++
+	if w.watches > 1 {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w.stopLease = cancel
+	go holdEventStreamLease(ctx, w.db, uuid.New().String())
+}
+
+// releaseLease stops holding the lease once the last watch stops.
+func (w *EventWatcher) releaseLease() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.watches--
+	if w.watches == 0 {
+		w.stopLease()
+	}
+}
+
+func (w *EventWatcher) readNotification(ctx context.Context, payload *watchNotification) (corev3.Resource, storev2.WatchActionType, error) {
+	if len(payload.Event) > 0 {
+		var event corev2.Event
+		if err := json.Unmarshal(payload.Event, &event); err != nil {
+			return nil, storev2.WatchError, err
+		}
+		return &event, storev2.WatchUpdate, nil
+	}
+	// the events too large to be notified are read
+	ctx = context.WithValue(ctx, corev2.NamespaceKey, payload.Namespace)
+	event, err := w.events.GetEventByEntityCheck(ctx, payload.Entity, payload.Name)
+	if err != nil {
+		return nil, storev2.WatchError, err
+	}
+	if event == nil {
+		// deleted since
+		return nil, storev2.WatchUnknown, nil
+	}
+	return event, storev2.WatchUpdate, nil
+}
@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lib/pq"
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/backend/messaging"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func receiveWatchEvent(t *testing.T, ch <-chan []storev2.WatchEvent) storev2.WatchEvent {
+	t.Helper()
+	select {
+	case events, ok := <-ch:
+		if !ok {
+			t.Fatal("watcher closed unexpectedly")
+		}
+		require.Len(t, events, 1)
+		return events[0]
+	case <-time.After(5 * time.Second):
+		t.Fatal("no watch event received before timeout")
+	}
+	return storev2.WatchEvent{}
+}
+
+func TestSilenceStore_WatchNotifications(t *testing.T) {
+	listener := new(mockListener)
+	listener.On("Listen", silenceNotifyChannel).Return(nil)
+	notifications := make(chan *pq.Notification)
+	listener.On("NotificationChannel").Return(notifications)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := &SilenceStore{notifications: NewBus(ctx, listener)}
+	watchChan := s.Watch(ctx, "default")
+
+	// invalid notifications, and notifications about other namespaces, are
+	// ignored
+	notifications <- &pq.Notification{Channel: silenceNotifyChannel, Extra: "{"}
+	notifications <- &pq.Notification{Channel: silenceNotifyChannel, Extra: `{"api_version":"core/v2","type":"Silenced","namespace":"dev","name":"foo","operation":"DELETE"}`}
+	notifications <- &pq.Notification{Channel: silenceNotifyChannel, Extra: `{"api_version":"core/v2","type":"Silenced","namespace":"default","name":"bar","operation":"DELETE"}`}
+
+	event := receiveWatchEvent(t, watchChan)
+	assert.Equal(t, storev2.WatchDelete, event.Type)
+	assert.Equal(t, new(corev2.Silenced).StoreName(), event.Key.StoreName)
+	value, err := storev2.ReadEventValue[*corev2.Silenced](event)
+	require.NoError(t, err)
+	assert.Equal(t, "default", value.Namespace)
+	assert.Equal(t, "bar", value.Name)
+
+	cancel()
+	select {
+	case _, ok := <-watchChan:
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("watcher not closed")
+	}
+}
+
+func TestSilenceStore_Watch(t *testing.T) {
+	withPostgres(t, func(ctx context.Context, db *pgxpool.Pool, dsn string) {
+		if err := NewNamespaceStore(db).CreateIfNotExists(ctx, corev3.FixtureNamespace("default")); err != nil {
+			t.Fatal(err)
+		}
+		listener := pq.NewListener(dsn, time.Second, time.Minute, nil)
+		defer listener.Close()
+		s := &SilenceStore{db: db, notifications: NewBus(ctx, listener)}
+
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		watchChan := s.Watch(watchCtx, "default")
+
+		silence := &corev2.Silenced{
+			ObjectMeta: corev2.NewObjectMeta("silence", "default"),
+			Check:      "foo",
+			Begin:      time.Now().Unix(),
+		}
+		require.NoError(t, s.UpdateSilence(ctx, silence))
+		assert.Equal(t, storev2.WatchCreate, receiveWatchEvent(t, watchChan).Type)
+
+		silence.Reason = "maintenance"
+		require.NoError(t, s.UpdateSilence(ctx, silence))
+		event := receiveWatchEvent(t, watchChan)
+		assert.Equal(t, storev2.WatchUpdate, event.Type)
+		value, err := storev2.ReadEventValue[*corev2.Silenced](event)
+		require.NoError(t, err)
+		assert.Equal(t, "maintenance", value.Reason)
+
+		require.NoError(t, s.DeleteSilences(ctx, "default", []string{"silence"}))
+		assert.Equal(t, storev2.WatchDelete, receiveWatchEvent(t, watchChan).Type)
+	})
+}
+
+func TestEventNotification(t *testing.T) {
+	event := corev2.FixtureEvent("entity", "check")
+	payload, err := eventNotification(event, time.Now())
+	require.NoError(t, err)
+
+	// the watchers read the notified events without querying them
+	var notification watchNotification
+	require.NoError(t, json.Unmarshal(payload, &notification))
+	assert.Equal(t, "default", notification.Namespace)
+	assert.Equal(t, "entity", notification.Entity)
+	assert.Equal(t, "check", notification.Name)
+	resource, action, err := new(EventWatcher).readNotification(context.Background(), &notification)
+	require.NoError(t, err)
+	assert.Equal(t, storev2.WatchUpdate, action)
+	require.IsType(t, &corev2.Event{}, resource)
+	assert.Equal(t, event.ID, resource.(*corev2.Event).ID)
+	assert.Equal(t, event.Check.Output, resource.(*corev2.Event).Check.Output)
+
+	// the events too large to be notified are notified without their content
+	event.Check.Output = strings.Repeat("x", maxNotificationSize)
+	payload, err = eventNotification(event, time.Now())
+	require.NoError(t, err)
+	assert.Less(t, len(payload), maxNotificationSize)
+	notification = watchNotification{}
+	require.NoError(t, json.Unmarshal(payload, &notification))
+	assert.Empty(t, notification.Event)
+	assert.Equal(t, "check", notification.Name)
+}
+
+func TestEventWatcher_Watch(t *testing.T) {
+	withPostgres(t, func(ctx context.Context, db *pgxpool.Pool, dsn string) {
+		listener := pq.NewListener(dsn, time.Second, time.Minute, nil)
+		defer listener.Close()
+		watcher := NewEventWatcher(db, NewBus(ctx, listener))
+
+		if err := NewNamespaceStore(db).CreateIfNotExists(ctx, corev3.FixtureNamespace("default")); err != nil {
+			t.Fatal(err)
+		}
+		eventStore, err := NewEventStore(db, nil, Config{})
+		require.NoError(t, err)
+		bus, err := messaging.NewWizardBus(messaging.WizardBusConfig{})
+		require.NoError(t, err)
+		require.NoError(t, bus.Start())
+		defer bus.Stop()
+		notifier := NewEventNotifier(db, bus)
+		require.NoError(t, notifier.Start())
+		defer notifier.Stop()
+
+		// nothing is notified while the events are not streamed
+		event := corev2.FixtureEvent("entity", "check")
+		eventCtx := context.WithValue(ctx, corev2.NamespaceKey, event.Entity.Namespace)
+		_, _, err = eventStore.UpdateEvent(eventCtx, event)
+		require.NoError(t, err)
+		assert.False(t, notifier.isStreaming(ctx))
+
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		watchChan := watcher.Watch(watchCtx, "")
+		require.Eventually(t, func() bool {
+			var streaming bool
+			return db.QueryRow(ctx, hasEventStreamsQuery).Scan(&streaming) == nil && streaming
+		}, 5*time.Second, 10*time.Millisecond)
+		notifier.checkedAt = time.Time{}
+
+		require.NoError(t, bus.Publish(messaging.TopicEvent, event))
+		watchEvent := receiveWatchEvent(t, watchChan)
+		assert.Equal(t, storev2.WatchUpdate, watchEvent.Type)
+		value, err := storev2.ReadEventValue[*corev2.Event](watchEvent)
+		require.NoError(t, err)
+		assert.Equal(t, "entity", value.Entity.Name)
+		assert.Equal(t, "check", value.Check.Name)
+	})
+}
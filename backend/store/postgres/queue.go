@@ -23,13 +23,17 @@ func NewQueue(db DBI) *Queue {
 
 // Enqueue a queue item
 func (q *Queue) Enqueue(ctx context.Context, item queue.Item) error {
-	_, err := q.db.Exec(ctx, queueEnqueue, item.Queue, item.Value)
+	var notBefore *time.Time
+	if !item.NotBefore.IsZero() {
+		notBefore = &item.NotBefore
+	}
+	_, err := q.db.Exec(ctx, queueEnqueue, item.Queue, item.Value, notBefore)
 	return err
 }
 
-// Reserve reserves a queue item.
-// When the queue is empty Reserve will block and poll for new items until either
-// an item is found, or the context is cancelled.
+// Reserve reserves a queue item, once it is due.
+// When the queue has no due items Reserve will block and poll for new items until
+// either an item is found, or the context is cancelled.
 //
 // When Reserve returns a Reservation, the caller MUST Ack or Nack that Reservation.
 // Otherwise a transaction + connection could be leaked depending on the session
@@ -53,7 +57,7 @@ func (q *Queue) Reserve(ctx context.Context, queueName string) (queue.Reservatio
 		var item queue.Item
 
 		row := tx.QueryRow(ctx, queueReserveItem, queueName)
-		if err := row.Scan(&item.ID, &item.Queue, &item.Value); err != nil {
+		if err := row.Scan(&item.ID, &item.Queue, &item.Value, &item.NotBefore); err != nil {
 			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
 				return nil, fmt.Errorf("unexpected rollback error: %w", rollbackErr)
 			}
@@ -9,16 +9,23 @@ CREATE TABLE IF NOT EXISTS queue_items (
 );
 `
 
+// queueNotBeforeSchema postpones the queue items until their not_before
+// time.
+const queueNotBeforeSchema = `
+ALTER TABLE queue_items ADD COLUMN not_before timestamptz NOT NULL DEFAULT NOW();
+CREATE INDEX IF NOT EXISTS queue_items_queue_not_before_idx ON queue_items (queue, not_before);
+`
+
 const queueEnqueue = `
-INSERT INTO queue_items  (queue, value)
-	VALUES ($1, $2);
+INSERT INTO queue_items  (queue, value, not_before)
+	VALUES ($1, $2, COALESCE($3, NOW()));
 `
 
 const queueReserveItem = `
 SELECT
-	id, queue, value
+	id, queue, value, not_before
  FROM queue_items
-WHERE queue = $1
+WHERE queue = $1 AND not_before <= NOW()
 ORDER BY updated_at LIMIT 1
 FOR UPDATE SKIP LOCKED;
 `
@@ -216,7 +216,20 @@ func (r *Ring) produce(ctx context.Context, sub ringv2.Subscription, ch chan rin
 		case <-ctx.Done():
 			logger.Trace("context canceled")
 			return
-		case <-notifications:
+		case _, ok := <-notifications:
+			if !ok {
+				// The bus dropped the subscription as it fell behind.
+				// Subscribe again, and produce to catch up.
+				notifications, err = r.bus.Subscribe(ctx, r.namespace, sub.Name)
+				if err != nil {
+					ch <- ringv2.Event{
+						Type: ringv2.EventError,
+						Err:  err,
+					}
+					logger.WithError(err).Error("error setting up postgres notification listener")
+					return
+				}
+			}
 			ch <- r.doProduce(ctx, sub)
 		}
 	}
@@ -54,13 +54,22 @@ func NewConfigSelectorSQLBuilder(selector *selector.Selector) *SelectorSQLBuilde
 }
 
 func (s *SelectorSQLBuilder) GetSelectorCond(ctr *argCounter) (string, []interface{}, error) {
+	return s.selectorCond(ctr, s.selector)
+}
+
+// selectorCond returns the condition matching all the operations and
+// disjunctions of the selector. The selectors of the disjunctions are
+// translated recursively.
+func (s *SelectorSQLBuilder) selectorCond(ctr *argCounter, sel *selector.Selector) (string, []interface{}, error) {
 	vars := make([]interface{}, 0, 4)
 	conds := make([]string, 0, 4)
 	inclusions := map[string]string{}
 	exclusions := map[string]string{}
-	for _, op := range s.selector.Operations {
+	for _, op := range sel.Operations {
 		switch op.Operator {
-		case selector.DoubleEqualSignOperator, selector.NotEqualOperator, selector.MatchesOperator:
+		case selector.DoubleEqualSignOperator, selector.NotEqualOperator, selector.MatchesOperator,
+			selector.LessThanOperator, selector.LessThanOrEqualOperator,
+			selector.GreaterThanOperator, selector.GreaterThanOrEqualOperator:
 			if len(op.RValues) != 1 {
 				return "", nil, fmt.Errorf("invalid operator: %v", op)
 			}
@@ -94,11 +103,29 @@ func (s *SelectorSQLBuilder) GetSelectorCond(ctr *argCounter) (string, []interfa
 			cond, vr := s.matchOperator(ctr, op)
 			conds = append(conds, cond)
 			vars = append(vars, vr...)
+		case selector.LessThanOperator, selector.LessThanOrEqualOperator,
+			selector.GreaterThanOperator, selector.GreaterThanOrEqualOperator:
+			cond, vr := s.compareOperator(ctr, op)
+			conds = append(conds, cond)
+			vars = append(vars, vr...)
+		case selector.ExistsOperator:
+			cond, vr := s.existsOperator(ctr, op)
+			conds = append(conds, cond)
+			vars = append(vars, vr...)
 		default:
 			return "", nil, fmt.Errorf("unsupported operator: %s", op.Operator)
 		}
 	}
 
+	for _, disjunction := range sel.Disjunctions {
+		cond, vr, err := s.disjunctionCond(ctr, disjunction)
+		if err != nil {
+			return "", nil, err
+		}
+		conds = append(conds, cond)
+		vars = append(vars, vr...)
+	}
+
 	cnds, vrs := s.formatSelectorConds(ctr, inclusions, exclusions)
 	conds = append(conds, cnds...)
 	vars = append(vars, vrs...)
@@ -203,6 +230,80 @@ func (s *SelectorSQLBuilder) matchOperator(ctr *argCounter, op selector.Operatio
 	return query, []interface{}{op.LValue, op.RValues[0]}
 }
 
+// disjunctionCond matches if any of the selectors of the disjunction matches.
+func (s *SelectorSQLBuilder) disjunctionCond(ctr *argCounter, disjunction []*selector.Selector) (string, []interface{}, error) {
+	if len(disjunction) == 0 {
+		return "false", nil, nil
+	}
+	vars := make([]interface{}, 0, len(disjunction))
+	fragments := make([]string, 0, len(disjunction))
+	for _, sel := range disjunction {
+		cond, vr, err := s.selectorCond(ctr, sel)
+		if err != nil {
+			return "", nil, err
+		}
+		if cond == "" {
+			cond = "true"
+		}
+		fragments = append(fragments, fmt.Sprintf("(%s)", cond))
+		vars = append(vars, vr...)
+	}
+	return fmt.Sprintf("(%s)", strings.Join(fragments, " OR ")), vars, nil
+}
+
+// existsOperator matches if the field, or label, is set.
+func (s *SelectorSQLBuilder) existsOperator(ctr *argCounter, op selector.Operation) (string, []interface{}) {
+	if s.validFieldKey(&op) {
+		query := fmt.Sprintf("%s#>>$%d IS NOT NULL", s.selectorColumn, ctr.Next())
+		return query, []interface{}{s.matchLValue(op.LValue)}
+	}
+	if !strings.HasPrefix(op.LValue, "labels.") && s.includeLabelCaption {
+		op.LValue = fmt.Sprintf("labels.%s", op.LValue)
+	}
+	keyArg := ctr.Next()
+	fragments := make([]string, 0, len(s.labelPrefixes))
+	for _, prefix := range s.labelPrefixes {
+		pfx := pq.QuoteLiteral(prefix)
+		fragments = append(fragments, fmt.Sprintf("(%s ? (%s || $%d))", s.labelColumn, pfx, keyArg))
+	}
+	return fmt.Sprintf("(%s)", strings.Join(fragments, " OR ")), []interface{}{op.LValue}
+}
+
+// numericPattern matches the values that compareOperator casts to numeric,
+// so that comparing non-numeric values doesn't fail the query. It's the
+// pattern of the numbers of the selector evaluator, so that both agree on
+// which values are numbers.
+var numericPattern = pq.QuoteLiteral(selector.NumberPattern)
+
+// compareOperator compares the field, or label, numerically. Like the
+// selector evaluator, the values that aren't numbers don't match.
+func (s *SelectorSQLBuilder) compareOperator(ctr *argCounter, op selector.Operation) (string, []interface{}) {
+	operand := op.RValues[0]
+	if _, ok := selector.ParseNumber(operand); !ok {
+		return "false", nil
+	}
+	numeric := func(value string) string {
+		return fmt.Sprintf("(CASE WHEN %s ~ %s THEN (%s)::numeric END)", value, numericPattern, value)
+	}
+	if s.validFieldKey(&op) {
+		value := fmt.Sprintf("%s#>>$%d", s.selectorColumn, ctr.Next())
+		query := fmt.Sprintf("%s %s $%d::numeric", numeric(value), op.Operator, ctr.Next())
+		return query, []interface{}{s.matchLValue(op.LValue), operand}
+	}
+	if !strings.HasPrefix(op.LValue, "labels.") && s.includeLabelCaption {
+		op.LValue = fmt.Sprintf("labels.%s", op.LValue)
+	}
+	keyArg := ctr.Next()
+	operandArg := ctr.Next()
+	fragments := make([]string, 0, len(s.labelPrefixes))
+	for _, prefix := range s.labelPrefixes {
+		pfx := pq.QuoteLiteral(prefix)
+		value := fmt.Sprintf("%s->>(%s || $%d)", s.labelColumn, pfx, keyArg)
+		fragments = append(fragments, fmt.Sprintf("%s %s $%d::numeric", numeric(value), op.Operator, operandArg))
+	}
+	return fmt.Sprintf("(%s)", strings.Join(fragments, " OR ")), []interface{}{op.LValue, operand}
+}
+
 func (s *SelectorSQLBuilder) formatSelectorConds(ctr *argCounter, inclusions, exclusions map[string]string) ([]string, []interface{}) {
 	conds := make([]string, 0, 2)
 	vars := make([]interface{}, 0, 2)
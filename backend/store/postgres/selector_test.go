@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/sensu/sensu-go/backend/selector"
@@ -16,7 +17,9 @@ func TestGetSelectorCond(t *testing.T) {
 
 	testCases := []struct {
 		input         string
+		label         bool
 		expectedQuery string
+		expectedVars  []interface{}
 	}{
 		{
 			input:         "foo == bar",
@@ -26,16 +29,54 @@ func TestGetSelectorCond(t *testing.T) {
 			input:         "foo == bar && zip != zap && bim == bap",
 			expectedQuery: "testSelectorCol @> $1 AND NOT testSelectorCol @> $2",
 		},
+		{
+			input:         "foo == bar || zip == zap",
+			expectedQuery: "((testSelectorCol @> $1) OR (testSelectorCol @> $2))",
+		},
+		{
+			input:         "foo == bar && (zip == zap || count >= 3)",
+			expectedQuery: "((testSelectorCol @> $1) OR ((CASE WHEN testSelectorCol#>>$2 ~ " + numericPattern + " THEN (testSelectorCol#>>$2)::numeric END) >= $3::numeric)) AND testSelectorCol @> $4",
+		},
+		{
+			input:         "count > 2.5",
+			expectedQuery: "(CASE WHEN testSelectorCol#>>$1 ~ " + numericPattern + " THEN (testSelectorCol#>>$1)::numeric END) > $2::numeric",
+			expectedVars:  []interface{}{"{count}", "2.5"},
+		},
+		{
+			input:         "count > abc",
+			expectedQuery: "false",
+		},
+		{
+			input:         "foo exists",
+			expectedQuery: "testSelectorCol#>>$1 IS NOT NULL",
+			expectedVars:  []interface{}{"{foo}"},
+		},
+		{
+			input:         "region exists",
+			label:         true,
+			expectedQuery: "((testLabelCol ? ('' || $1)))",
+			expectedVars:  []interface{}{"region"},
+		},
+		{
+			input:         "tier < 2",
+			label:         true,
+			expectedQuery: "((CASE WHEN testLabelCol->>('' || $1) ~ " + numericPattern + " THEN (testLabelCol->>('' || $1))::numeric END) < $2::numeric)",
+			expectedVars:  []interface{}{"tier", "2"},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.input, func(t *testing.T) {
-			selector, err := selector.ParseFieldSelector(tc.input)
+			parse := selector.ParseFieldSelector
+			if tc.label {
+				parse = selector.ParseLabelSelector
+			}
+			selector, err := parse(tc.input)
 			if err != nil {
 				t.Fatal(err)
 			}
 			builder.selector = selector
-			actualQuery, _, err := builder.GetSelectorCond(&argCounter{0})
+			actualQuery, actualVars, err := builder.GetSelectorCond(&argCounter{0})
 			if err != nil {
 				t.Error(err)
 				return
@@ -43,6 +84,9 @@ func TestGetSelectorCond(t *testing.T) {
 			if actualQuery != tc.expectedQuery {
 				t.Errorf("expected %s, got %s", tc.expectedQuery, actualQuery)
 			}
+			if tc.expectedVars != nil && !reflect.DeepEqual(actualVars, tc.expectedVars) {
+				t.Errorf("expected vars %v, got %v", tc.expectedVars, actualVars)
+			}
 		})
 	}
 
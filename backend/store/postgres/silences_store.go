@@ -4,13 +4,15 @@ import (
 	"context"
 	"encoding/json"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	corev2 "github.com/sensu/core/v2"
 	"github.com/sensu/sensu-go/backend/store"
 )
 
 type SilenceStore struct {
-	db DBI
+	db            DBI
+	notifications *Bus
 }
 
 func NewSilenceStore(db *pgxpool.Pool) *SilenceStore {
@@ -197,7 +199,11 @@ LIMIT 1;
 
 func (s *SilenceStore) GetSilenceByName(ctx context.Context, namespace, name string) (*corev2.Silenced, error) {
 	row := s.db.QueryRow(ctx, getSilenceByNameQuery, namespace, name)
-	return readSilence(row.Scan)
+	silenced, err := readSilence(row.Scan)
+	if err == pgx.ErrNoRows {
+		return nil, &store.ErrNotFound{Key: name}
+	}
+	return silenced, err
 }
 
 const updateSilencesQuery = `
@@ -240,6 +240,10 @@ func TestSilenceStoreGetSilenceByName(t *testing.T) {
 		if !cmp.Equal(got, want) {
 			t.Errorf("silences not equal: got %v", cmp.Diff(got, want))
 		}
+		_, err = sstore.GetSilenceByName(ctx, "default", "foo:baz")
+		if _, ok := err.(*store.ErrNotFound); !ok {
+			t.Errorf("wanted ErrNotFound, but got %T (%s)", err, err)
+		}
 	})
 }
 
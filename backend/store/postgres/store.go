@@ -33,6 +33,15 @@ type StoreConfig struct {
 	WatchTxnWindow    time.Duration
 	Bus               messaging.MessageBus
 	DisableEventCache bool
+
+	// Notifications, when set, drives the configuration and entity config
+	// watchers with the postgres notifications of their changes. The
+	// WatchInterval polls then only catch up with missed notifications,
+	// every 30 seconds by default.
+	Notifications *Bus
+
+	// EventHistory enables recording every event in the event history.
+	EventHistory bool
 }
 
 func NewStore(cfg StoreConfig) *Store {
@@ -43,6 +52,8 @@ func NewStore(cfg StoreConfig) *Store {
 		maxTPS:            cfg.MaxTPS,
 		bus:               cfg.Bus,
 		disableEventCache: cfg.DisableEventCache,
+		eventHistory:      cfg.EventHistory,
+		notifications:     cfg.Notifications,
 	}
 }
 
@@ -55,6 +66,8 @@ type Store struct {
 	once              sync.Once
 	bus               messaging.MessageBus
 	disableEventCache bool
+	eventHistory      bool
+	notifications     *Bus
 }
 
 func (s *Store) GetConfigStore() storev2.ConfigStore {
@@ -62,12 +75,14 @@ func (s *Store) GetConfigStore() storev2.ConfigStore {
 		db:             s.db,
 		watchTxnWindow: s.watchTxnWindow,
 		watchInterval:  s.watchInterval,
+		notifications:  s.notifications,
 	}
 }
 
 func (s *Store) GetEntityConfigStore() storev2.EntityConfigStore {
 	return &EntityConfigStore{
-		db: s.db,
+		db:            s.db,
+		notifications: s.notifications,
 	}
 }
 
@@ -90,22 +105,32 @@ func (s *Store) GetEventStore() store.EventStore {
 		eventStore, _ := NewEventStore(s.db, sstore, Config{})
 		if s.disableEventCache {
 			s.eventStore = eventStore
-			return
+		} else {
+			cfg := memory.EventStoreConfig{
+				BackingStore:    eventStore,
+				FlushInterval:   time.Second,
+				EventWriteLimit: rate.Limit(s.maxTPS),
+				SilenceStore:    sstore,
+				Bus:             s.bus,
+			}
+			memstore := memory.NewEventStore(cfg)
+			memstore.Start(context.Background())
+			s.eventStore = memstore
 		}
-		cfg := memory.EventStoreConfig{
-			BackingStore:    eventStore,
-			FlushInterval:   time.Second,
-			EventWriteLimit: rate.Limit(s.maxTPS),
-			SilenceStore:    sstore,
-			Bus:             s.bus,
+		if s.eventHistory {
+			// Events are recorded as they are updated, as the event cache
+			// only writes the latest version of each event to postgresql.
+			// They are recorded asynchronously, in batches.
+			s.eventStore = newHistoryEventStore(context.Background(), s.eventStore, NewEventHistoryStore(s.db))
 		}
-		memstore := memory.NewEventStore(cfg)
-		memstore.Start(context.Background())
-		s.eventStore = memstore
 	})
 	return s.eventStore
 }
 
+func (s *Store) GetEventHistoryStore() store.EventHistoryStore {
+	return NewEventHistoryStore(s.db)
+}
+
 // legacy
 func (s *Store) GetEntityStore() store.EntityStore {
 	return &EntityStore{
@@ -114,7 +139,7 @@ func (s *Store) GetEntityStore() store.EntityStore {
 }
 
 func (s *Store) GetSilencesStore() storev2.SilencesStore {
-	return &SilenceStore{db: s.db}
+	return &SilenceStore{db: s.db, notifications: s.notifications}
 }
 
 const pgUniqueViolationCode = "23505"
@@ -131,6 +156,7 @@ type ConfigStore struct {
 	db             DBI
 	watchInterval  time.Duration
 	watchTxnWindow time.Duration
+	notifications  *Bus
 }
 
 type configRecord struct {
@@ -190,6 +216,9 @@ func (s *ConfigStore) Watch(ctx context.Context, req storev2.ResourceRequest) <-
 	if req.APIVersion == "" || req.Type == "" {
 		return nil
 	}
+	if s.notifications != nil {
+		return NewNotifiedWatcher(s, s.notifications, configNotifyChannel, s.watchInterval, s.watchTxnWindow).Watch(ctx, req)
+	}
 	return NewWatcher(s, s.watchInterval, s.watchTxnWindow).Watch(ctx, req)
 }
 
@@ -786,7 +815,7 @@ func storeKey(req storev2.ResourceRequest) string {
 func getSelectorSQL(ctx context.Context, apiVersion, typeName string, nargs int) (string, []interface{}, error) {
 	ctxSelector := storev2.SelectorFromContext(ctx, corev2.TypeMeta{APIVersion: apiVersion, Type: typeName})
 
-	if ctxSelector != nil && len(ctxSelector.Operations) > 0 {
+	if ctxSelector != nil && !ctxSelector.IsEmpty() {
 		argCounter := argCounter{value: nargs}
 		builder := NewConfigSelectorSQLBuilder(ctxSelector)
 		return builder.GetSelectorCond(&argCounter)
@@ -0,0 +1,144 @@
+package postgres
+
+const (
+	// configNotifyChannel is the channel of the notifications of the
+	// configuration table changes.
+	configNotifyChannel = "sensu_configuration"
+
+	// entityConfigNotifyChannel is the channel of the notifications of the
+	// entity_configs table changes.
+	entityConfigNotifyChannel = "sensu_entity_configs"
+)
+
+// watchNotifySchema notifies the watchers of the configuration and
+// entity_configs changes. The notifications are only sent when the
+// transactions commit, and carry the time they were sent at so that the
+// watchers can measure their lag.
+const watchNotifySchema = `
+CREATE OR REPLACE FUNCTION notify_configuration_change()
+RETURNS TRIGGER AS $$
+BEGIN
+	PERFORM pg_notify('sensu_configuration', json_build_object(
+		'api_version', NEW.api_version,
+		'type', NEW.api_type,
+		'namespace', NEW.namespace,
+		'name', NEW.name,
+		'sent_at', extract(epoch from clock_timestamp())
+	)::text);
+	RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER configuration_notify AFTER INSERT OR UPDATE
+	ON configuration FOR EACH ROW EXECUTE PROCEDURE
+	notify_configuration_change();
+
+CREATE OR REPLACE FUNCTION notify_entity_config_change()
+RETURNS TRIGGER AS $$
+BEGIN
+	PERFORM pg_notify('sensu_entity_configs', json_build_object(
+		'api_version', 'core/v3',
+		'type', 'EntityConfig',
+		'namespace', (SELECT name FROM namespaces WHERE id = NEW.namespace_id),
+		'name', NEW.name,
+		'sent_at', extract(epoch from clock_timestamp())
+	)::text);
+	RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER entity_configs_notify AFTER INSERT OR UPDATE
+	ON entity_configs FOR EACH ROW EXECUTE PROCEDURE
+	notify_entity_config_change();
+`
+
+const (
+	// eventNotifyChannel is the channel of the notifications of the events
+	// table changes.
+	eventNotifyChannel = "sensu_events"
+
+	// silenceNotifyChannel is the channel of the notifications of the
+	// silences table changes.
+	silenceNotifyChannel = "sensu_silences"
+)
+
+// eventSilenceNotifySchema notifies the watchers of the events and silences
+// changes. Unlike the configuration tables, these tables don't record when
+// their rows changed, so the notifications also carry the operation, and the
+// deletions of silences are notified too. The events trigger is dropped by
+// eventStreamsSchema.
+const eventSilenceNotifySchema = `
+CREATE OR REPLACE FUNCTION notify_event_change()
+RETURNS TRIGGER AS $$
+BEGIN
+	PERFORM pg_notify('sensu_events', json_build_object(
+		'api_version', 'core/v2',
+		'type', 'Event',
+		'namespace', (SELECT name FROM namespaces WHERE id = NEW.namespace),
+		'name', NEW.check_name,
+		'entity', NEW.entity_name,
+		'operation', TG_OP,
+		'sent_at', extract(epoch from clock_timestamp())
+	)::text);
+	RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER events_notify AFTER INSERT OR UPDATE
+	ON events FOR EACH ROW EXECUTE PROCEDURE
+	notify_event_change();
+
+CREATE OR REPLACE FUNCTION notify_silence_change()
+RETURNS TRIGGER AS $$
+DECLARE
+	changed silences%ROWTYPE;
+BEGIN
+	IF TG_OP = 'DELETE' THEN
+		changed := OLD;
+	ELSE
+		changed := NEW;
+	END IF;
+	PERFORM pg_notify('sensu_silences', json_build_object(
+		'api_version', 'core/v2',
+		'type', 'Silenced',
+		'namespace', (SELECT name FROM namespaces WHERE id = changed.namespace),
+		'name', changed.name,
+		'operation', TG_OP,
+		'sent_at', extract(epoch from clock_timestamp())
+	)::text);
+	RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER silences_notify AFTER INSERT OR UPDATE OR DELETE
+	ON silences FOR EACH ROW EXECUTE PROCEDURE
+	notify_silence_change();
+`
+
+// eventStreamsSchema replaces the events trigger, which notified every event
+// write even when no backend streamed the events, with the leases of the
+// backends that stream them. The events are notified by the EventNotifier of
+// every backend, only while a lease is held.
+const eventStreamsSchema = `
+DROP TRIGGER IF EXISTS events_notify ON events;
+DROP FUNCTION IF EXISTS notify_event_change();
+
+CREATE TABLE IF NOT EXISTS event_streams (
+	id                  text          PRIMARY KEY,
+	expires_at          timestamptz   NOT NULL
+);
+`
+
+const holdEventStreamLeaseQuery = `
+INSERT INTO event_streams ( id, expires_at )
+VALUES ( $1, now() + make_interval(secs => $2) )
+ON CONFLICT ( id ) DO UPDATE SET expires_at = excluded.expires_at;
+`
+
+const deleteEventStreamLeaseQuery = `
+DELETE FROM event_streams WHERE id = $1 OR expires_at < now();
+`
+
+const hasEventStreamsQuery = `
+SELECT EXISTS ( SELECT 1 FROM event_streams WHERE expires_at > now() );
+`
@@ -2,9 +2,13 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math"
+	"sync"
 	"time"
 
+	"github.com/lib/pq"
 	v3 "github.com/sensu/core/v3"
 	"github.com/sensu/sensu-go/backend/poll"
 	storev2 "github.com/sensu/sensu-go/backend/store/v2"
@@ -15,10 +19,16 @@ type WatchableStore interface {
 	GetPoller(request storev2.ResourceRequest) (poll.Table, error)
 }
 
+// defaultWatchFallbackInterval is the interval of the polls of the watchers
+// driven by notifications, which only catch up with missed notifications.
+const defaultWatchFallbackInterval = 30 * time.Second
+
 type Watcher struct {
 	store          WatchableStore
 	watchInterval  time.Duration
 	watchTxnWindow time.Duration
+	bus            *Bus
+	channel        string
 }
 
 func NewWatcher(store WatchableStore, watchInterval time.Duration, watchTxnWindow time.Duration) *Watcher {
@@ -29,6 +39,23 @@ func NewWatcher(store WatchableStore, watchInterval time.Duration, watchTxnWindo
 	}
 }
 
+// NewNotifiedWatcher creates a watcher that polls as soon as it is notified of
+// changes on the bus channel. The watch interval, if not set, defaults to
+// defaultWatchFallbackInterval, as the polls only catch up with the missed
+// notifications, for instance while the listener reconnects.
+func NewNotifiedWatcher(store WatchableStore, bus *Bus, channel string, watchInterval time.Duration, watchTxnWindow time.Duration) *Watcher {
+	if watchInterval <= 0 {
+		watchInterval = defaultWatchFallbackInterval
+	}
+	return &Watcher{
+		store:          store,
+		watchInterval:  watchInterval,
+		watchTxnWindow: watchTxnWindow,
+		bus:            bus,
+		channel:        channel,
+	}
+}
+
 func (w *Watcher) Watch(ctx context.Context, req storev2.ResourceRequest) <-chan []storev2.WatchEvent {
 	eventChan := make(chan []storev2.WatchEvent, 32)
 
@@ -50,6 +77,20 @@ func (w *Watcher) Watch(ctx context.Context, req storev2.ResourceRequest) <-chan
 		Table:     table,
 	}
 
+	var notifier *watchNotifier
+	if w.bus != nil {
+		// Subscribe before the poller initialization, so that no change
+		// is missed in between
+		notifications, err := w.bus.Subscribe(ctx, "", w.channel)
+		if err != nil {
+			logger.WithError(err).Error("watcher failed to listen for notifications, falling back to polling")
+		} else {
+			notifier = newWatchNotifier(req)
+			poller.Notify = notifier.wake
+			go notifier.run(ctx, w.bus, w.channel, notifications)
+		}
+	}
+
 	backoff := retry.ExponentialBackoff{
 		Ctx: ctx,
 	}
@@ -66,11 +107,11 @@ func (w *Watcher) Watch(ctx context.Context, req storev2.ResourceRequest) <-chan
 		return eventChan
 	}
 
-	go w.watchLoop(ctx, req, poller, eventChan)
+	go w.watchLoop(ctx, req, poller, notifier, eventChan)
 	return eventChan
 }
 
-func (w *Watcher) watchLoop(ctx context.Context, req storev2.ResourceRequest, poller *poll.Poller, watchChan chan []storev2.WatchEvent) {
+func (w *Watcher) watchLoop(ctx context.Context, req storev2.ResourceRequest, poller *poll.Poller, notifier *watchNotifier, watchChan chan []storev2.WatchEvent) {
 	defer close(watchChan)
 	for {
 		changes, err := poller.Next(ctx)
@@ -80,6 +121,10 @@ func (w *Watcher) watchLoop(ctx context.Context, req storev2.ResourceRequest, po
 			}
 			logger.Error(err)
 		}
+		var sentAt time.Time
+		if notifier != nil {
+			sentAt = notifier.take()
+		}
 		if len(changes) == 0 {
 			continue
 		}
@@ -128,5 +173,126 @@ func (w *Watcher) watchLoop(ctx context.Context, req storev2.ResourceRequest, po
 			req.Namespace,
 			storev2.WatcherProviderPG,
 		).Add(float64(len(notifications)))
+		if status == storev2.WatchEventsStatusHandled && !sentAt.IsZero() {
+			lag := time.Since(sentAt)
+			if lag < 0 {
+				// the clocks of postgres and sensu-backend differ
+				lag = 0
+			}
+			storev2.WatchNotificationLag.WithLabelValues(
+				req.StoreName,
+				storev2.WatcherProviderPG,
+			).Observe(lag.Seconds())
+		}
+	}
+}
+
+// watchNotification is the payload of the notifications of the watched
+// tables changes. See watchNotifySchema.
+type watchNotification struct {
+	APIVersion string  `json:"api_version"`
+	Type       string  `json:"type"`
+	Namespace  string  `json:"namespace"`
+	Name       string  `json:"name"`
+	Entity     string  `json:"entity,omitempty"`
+	Operation  string  `json:"operation,omitempty"`
+	SentAt     float64 `json:"sent_at"`
+
+	// Event is the notified event, unless it's too large to be notified.
+	Event json.RawMessage `json:"event,omitempty"`
+}
+
+// matches returns whether the notification is about a resource of the
+// request.
+func (n *watchNotification) matches(req storev2.ResourceRequest) bool {
+	if n.APIVersion != req.APIVersion || n.Type != req.Type {
+		return false
+	}
+	if req.Namespace != "" && n.Namespace != req.Namespace {
+		return false
+	}
+	if req.Name != "" && n.Name != req.Name {
+		return false
 	}
+	return true
+}
+
+// watchNotifier wakes a poller up when it receives notifications about the
+// resources of its request, and keeps track of the oldest pending
+// notification to measure the watch lag.
+type watchNotifier struct {
+	req    storev2.ResourceRequest
+	wake   chan struct{}
+	mu     sync.Mutex
+	sentAt time.Time
+}
+
+func newWatchNotifier(req storev2.ResourceRequest) *watchNotifier {
+	return &watchNotifier{
+		req:  req,
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// run wakes the poller up as notifications are received. If the bus drops
+// the subscription, as the notifier fell behind, it subscribes again and wakes
+// the poller up, so that it catches up with the dropped notifications. It only
+// falls back to the polling interval if it can't subscribe again.
+func (n *watchNotifier) run(ctx context.Context, bus *Bus, channel string, notifications <-chan *pq.Notification) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case notification, ok := <-notifications:
+			if !ok {
+				var err error
+				notifications, err = bus.Subscribe(ctx, "", channel)
+				if err != nil {
+					logger.WithError(err).Error("watcher failed to listen for notifications again, falling back to polling")
+					return
+				}
+				n.wakeUp()
+				continue
+			}
+			n.notify(notification)
+		}
+	}
+}
+
+func (n *watchNotifier) notify(notification *pq.Notification) {
+	var payload watchNotification
+	if err := json.Unmarshal([]byte(notification.Extra), &payload); err != nil {
+		logger.WithError(err).Error("invalid watch notification")
+		return
+	}
+	if !payload.matches(n.req) {
+		return
+	}
+	sec, frac := math.Modf(payload.SentAt)
+	sentAt := time.Unix(int64(sec), int64(frac*1e9))
+	n.mu.Lock()
+	if n.sentAt.IsZero() || sentAt.Before(n.sentAt) {
+		n.sentAt = sentAt
+	}
+	n.mu.Unlock()
+	n.wakeUp()
+}
+
+// wakeUp wakes the poller up. The poller is woken up once for any number of
+// pending notifications.
+func (n *watchNotifier) wakeUp() {
+	select {
+	case n.wake <- struct{}{}:
+	default:
+	}
+}
+
+// take returns the time the oldest pending notification was sent at, or the
+// zero time if there are none, and forgets it.
+func (n *watchNotifier) take() time.Time {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	sentAt := n.sentAt
+	n.sentAt = time.Time{}
+	return sentAt
 }
@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lib/pq"
+	corev2 "github.com/sensu/core/v2"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchNotificationMatches(t *testing.T) {
+	notification := &watchNotification{
+		APIVersion: "core/v2",
+		Type:       "CheckConfig",
+		Namespace:  "default",
+		Name:       "check",
+	}
+	tests := []struct {
+		name string
+		req  storev2.ResourceRequest
+		want bool
+	}{
+		{
+			name: "all namespaces",
+			req:  storev2.ResourceRequest{APIVersion: "core/v2", Type: "CheckConfig"},
+			want: true,
+		},
+		{
+			name: "namespace and name",
+			req:  storev2.ResourceRequest{APIVersion: "core/v2", Type: "CheckConfig", Namespace: "default", Name: "check"},
+			want: true,
+		},
+		{
+			name: "other type",
+			req:  storev2.ResourceRequest{APIVersion: "core/v2", Type: "Handler"},
+			want: false,
+		},
+		{
+			name: "other namespace",
+			req:  storev2.ResourceRequest{APIVersion: "core/v2", Type: "CheckConfig", Namespace: "dev"},
+			want: false,
+		},
+		{
+			name: "other name",
+			req:  storev2.ResourceRequest{APIVersion: "core/v2", Type: "CheckConfig", Name: "other"},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, notification.matches(tt.req))
+		})
+	}
+}
+
+func TestWatchNotifier(t *testing.T) {
+	notifier := newWatchNotifier(storev2.ResourceRequest{APIVersion: "core/v3", Type: "EntityConfig", Namespace: "default"})
+
+	// notifications about other resources are ignored
+	notifier.notify(&pq.Notification{Extra: `{"api_version":"core/v3","type":"EntityConfig","namespace":"dev","name":"foo","sent_at":1}`})
+	assert.Len(t, notifier.wake, 0)
+	assert.True(t, notifier.take().IsZero())
+
+	notifier.notify(&pq.Notification{Extra: `{"api_version":"core/v3","type":"EntityConfig","namespace":"default","name":"foo","sent_at":20.5}`})
+	notifier.notify(&pq.Notification{Extra: `{"api_version":"core/v3","type":"EntityConfig","namespace":"default","name":"bar","sent_at":10.5}`})
+	assert.Len(t, notifier.wake, 1)
+	assert.Equal(t, time.Unix(10, 5e8), notifier.take())
+	assert.True(t, notifier.take().IsZero())
+}
+
+func TestWatchNotifierSubscribesAgain(t *testing.T) {
+	listener := new(mockListener)
+	listener.On("Listen", configNotifyChannel).Return(nil)
+	listener.On("NotificationChannel").Return(make(chan *pq.Notification))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bus := NewBus(ctx, listener)
+	notifier := newWatchNotifier(storev2.ResourceRequest{APIVersion: "core/v2", Type: "Asset"})
+
+	// the subscription is dropped by the bus
+	notifications := make(chan *pq.Notification)
+	close(notifications)
+	go notifier.run(ctx, bus, configNotifyChannel, notifications)
+
+	// the poller is woken up to catch up, and the notifier subscribes again
+	select {
+	case <-notifier.wake:
+	case <-time.After(5 * time.Second):
+		t.Fatal("poller not woken up")
+	}
+	assert.Eventually(t, func() bool {
+		return len(bus.getSubscriptionSet(configNotifyChannel).Channels()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestConfigStore_NotifiedWatch(t *testing.T) {
+	withPostgres(t, func(ctx context.Context, db *pgxpool.Pool, dsn string) {
+		listener := pq.NewListener(dsn, time.Second, time.Minute, nil)
+		defer listener.Close()
+		s := &ConfigStore{
+			db:             db,
+			watchInterval:  time.Hour,
+			watchTxnWindow: time.Second,
+			notifications:  NewBus(ctx, listener),
+		}
+
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		watchChannel := s.Watch(watchCtx, storev2.ResourceRequest{
+			APIVersion: "core/v2",
+			Type:       "Asset",
+		})
+
+		// The watch event is only received before the one hour polling
+		// interval if the watcher is notified
+		asset := corev2.FixtureAsset("my-asset")
+		if err := createOrUpdateAsset(ctx, s, asset); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case watchEvents, ok := <-watchChannel:
+			if !ok {
+				t.Fatal("watcher closed unexpectedly")
+			}
+			if len(watchEvents) != 1 {
+				t.Fatal("expected 1 watch event")
+			}
+			assert.Equal(t, storev2.WatchCreate, watchEvents[0].Type)
+		case <-time.After(5 * time.Second):
+			t.Fatal("no watch event received before timeout")
+		}
+	})
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"fmt"
+	"time"
 
 	corev2 "github.com/sensu/core/v2"
 	corev3 "github.com/sensu/core/v3"
@@ -422,6 +423,29 @@ type EventStore interface {
 	EventStoreSupportsFiltering(ctx context.Context) bool
 }
 
+// EventHistoryStore provides methods for retrieving the past events of an
+// entity and check pair.
+type EventHistoryStore interface {
+	// GetEventHistory returns the recorded events for the given entity and
+	// check, within the namespace stored in ctx, most recent first. A nil slice
+	// with no error is returned if none were found.
+	GetEventHistory(ctx context.Context, entity, check string, pred *EventHistoryPredicate) ([]*corev2.Event, error)
+}
+
+// EventHistoryPredicate selects a time range and a page of event history.
+type EventHistoryPredicate struct {
+	// Start selects events with a timestamp at or after it, if not zero.
+	Start time.Time
+	// End selects events with a timestamp before it, if not zero.
+	End time.Time
+	// Limit indicates the number of events to retrieve
+	Limit int64
+	// Continue provides the position from which the selection should start.
+	// If returned empty from the store, it indicates that there's no
+	// additional events available.
+	Continue string
+}
+
 // EventFilterStore provides methods for managing events filters
 type EventFilterStore interface {
 	// DeleteEventFilterByName deletes an event filter using the given name and the
@@ -18,6 +18,7 @@ type Interface interface {
 	EventStoreGetter
 	EntityStoreGetter
 	SilencesStoreGetter
+	EventHistoryStoreGetter
 }
 
 // Wrapper is an abstraction of a store wrapper.
@@ -68,6 +69,11 @@ type SilencesStoreGetter interface {
 	GetSilencesStore() SilencesStore
 }
 
+// EventHistoryStoreGetter gets you an EventHistoryStore.
+type EventHistoryStoreGetter interface {
+	GetEventHistoryStore() store.EventHistoryStore
+}
+
 // ConfigStore specifies the interface of a v2 store.
 type ConfigStore interface {
 	// CreateOrUpdate creates or updates the wrapped resource.
@@ -265,4 +271,8 @@ type SilencesStore interface {
 
 	// DeleteSilences deletes one or more named silences
 	DeleteSilences(ctx context.Context, namespace string, names []string) error
+
+	// Watch creates a watcher for the silences of the namespace given, or of
+	// every namespace if it is blank.
+	Watch(ctx context.Context, namespace string) <-chan []WatchEvent
 }
@@ -121,6 +121,8 @@ const (
 	WatcherProvider     = "provider"
 	WatcherProviderPG   = "postgres"
 	WatcherProviderEtcd = "etcd"
+
+	WatchNotificationLagHistogramVec = "sensu_go_watch_notification_lag_seconds"
 )
 
 var (
@@ -131,10 +133,22 @@ var (
 		},
 		[]string{WatchEventsLabelStatus, WatchEventsLabelResourceType, WatchEventsLabelNamespace, WatcherProvider},
 	)
+
+	WatchNotificationLag = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    WatchNotificationLagHistogramVec,
+			Help:    "The time between store changes and the delivery of their watch notifications",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		},
+		[]string{WatchEventsLabelResourceType, WatcherProvider},
+	)
 )
 
 func init() {
 	if err := prometheus.Register(WatchEventsProcessed); err != nil {
 		panic(err)
 	}
+	if err := prometheus.Register(WatchNotificationLag); err != nil {
+		panic(err)
+	}
 }
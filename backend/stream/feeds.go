@@ -0,0 +1,125 @@
+package stream
+
+import (
+	"context"
+
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+// NamespaceWatcher watches the resources of a namespace, or of every
+// namespace if it's empty.
+type NamespaceWatcher interface {
+	Watch(ctx context.Context, namespace string) <-chan []storev2.WatchEvent
+}
+
+// NewFeeds returns the feeds of every kind of streamed resource. The events
+// come from the events watcher, which sees the events of every backend, and
+// the other resources from the watchers of the store.
+func NewFeeds(events NamespaceWatcher, store storev2.Interface) map[Kind]Feed {
+	return map[Kind]Feed{
+		KindEvents:   EventFeed(events),
+		KindEntities: EntityConfigFeed(store),
+		KindChecks:   WatchFeed[*corev2.CheckConfig](store),
+		KindSilenced: SilencedFeed(store),
+	}
+}
+
+// EventFeed sends the events of every namespace once they are stored.
+func EventFeed(events NamespaceWatcher) Feed {
+	return func(ctx context.Context, out chan<- Message) {
+		watch := func() <-chan []storev2.WatchEvent {
+			return events.Watch(ctx, "")
+		}
+		forwardWatch[*corev2.Event](ctx, watch, out)
+	}
+}
+
+// EntityConfigFeed sends the changes of the entity configs of every
+// namespace.
+func EntityConfigFeed(store storev2.Interface) Feed {
+	return func(ctx context.Context, out chan<- Message) {
+		watch := func() <-chan []storev2.WatchEvent {
+			return store.GetEntityConfigStore().Watch(ctx, "", "")
+		}
+		forwardWatch[*corev3.EntityConfig](ctx, watch, out)
+	}
+}
+
+// WatchFeed sends the changes of the configuration store resources of type R
+// in every namespace.
+func WatchFeed[R storev2.Resource[T], T any](store storev2.Interface) Feed {
+	return func(ctx context.Context, out chan<- Message) {
+		var resource R = new(T)
+		req := storev2.NewResourceRequestFromResource(resource)
+		req.Namespace = ""
+		watch := func() <-chan []storev2.WatchEvent {
+			return store.GetConfigStore().Watch(ctx, req)
+		}
+		forwardWatch[R](ctx, watch, out)
+	}
+}
+
+// forwardWatch sends the events of a store watcher, and restarts the watcher
+// if it stops before the context is canceled.
+func forwardWatch[R storev2.Resource[T], T any](ctx context.Context, watch func() <-chan []storev2.WatchEvent, out chan<- Message) {
+	watchChan := watch()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case events, ok := <-watchChan:
+			if !ok {
+				if ctx.Err() != nil {
+					return
+				}
+				logger.Info("restarting stream watcher")
+				watchChan = watch()
+				continue
+			}
+			for _, event := range events {
+				value, err := storev2.ReadEventValue[R](event)
+				if err != nil {
+					logger.WithError(err).Error("couldn't read stream watch event")
+					continue
+				}
+				if value == nil {
+					continue
+				}
+				resource, ok := any(value).(corev3.Resource)
+				if !ok {
+					continue
+				}
+				send(ctx, out, Message{Action: watchAction(event.Type), Resource: resource})
+			}
+		}
+	}
+}
+
+func watchAction(t storev2.WatchActionType) string {
+	switch t {
+	case storev2.WatchCreate:
+		return ActionCreate
+	case storev2.WatchDelete:
+		return ActionDelete
+	}
+	return ActionUpdate
+}
+
+// SilencedFeed sends the changes of the silences of every namespace.
+func SilencedFeed(store storev2.Interface) Feed {
+	return func(ctx context.Context, out chan<- Message) {
+		watch := func() <-chan []storev2.WatchEvent {
+			return store.GetSilencesStore().Watch(ctx, "")
+		}
+		forwardWatch[*corev2.Silenced](ctx, watch, out)
+	}
+}
+
+func send(ctx context.Context, out chan<- Message, msg Message) {
+	select {
+	case out <- msg:
+	case <-ctx.Done():
+	}
+}
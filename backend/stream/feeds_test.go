@@ -0,0 +1,81 @@
+package stream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	"github.com/sensu/sensu-go/testing/mockstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// testWatcher is a NamespaceWatcher that returns its watch channels in
+// order.
+type testWatcher struct {
+	watches chan chan []storev2.WatchEvent
+}
+
+func (w *testWatcher) Watch(ctx context.Context, namespace string) <-chan []storev2.WatchEvent {
+	return <-w.watches
+}
+
+func feedMessage(t *testing.T, out <-chan Message) Message {
+	t.Helper()
+	select {
+	case msg := <-out:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a message")
+	}
+	return Message{}
+}
+
+func TestEventFeed(t *testing.T) {
+	watcher := &testWatcher{watches: make(chan chan []storev2.WatchEvent, 2)}
+	first, second := make(chan []storev2.WatchEvent, 1), make(chan []storev2.WatchEvent, 1)
+	watcher.watches <- first
+	watcher.watches <- second
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := make(chan Message)
+	go EventFeed(watcher)(ctx, out)
+
+	event := corev2.FixtureEvent("entity", "check")
+	first <- []storev2.WatchEvent{{Type: storev2.WatchUpdate, Value: mockstore.Wrapper[*corev2.Event]{Value: event}}}
+	msg := feedMessage(t, out)
+	assert.Equal(t, ActionUpdate, msg.Action)
+	assert.Equal(t, event, msg.Resource)
+
+	// the watcher is restarted when it stops
+	close(first)
+	second <- []storev2.WatchEvent{{Type: storev2.WatchUpdate, Value: mockstore.Wrapper[*corev2.Event]{Value: event}}}
+	assert.Equal(t, event, feedMessage(t, out).Resource)
+}
+
+func TestSilencedFeed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	watchChan := make(chan []storev2.WatchEvent, 1)
+	silences := new(mockstore.SilencesStore)
+	silences.On("Watch", mock.Anything, "").Return((<-chan []storev2.WatchEvent)(watchChan))
+	store := new(mockstore.V2MockStore)
+	store.On("GetSilencesStore").Return(silences)
+
+	out := make(chan Message)
+	go SilencedFeed(store)(ctx, out)
+
+	silenced := corev2.FixtureSilenced("sub:check")
+	watchChan <- []storev2.WatchEvent{
+		{Type: storev2.WatchCreate, Value: mockstore.Wrapper[*corev2.Silenced]{Value: silenced}},
+		{Type: storev2.WatchDelete, Value: mockstore.Wrapper[*corev2.Silenced]{Value: silenced}},
+	}
+	msg := feedMessage(t, out)
+	assert.Equal(t, ActionCreate, msg.Action)
+	assert.Equal(t, silenced, msg.Resource)
+	assert.Equal(t, ActionDelete, feedMessage(t, out).Action)
+}
@@ -0,0 +1,222 @@
+// Package stream fans out the changes made to events, entities, checks and
+// silences to the clients that follow them, so they don't have to poll the
+// API. A single feed per kind of resource is shared by every subscriber, and
+// it only runs while it has subscribers.
+package stream
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ActionCreate is the action of a message about a created resource.
+	ActionCreate = "create"
+
+	// ActionUpdate is the action of a message about an updated resource, or
+	// about an event processed by the backend.
+	ActionUpdate = "update"
+
+	// ActionDelete is the action of a message about a deleted resource.
+	ActionDelete = "delete"
+
+	// subscriptionBuffer is the number of messages buffered for each
+	// subscriber. A subscriber that falls further behind is closed.
+	subscriptionBuffer = 256
+)
+
+var logger = logrus.WithFields(logrus.Fields{
+	"component": "stream",
+})
+
+// Kind is a kind of streamed resource, named after its RBAC resource.
+type Kind string
+
+const (
+	// KindEvents streams the events processed by the backends.
+	KindEvents Kind = "events"
+
+	// KindEntities streams the changes of the entity configs.
+	KindEntities Kind = "entities"
+
+	// KindChecks streams the changes of the check configs.
+	KindChecks Kind = "checks"
+
+	// KindSilenced streams the changes of the silences.
+	KindSilenced Kind = "silenced"
+)
+
+// Message is a change of a streamed resource.
+type Message struct {
+	// Action is one of create, update or delete.
+	Action string
+
+	// Resource is the resource after the change, or before it was deleted.
+	Resource corev3.Resource
+}
+
+// Namespace returns the namespace of the resource.
+func (m Message) Namespace() string {
+	if meta := m.Resource.GetMetadata(); meta != nil {
+		return meta.Namespace
+	}
+	return ""
+}
+
+// Name returns the RBAC name of the resource. The name of an event is made of
+// the names of its entity and check.
+func (m Message) Name() string {
+	if event, ok := m.Resource.(*corev2.Event); ok && event.Entity != nil {
+		if event.HasCheck() {
+			return path.Join(event.Entity.Name, event.Check.Name)
+		}
+		return event.Entity.Name
+	}
+	if meta := m.Resource.GetMetadata(); meta != nil {
+		return meta.Name
+	}
+	return ""
+}
+
+// Fields returns the fields of the resource matched by field selectors.
+func (m Message) Fields() map[string]string {
+	if fielder, ok := m.Resource.(interface{ Fields() map[string]string }); ok {
+		return fielder.Fields()
+	}
+	return map[string]string{}
+}
+
+// Labels returns the labels matched by label selectors. The labels of an
+// event include the labels of its entity and check.
+func Labels(fields map[string]string) map[string]string {
+	labels := make(map[string]string)
+	for key, value := range fields {
+		if i := strings.Index(key, ".labels."); i >= 0 {
+			labels[key[i+len(".labels."):]] = value
+		}
+	}
+	return labels
+}
+
+// A Feed sends the changes of a kind of resource to out, until the context is
+// canceled.
+type Feed func(ctx context.Context, out chan<- Message)
+
+// Hub fans out the messages of the feeds to their subscribers.
+type Hub struct {
+	feeds map[Kind]Feed
+
+	mu      sync.Mutex
+	running map[Kind]*runningFeed
+}
+
+type runningFeed struct {
+	cancel      context.CancelFunc
+	subscribers map[*Subscription]struct{}
+}
+
+// NewHub returns a hub for the given feeds.
+func NewHub(feeds map[Kind]Feed) *Hub {
+	return &Hub{
+		feeds:   feeds,
+		running: make(map[Kind]*runningFeed),
+	}
+}
+
+// Subscription receives the messages of a feed.
+type Subscription struct {
+	// C receives the messages. It is closed when the subscription is canceled,
+	// or when the subscriber falls too far behind.
+	C <-chan Message
+
+	ch     chan Message
+	kind   Kind
+	hub    *Hub
+	closed bool
+}
+
+// Subscribe subscribes to the messages of a kind of resource. The feed of
+// that kind is started if it isn't running yet.
+func (h *Hub) Subscribe(kind Kind) (*Subscription, error) {
+	feed, ok := h.feeds[kind]
+	if !ok {
+		return nil, fmt.Errorf("can't stream %s", kind)
+	}
+	ch := make(chan Message, subscriptionBuffer)
+	sub := &Subscription{C: ch, ch: ch, kind: kind, hub: h}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	running, ok := h.running[kind]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		running = &runningFeed{cancel: cancel, subscribers: make(map[*Subscription]struct{})}
+		h.running[kind] = running
+		out := make(chan Message, subscriptionBuffer)
+		go feed(ctx, out)
+		go h.broadcast(ctx, kind, running, out)
+		logger.WithField("kind", kind).Debug("started stream feed")
+	}
+	running.subscribers[sub] = struct{}{}
+	return sub, nil
+}
+
+// Cancel cancels the subscription. The feed is stopped once it has no
+// subscribers left.
+func (s *Subscription) Cancel() {
+	h := s.hub
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s.close()
+	running, ok := h.running[s.kind]
+	if !ok {
+		return
+	}
+	delete(running.subscribers, s)
+	if len(running.subscribers) == 0 {
+		running.cancel()
+		delete(h.running, s.kind)
+		logger.WithField("kind", s.kind).Debug("stopped stream feed")
+	}
+}
+
+// close closes the channel of the subscription. It must be called with the
+// lock of the hub held.
+func (s *Subscription) close() {
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
+
+// broadcast sends the messages of a feed to its subscribers. The subscribers
+// whose buffer is full are closed rather than blocking the others.
+func (h *Hub) broadcast(ctx context.Context, kind Kind, running *runningFeed, in <-chan Message) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-in:
+			h.mu.Lock()
+			for sub := range running.subscribers {
+				if sub.closed {
+					continue
+				}
+				select {
+				case sub.ch <- msg:
+				default:
+					logger.WithField("kind", kind).Warning("closing lagging stream subscriber")
+					sub.close()
+				}
+			}
+			h.mu.Unlock()
+		}
+	}
+}
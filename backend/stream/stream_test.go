@@ -0,0 +1,137 @@
+package stream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testFeed is a feed that forwards the messages of its input, and reports
+// whether it is running.
+type testFeed struct {
+	in      chan Message
+	started chan struct{}
+	stopped chan struct{}
+}
+
+func newTestFeed() *testFeed {
+	return &testFeed{
+		in:      make(chan Message),
+		started: make(chan struct{}, 1),
+		stopped: make(chan struct{}, 1),
+	}
+}
+
+func (f *testFeed) feed(ctx context.Context, out chan<- Message) {
+	f.started <- struct{}{}
+	defer func() { f.stopped <- struct{}{} }()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-f.in:
+			send(ctx, out, msg)
+		}
+	}
+}
+
+func receive(t *testing.T, sub *Subscription) (Message, bool) {
+	t.Helper()
+	select {
+	case msg, ok := <-sub.C:
+		return msg, ok
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a message")
+	}
+	return Message{}, false
+}
+
+func wait(t *testing.T, ch <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the feed")
+	}
+}
+
+func TestHubFanOut(t *testing.T) {
+	feed := newTestFeed()
+	hub := NewHub(map[Kind]Feed{KindChecks: feed.feed})
+
+	_, err := hub.Subscribe(KindEvents)
+	assert.Error(t, err)
+
+	first, err := hub.Subscribe(KindChecks)
+	require.NoError(t, err)
+	wait(t, feed.started)
+	second, err := hub.Subscribe(KindChecks)
+	require.NoError(t, err)
+
+	check := corev2.FixtureCheckConfig("check-cpu")
+	feed.in <- Message{Action: ActionCreate, Resource: check}
+	for _, sub := range []*Subscription{first, second} {
+		msg, ok := receive(t, sub)
+		require.True(t, ok)
+		assert.Equal(t, ActionCreate, msg.Action)
+		assert.Equal(t, "check-cpu", msg.Name())
+	}
+
+	// The feed keeps running until its last subscriber is canceled
+	first.Cancel()
+	_, ok := receive(t, first)
+	assert.False(t, ok)
+	select {
+	case <-feed.stopped:
+		t.Fatal("the feed stopped with a subscriber left")
+	default:
+	}
+	second.Cancel()
+	wait(t, feed.stopped)
+
+	// The feed is started again by the next subscriber
+	third, err := hub.Subscribe(KindChecks)
+	require.NoError(t, err)
+	defer third.Cancel()
+	wait(t, feed.started)
+}
+
+func TestHubClosesLaggingSubscribers(t *testing.T) {
+	feed := newTestFeed()
+	hub := NewHub(map[Kind]Feed{KindChecks: feed.feed})
+	lagging, err := hub.Subscribe(KindChecks)
+	require.NoError(t, err)
+	defer lagging.Cancel()
+
+	check := corev2.FixtureCheckConfig("check-cpu")
+	for i := 0; i <= subscriptionBuffer; i++ {
+		feed.in <- Message{Action: ActionUpdate, Resource: check}
+	}
+	assert.Eventually(t, func() bool {
+		hub.mu.Lock()
+		defer hub.mu.Unlock()
+		return lagging.closed
+	}, 5*time.Second, 10*time.Millisecond)
+	for i := 0; i < subscriptionBuffer; i++ {
+		_, ok := receive(t, lagging)
+		require.True(t, ok)
+	}
+	_, ok := receive(t, lagging)
+	assert.False(t, ok)
+}
+
+func TestMessage(t *testing.T) {
+	event := corev2.FixtureEvent("entity1", "check1")
+	event.Entity.Labels = map[string]string{"region": "us-west-1"}
+	msg := Message{Action: ActionUpdate, Resource: event}
+	assert.Equal(t, "default", msg.Namespace())
+	assert.Equal(t, "entity1/check1", msg.Name())
+	assert.Equal(t, "us-west-1", Labels(msg.Fields())["region"])
+
+	msg = Message{Action: ActionDelete, Resource: corev2.FixtureSilenced("linux:check1")}
+	assert.Equal(t, "linux:check1", msg.Name())
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"encoding/json"
+	"net/url"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/audit"
+)
+
+// AuditPath is the api path for the audit log.
+var AuditPath = createNSBasePath(coreAPIGroup, "v3", "audit")
+
+// ListAuditRecords lists the audit records of a namespace, or of every
+// namespace if it is empty, filtered by the given query parameters.
+func (client *RestClient) ListAuditRecords(namespace string, query url.Values, options *ListOptions) ([]*audit.Record, error) {
+	records := []*audit.Record{}
+	for {
+		request := client.R().SetQueryParamsFromValues(query)
+		ApplyListOptions(request, options)
+
+		res, err := request.Get(AuditPath(namespace))
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode() >= 400 {
+			return nil, UnmarshalError(res)
+		}
+
+		var page []*audit.Record
+		if err := json.Unmarshal(res.Body(), &page); err != nil {
+			return nil, err
+		}
+		records = append(records, page...)
+
+		options.ContinueToken = res.Header().Get(corev2.PaginationContinueHeader)
+		if options.ContinueToken == "" {
+			return records, nil
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/go-resty/resty/v2"
 	jwt "github.com/golang-jwt/jwt/v4"
 	corev2 "github.com/sensu/core/v2"
+	authenticationv2 "github.com/sensu/sensu-go/backend/authentication/v2"
 )
 
 // CreateAccessToken returns a new access token given userid and password
@@ -34,6 +35,33 @@ func (client *RestClient) CreateAccessToken(url, userid, password string) (*core
 	return tokens, err
 }
 
+// CreateOIDCAccessToken returns a new access token given an authorization
+// code issued by the identity provider of an OIDC provider
+func (client *RestClient) CreateOIDCAccessToken(url string, req *authenticationv2.OIDCTokenRequest) (*corev2.Tokens, error) {
+	// Make sure any existing auth token doesn't get injected instead
+	client.ClearAuthToken()
+	defer client.Reset()
+
+	res, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(req).
+		Post(url + "/auth/oidc/token")
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode() >= 400 {
+		return nil, errors.New(string(res.Body()))
+	}
+
+	tokens := &corev2.Tokens{}
+	if err = json.Unmarshal(res.Body(), tokens); err != nil {
+		return nil, fmt.Errorf("could not unmarshal response from server: %s", err)
+	}
+
+	return tokens, nil
+}
+
 // TestCreds checks if the provided User credentials are valid
 func (client *RestClient) TestCreds(userid, password string) error {
 	client.ClearAuthToken()
@@ -0,0 +1,38 @@
+package client
+
+import (
+	pipelinev1 "github.com/sensu/sensu-go/backend/pipeline/v1"
+)
+
+// DeadLettersPath is the api path for the dead letters of the handlers.
+var DeadLettersPath = createNSBasePath("pipeline", "v1", "dead-letters")
+
+// ListDeadLetters lists the dead letters of a namespace, or of every
+// namespace if it is empty.
+func (client *RestClient) ListDeadLetters(namespace string, options *ListOptions) ([]pipelinev1.DeadLetter, error) {
+	letters := []pipelinev1.DeadLetter{}
+	if err := client.List(DeadLettersPath(namespace), &letters, options, nil); err != nil {
+		return nil, err
+	}
+	return letters, nil
+}
+
+// ReplayDeadLetter queues a dead letter for a retry of its handler. The dead
+// letter is deleted.
+func (client *RestClient) ReplayDeadLetter(namespace, name string) error {
+	res, err := client.R().Post(DeadLettersPath(namespace, name, "replay"))
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode() >= 400 {
+		return UnmarshalError(res)
+	}
+
+	return nil
+}
+
+// DeleteDeadLetter deletes a dead letter without replaying it.
+func (client *RestClient) DeleteDeadLetter(namespace, name string) error {
+	return client.Delete(DeadLettersPath(namespace, name))
+}
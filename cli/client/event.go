@@ -1,7 +1,13 @@
 package client
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	corev2 "github.com/sensu/core/v2"
@@ -11,6 +17,9 @@ import (
 // EventsPath is the api path for events.
 var EventsPath = createNSBasePath(coreAPIGroup, coreAPIVersion, "events")
 
+// EventStreamPath is the api path for the stream of events.
+var EventStreamPath = createNSBasePath(coreAPIGroup, "v3", "events", "stream")
+
 // FetchEvent fetches a specific event
 func (client *RestClient) FetchEvent(entity, check string) (*corev2.Event, error) {
 	path := EventsPath(client.config.Namespace(), entity, check)
@@ -61,3 +70,83 @@ func (client *RestClient) ResolveEvent(event *corev2.Event) error {
 	event.Timestamp = event.Check.Executed
 	return client.UpdateEvent(event)
 }
+
+// StreamEvents follows the events of a namespace, or of every namespace if it
+// is empty, that match the selectors of the options. The function is called
+// with every event as it is processed by the backend, until the context is
+// canceled, the function returns an error or the stream ends.
+func (client *RestClient) StreamEvents(ctx context.Context, namespace string, options *ListOptions, fn func(*corev2.Event) error) error {
+	// The stream is long-lived, so it must outlive the timeout of the client
+	client.resty.SetTimeout(0)
+	defer client.resty.SetTimeout(client.config.Timeout())
+
+	request := client.R().
+		SetContext(ctx).
+		SetDoNotParseResponse(true).
+		SetHeader("Accept", "text/event-stream")
+	ApplyListOptions(request, options)
+
+	res, err := request.Get(EventStreamPath(namespace))
+	if err != nil {
+		return err
+	}
+	body := res.RawBody()
+	defer body.Close()
+
+	if res.StatusCode() >= 400 {
+		data, _ := io.ReadAll(body)
+		apiErr := APIError{Message: fmt.Sprintf("the API returned: %s", res.Status())}
+		_ = json.Unmarshal(data, &apiErr)
+		return apiErr
+	}
+
+	err = readServerSentEvents(body, func(name string, data []byte) error {
+		if name == "error" {
+			var apiErr APIError
+			if err := json.Unmarshal(data, &apiErr); err != nil {
+				return errors.New(string(data))
+			}
+			return apiErr
+		}
+		var wrapper types.Wrapper
+		if err := json.Unmarshal(data, &wrapper); err != nil {
+			return err
+		}
+		event, ok := wrapper.Value.(*corev2.Event)
+		if !ok {
+			return fmt.Errorf("unexpected streamed resource: %T", wrapper.Value)
+		}
+		return fn(event)
+	})
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// readServerSentEvents calls fn with the name and data of every server-sent
+// event read from r. Comments, such as keepalives, are skipped.
+func readServerSentEvents(r io.Reader, fn func(name string, data []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var name string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				if err := fn(name, []byte(strings.Join(data, "\n"))); err != nil {
+					return err
+				}
+			}
+			name, data = "", nil
+		case strings.HasPrefix(line, ":"):
+		case strings.HasPrefix(line, "event:"):
+			name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/cli/client/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamEvents(t *testing.T) {
+	testHandler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/core/v3/namespaces/dev/events/stream", r.URL.Path)
+		assert.Equal(t, "region == west", r.URL.Query().Get("labelSelector"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": keepalive\n\n")
+		for _, entity := range []string{"first", "second"} {
+			fmt.Fprintf(w, "event: update\ndata: {\"type\":\"Event\",\"api_version\":\"core/v2\",\"metadata\":{\"namespace\":\"dev\"},\"spec\":{\"entity\":{\"metadata\":{\"name\":%q}}}}\n\n", entity)
+		}
+		fmt.Fprint(w, "event: error\ndata: {\"message\":\"the stream fell behind\"}\n\n")
+	}
+	server := httptest.NewServer(http.HandlerFunc(testHandler))
+	defer server.Close()
+
+	mockConfig := &config.MockConfig{}
+	client := &RestClient{resty: resty.New(), config: mockConfig}
+	mockConfig.On("APIUrl").Return(server.URL)
+	mockConfig.On("Tokens").Return(&corev2.Tokens{})
+	mockConfig.On("APIKey").Return("")
+	mockConfig.On("Timeout").Return(15 * time.Second)
+
+	var entities []string
+	err := client.StreamEvents(context.Background(), "dev", &ListOptions{LabelSelector: "region == west"}, func(event *corev2.Event) error {
+		entities = append(entities, event.Entity.Name)
+		return nil
+	})
+	assert.EqualError(t, err, "the stream fell behind")
+	assert.Equal(t, []string{"first", "second"}, entities)
+
+	stop := errors.New("stop")
+	err = client.StreamEvents(context.Background(), "dev", &ListOptions{LabelSelector: "region == west"}, func(event *corev2.Event) error {
+		return stop
+	})
+	require.ErrorIs(t, err, stop)
+}
+
+func TestStreamEventsError(t *testing.T) {
+	testHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message":"request unauthorized","code":7}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(testHandler))
+	defer server.Close()
+
+	mockConfig := &config.MockConfig{}
+	client := &RestClient{resty: resty.New(), config: mockConfig}
+	mockConfig.On("APIUrl").Return(server.URL)
+	mockConfig.On("Tokens").Return(&corev2.Tokens{})
+	mockConfig.On("APIKey").Return("")
+	mockConfig.On("Timeout").Return(15 * time.Second)
+
+	err := client.StreamEvents(context.Background(), "", &ListOptions{}, func(event *corev2.Event) error {
+		return nil
+	})
+	assert.EqualError(t, err, "request unauthorized")
+}
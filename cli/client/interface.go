@@ -1,12 +1,18 @@
 package client
 
 import (
+	"context"
 	"net/http"
+	"net/url"
 
 	"github.com/go-resty/resty/v2"
 	corev2 "github.com/sensu/core/v2"
 	corev3 "github.com/sensu/core/v3"
 	"github.com/sensu/core/v3/types"
+	"github.com/sensu/sensu-go/backend/audit"
+	authenticationv2 "github.com/sensu/sensu-go/backend/authentication/v2"
+	pipelinev1 "github.com/sensu/sensu-go/backend/pipeline/v1"
+	quotav1 "github.com/sensu/sensu-go/backend/quota/v1"
 )
 
 // ListOptions represents the various options that can be used when listing
@@ -29,9 +35,11 @@ type APIClient interface {
 	APIKeyClient
 	AuthenticationAPIClient
 	AssetAPIClient
+	AuditAPIClient
 	CheckAPIClient
 	ClusterRoleAPIClient
 	ClusterRoleBindingAPIClient
+	DeadLetterAPIClient
 	EntityAPIClient
 	EventAPIClient
 	FilterAPIClient
@@ -41,6 +49,7 @@ type APIClient interface {
 	MutatorAPIClient
 	NamespaceAPIClient
 	PipelineAPIClient
+	QuotaAPIClient
 	RoleAPIClient
 	RoleBindingAPIClient
 	UserAPIClient
@@ -77,6 +86,7 @@ type GenericClient interface {
 // AuthenticationAPIClient client methods for authenticating
 type AuthenticationAPIClient interface {
 	CreateAccessToken(url string, userid string, secret string) (*corev2.Tokens, error)
+	CreateOIDCAccessToken(url string, req *authenticationv2.OIDCTokenRequest) (*corev2.Tokens, error)
 	TestCreds(userid string, secret string) error
 	Logout(token string) error
 	RefreshAccessToken(tokens *corev2.Tokens) (*corev2.Tokens, error)
@@ -89,6 +99,11 @@ type AssetAPIClient interface {
 	FetchAsset(string) (*corev2.Asset, error)
 }
 
+// AuditAPIClient client methods for the audit log
+type AuditAPIClient interface {
+	ListAuditRecords(namespace string, query url.Values, options *ListOptions) ([]*audit.Record, error)
+}
+
 // CheckAPIClient client methods for checks
 type CheckAPIClient interface {
 	CreateCheck(*corev2.CheckConfig) error
@@ -139,6 +154,10 @@ type EventAPIClient interface {
 	DeleteEvent(namespace, entity, check string) error
 	UpdateEvent(*corev2.Event) error
 	ResolveEvent(*corev2.Event) error
+
+	// StreamEvents calls fn with the events of the namespace as they are
+	// processed, until the context is canceled.
+	StreamEvents(ctx context.Context, namespace string, options *ListOptions, fn func(*corev2.Event) error) error
 }
 
 // HandlerAPIClient client methods for handlers
@@ -149,6 +168,13 @@ type HandlerAPIClient interface {
 	UpdateHandler(*corev2.Handler) error
 }
 
+// DeadLetterAPIClient client methods for the dead letters of the handlers
+type DeadLetterAPIClient interface {
+	ListDeadLetters(namespace string, options *ListOptions) ([]pipelinev1.DeadLetter, error)
+	ReplayDeadLetter(namespace, name string) error
+	DeleteDeadLetter(namespace, name string) error
+}
+
 // HealthAPIClient client methods for health api
 type HealthAPIClient interface {
 	Health() (*corev2.HealthResponse, error)
@@ -182,6 +208,13 @@ type NamespaceAPIClient interface {
 type PipelineAPIClient interface {
 	DeletePipeline(string, string) error
 	FetchPipeline(string) (*corev2.Pipeline, error)
+	TestPipeline(string, string, *corev2.Event, bool) (*pipelinev1.PipelineTrace, error)
+}
+
+// QuotaAPIClient client methods for the namespace quotas
+type QuotaAPIClient interface {
+	ListQuotas(namespace string, options *ListOptions) ([]quotav1.Quota, error)
+	FetchQuotaUsage(namespace, name string) (*quotav1.QuotaUsage, error)
 }
 
 // UserAPIClient client methods for users
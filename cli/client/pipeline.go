@@ -2,9 +2,11 @@ package client
 
 import (
 	"encoding/json"
+	"strconv"
 
 	corev2 "github.com/sensu/core/v2"
 	"github.com/sensu/core/v3/types"
+	pipelinev1 "github.com/sensu/sensu-go/backend/pipeline/v1"
 )
 
 // PipelinesPath is the api path for pipelines.
@@ -71,3 +73,30 @@ func (client *RestClient) CreatePipeline(pipeline *corev2.Pipeline) (err error)
 
 	return nil
 }
+
+// TestPipeline runs an event through a pipeline and returns the trace of its
+// filters, mutators and handlers. The handlers are only executed if execute
+// is true.
+func (client *RestClient) TestPipeline(namespace, name string, event *corev2.Event, execute bool) (*pipelinev1.PipelineTrace, error) {
+	bytes, err := json.Marshal(types.WrapResource(event))
+	if err != nil {
+		return nil, err
+	}
+
+	path := PipelinesPath(namespace, name, "test")
+	res, err := client.R().
+		SetQueryParam("execute", strconv.FormatBool(execute)).
+		SetBody(bytes).
+		Post(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode() >= 400 {
+		return nil, UnmarshalError(res)
+	}
+
+	var trace pipelinev1.PipelineTrace
+	err = json.Unmarshal(res.Body(), &trace)
+	return &trace, err
+}
@@ -0,0 +1,36 @@
+package client
+
+import (
+	"encoding/json"
+
+	quotav1 "github.com/sensu/sensu-go/backend/quota/v1"
+)
+
+// QuotasPath is the api path for the namespace quotas.
+var QuotasPath = createNSBasePath("quota", "v1", "quotas")
+
+// ListQuotas lists the quotas of a namespace.
+func (client *RestClient) ListQuotas(namespace string, options *ListOptions) ([]quotav1.Quota, error) {
+	quotas := []quotav1.Quota{}
+	if err := client.List(QuotasPath(namespace), &quotas, options, nil); err != nil {
+		return nil, err
+	}
+	return quotas, nil
+}
+
+// FetchQuotaUsage fetches the usage of the namespace resources against the
+// limits of a quota.
+func (client *RestClient) FetchQuotaUsage(namespace, name string) (*quotav1.QuotaUsage, error) {
+	res, err := client.R().Get(QuotasPath(namespace, name, "usage"))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode() >= 400 {
+		return nil, UnmarshalError(res)
+	}
+
+	var usage quotav1.QuotaUsage
+	err = json.Unmarshal(res.Body(), &usage)
+	return &usage, err
+}
@@ -0,0 +1,14 @@
+package testing
+
+import (
+	"net/url"
+
+	"github.com/sensu/sensu-go/backend/audit"
+	"github.com/sensu/sensu-go/cli/client"
+)
+
+// ListAuditRecords for use with mock lib
+func (c *MockClient) ListAuditRecords(namespace string, query url.Values, options *client.ListOptions) ([]*audit.Record, error) {
+	args := c.Called(namespace, query, options)
+	return args.Get(0).([]*audit.Record), args.Error(1)
+}
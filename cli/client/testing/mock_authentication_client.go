@@ -2,6 +2,7 @@ package testing
 
 import (
 	corev2 "github.com/sensu/core/v2"
+	authenticationv2 "github.com/sensu/sensu-go/backend/authentication/v2"
 )
 
 // CreateAccessToken for use with mock lib
@@ -10,6 +11,12 @@ func (c *MockClient) CreateAccessToken(url, u, p string) (*corev2.Tokens, error)
 	return args.Get(0).(*corev2.Tokens), args.Error(1)
 }
 
+// CreateOIDCAccessToken for use with mock lib
+func (c *MockClient) CreateOIDCAccessToken(url string, req *authenticationv2.OIDCTokenRequest) (*corev2.Tokens, error) {
+	args := c.Called(url, req)
+	return args.Get(0).(*corev2.Tokens), args.Error(1)
+}
+
 // TestCreds for use with mock lib
 func (c *MockClient) TestCreds(u, p string) error {
 	args := c.Called(u, p)
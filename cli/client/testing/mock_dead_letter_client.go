@@ -0,0 +1,22 @@
+package testing
+
+import (
+	pipelinev1 "github.com/sensu/sensu-go/backend/pipeline/v1"
+	"github.com/sensu/sensu-go/cli/client"
+)
+
+// ListDeadLetters for use with mock lib
+func (c *MockClient) ListDeadLetters(namespace string, options *client.ListOptions) ([]pipelinev1.DeadLetter, error) {
+	args := c.Called(namespace, options)
+	return args.Get(0).([]pipelinev1.DeadLetter), args.Error(1)
+}
+
+// ReplayDeadLetter for use with mock lib
+func (c *MockClient) ReplayDeadLetter(namespace, name string) error {
+	return c.Called(namespace, name).Error(0)
+}
+
+// DeleteDeadLetter for use with mock lib
+func (c *MockClient) DeleteDeadLetter(namespace, name string) error {
+	return c.Called(namespace, name).Error(0)
+}
@@ -1,7 +1,10 @@
 package testing
 
 import (
+	"context"
+
 	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/cli/client"
 )
 
 // FetchEvent for use with mock lib
@@ -27,3 +30,9 @@ func (c *MockClient) ResolveEvent(event *corev2.Event) error {
 	args := c.Called(event)
 	return args.Error(0)
 }
+
+// StreamEvents for use with mock lib
+func (c *MockClient) StreamEvents(ctx context.Context, namespace string, options *client.ListOptions, fn func(*corev2.Event) error) error {
+	args := c.Called(ctx, namespace, options, fn)
+	return args.Error(0)
+}
@@ -2,6 +2,7 @@ package testing
 
 import (
 	corev2 "github.com/sensu/core/v2"
+	pipelinev1 "github.com/sensu/sensu-go/backend/pipeline/v1"
 )
 
 // FetchPipeline for use with mock lib
@@ -27,3 +28,10 @@ func (c *MockClient) CreatePipeline(pipeline *corev2.Pipeline) error {
 	args := c.Called(pipeline)
 	return args.Error(0)
 }
+
+// TestPipeline for use with mock lib
+func (c *MockClient) TestPipeline(namespace, name string, event *corev2.Event, execute bool) (*pipelinev1.PipelineTrace, error) {
+	args := c.Called(namespace, name, event, execute)
+	trace, _ := args.Get(0).(*pipelinev1.PipelineTrace)
+	return trace, args.Error(1)
+}
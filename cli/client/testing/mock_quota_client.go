@@ -0,0 +1,18 @@
+package testing
+
+import (
+	quotav1 "github.com/sensu/sensu-go/backend/quota/v1"
+	"github.com/sensu/sensu-go/cli/client"
+)
+
+// ListQuotas for use with mock lib
+func (c *MockClient) ListQuotas(namespace string, options *client.ListOptions) ([]quotav1.Quota, error) {
+	args := c.Called(namespace, options)
+	return args.Get(0).([]quotav1.Quota), args.Error(1)
+}
+
+// FetchQuotaUsage for use with mock lib
+func (c *MockClient) FetchQuotaUsage(namespace, name string) (*quotav1.QuotaUsage, error) {
+	args := c.Called(namespace, name)
+	return args.Get(0).(*quotav1.QuotaUsage), args.Error(1)
+}
@@ -0,0 +1,178 @@
+package audit
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/sensu/sensu-go/backend/audit"
+	"github.com/sensu/sensu-go/cli"
+	"github.com/sensu/sensu-go/cli/client"
+	"github.com/sensu/sensu-go/cli/commands/flags"
+	"github.com/sensu/sensu-go/cli/commands/helpers"
+	"github.com/sensu/sensu-go/cli/elements/table"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagUser     = "user"
+	flagResource = "resource"
+	flagVerb     = "verb"
+	flagStart    = "start"
+	flagEnd      = "end"
+)
+
+// ListCommand defines new list audit records command
+func ListCommand(cli *cli.SensuCli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "list audit records, most recent first",
+		Example: `# list the records of the current namespace
+sensuctl audit list
+
+# list the deletions made by a user in every namespace since a date
+sensuctl audit list --all-namespaces --user alice --verb delete --start 2023-01-01T00:00:00Z`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				_ = cmd.Help()
+				return errors.New("invalid argument(s) received")
+			}
+			// Records are listed for every namespace, including cluster-wide
+			// resources, with an empty namespace
+			namespace := cli.Config.Namespace()
+			if ok, _ := cmd.Flags().GetBool(flags.AllNamespaces); ok {
+				namespace = ""
+			}
+
+			query := url.Values{}
+			for _, flag := range []string{flagUser, flagResource, flagVerb, flagStart, flagEnd} {
+				if value, _ := cmd.Flags().GetString(flag); value != "" {
+					query.Set(flag, value)
+				}
+			}
+
+			chunkSize, _ := cmd.Flags().GetInt(flags.ChunkSize)
+			opts := client.ListOptions{ChunkSize: chunkSize}
+
+			records, err := cli.Client.ListAuditRecords(namespace, query, &opts)
+			if err != nil {
+				return err
+			}
+
+			return helpers.Print(cmd, cli.Config.Format(), printToTable, nil, records)
+		},
+	}
+
+	cmd.Flags().String(flagUser, "", "only list the records of the given user")
+	cmd.Flags().String(flagResource, "", "only list the records of the given resource type, e.g. checks")
+	cmd.Flags().String(flagVerb, "", "only list the records of the given verb: create, update, patch, delete or mutate")
+	cmd.Flags().String(flagStart, "", "only list the records at or after the given RFC 3339 date or unix timestamp")
+	cmd.Flags().String(flagEnd, "", "only list the records before the given RFC 3339 date or unix timestamp")
+
+	helpers.AddFormatFlag(cmd.Flags())
+	helpers.AddAllNamespace(cmd.Flags())
+	helpers.AddChunkSizeFlag(cmd.Flags())
+
+	return cmd
+}
+
+func printToTable(results interface{}, writer io.Writer) {
+	table := table.New([]*table.Column{
+		{
+			Title:       "Time",
+			ColumnStyle: table.PrimaryTextStyle,
+			CellTransformer: func(data interface{}) string {
+				record, ok := data.(*audit.Record)
+				if !ok {
+					return cli.TypeError
+				}
+				return record.Time.Format(time.RFC3339)
+			},
+		},
+		{
+			Title: "User",
+			CellTransformer: func(data interface{}) string {
+				record, ok := data.(*audit.Record)
+				if !ok {
+					return cli.TypeError
+				}
+				return record.User
+			},
+		},
+		{
+			Title: "Verb",
+			CellTransformer: func(data interface{}) string {
+				record, ok := data.(*audit.Record)
+				if !ok {
+					return cli.TypeError
+				}
+				return record.Verb
+			},
+		},
+		{
+			Title: "Namespace",
+			CellTransformer: func(data interface{}) string {
+				record, ok := data.(*audit.Record)
+				if !ok {
+					return cli.TypeError
+				}
+				return record.Namespace
+			},
+		},
+		{
+			Title: "Resource",
+			CellTransformer: func(data interface{}) string {
+				record, ok := data.(*audit.Record)
+				if !ok {
+					return cli.TypeError
+				}
+				return record.Resource
+			},
+		},
+		{
+			Title: "Name",
+			CellTransformer: func(data interface{}) string {
+				record, ok := data.(*audit.Record)
+				if !ok {
+					return cli.TypeError
+				}
+				return record.Name
+			},
+		},
+		{
+			Title: "Outcome",
+			CellTransformer: func(data interface{}) string {
+				record, ok := data.(*audit.Record)
+				if !ok {
+					return cli.TypeError
+				}
+				return record.Outcome + " (" + strconv.Itoa(record.Status) + ")"
+			},
+		},
+		{
+			Title: "Source IP",
+			CellTransformer: func(data interface{}) string {
+				record, ok := data.(*audit.Record)
+				if !ok {
+					return cli.TypeError
+				}
+				return record.SourceIP
+			},
+		},
+		{
+			Title: "Request",
+			CellTransformer: func(data interface{}) string {
+				record, ok := data.(*audit.Record)
+				if !ok {
+					return cli.TypeError
+				}
+				return string(record.Request)
+			},
+		},
+	})
+
+	table.Render(writer, results)
+}
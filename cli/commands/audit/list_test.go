@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/sensu/sensu-go/backend/audit"
+	client "github.com/sensu/sensu-go/cli/client/testing"
+	"github.com/sensu/sensu-go/cli/commands/flags"
+	test "github.com/sensu/sensu-go/cli/commands/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func fixtureRecords() []*audit.Record {
+	return []*audit.Record{
+		{
+			Time:      time.Unix(1700000000, 0),
+			User:      "alice",
+			Namespace: "default",
+			Resource:  "checks",
+			Name:      "check-cpu",
+			Verb:      "update",
+			Request:   json.RawMessage(`{"interval":30}`),
+			Outcome:   audit.OutcomeSuccess,
+			Status:    201,
+		},
+	}
+}
+
+func TestListCommand(t *testing.T) {
+	cli := test.NewMockCLI()
+	config := cli.Config.(*client.MockConfig)
+	config.On("Format").Return("none")
+	client := cli.Client.(*client.MockClient)
+	client.On("ListAuditRecords", "default", url.Values{"user": {"alice"}, "verb": {"update"}}, mock.Anything).
+		Return(fixtureRecords(), nil)
+
+	cmd := ListCommand(cli)
+	require.NoError(t, cmd.Flags().Set(flagUser, "alice"))
+	require.NoError(t, cmd.Flags().Set(flagVerb, "update"))
+	out, err := test.RunCmd(cmd, []string{})
+	require.NoError(t, err)
+	assert.Contains(t, out, "check-cpu")
+	assert.Contains(t, out, `{"interval":30}`)
+	assert.Contains(t, out, "success (201)")
+}
+
+func TestListCommandAllNamespacesJSON(t *testing.T) {
+	cli := test.NewMockCLI()
+	config := cli.Config.(*client.MockConfig)
+	config.On("Format").Return("none")
+	client := cli.Client.(*client.MockClient)
+	client.On("ListAuditRecords", "", url.Values{}, mock.Anything).Return(fixtureRecords(), nil)
+
+	cmd := ListCommand(cli)
+	require.NoError(t, cmd.Flags().Set(flags.Format, "json"))
+	require.NoError(t, cmd.Flags().Set(flags.AllNamespaces, "true"))
+	out, err := test.RunCmd(cmd, []string{})
+	require.NoError(t, err)
+
+	var records []*audit.Record
+	require.NoError(t, json.Unmarshal([]byte(out), &records))
+	require.Len(t, records, 1)
+	assert.Equal(t, "alice", records[0].User)
+}
+
+func TestListCommandError(t *testing.T) {
+	cli := test.NewMockCLI()
+	client := cli.Client.(*client.MockClient)
+	client.On("ListAuditRecords", mock.Anything, mock.Anything, mock.Anything).
+		Return([]*audit.Record(nil), errors.New("error"))
+
+	cmd := ListCommand(cli)
+	_, err := test.RunCmd(cmd, []string{})
+	assert.Error(t, err)
+}
+
+func TestListCommandArgs(t *testing.T) {
+	cli := test.NewMockCLI()
+	cmd := ListCommand(cli)
+	_, err := test.RunCmd(cmd, []string{"foo"})
+	assert.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/sensu/sensu-go/cli"
 	"github.com/sensu/sensu-go/cli/commands/apikey"
 	"github.com/sensu/sensu-go/cli/commands/asset"
+	"github.com/sensu/sensu-go/cli/commands/audit"
 	"github.com/sensu/sensu-go/cli/commands/check"
 	"github.com/sensu/sensu-go/cli/commands/clusterrole"
 	"github.com/sensu/sensu-go/cli/commands/clusterrolebinding"
@@ -22,6 +23,7 @@ import (
 	"github.com/sensu/sensu-go/cli/commands/filter"
 	"github.com/sensu/sensu-go/cli/commands/handler"
 	"github.com/sensu/sensu-go/cli/commands/hook"
+	"github.com/sensu/sensu-go/cli/commands/login"
 	"github.com/sensu/sensu-go/cli/commands/logout"
 	"github.com/sensu/sensu-go/cli/commands/mutator"
 	"github.com/sensu/sensu-go/cli/commands/namespace"
@@ -40,11 +42,13 @@ func AddCommands(rootCmd *cobra.Command, cli *cli.SensuCli) {
 		configure.Command(cli),
 		completion.Command(rootCmd),
 		env.Command(cli),
+		login.Command(cli),
 		logout.Command(cli),
 
 		// Management Commands
 		asset.HelpCommand(cli),
 		apikey.HelpCommand(cli),
+		audit.HelpCommand(cli),
 		check.HelpCommand(cli),
 		config.HelpCommand(cli),
 		clusterrole.HelpCommand(cli),
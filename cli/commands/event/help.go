@@ -19,6 +19,7 @@ func HelpCommand(cli *cli.SensuCli) *cobra.Command {
 	cmd.AddCommand(InfoCommand(cli))
 	cmd.AddCommand(DeleteCommand(cli))
 	cmd.AddCommand(ResolveCommand(cli))
+	cmd.AddCommand(TailCommand(cli))
 
 	return cmd
 }
@@ -0,0 +1,95 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/cli"
+	"github.com/sensu/sensu-go/cli/client/config"
+	"github.com/sensu/sensu-go/cli/commands/flags"
+	"github.com/sensu/sensu-go/cli/commands/helpers"
+	"github.com/spf13/cobra"
+)
+
+// TailCommand defines new tail events command
+func TailCommand(cli *cli.SensuCli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "tail",
+		Short:        "follow events as they are processed",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				_ = cmd.Help()
+				return errors.New("invalid argument(s) received")
+			}
+			namespace := cli.Config.Namespace()
+			if ok, _ := cmd.Flags().GetBool(flags.AllNamespaces); ok {
+				namespace = corev2.NamespaceTypeAll
+			}
+
+			opts, err := helpers.ListOptionsFromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			flag := helpers.GetChangedStringValueViper(flags.Format, cmd.Flags())
+			format := cli.Config.Format()
+			if flag != "" {
+				format = flag
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			return cli.Client.StreamEvents(ctx, namespace, &opts, func(event *corev2.Event) error {
+				return printTailedEvent(format, event, cmd.OutOrStdout())
+			})
+		},
+	}
+
+	helpers.AddFormatFlag(cmd.Flags())
+	helpers.AddAllNamespace(cmd.Flags())
+	helpers.AddFieldSelectorFlag(cmd.Flags())
+	helpers.AddLabelSelectorFlag(cmd.Flags())
+
+	return cmd
+}
+
+// printTailedEvent prints an event of the stream. In the tabular format, every
+// event is summarized on a single line.
+func printTailedEvent(format string, event *corev2.Event, w io.Writer) error {
+	switch format {
+	case config.FormatJSON, config.FormatWrappedJSON:
+		return helpers.PrintResourceJSON(event, w)
+	case config.FormatYAML:
+		if _, err := fmt.Fprintln(w, "---"); err != nil {
+			return err
+		}
+		return helpers.PrintYAML(event, w)
+	}
+
+	var name, output string
+	var status uint32
+	if event.Entity != nil {
+		name = event.Entity.Namespace + "/" + event.Entity.Name
+	}
+	if event.HasCheck() {
+		name += "/" + event.Check.Name
+		status = event.Check.Status
+		output = strings.TrimSpace(event.Check.Output)
+		if i := strings.IndexByte(output, '\n'); i >= 0 {
+			output = output[:i]
+		}
+	} else if event.HasMetrics() {
+		output = fmt.Sprintf("%d metric points", len(event.Metrics.Points))
+	}
+	_, err := fmt.Fprintf(w, "%s  %s  status=%d  %s\n",
+		time.Unix(event.Timestamp, 0).Format(time.RFC3339), name, status, output)
+	return err
+}
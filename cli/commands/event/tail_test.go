@@ -0,0 +1,81 @@
+package event
+
+import (
+	"errors"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/cli/client"
+	clienttest "github.com/sensu/sensu-go/cli/client/testing"
+	"github.com/sensu/sensu-go/cli/commands/flags"
+	test "github.com/sensu/sensu-go/cli/commands/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTailCommand(t *testing.T) {
+	assert := assert.New(t)
+
+	cli := newConfiguredCLI()
+	cmd := TailCommand(cli)
+
+	assert.NotNil(cmd, "cmd should be returned")
+	assert.NotNil(cmd.RunE, "cmd should be able to be executed")
+	assert.Regexp("tail", cmd.Use)
+	assert.Regexp("events", cmd.Short)
+}
+
+func TestTailCommandRunEClosure(t *testing.T) {
+	cli := newConfiguredCLI()
+	mockClient := cli.Client.(*clienttest.MockClient)
+	mockClient.On("StreamEvents", mock.Anything, "", mock.MatchedBy(func(opts interface{}) bool {
+		return opts.(*client.ListOptions).LabelSelector == "region == west"
+	}), mock.Anything).Return(nil).Run(
+		func(args mock.Arguments) {
+			fn := args[3].(func(*corev2.Event) error)
+			event := corev2.FixtureEvent("entity1", "check1")
+			event.Check.Output = "CRITICAL\nmore details"
+			event.Check.Status = 2
+			require.NoError(t, fn(event))
+		},
+	)
+
+	cmd := TailCommand(cli)
+	require.NoError(t, cmd.Flags().Set(flags.Format, "tabular"))
+	require.NoError(t, cmd.Flags().Set(flags.AllNamespaces, "true"))
+	require.NoError(t, cmd.Flags().Set(flags.LabelSelector, "region == west"))
+	out, err := test.RunCmd(cmd, []string{})
+	require.NoError(t, err)
+	assert.Contains(t, out, "default/entity1/check1  status=2  CRITICAL\n")
+	assert.NotContains(t, out, "more details")
+}
+
+func TestTailCommandRunEClosureWithFormat(t *testing.T) {
+	cli := newConfiguredCLI()
+	mockClient := cli.Client.(*clienttest.MockClient)
+	mockClient.On("StreamEvents", mock.Anything, "default", mock.Anything, mock.Anything).Return(nil).Run(
+		func(args mock.Arguments) {
+			fn := args[3].(func(*corev2.Event) error)
+			require.NoError(t, fn(corev2.FixtureEvent("entity1", "check1")))
+		},
+	)
+
+	cmd := TailCommand(cli)
+	require.NoError(t, cmd.Flags().Set(flags.Format, "json"))
+	out, err := test.RunCmd(cmd, []string{})
+	require.NoError(t, err)
+	assert.Contains(t, out, `"type": "Event"`)
+	assert.Contains(t, out, "entity1")
+}
+
+func TestTailCommandRunEClosureWithErr(t *testing.T) {
+	cli := newConfiguredCLI()
+	mockClient := cli.Client.(*clienttest.MockClient)
+	mockClient.On("StreamEvents", mock.Anything, "default", mock.Anything, mock.Anything).Return(errors.New("fire"))
+
+	cmd := TailCommand(cli)
+	out, err := test.RunCmd(cmd, []string{})
+	assert.EqualError(t, err, "fire")
+	assert.Empty(t, out)
+}
@@ -0,0 +1,261 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	pipelinev1 "github.com/sensu/sensu-go/backend/pipeline/v1"
+	"github.com/sensu/sensu-go/cli"
+	"github.com/sensu/sensu-go/cli/client"
+	"github.com/sensu/sensu-go/cli/commands/flags"
+	"github.com/sensu/sensu-go/cli/commands/helpers"
+	"github.com/sensu/sensu-go/cli/elements/table"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagHandler = "handler"
+	flagAll     = "all"
+)
+
+// DLQCommand defines the parent of the commands that manage the dead letters
+// of the handlers: the events whose handler failed after all its retries.
+func DLQCommand(cli *cli.SensuCli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dlq",
+		Short: "Manage the dead letters of handlers",
+		RunE:  helpers.DefaultSubCommandRunE,
+	}
+
+	cmd.AddCommand(
+		DLQListCommand(cli),
+		DLQReplayCommand(cli),
+		DLQPurgeCommand(cli),
+	)
+
+	return cmd
+}
+
+// DLQListCommand defines the command that lists dead letters
+func DLQListCommand(cli *cli.SensuCli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "list",
+		Short:        "list the dead letters of handlers",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				_ = cmd.Help()
+				return errors.New("invalid argument(s) received")
+			}
+			namespace := cli.Config.Namespace()
+			if ok, _ := cmd.Flags().GetBool(flags.AllNamespaces); ok {
+				namespace = corev2.NamespaceTypeAll
+			}
+
+			letters, err := listDeadLetters(cli, cmd, namespace)
+			if err != nil {
+				return err
+			}
+			return helpers.Print(cmd, cli.Config.Format(), printDeadLettersToTable, nil, letters)
+		},
+	}
+
+	cmd.Flags().String(flagHandler, "", "only list the dead letters of the given handler")
+	helpers.AddFormatFlag(cmd.Flags())
+	helpers.AddAllNamespace(cmd.Flags())
+	helpers.AddFieldSelectorFlag(cmd.Flags())
+	helpers.AddChunkSizeFlag(cmd.Flags())
+
+	return cmd
+}
+
+// DLQReplayCommand defines the command that replays dead letters
+func DLQReplayCommand(cli *cli.SensuCli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "replay [ID...]",
+		Short:        "retry the handlers of dead letters, given IDs or --all",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			namespace := cli.Config.Namespace()
+			ids, err := deadLetterIDs(cli, cmd, namespace, args)
+			if err != nil {
+				return err
+			}
+			for _, id := range ids {
+				if err := cli.Client.ReplayDeadLetter(namespace, id); err != nil {
+					return fmt.Errorf("couldn't replay dead letter %s: %s", id, err)
+				}
+			}
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "Replayed %d dead letters\n", len(ids))
+			return err
+		},
+	}
+
+	cmd.Flags().Bool(flagAll, false, "replay all the dead letters of the namespace")
+	cmd.Flags().String(flagHandler, "", "with --all, only replay the dead letters of the given handler")
+
+	return cmd
+}
+
+// DLQPurgeCommand defines the command that deletes dead letters
+func DLQPurgeCommand(cli *cli.SensuCli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "purge [ID...]",
+		Short:        "delete dead letters without replaying them, given IDs or --all",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			namespace := cli.Config.Namespace()
+			ids, err := deadLetterIDs(cli, cmd, namespace, args)
+			if err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				_, err := fmt.Fprintln(cmd.OutOrStdout(), "No dead letters")
+				return err
+			}
+
+			if skipConfirm, _ := cmd.Flags().GetBool("skip-confirm"); !skipConfirm {
+				confirm := &helpers.ConfirmDestructiveOp{
+					Type: "dead letters of namespace",
+					Op:   fmt.Sprintf("purge %d", len(ids)),
+				}
+				if ok, _ := confirm.Ask(namespace); !ok {
+					fmt.Fprintln(cmd.OutOrStdout(), "Canceled")
+					return nil
+				}
+			}
+
+			for _, id := range ids {
+				if err := cli.Client.DeleteDeadLetter(namespace, id); err != nil {
+					return fmt.Errorf("couldn't delete dead letter %s: %s", id, err)
+				}
+			}
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "Purged %d dead letters\n", len(ids))
+			return err
+		},
+	}
+
+	cmd.Flags().Bool(flagAll, false, "purge all the dead letters of the namespace")
+	cmd.Flags().String(flagHandler, "", "with --all, only purge the dead letters of the given handler")
+	cmd.Flags().Bool("skip-confirm", false, "skip interactive confirmation prompt")
+
+	return cmd
+}
+
+// listDeadLetters lists the dead letters of the namespace, restricted to the
+// handler given by the --handler flag, if any.
+func listDeadLetters(cli *cli.SensuCli, cmd *cobra.Command, namespace string) ([]pipelinev1.DeadLetter, error) {
+	opts := client.ListOptions{}
+	if cmd.Flags().Lookup(flags.FieldSelector) != nil {
+		opts.FieldSelector, _ = cmd.Flags().GetString(flags.FieldSelector)
+		opts.ChunkSize, _ = cmd.Flags().GetInt(flags.ChunkSize)
+	}
+	if handler, _ := cmd.Flags().GetString(flagHandler); handler != "" {
+		selector := fmt.Sprintf("dead_letter.handler == %s", handler)
+		if opts.FieldSelector != "" {
+			selector = opts.FieldSelector + " && " + selector
+		}
+		opts.FieldSelector = selector
+	}
+	return cli.Client.ListDeadLetters(namespace, &opts)
+}
+
+// deadLetterIDs returns the dead letters given as arguments, or all the dead
+// letters of the namespace with --all.
+func deadLetterIDs(cli *cli.SensuCli, cmd *cobra.Command, namespace string, args []string) ([]string, error) {
+	all, _ := cmd.Flags().GetBool(flagAll)
+	if all == (len(args) > 0) {
+		_ = cmd.Help()
+		return nil, errors.New("either dead letter IDs or --all must be given")
+	}
+	if !all {
+		return args, nil
+	}
+	letters, err := listDeadLetters(cli, cmd, namespace)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(letters))
+	for _, letter := range letters {
+		ids = append(ids, letter.Metadata.Name)
+	}
+	return ids, nil
+}
+
+func printDeadLettersToTable(results interface{}, writer io.Writer) {
+	table := table.New([]*table.Column{
+		{
+			Title:       "ID",
+			ColumnStyle: table.PrimaryTextStyle,
+			CellTransformer: func(data interface{}) string {
+				letter, ok := data.(pipelinev1.DeadLetter)
+				if !ok {
+					return cli.TypeError
+				}
+				return letter.Metadata.Name
+			},
+		},
+		{
+			Title: "Handler",
+			CellTransformer: func(data interface{}) string {
+				letter, ok := data.(pipelinev1.DeadLetter)
+				if !ok {
+					return cli.TypeError
+				}
+				return letter.Handler
+			},
+		},
+		{
+			Title: "Event",
+			CellTransformer: func(data interface{}) string {
+				letter, ok := data.(pipelinev1.DeadLetter)
+				if !ok {
+					return cli.TypeError
+				}
+				if letter.Event == nil || letter.Event.Entity == nil {
+					return ""
+				}
+				if !letter.Event.HasCheck() {
+					return letter.Event.Entity.Name
+				}
+				return letter.Event.Entity.Name + "/" + letter.Event.Check.Name
+			},
+		},
+		{
+			Title: "Attempts",
+			CellTransformer: func(data interface{}) string {
+				letter, ok := data.(pipelinev1.DeadLetter)
+				if !ok {
+					return cli.TypeError
+				}
+				return strconv.FormatUint(uint64(letter.Attempts), 10)
+			},
+		},
+		{
+			Title: "Failed At",
+			CellTransformer: func(data interface{}) string {
+				letter, ok := data.(pipelinev1.DeadLetter)
+				if !ok {
+					return cli.TypeError
+				}
+				return time.Unix(letter.FailedAt, 0).Format(time.RFC3339)
+			},
+		},
+		{
+			Title: "Error",
+			CellTransformer: func(data interface{}) string {
+				letter, ok := data.(pipelinev1.DeadLetter)
+				if !ok {
+					return cli.TypeError
+				}
+				return letter.Error
+			},
+		},
+	})
+
+	table.Render(writer, results)
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	pipelinev1 "github.com/sensu/sensu-go/backend/pipeline/v1"
+	clientpkg "github.com/sensu/sensu-go/cli/client"
+	client "github.com/sensu/sensu-go/cli/client/testing"
+	"github.com/sensu/sensu-go/cli/commands/flags"
+	test "github.com/sensu/sensu-go/cli/commands/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func fixtureDeadLetters() []pipelinev1.DeadLetter {
+	return []pipelinev1.DeadLetter{
+		{
+			Metadata: corev2.ObjectMeta{Name: "one", Namespace: "default"},
+			Handler:  "slack",
+			Event:    corev2.FixtureEvent("entity1", "check1"),
+			Attempts: 3,
+			Error:    "pipe handler exited with status 2",
+		},
+		{
+			Metadata: corev2.ObjectMeta{Name: "two", Namespace: "default"},
+			Handler:  "slack",
+			Event:    corev2.FixtureEvent("entity2", "check1"),
+			Attempts: 3,
+		},
+	}
+}
+
+func TestDLQListCommand(t *testing.T) {
+	cli := test.NewCLI()
+	client := cli.Client.(*client.MockClient)
+	client.On("ListDeadLetters", "default", mock.Anything).Return(fixtureDeadLetters(), nil)
+
+	cmd := DLQListCommand(cli)
+	require.NoError(t, cmd.Flags().Set(flags.Format, "tabular"))
+	require.NoError(t, cmd.Flags().Set(flagHandler, "slack"))
+	out, err := test.RunCmd(cmd, []string{})
+	require.NoError(t, err)
+	assert.Contains(t, out, "entity1/check1")
+	assert.Contains(t, out, "exited with status 2")
+
+	opts := client.Calls[0].Arguments.Get(1).(*clientpkg.ListOptions)
+	assert.Equal(t, "dead_letter.handler == slack", opts.FieldSelector)
+}
+
+func TestDLQReplayCommand(t *testing.T) {
+	cli := test.NewCLI()
+	client := cli.Client.(*client.MockClient)
+	client.On("ListDeadLetters", "default", mock.Anything).Return(fixtureDeadLetters(), nil)
+	client.On("ReplayDeadLetter", "default", mock.Anything).Return(nil)
+
+	cmd := DLQReplayCommand(cli)
+	_, err := test.RunCmd(cmd, []string{})
+	assert.Error(t, err)
+
+	out, err := test.RunCmd(cmd, []string{"one"})
+	require.NoError(t, err)
+	assert.Contains(t, out, "Replayed 1 dead letters")
+
+	require.NoError(t, cmd.Flags().Set(flagAll, "true"))
+	out, err = test.RunCmd(cmd, []string{})
+	require.NoError(t, err)
+	assert.Contains(t, out, "Replayed 2 dead letters")
+	client.AssertNumberOfCalls(t, "ReplayDeadLetter", 3)
+}
+
+func TestDLQPurgeCommand(t *testing.T) {
+	cli := test.NewCLI()
+	client := cli.Client.(*client.MockClient)
+	client.On("DeleteDeadLetter", "default", "one").Return(nil)
+	client.On("DeleteDeadLetter", "default", "two").Return(errors.New("error"))
+
+	cmd := DLQPurgeCommand(cli)
+	require.NoError(t, cmd.Flags().Set("skip-confirm", "true"))
+	out, err := test.RunCmd(cmd, []string{"one"})
+	require.NoError(t, err)
+	assert.Contains(t, out, "Purged 1 dead letters")
+
+	_, err = test.RunCmd(cmd, []string{"two"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,253 @@
+package login
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	authenticationv2 "github.com/sensu/sensu-go/backend/authentication/v2"
+	"github.com/sensu/sensu-go/cli"
+	"github.com/sensu/sensu-go/cli/commands/configure"
+	"github.com/sensu/sensu-go/cli/commands/hooks"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagOIDC      = "oidc"
+	flagProvider  = "provider"
+	flagURL       = "url"
+	flagPort      = "port"
+	flagNoBrowser = "no-browser"
+	flagTimeout   = "timeout"
+)
+
+// Command defines the login command
+func Command(cli *cli.SensuCli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "login",
+		Short:        "Log in to the sensu backend",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				_ = cmd.Help()
+				return errors.New("invalid argument(s) received")
+			}
+
+			apiURL, _ := cmd.Flags().GetString(flagURL)
+			if apiURL == "" {
+				return errors.New("the sensu backend url must be configured or specified with --url")
+			}
+			apiURL = strings.TrimSuffix(apiURL, "/")
+
+			useOIDC, _ := cmd.Flags().GetBool(flagOIDC)
+			if !useOIDC {
+				answers := &configure.Answers{URL: apiURL}
+				qs := []*survey.Question{
+					configure.AskForUsername(),
+					configure.AskForPassword(),
+				}
+				if err := survey.Ask(qs, answers); err != nil {
+					return err
+				}
+				if err := configure.SaveAPIURL(cli, answers); err != nil {
+					return err
+				}
+				return configure.Authenticate(cli, answers)
+			}
+
+			provider, _ := cmd.Flags().GetString(flagProvider)
+			port, _ := cmd.Flags().GetInt(flagPort)
+			noBrowser, _ := cmd.Flags().GetBool(flagNoBrowser)
+			timeout, _ := cmd.Flags().GetDuration(flagTimeout)
+
+			login := &oidcLogin{
+				cli:      cli,
+				apiURL:   apiURL,
+				provider: provider,
+				port:     port,
+				out:      cmd.OutOrStdout(),
+			}
+			open := openBrowser
+			if noBrowser {
+				open = func(string) error { return nil }
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+			if err := login.run(ctx, open); err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "You are logged in")
+			return nil
+		},
+		Annotations: map[string]string{
+			// We want to be able to log in regardless of whether the CLI has
+			// been configured.
+			hooks.ConfigurationRequirement: hooks.ConfigurationNotRequired,
+		},
+	}
+
+	_ = cmd.Flags().Bool(flagOIDC, false, "log in with an OIDC provider, in a web browser")
+	_ = cmd.Flags().String(flagProvider, "", "name of the OIDC provider, if several are configured")
+	_ = cmd.Flags().String(flagURL, cli.Config.APIUrl(), "the sensu backend url")
+	_ = cmd.Flags().Int(flagPort, 0, "port of the local OIDC redirect listener, random by default")
+	_ = cmd.Flags().Bool(flagNoBrowser, false, "print the OIDC login URL instead of opening a web browser")
+	_ = cmd.Flags().Duration(flagTimeout, 5*time.Minute, "time allowed to complete the OIDC login")
+
+	return cmd
+}
+
+// oidcLogin logs in with the authorization code flow: the user logs in with
+// the identity provider in a web browser, which is redirected to a loopback
+// listener with an authorization code. The code is then exchanged by the
+// backend for Sensu tokens.
+type oidcLogin struct {
+	cli      *cli.SensuCli
+	apiURL   string
+	provider string
+	port     int
+	out      io.Writer
+}
+
+type callbackResult struct {
+	code string
+	err  error
+}
+
+func (l *oidcLogin) run(ctx context.Context, open func(string) error) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", l.port))
+	if err != nil {
+		return fmt.Errorf("couldn't start the OIDC redirect listener: %s", err)
+	}
+	redirectURI := fmt.Sprintf("http://%s/callback", listener.Addr().String())
+
+	state, err := randomString()
+	if err != nil {
+		return err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return err
+	}
+	verifier, err := randomString()
+	if err != nil {
+		return err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	results := make(chan callbackResult, 1)
+	server := &http.Server{
+		Handler:           callbackHandler(state, results),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+
+	query := url.Values{}
+	query.Set("redirect_uri", redirectURI)
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if l.provider != "" {
+		query.Set("provider", l.provider)
+	}
+	authorizeURL := l.apiURL + "/auth/oidc/authorize?" + query.Encode()
+
+	fmt.Fprintf(l.out, "Open the following URL in a web browser to log in:\n\n%s\n\n", authorizeURL)
+	if err := open(authorizeURL); err != nil {
+		fmt.Fprintf(l.out, "Couldn't open a web browser: %s\n", err)
+	}
+
+	var result callbackResult
+	select {
+	case result = <-results:
+	case <-ctx.Done():
+		return errors.New("timed out waiting for the OIDC login")
+	}
+	if result.err != nil {
+		return result.err
+	}
+
+	tokens, err := l.cli.Client.CreateOIDCAccessToken(l.apiURL, &authenticationv2.OIDCTokenRequest{
+		Provider:     l.provider,
+		Code:         result.code,
+		RedirectURI:  redirectURI,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to authenticate with error: %s", err)
+	}
+
+	if err := l.cli.Config.SaveAPIUrl(l.apiURL); err != nil {
+		return fmt.Errorf("unable to write new configuration file with error: %s", err)
+	}
+	if err := l.cli.Config.SaveTokens(tokens); err != nil {
+		return fmt.Errorf("unable to write new configuration file with error: %s", err)
+	}
+	return nil
+}
+
+// callbackHandler handles the redirection of the identity provider, and
+// sends the authorization code to results.
+func callbackHandler(state string, results chan<- callbackResult) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		var result callbackResult
+		switch {
+		case query.Get("state") != state:
+			// not a response to our request, keep waiting
+			http.Error(w, "invalid state", http.StatusBadRequest)
+			return
+		case query.Get("error") != "":
+			result.err = fmt.Errorf("the identity provider returned an error: %s %s", query.Get("error"), query.Get("error_description"))
+		case query.Get("code") == "":
+			result.err = errors.New("the identity provider returned no authorization code")
+		default:
+			result.code = query.Get("code")
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if result.err != nil {
+			fmt.Fprintf(w, "<p>Login failed: %s</p>", html.EscapeString(result.err.Error()))
+		} else {
+			fmt.Fprint(w, "<p>You are logged in, you can close this window.</p>")
+		}
+		select {
+		case results <- result:
+		default:
+		}
+	})
+	return mux
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func openBrowser(u string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", u).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", u).Start()
+	default:
+		return exec.Command("xdg-open", u).Start()
+	}
+}
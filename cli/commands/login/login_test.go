@@ -0,0 +1,116 @@
+package login
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	authenticationv2 "github.com/sensu/sensu-go/backend/authentication/v2"
+	clienttest "github.com/sensu/sensu-go/cli/client/testing"
+	test "github.com/sensu/sensu-go/cli/commands/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// redirectTo simulates the browser and the identity provider: it follows the
+// authorize URL to the redirect URI, with the given callback parameters.
+func redirectTo(t *testing.T, params url.Values, authorizeURL *url.URL) func(string) error {
+	return func(u string) error {
+		parsed, err := url.Parse(u)
+		require.NoError(t, err)
+		*authorizeURL = *parsed
+		query := parsed.Query()
+		if params.Get("state") == "" {
+			params.Set("state", query.Get("state"))
+		}
+		resp, err := http.Get(query.Get("redirect_uri") + "?" + params.Encode())
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+}
+
+func TestOIDCLogin(t *testing.T) {
+	cli := test.NewMockCLI()
+	client := cli.Client.(*clienttest.MockClient)
+	config := cli.Config.(*clienttest.MockConfig)
+	tokens := corev2.FixtureTokens("access", "refresh")
+
+	var req *authenticationv2.OIDCTokenRequest
+	client.On("CreateOIDCAccessToken", "https://sensu.example.com", mock.Anything).
+		Run(func(args mock.Arguments) { req = args.Get(1).(*authenticationv2.OIDCTokenRequest) }).
+		Return(tokens, nil)
+	config.On("SaveAPIUrl", "https://sensu.example.com").Return(nil)
+	config.On("SaveTokens", tokens).Return(nil)
+
+	var authorizeURL url.URL
+	login := &oidcLogin{cli: cli, apiURL: "https://sensu.example.com", provider: "idp", out: new(bytes.Buffer)}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := login.run(ctx, redirectTo(t, url.Values{"code": {"c0de"}}, &authorizeURL))
+	require.NoError(t, err)
+	config.AssertCalled(t, "SaveAPIUrl", "https://sensu.example.com")
+	config.AssertCalled(t, "SaveTokens", tokens)
+
+	query := authorizeURL.Query()
+	assert.Equal(t, "/auth/oidc/authorize", authorizeURL.Path)
+	assert.Equal(t, "idp", query.Get("provider"))
+	assert.Equal(t, "c0de", req.Code)
+	assert.Equal(t, "idp", req.Provider)
+	assert.Equal(t, query.Get("redirect_uri"), req.RedirectURI)
+	assert.Equal(t, query.Get("nonce"), req.Nonce)
+	challenge := sha256.Sum256([]byte(req.CodeVerifier))
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(challenge[:]), query.Get("code_challenge"))
+}
+
+func TestOIDCLoginIdPError(t *testing.T) {
+	cli := test.NewMockCLI()
+	login := &oidcLogin{cli: cli, apiURL: "https://sensu.example.com", out: new(bytes.Buffer)}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var authorizeURL url.URL
+	err := login.run(ctx, redirectTo(t, url.Values{"error": {"access_denied"}}, &authorizeURL))
+	assert.ErrorContains(t, err, "access_denied")
+}
+
+func TestOIDCLoginExchangeError(t *testing.T) {
+	cli := test.NewMockCLI()
+	client := cli.Client.(*clienttest.MockClient)
+	client.On("CreateOIDCAccessToken", mock.Anything, mock.Anything).Return((*corev2.Tokens)(nil), errors.New("Unauthorized"))
+	login := &oidcLogin{cli: cli, apiURL: "https://sensu.example.com", out: new(bytes.Buffer)}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var authorizeURL url.URL
+	err := login.run(ctx, redirectTo(t, url.Values{"code": {"c0de"}}, &authorizeURL))
+	assert.ErrorContains(t, err, "Unauthorized")
+}
+
+func TestOIDCLoginTimeout(t *testing.T) {
+	cli := test.NewMockCLI()
+	login := &oidcLogin{cli: cli, apiURL: "https://sensu.example.com", out: new(bytes.Buffer)}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	// a callback with the wrong state is ignored
+	var authorizeURL url.URL
+	err := login.run(ctx, redirectTo(t, url.Values{"code": {"c0de"}, "state": {"forged"}}, &authorizeURL))
+	assert.ErrorContains(t, err, "timed out")
+}
+
+func TestLoginNoURL(t *testing.T) {
+	cli := test.NewMockCLI()
+	config := cli.Config.(*clienttest.MockConfig)
+	config.On("APIUrl").Return("")
+	cmd := Command(cli)
+
+	_, err := test.RunCmd(cmd, []string{"--oidc"})
+	assert.Error(t, err)
+}
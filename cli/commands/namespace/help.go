@@ -19,6 +19,7 @@ func HelpCommand(cli *cli.SensuCli) *cobra.Command {
 		CreateCommand(cli),
 		DeleteCommand(cli),
 		ListCommand(cli),
+		QuotaCommand(cli),
 	)
 
 	return cmd
@@ -0,0 +1,120 @@
+package namespace
+
+import (
+	"errors"
+	"io"
+	"strconv"
+
+	quotav1 "github.com/sensu/sensu-go/backend/quota/v1"
+	"github.com/sensu/sensu-go/cli"
+	"github.com/sensu/sensu-go/cli/client"
+	"github.com/sensu/sensu-go/cli/commands/helpers"
+	"github.com/sensu/sensu-go/cli/elements/table"
+
+	"github.com/spf13/cobra"
+)
+
+// quotaUsageRow is the usage of a resource against the limit of a quota.
+type quotaUsageRow struct {
+	quota string
+	*quotav1.ResourceUsage
+}
+
+// QuotaCommand defines *namespace quota* command
+func QuotaCommand(cli *cli.SensuCli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "quota [NAMESPACE]",
+		Short:        "show the usage of the quotas of a namespace",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				_ = cmd.Help()
+				return errors.New("invalid argument(s) received")
+			}
+			namespace := cli.Config.Namespace()
+			if len(args) == 1 {
+				namespace = args[0]
+			}
+
+			quotas, err := cli.Client.ListQuotas(namespace, &client.ListOptions{})
+			if err != nil {
+				return err
+			}
+			usages := make([]*quotav1.QuotaUsage, 0, len(quotas))
+			for _, quota := range quotas {
+				usage, err := cli.Client.FetchQuotaUsage(namespace, quota.Metadata.Name)
+				if err != nil {
+					return err
+				}
+				usages = append(usages, usage)
+			}
+			return helpers.Print(cmd, cli.Config.Format(), printQuotaUsagesToTable, nil, usages)
+		},
+	}
+
+	helpers.AddFormatFlag(cmd.Flags())
+
+	return cmd
+}
+
+func printQuotaUsagesToTable(results interface{}, writer io.Writer) {
+	usages, ok := results.([]*quotav1.QuotaUsage)
+	if !ok {
+		return
+	}
+	rows := []quotaUsageRow{}
+	for _, usage := range usages {
+		for _, resource := range usage.Resources {
+			rows = append(rows, quotaUsageRow{quota: usage.Quota, ResourceUsage: resource})
+		}
+	}
+
+	table := table.New([]*table.Column{
+		{
+			Title:       "Quota",
+			ColumnStyle: table.PrimaryTextStyle,
+			CellTransformer: func(data interface{}) string {
+				row, ok := data.(quotaUsageRow)
+				if !ok {
+					return cli.TypeError
+				}
+				return row.quota
+			},
+		},
+		{
+			Title: "Resource",
+			CellTransformer: func(data interface{}) string {
+				row, ok := data.(quotaUsageRow)
+				if !ok {
+					return cli.TypeError
+				}
+				return row.Resource
+			},
+		},
+		{
+			Title: "Used",
+			CellTransformer: func(data interface{}) string {
+				row, ok := data.(quotaUsageRow)
+				if !ok {
+					return cli.TypeError
+				}
+				return strconv.FormatUint(row.Used, 10)
+			},
+		},
+		{
+			Title: "Limit",
+			CellTransformer: func(data interface{}) string {
+				row, ok := data.(quotaUsageRow)
+				if !ok {
+					return cli.TypeError
+				}
+				if row.Limit == 0 {
+					return "unlimited"
+				}
+				return strconv.FormatUint(row.Limit, 10)
+			},
+		},
+	})
+
+	table.Render(writer, rows)
+}
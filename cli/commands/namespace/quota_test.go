@@ -0,0 +1,58 @@
+package namespace
+
+import (
+	"errors"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	quotav1 "github.com/sensu/sensu-go/backend/quota/v1"
+	client "github.com/sensu/sensu-go/cli/client/testing"
+	"github.com/sensu/sensu-go/cli/commands/flags"
+	test "github.com/sensu/sensu-go/cli/commands/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaCommand(t *testing.T) {
+	cli := test.NewCLI()
+	client := cli.Client.(*client.MockClient)
+	quotas := []quotav1.Quota{
+		{Metadata: corev2.ObjectMeta{Name: "team-a", Namespace: "dev"}, Entities: 100},
+	}
+	client.On("ListQuotas", "dev", mock.Anything).Return(quotas, nil)
+	client.On("FetchQuotaUsage", "dev", "team-a").Return(&quotav1.QuotaUsage{
+		Quota:     "team-a",
+		Namespace: "dev",
+		Resources: []*quotav1.ResourceUsage{
+			{Resource: quotav1.ResourceEntities, Used: 42, Limit: 100},
+			{Resource: quotav1.ResourceChecks, Used: 7},
+		},
+	}, nil)
+
+	cmd := QuotaCommand(cli)
+	require.NoError(t, cmd.Flags().Set(flags.Format, "tabular"))
+	out, err := test.RunCmd(cmd, []string{"dev"})
+	require.NoError(t, err)
+	assert.Contains(t, out, "team-a")
+	assert.Regexp(t, `entities\s+42\s+100`, out)
+	assert.Regexp(t, `checks\s+7\s+unlimited`, out)
+
+	require.NoError(t, cmd.Flags().Set(flags.Format, "json"))
+	out, err = test.RunCmd(cmd, []string{"dev"})
+	require.NoError(t, err)
+	assert.Contains(t, out, `"quota": "team-a"`)
+}
+
+func TestQuotaCommandErrors(t *testing.T) {
+	cli := test.NewCLI()
+	client := cli.Client.(*client.MockClient)
+	client.On("ListQuotas", "default", mock.Anything).Return([]quotav1.Quota{}, errors.New("error"))
+
+	cmd := QuotaCommand(cli)
+	_, err := test.RunCmd(cmd, []string{"one", "two"})
+	assert.Error(t, err)
+
+	_, err = test.RunCmd(cmd, []string{})
+	assert.Error(t, err)
+}
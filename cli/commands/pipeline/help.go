@@ -18,6 +18,7 @@ func HelpCommand(cli *cli.SensuCli) *cobra.Command {
 	cmd.AddCommand(ListCommand(cli))
 	cmd.AddCommand(InfoCommand(cli))
 	cmd.AddCommand(DeleteCommand(cli))
+	cmd.AddCommand(TestCommand(cli))
 
 	return cmd
 }
@@ -0,0 +1,210 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/core/v3/types"
+	pipelinev1 "github.com/sensu/sensu-go/backend/pipeline/v1"
+	"github.com/sensu/sensu-go/cli"
+	"github.com/sensu/sensu-go/cli/client/config"
+	"github.com/sensu/sensu-go/cli/commands/helpers"
+	"github.com/sensu/sensu-go/cli/elements/list"
+	"github.com/spf13/cobra"
+)
+
+// TestCommand defines the command that runs an event through a pipeline
+func TestCommand(cli *cli.SensuCli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test [PIPELINE]",
+		Short: "run an event from file or stdin through a pipeline and show the trace",
+		Long: `Run an event through a pipeline and show, for each workflow, the filters
+that allowed or denied it, the mutator output and the handler that would run.
+Handlers are only executed with --execute. The name legacy-pipeline tests
+the handlers of the event check.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				_ = cmd.Help()
+				return errors.New("invalid argument(s) received")
+			}
+
+			eventPath, _ := cmd.Flags().GetString("file")
+			var in io.Reader = cmd.InOrStdin()
+			if len(eventPath) > 0 {
+				f, err := os.Open(eventPath)
+				if err != nil {
+					return err
+				}
+				defer func() { _ = f.Close() }()
+				in = f
+			}
+			event, err := readEvent(in)
+			if err != nil {
+				return err
+			}
+
+			execute, _ := cmd.Flags().GetBool("execute")
+			namespace := cli.Config.Namespace()
+			trace, err := cli.Client.TestPipeline(namespace, args[0], event, execute)
+			if err != nil {
+				return err
+			}
+
+			// Determine the format to use to output the data
+			format := helpers.GetChangedStringValueViper("format", cmd.Flags())
+			if format == "" {
+				format = cli.Config.Format()
+			}
+			switch format {
+			case config.FormatJSON:
+				return helpers.PrintJSON(trace, cmd.OutOrStdout())
+			case config.FormatYAML:
+				return helpers.PrintYAML(trace, cmd.OutOrStdout())
+			default:
+				return printTraceToList(trace, cmd.OutOrStdout())
+			}
+		},
+	}
+
+	cmd.Flags().StringP("file", "f", "", "event file, in JSON, wrapped or not")
+	cmd.Flags().Bool("execute", false, "execute the handlers instead of only reporting what they would run")
+	helpers.AddFormatFlag(cmd.Flags())
+
+	return cmd
+}
+
+// readEvent reads an event, either wrapped, as exported by sensuctl dump, or
+// not, as produced by agents.
+func readEvent(r io.Reader) (*corev2.Event, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var wrapper types.Wrapper
+	if err := json.Unmarshal(body, &wrapper); err == nil {
+		if event, ok := wrapper.Value.(*corev2.Event); ok {
+			return event, nil
+		}
+	}
+	var event corev2.Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("invalid event: %s", err)
+	}
+	return &event, nil
+}
+
+func printTraceToList(trace *pipelinev1.PipelineTrace, writer io.Writer) error {
+	cfg := &list.Config{
+		Title: trace.Pipeline,
+		Rows: []*list.Row{
+			{
+				Label: "Handlers Executed",
+				Value: fmt.Sprint(trace.Executed),
+			},
+			{
+				Label: "Workflows",
+				Value: "",
+			},
+		},
+	}
+
+	for _, workflow := range trace.Workflows {
+		cfg.Rows = append(cfg.Rows, &list.Row{
+			Label: fmt.Sprintf("  %s", workflow.Name),
+			Value: "",
+		})
+
+		for _, filter := range workflow.Filters {
+			cfg.Rows = append(cfg.Rows, &list.Row{
+				Label: "    Filter",
+				Value: fmt.Sprintf("%s: %s", filter.Reference.ResourceID(), filterResult(filter)),
+			})
+		}
+		if workflow.Filtered {
+			cfg.Rows = append(cfg.Rows, &list.Row{
+				Label: "    Result",
+				Value: "event filtered",
+			})
+			continue
+		}
+
+		if mutator := workflow.Mutator; mutator != nil {
+			cfg.Rows = append(cfg.Rows, &list.Row{
+				Label: "    Mutator",
+				Value: mutator.Reference.ResourceID(),
+			})
+			if mutator.Error != "" {
+				cfg.Rows = append(cfg.Rows, &list.Row{
+					Label: "    Mutator Error",
+					Value: mutator.Error,
+				})
+			} else {
+				cfg.Rows = append(cfg.Rows, &list.Row{
+					Label: "    Mutator Output",
+					Value: mutator.Output,
+				})
+			}
+		}
+
+		if handler := workflow.Handler; handler != nil {
+			cfg.Rows = append(cfg.Rows, &list.Row{
+				Label: "    Handler",
+				Value: handler.Reference.ResourceID(),
+			})
+			cfg.Rows = append(cfg.Rows, handlerRows("      ", handler)...)
+			for _, member := range handler.Members {
+				cfg.Rows = append(cfg.Rows, &list.Row{
+					Label: "      Member",
+					Value: member.Reference.Name,
+				})
+				cfg.Rows = append(cfg.Rows, handlerRows("        ", member)...)
+			}
+		}
+	}
+
+	if trace.Error != "" {
+		cfg.Rows = append(cfg.Rows, &list.Row{
+			Label: "Error",
+			Value: trace.Error,
+		})
+	}
+
+	return list.Print(writer, cfg)
+}
+
+func filterResult(filter *pipelinev1.FilterTrace) string {
+	if filter.Error != "" {
+		return "error: " + filter.Error
+	}
+	result := "allowed"
+	if filter.Denied {
+		result = "denied"
+	}
+	if filter.Reason != "" {
+		result = fmt.Sprintf("%s (%s)", result, filter.Reason)
+	}
+	return result
+}
+
+func handlerRows(indent string, handler *pipelinev1.HandlerTrace) []*list.Row {
+	var rows []*list.Row
+	if handler.Type != "" {
+		rows = append(rows, &list.Row{Label: indent + "Type", Value: handler.Type})
+	}
+	if handler.Command != "" {
+		rows = append(rows, &list.Row{Label: indent + "Command", Value: handler.Command})
+	}
+	if handler.Address != "" {
+		rows = append(rows, &list.Row{Label: indent + "Address", Value: handler.Address})
+	}
+	rows = append(rows, &list.Row{Label: indent + "Executed", Value: fmt.Sprint(handler.Executed)})
+	if handler.Error != "" {
+		rows = append(rows, &list.Row{Label: indent + "Error", Value: handler.Error})
+	}
+	return rows
+}
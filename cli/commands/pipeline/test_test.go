@@ -0,0 +1,134 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/core/v3/types"
+	pipelinev1 "github.com/sensu/sensu-go/backend/pipeline/v1"
+	client "github.com/sensu/sensu-go/cli/client/testing"
+	test "github.com/sensu/sensu-go/cli/commands/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func writeEventFile(t *testing.T, v interface{}) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "event.json")
+	require.NoError(t, os.WriteFile(path, b, 0600))
+	return path
+}
+
+func fixtureTrace() *pipelinev1.PipelineTrace {
+	return &pipelinev1.PipelineTrace{
+		Pipeline: "foo",
+		Workflows: []*pipelinev1.WorkflowTrace{
+			{
+				Name: "incidents",
+				Filters: []*pipelinev1.FilterTrace{
+					{
+						Reference: &corev2.ResourceReference{APIVersion: "core/v2", Type: "EventFilter", Name: "production"},
+						Reason:    "all the expressions match",
+					},
+				},
+				Mutator: &pipelinev1.MutatorTrace{
+					Reference: &corev2.ResourceReference{APIVersion: "core/v2", Type: "Mutator", Name: "json"},
+					Output:    `{"check":{}}`,
+				},
+				Handler: &pipelinev1.HandlerTrace{
+					Reference: &corev2.ResourceReference{APIVersion: "core/v2", Type: "Handler", Name: "slack"},
+					Type:      "pipe",
+					Command:   "sensu-slack-handler",
+				},
+			},
+			{
+				Name:     "metrics",
+				Filtered: true,
+				Filters: []*pipelinev1.FilterTrace{
+					{
+						Reference: &corev2.ResourceReference{APIVersion: "core/v2", Type: "EventFilter", Name: "has_metrics"},
+						Denied:    true,
+						Reason:    "denied by the has_metrics filter",
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestTestCommand(t *testing.T) {
+	cli := test.NewMockCLI()
+	cmd := TestCommand(cli)
+
+	assert.NotNil(t, cmd, "cmd should be returned")
+	assert.NotNil(t, cmd.RunE, "cmd should be able to be executed")
+	assert.Regexp(t, "test", cmd.Use)
+	assert.Regexp(t, "pipeline", cmd.Short)
+}
+
+func TestTestCommandRunMissingArgs(t *testing.T) {
+	cli := test.NewMockCLI()
+	cmd := TestCommand(cli)
+	out, err := test.RunCmd(cmd, []string{})
+	require.Error(t, err)
+	assert.Contains(t, out, "Usage")
+}
+
+func TestTestCommandRunEClosureWithTable(t *testing.T) {
+	cli := test.NewMockCLI()
+	cli.Client.(*client.MockClient).
+		On("TestPipeline", "default", "foo", mock.Anything, false).
+		Return(fixtureTrace(), nil)
+	cli.Config.(*client.MockConfig).On("Format").Return("tabular")
+
+	cmd := TestCommand(cli)
+	require.NoError(t, cmd.Flags().Set("file", writeEventFile(t, corev2.FixtureEvent("entity1", "check1"))))
+	require.NoError(t, cmd.Flags().Set("format", "tabular"))
+
+	out, err := test.RunCmd(cmd, []string{"foo"})
+	require.NoError(t, err)
+	assert.Contains(t, out, "allowed (all the expressions match)")
+	assert.Contains(t, out, "denied (denied by the has_metrics filter)")
+	assert.Contains(t, out, "sensu-slack-handler")
+	assert.Contains(t, out, "event filtered")
+}
+
+func TestTestCommandRunEClosureWrappedEventAndExecute(t *testing.T) {
+	cli := test.NewMockCLI()
+	isEvent := mock.MatchedBy(func(event *corev2.Event) bool {
+		return event.Check.Name == "check1"
+	})
+	cli.Client.(*client.MockClient).
+		On("TestPipeline", "default", "foo", isEvent, true).
+		Return(fixtureTrace(), nil)
+	cli.Config.(*client.MockConfig).On("Format").Return("json")
+
+	cmd := TestCommand(cli)
+	event := types.WrapResource(corev2.FixtureEvent("entity1", "check1"))
+	require.NoError(t, cmd.Flags().Set("file", writeEventFile(t, event)))
+	require.NoError(t, cmd.Flags().Set("execute", "true"))
+
+	out, err := test.RunCmd(cmd, []string{"foo"})
+	require.NoError(t, err)
+	assert.Contains(t, out, `"pipeline": "foo"`)
+}
+
+func TestTestCommandRunEClosureWithErr(t *testing.T) {
+	cli := test.NewMockCLI()
+	cli.Client.(*client.MockClient).
+		On("TestPipeline", "default", "foo", mock.Anything, false).
+		Return(nil, errors.New("not found"))
+
+	cmd := TestCommand(cli)
+	require.NoError(t, cmd.Flags().Set("file", writeEventFile(t, corev2.FixtureEvent("entity1", "check1"))))
+
+	_, err := test.RunCmd(cmd, []string{"foo"})
+	assert.EqualError(t, err, "not found")
+}
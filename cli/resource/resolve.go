@@ -10,6 +10,11 @@ import (
 	corev3 "github.com/sensu/core/v3"
 	apitools "github.com/sensu/sensu-api-tools"
 	"github.com/sensu/core/v3/types"
+	pipelinev1 "github.com/sensu/sensu-go/backend/pipeline/v1"
+	aggregatev1 "github.com/sensu/sensu-go/backend/aggregate/v1"
+	quotav1 "github.com/sensu/sensu-go/backend/quota/v1"
+	authenticationv2 "github.com/sensu/sensu-go/backend/authentication/v2"
+	secretsv1 "github.com/sensu/sensu-go/backend/secrets/v1"
 )
 
 var (
@@ -21,6 +26,10 @@ var (
 		&corev2.User{},
 		&corev2.APIKey{},
 		&corev2.TessenConfig{},
+		&secretsv1.Env{},
+		&secretsv1.File{},
+		&authenticationv2.OIDC{},
+		&authenticationv2.LDAP{},
 		&corev2.Asset{},
 		&corev2.CheckConfig{},
 		&corev2.Entity{},
@@ -33,6 +42,10 @@ var (
 		&corev2.Role{},
 		&corev2.RoleBinding{},
 		&corev2.Silenced{},
+		&secretsv1.Secret{},
+		&pipelinev1.HTTPHandler{},
+		&aggregatev1.Aggregate{},
+		&quotav1.Quota{},
 	}
 
 	// synonyms provides user-friendly resource synonyms like checks, entities
@@ -45,6 +58,10 @@ func init() {
 	}
 	synonyms["namespace"] = All[0]
 	synonyms["namespaces"] = All[0]
+	// The secrets and authentication providers share an RBAC name per API
+	// group, they must be referred to by their fully qualified type
+	delete(synonyms, secretsv1.ProvidersResource)
+	delete(synonyms, authenticationv2.ProvidersResource)
 }
 
 type lifter interface {
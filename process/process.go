@@ -2,10 +2,22 @@ package process
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
 
 	corev2 "github.com/sensu/core/v2"
 )
 
+// DefaultMaxProcesses is the default maximum number of processes reported by
+// a Getter created with NewGetter.
+const DefaultMaxProcesses = 500
+
+// ProcessesAnnotation is the entity annotation holding the details of the
+// reported processes, as a JSON list of Info.
+const ProcessesAnnotation = "sensu.io/processes"
+
 // A Getter is responsible for getting the process info of an agent.
 type Getter interface {
 	Get(context.Context) ([]*corev2.Process, error)
@@ -18,3 +30,143 @@ type NoopProcessGetter struct{}
 func (NoopProcessGetter) Get(ctx context.Context) ([]*corev2.Process, error) {
 	return ([]*corev2.Process)(nil), nil
 }
+
+// A Lister is a Getter that also reports the details of the processes.
+type Lister interface {
+	Getter
+	List(context.Context) ([]Info, error)
+}
+
+// Info contains detailed information about a single process. Only the
+// process name is carried by corev2.Process, the remaining fields are
+// reported in the ProcessesAnnotation of the entity.
+type Info struct {
+	// PID is the process identifier.
+	PID int32 `json:"pid"`
+
+	// PPID is the parent process identifier.
+	PPID int32 `json:"ppid"`
+
+	// Name is the executable name of the process.
+	Name string `json:"name"`
+
+	// Cmdline is the full command line of the process.
+	Cmdline string `json:"cmdline"`
+
+	// User is the name of the user owning the process, or its numeric UID if
+	// it could not be resolved.
+	User string `json:"user"`
+
+	// State is the single letter process state (R, S, D, Z, T...).
+	State string `json:"state"`
+
+	// CPUPercent is the average CPU usage of the process over its lifetime.
+	CPUPercent float64 `json:"cpu_percent"`
+
+	// RSS is the resident set size of the process, in bytes.
+	RSS uint64 `json:"rss"`
+}
+
+// Filter determines which processes are reported. A process is reported if
+// its name or command line matches at least one Include pattern (or Include
+// is empty), and matches none of the Exclude patterns.
+type Filter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// NewFilter compiles the include and exclude regular expressions into a
+// Filter.
+func NewFilter(include, exclude []string) (*Filter, error) {
+	var f Filter
+	for _, pattern := range include {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid process include pattern %q: %s", pattern, err)
+		}
+		f.include = append(f.include, re)
+	}
+	for _, pattern := range exclude {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid process exclude pattern %q: %s", pattern, err)
+		}
+		f.exclude = append(f.exclude, re)
+	}
+	return &f, nil
+}
+
+// Match returns true if the process should be reported.
+func (f *Filter) Match(info Info) bool {
+	if f == nil {
+		return true
+	}
+	for _, re := range f.exclude {
+		if re.MatchString(info.Name) || re.MatchString(info.Cmdline) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, re := range f.include {
+		if re.MatchString(info.Name) || re.MatchString(info.Cmdline) {
+			return true
+		}
+	}
+	return false
+}
+
+// Config configures the Getter returned by NewGetter.
+type Config struct {
+	// Include is a list of regular expressions; when non-empty, only matching
+	// processes are reported.
+	Include []string
+
+	// Exclude is a list of regular expressions; matching processes are never
+	// reported.
+	Exclude []string
+
+	// MaxProcesses caps the number of processes reported. Zero means
+	// DefaultMaxProcesses.
+	MaxProcesses int
+}
+
+// limit filters and caps a list of processes. When the cap is exceeded, the
+// processes using the most memory are kept.
+func limit(infos []Info, filter *Filter, max int) []Info {
+	result := make([]Info, 0, len(infos))
+	for _, info := range infos {
+		if filter.Match(info) {
+			result = append(result, info)
+		}
+	}
+	if max > 0 && len(result) > max {
+		sort.SliceStable(result, func(i, j int) bool {
+			return result[i].RSS > result[j].RSS
+		})
+		result = result[:max]
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].PID < result[j].PID
+	})
+	return result
+}
+
+// ToCoreProcesses converts the processes to their corev2 representation.
+func ToCoreProcesses(infos []Info) []*corev2.Process {
+	processes := make([]*corev2.Process, 0, len(infos))
+	for _, info := range infos {
+		processes = append(processes, &corev2.Process{Name: info.Name})
+	}
+	return processes
+}
+
+// Annotation returns the value of the ProcessesAnnotation for the processes.
+func Annotation(infos []Info) (string, error) {
+	b, err := json.Marshal(infos)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
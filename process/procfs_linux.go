@@ -0,0 +1,206 @@
+//go:build linux
+// +build linux
+
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	corev2 "github.com/sensu/core/v2"
+)
+
+// clockTicks is the number of clock ticks per second used by the kernel to
+// report process times (USER_HZ). It is 100 on every supported architecture.
+const clockTicks = 100
+
+// DefaultProcRoot is the mount point of the proc filesystem.
+const DefaultProcRoot = "/proc"
+
+// ProcfsGetter gets process information by reading the proc filesystem.
+type ProcfsGetter struct {
+	// Root is the mount point of the proc filesystem. Defaults to
+	// DefaultProcRoot.
+	Root string
+
+	// Filter selects the processes that are reported. A nil Filter reports
+	// every process.
+	Filter *Filter
+
+	// MaxProcesses caps the number of processes reported. Zero means no cap.
+	MaxProcesses int
+
+	usersMu sync.Mutex
+	users   map[string]string
+}
+
+// NewGetter returns a Getter backed by the proc filesystem.
+func NewGetter(config Config) (Getter, error) {
+	filter, err := NewFilter(config.Include, config.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	max := config.MaxProcesses
+	if max == 0 {
+		max = DefaultMaxProcesses
+	}
+	return &ProcfsGetter{Filter: filter, MaxProcesses: max}, nil
+}
+
+// Get returns the filtered list of processes running on the system.
+func (p *ProcfsGetter) Get(ctx context.Context) ([]*corev2.Process, error) {
+	infos, err := p.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ToCoreProcesses(infos), nil
+}
+
+// List returns detailed information about the filtered list of processes
+// running on the system.
+func (p *ProcfsGetter) List(ctx context.Context) ([]Info, error) {
+	root := p.root()
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list processes: %s", err)
+	}
+	uptime, err := readUptime(root)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list processes: %s", err)
+	}
+	pageSize := uint64(os.Getpagesize())
+
+	infos := make([]Info, 0, len(entries))
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		pid, err := strconv.ParseInt(entry.Name(), 10, 32)
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		info, err := p.readProcess(filepath.Join(root, entry.Name()), uptime, pageSize)
+		if err != nil {
+			// the process has most likely exited since the directory was
+			// listed
+			continue
+		}
+		info.PID = int32(pid)
+		infos = append(infos, info)
+	}
+
+	return limit(infos, p.Filter, p.MaxProcesses), nil
+}
+
+func (p *ProcfsGetter) root() string {
+	if p.Root == "" {
+		return DefaultProcRoot
+	}
+	return p.Root
+}
+
+func (p *ProcfsGetter) readProcess(dir string, uptime float64, pageSize uint64) (Info, error) {
+	var info Info
+
+	stat, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return info, err
+	}
+	// The process name is enclosed in parentheses and may itself contain
+	// spaces or parentheses, so split around the last closing one.
+	start := bytes.IndexByte(stat, '(')
+	end := bytes.LastIndexByte(stat, ')')
+	if start < 0 || end < start {
+		return info, fmt.Errorf("malformed stat file: %s", dir)
+	}
+	info.Name = string(stat[start+1 : end])
+	fields := strings.Fields(string(stat[end+1:]))
+	if len(fields) < 22 {
+		return info, fmt.Errorf("malformed stat file: %s", dir)
+	}
+	info.State = fields[0]
+	ppid, _ := strconv.ParseInt(fields[1], 10, 32)
+	info.PPID = int32(ppid)
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+	starttime, _ := strconv.ParseUint(fields[19], 10, 64)
+	rss, _ := strconv.ParseInt(fields[21], 10, 64)
+	if rss > 0 {
+		info.RSS = uint64(rss) * pageSize
+	}
+	if elapsed := uptime - float64(starttime)/clockTicks; elapsed > 0 {
+		info.CPUPercent = 100 * (float64(utime+stime) / clockTicks) / elapsed
+	}
+
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		info.Cmdline = strings.TrimSpace(string(bytes.ReplaceAll(cmdline, []byte{0}, []byte{' '})))
+	}
+
+	if uid, err := readUID(filepath.Join(dir, "status")); err == nil {
+		info.User = p.lookupUser(uid)
+	}
+
+	return info, nil
+}
+
+func (p *ProcfsGetter) lookupUser(uid string) string {
+	p.usersMu.Lock()
+	defer p.usersMu.Unlock()
+	if name, ok := p.users[uid]; ok {
+		return name
+	}
+	if p.users == nil {
+		p.users = make(map[string]string)
+	}
+	name := uid
+	if u, err := user.LookupId(uid); err == nil {
+		name = u.Username
+	}
+	p.users[uid] = name
+	return name
+}
+
+func readUptime(root string) (float64, error) {
+	b, err := os.ReadFile(filepath.Join(root, "uptime"))
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(b))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("malformed uptime file")
+	}
+	return strconv.ParseFloat(fields[0], 64)
+}
+
+func readUID(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "Uid:") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "Uid:"))
+		if len(fields) == 0 {
+			break
+		}
+		// the real user ID is the first field
+		return fields[0], nil
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no uid found in %s", path)
+}
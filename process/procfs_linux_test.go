@@ -0,0 +1,106 @@
+//go:build linux
+// +build linux
+
+package process
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFakeProcess(t *testing.T, root, pid, stat, cmdline, uid string) {
+	t.Helper()
+	dir := filepath.Join(root, pid)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cmdline"), []byte(cmdline), 0644))
+	status := "Name:\tfoo\nUid:\t" + uid + "\t" + uid + "\t" + uid + "\t" + uid + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "status"), []byte(status), 0644))
+}
+
+func fakeProcRoot(t *testing.T) string {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "uptime"), []byte("200.00 100.00\n"), 0644))
+	// utime=5000 stime=5000 starttime=0: 100s of cpu over 200s
+	writeFakeProcess(t, root, "1",
+		"1 (init) S 0 1 1 0 -1 4194560 0 0 0 0 5000 5000 0 0 20 0 1 0 0 1000 10 0 0",
+		"/sbin/init\x00splash\x00", "0")
+	writeFakeProcess(t, root, "42",
+		"42 (nginx: (worker)) R 1 42 42 0 -1 4194560 0 0 0 0 0 0 0 0 20 0 1 0 10000 1000 100 0 0",
+		"nginx: worker process\x00", "4242424")
+	writeFakeProcess(t, root, "43",
+		"43 (sshd) S 1 43 43 0 -1 4194560 0 0 0 0 0 0 0 0 20 0 1 0 10000 1000 5 0 0",
+		"/usr/sbin/sshd\x00-D\x00", "0")
+	// non-process entries are ignored
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "sys"), 0755))
+	return root
+}
+
+func TestProcfsGetterList(t *testing.T) {
+	getter := &ProcfsGetter{Root: fakeProcRoot(t)}
+	infos, err := getter.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, infos, 3)
+
+	first := infos[0]
+	assert.Equal(t, int32(1), first.PID)
+	assert.Equal(t, int32(0), first.PPID)
+	assert.Equal(t, "init", first.Name)
+	assert.Equal(t, "/sbin/init splash", first.Cmdline)
+	assert.Equal(t, "S", first.State)
+	assert.Equal(t, "root", first.User)
+	assert.InDelta(t, 50.0, first.CPUPercent, 0.001)
+	assert.Equal(t, uint64(10*os.Getpagesize()), first.RSS)
+
+	nginx := infos[1]
+	assert.Equal(t, int32(42), nginx.PID)
+	assert.Equal(t, int32(1), nginx.PPID)
+	assert.Equal(t, "nginx: (worker)", nginx.Name)
+	assert.Equal(t, "R", nginx.State)
+	assert.Equal(t, "4242424", nginx.User)
+}
+
+func TestProcfsGetterFilter(t *testing.T) {
+	filter, err := NewFilter([]string{"^nginx", "sshd"}, []string{"sshd"})
+	require.NoError(t, err)
+	getter := &ProcfsGetter{Root: fakeProcRoot(t), Filter: filter}
+	processes, err := getter.Get(context.Background())
+	require.NoError(t, err)
+	require.Len(t, processes, 1)
+	assert.Equal(t, "nginx: (worker)", processes[0].Name)
+}
+
+func TestProcfsGetterMaxProcesses(t *testing.T) {
+	getter := &ProcfsGetter{Root: fakeProcRoot(t), MaxProcesses: 2}
+	infos, err := getter.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+	// the processes with the largest resident set are kept
+	assert.Equal(t, int32(1), infos[0].PID)
+	assert.Equal(t, int32(42), infos[1].PID)
+}
+
+func TestProcfsGetterSelf(t *testing.T) {
+	getter := &ProcfsGetter{}
+	infos, err := getter.List(context.Background())
+	require.NoError(t, err)
+	self := int32(os.Getpid())
+	for _, info := range infos {
+		if info.PID == self {
+			return
+		}
+	}
+	t.Fatal("current process not found")
+}
+
+func TestNewFilterInvalid(t *testing.T) {
+	_, err := NewFilter([]string{"("}, nil)
+	assert.Error(t, err)
+	_, err = NewGetter(Config{Exclude: []string{"("}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+// +build !linux
+
+package process
+
+// NewGetter returns a Getter for the current platform. Process information
+// is only collected on Linux; other platforms report no processes.
+func NewGetter(config Config) (Getter, error) {
+	if _, err := NewFilter(config.Include, config.Exclude); err != nil {
+		return nil, err
+	}
+	return NoopProcessGetter{}, nil
+}
@@ -45,3 +45,9 @@ func (s *MockStore) CountEvents(ctx context.Context, pred *store.SelectionPredic
 func (s *MockStore) EventStoreSupportsFiltering(ctx context.Context) bool {
 	return s.Called(ctx).Get(0).(bool)
 }
+
+// GetEventHistory ...
+func (s *MockStore) GetEventHistory(ctx context.Context, entityName, checkName string, pred *store.EventHistoryPredicate) ([]*corev2.Event, error) {
+	args := s.Called(ctx, entityName, checkName, pred)
+	return args.Get(0).([]*corev2.Event), args.Error(1)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	v2 "github.com/sensu/core/v2"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
 )
 
 func (s *MockStore) DeleteSilences(ctx context.Context, namespace string, silencedID []string) error {
@@ -40,3 +41,8 @@ func (s *MockStore) UpdateSilence(ctx context.Context, silenced *v2.Silenced) er
 	args := s.Called(ctx, silenced)
 	return args.Error(0)
 }
+
+func (s *MockStore) Watch(ctx context.Context, namespace string) <-chan []storev2.WatchEvent {
+	args := s.Called(ctx, namespace)
+	return args.Get(0).(<-chan []storev2.WatchEvent)
+}
@@ -45,6 +45,10 @@ func (v *V2MockStore) GetSilencesStore() storev2.SilencesStore {
 	return v.Called().Get(0).(storev2.SilencesStore)
 }
 
+func (v *V2MockStore) GetEventHistoryStore() store.EventHistoryStore {
+	return v.Called().Get(0).(store.EventHistoryStore)
+}
+
 type ConfigStore struct {
 	mock.Mock
 }
@@ -338,3 +342,7 @@ func (s *SilencesStore) GetSilencesByName(ctx context.Context, namespace string,
 func (s *SilencesStore) DeleteSilences(ctx context.Context, namespace string, names []string) error {
 	return s.Called(ctx, namespace, names).Error(0)
 }
+
+func (s *SilencesStore) Watch(ctx context.Context, namespace string) <-chan []storev2.WatchEvent {
+	return s.Called(ctx, namespace).Get(0).(<-chan []storev2.WatchEvent)
+}